@@ -3,15 +3,42 @@ package main
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/clients/database"
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/controllers"
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/notify"
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/pubsub"
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/repository"
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/router"
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/storage"
 	"github.com/larissasthefanny/plena-app/backend/internal/config"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
 )
 
+// netWorthSnapshotInterval is how often the current month's net worth
+// snapshot is refreshed; the last run of a month becomes its closing value.
+const netWorthSnapshotInterval = 6 * time.Hour
+
+// billStatusInterval is how often bills past their payable date are marked
+// overdue.
+const billStatusInterval = 24 * time.Hour
+
+// eventDispatchInterval is how often the outbox is polled for new domain
+// events.
+const eventDispatchInterval = 2 * time.Second
+
+// webhookDeliveryInterval is how often webhook deliveries due for a retry
+// are sent; new events are sent as soon as they are queued.
+const webhookDeliveryInterval = 15 * time.Second
+
+// idempotencyPurgeInterval is how often expired Idempotency-Key records are
+// deleted.
+const idempotencyPurgeInterval = time.Hour
+
 func main() {
 	cfg := config.Load()
 
@@ -31,16 +58,166 @@ func main() {
 	transactionRepo := repository.NewPostgresTransactionRepository(dbConnection)
 	userRepo := repository.NewPostgresUserRepository(dbConnection)
 	goalRepo := repository.NewPostgresGoalRepository(dbConnection)
+	attachmentRepo := repository.NewPostgresAttachmentRepository(dbConnection)
+	ruleRepo := repository.NewPostgresRuleRepository(dbConnection)
+	auditRepo := repository.NewPostgresAuditRepository(dbConnection)
+	budgetRepo := repository.NewPostgresBudgetRepository(dbConnection)
+	envelopeRepo := repository.NewPostgresEnvelopeRepository(dbConnection)
+	reportRepo := repository.NewPostgresReportRepository(dbConnection)
+	netWorthRepo := repository.NewPostgresNetWorthRepository(dbConnection)
+	portfolioRepo := repository.NewPostgresPortfolioRepository(dbConnection)
+	indexRepo := repository.NewPostgresIndexRepository(dbConnection)
+	debtRepo := repository.NewPostgresDebtRepository(dbConnection)
+	billRepo := repository.NewPostgresBillRepository(dbConnection)
+	notificationRepo := repository.NewPostgresNotificationRepository(dbConnection)
+	outboxRepo := repository.NewPostgresOutboxRepository(dbConnection)
+	webhookRepo := repository.NewPostgresWebhookRepository(dbConnection)
+	syncRepo := repository.NewPostgresSyncRepository(dbConnection)
+	idempotencyRepo := repository.NewPostgresIdempotencyRepository(dbConnection)
 
+	blobStorage, err := newBlobStorage(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Could not initialize attachment storage: %v", err)
+	}
+
+	eventBus := services.NewEventBus(outboxRepo)
+	auditService := services.NewAuditService(auditRepo)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo)
+	notificationService.SetAuditService(auditService)
+	vapidPublicKey, err := addNotificationChannels(notificationService, cfg.Notifications)
+	if err != nil {
+		log.Fatalf("Could not initialize notification channels: %v", err)
+	}
+	stopDispatch := notificationService.StartDispatchJob(time.Duration(cfg.Notifications.DispatchSeconds) * time.Second)
+	defer stopDispatch()
+	webhookService := services.NewWebhookService(webhookRepo)
+	webhookService.SetAuditService(auditService)
+	eventBus.Subscribe("webhooks", webhookService.HandleEvent)
+	stopWebhooks := webhookService.StartDeliveryJob(webhookDeliveryInterval)
+	defer stopWebhooks()
+	latestEventID, err := outboxRepo.LatestEventID()
+	if err != nil {
+		log.Fatalf("Could not read the outbox: %v", err)
+	}
+	streamHub := services.NewStreamHub(latestEventID)
+	eventChannel := pubsub.NewPostgresEventChannel(dbConnection, dbConfig.ConnString())
+	stopListening, err := eventChannel.Listen(streamHub.Broadcast, streamHub.Reset)
+	if err != nil {
+		log.Fatalf("Could not listen for events: %v", err)
+	}
+	defer stopListening()
+	streamHub.SetPublisher(eventChannel)
+	eventBus.Subscribe("stream", streamHub.HandleEvent, domain.StreamEventTypes...)
+	// Subscribers are all registered: the bus would mark events delivered
+	// to none of them otherwise.
+	stopEvents := eventBus.StartDispatcher(eventDispatchInterval)
+	defer stopEvents()
 	transactionService := services.NewTransactionService(transactionRepo)
+	transactionService.SetAuditService(auditService)
 	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
+	authService.SetPasswordPolicy(validation.PasswordPolicy{
+		MinLength:     cfg.Password.MinLength,
+		MaxBytes:      validation.DefaultPasswordPolicy.MaxBytes,
+		RequireLetter: cfg.Password.RequireLetter,
+		RequireDigit:  cfg.Password.RequireDigit,
+	})
+	indexService := services.NewIndexService(indexRepo)
+	if err := indexService.LoadDir(cfg.IndexDataDir); err != nil {
+		log.Printf("Could not load economic indexes from %s: %v", cfg.IndexDataDir, err)
+	}
 	goalService := services.NewGoalService(goalRepo)
+	goalService.SetIndexRepository(indexRepo)
+	goalService.SetAuditService(auditService)
+	goalService.SetNotificationService(notificationService)
+	attachmentService := services.NewAttachmentService(attachmentRepo, transactionRepo, blobStorage, cfg.Storage.MaxUploadBytes)
+	attachmentService.SetAuditService(auditService)
+	ruleService := services.NewRuleService(ruleRepo, transactionRepo)
+	ruleService.SetAuditService(auditService)
+	ruleService.SetTransactionService(transactionService)
+	transactionService.SetRuleService(ruleService)
+	suggestionService := services.NewCategorySuggestionService(transactionRepo)
+	transactionService.SetCategorySuggestionService(suggestionService)
+	budgetService := services.NewBudgetService(budgetRepo, reportRepo)
+	budgetService.SetAuditService(auditService)
+	budgetService.SetNotificationService(notificationService)
+	transactionService.SetBudgetService(budgetService)
+	envelopeService := services.NewEnvelopeService(envelopeRepo, transactionRepo, reportRepo)
+	envelopeService.SetAuditService(auditService)
+	forecastService := services.NewForecastService(transactionRepo, goalRepo)
+	reportService := services.NewReportService(reportRepo)
+	portfolioService := services.NewPortfolioService(portfolioRepo)
+	portfolioService.SetAuditService(auditService)
+	debtService := services.NewDebtService(debtRepo, transactionRepo)
+	debtService.SetAuditService(auditService)
+	billService := services.NewBillService(billRepo, transactionService)
+	billService.SetAuditService(auditService)
+	billService.SetNotificationService(notificationService)
+	stopBillStatus := billService.StartStatusJob(billStatusInterval)
+	defer stopBillStatus()
+	netWorthService := services.NewNetWorthService(netWorthRepo, transactionRepo, goalRepo, userRepo)
+	netWorthService.SetPortfolioService(portfolioService)
+	netWorthService.SetAuditService(auditService)
+	stopSnapshots := netWorthService.StartSnapshotJob(netWorthSnapshotInterval)
+	defer stopSnapshots()
+
+	trashService := services.NewTrashService(transactionRepo, goalRepo, time.Duration(cfg.Trash.RetentionDays)*24*time.Hour)
+	trashService.SetAttachmentService(attachmentService)
+	trashService.SetCategorySuggestionService(suggestionService)
+	trashService.SetAuditService(auditService)
+	stopPurge := trashService.StartPurgeJob(time.Duration(cfg.Trash.PurgeIntervalMin) * time.Minute)
+	defer stopPurge()
+	syncService := services.NewSyncService(syncRepo, transactionService, goalService)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo)
+	stopIdempotencyPurge := idempotencyService.StartPurgeJob(idempotencyPurgeInterval)
+	defer stopIdempotencyPurge()
 
 	transController := controllers.NewTransactionController(transactionService)
 	authController := controllers.NewAuthController(authService)
 	goalController := controllers.NewGoalController(goalService)
+	attachmentController := controllers.NewAttachmentController(attachmentService, cfg.Storage.MaxUploadBytes)
+	ruleController := controllers.NewRuleController(ruleService)
+	suggestionController := controllers.NewSuggestionController(suggestionService)
+	trashController := controllers.NewTrashController(trashService)
+	auditController := controllers.NewAuditController(auditService)
+	budgetController := controllers.NewBudgetController(budgetService)
+	envelopeController := controllers.NewEnvelopeController(envelopeService)
+	forecastController := controllers.NewForecastController(forecastService)
+	reportController := controllers.NewReportController(reportService)
+	netWorthController := controllers.NewNetWorthController(netWorthService)
+	portfolioController := controllers.NewPortfolioController(portfolioService)
+	debtController := controllers.NewDebtController(debtService)
+	billController := controllers.NewBillController(billService)
+	notificationController := controllers.NewNotificationController(notificationService, vapidPublicKey)
+	webhookController := controllers.NewWebhookController(webhookService)
+	streamController := controllers.NewStreamController(streamHub)
+	syncController := controllers.NewSyncController(syncService)
+	// Keyed requests are buffered to fingerprint them; leave room for the
+	// multipart framing around the largest attachment.
+	idempotencyMiddleware := controllers.NewIdempotencyMiddleware(idempotencyService, cfg.Storage.MaxUploadBytes+1<<20)
 
-	appRouter := router.NewRouter(transController, authController, goalController, cfg)
+	appRouter := router.NewRouter(router.Controllers{
+		Transaction:  transController,
+		Auth:         authController,
+		Goal:         goalController,
+		Attachment:   attachmentController,
+		Rule:         ruleController,
+		Suggestion:   suggestionController,
+		Trash:        trashController,
+		Audit:        auditController,
+		Budget:       budgetController,
+		Envelope:     envelopeController,
+		Forecast:     forecastController,
+		Report:       reportController,
+		NetWorth:     netWorthController,
+		Portfolio:    portfolioController,
+		Debt:         debtController,
+		Bill:         billController,
+		Notification: notificationController,
+		Webhook:      webhookController,
+		Stream:       streamController,
+		Sync:         syncController,
+		Idempotency:  idempotencyMiddleware,
+	}, cfg)
 	handler := appRouter.Setup()
 
 	log.Printf("Server starting on port %s...", cfg.Port)
@@ -48,3 +225,47 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+func newBlobStorage(cfg config.StorageConfig) (ports.BlobStorage, error) {
+	if cfg.Driver == "s3" {
+		return storage.NewS3BlobStorage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}), nil
+	}
+	return storage.NewLocalBlobStorage(cfg.LocalPath)
+}
+
+// addNotificationChannels enables email and, when a VAPID key is set, Web
+// Push. It returns the VAPID public key for the PWA.
+func addNotificationChannels(notifications *services.NotificationService, cfg config.NotificationConfig) (string, error) {
+	if cfg.EmailDriver == "smtp" {
+		notifications.AddChannel(notify.NewSMTPChannel(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.EmailFrom,
+		}))
+	} else {
+		capture, err := notify.NewCaptureChannel(cfg.CapturePath, cfg.EmailFrom)
+		if err != nil {
+			return "", err
+		}
+		notifications.AddChannel(capture)
+	}
+
+	if cfg.VAPIDPrivateKey == "" {
+		log.Println("VAPID_PRIVATE_KEY not set, Web Push notifications are disabled")
+		return "", nil
+	}
+	push, err := notify.NewWebPushChannel(notify.VAPIDConfig{PrivateKey: cfg.VAPIDPrivateKey, Subject: cfg.VAPIDSubject})
+	if err != nil {
+		return "", err
+	}
+	notifications.AddChannel(push)
+	return push.PublicKey(), nil
+}
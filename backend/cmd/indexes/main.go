@@ -0,0 +1,69 @@
+// Command indexes loads CDI, Selic and IPCA series into index_rates. Series
+// can be downloaded once from the Banco Central SGS (codes 12, 11 and 433)
+// and loaded without network access.
+//
+//	indexes -index cdi -file cdi.csv   load one series
+//	indexes -dir ./data/indexes        load cdi.csv, selic.csv and ipca.csv
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/clients/database"
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/repository"
+	"github.com/larissasthefanny/plena-app/backend/internal/config"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+func main() {
+	index := flag.String("index", "", "series to load from -file: cdi, selic or ipca")
+	file := flag.String("file", "", "CSV file holding the -index series")
+	dir := flag.String("dir", "", "directory holding cdi.csv, selic.csv and ipca.csv")
+	flag.Parse()
+
+	if (*file == "") == (*dir == "") || (*file != "" && *index == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	dbConnection, err := database.NewPostgresConnection(database.Config{
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		User:     cfg.DB.User,
+		Password: cfg.DB.Password,
+		DBName:   cfg.DB.Name,
+	})
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	defer dbConnection.Close()
+
+	indexService := services.NewIndexService(repository.NewPostgresIndexRepository(dbConnection))
+
+	if *dir != "" {
+		if err := indexService.LoadDir(*dir); err != nil {
+			dbConnection.Close()
+			log.Fatalf("Could not load indexes: %v", err)
+		}
+		log.Printf("Indexes loaded from %s", *dir)
+		return
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		dbConnection.Close()
+		log.Fatalf("Could not open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	count, err := indexService.Import(*index, f)
+	if err != nil {
+		dbConnection.Close()
+		log.Fatalf("Could not load %s: %v", *index, err)
+	}
+	log.Printf("Loaded %d %s rate(s)", count, *index)
+}
@@ -0,0 +1,67 @@
+// Command rollup maintains the monthly_category_totals table.
+//
+//	rollup -rebuild [-user N]   recompute the rollup from transactions
+//	rollup -check               report rows that disagree with transactions
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/clients/database"
+	"github.com/larissasthefanny/plena-app/backend/internal/adapters/repository"
+	"github.com/larissasthefanny/plena-app/backend/internal/config"
+)
+
+func main() {
+	rebuild := flag.Bool("rebuild", false, "recompute the rollup from the transactions table")
+	check := flag.Bool("check", false, "compare the rollup with the transactions table")
+	userID := flag.Int("user", 0, "limit -rebuild to one user (0 means everyone)")
+	flag.Parse()
+
+	if !*rebuild && !*check {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	dbConnection, err := database.NewPostgresConnection(database.Config{
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		User:     cfg.DB.User,
+		Password: cfg.DB.Password,
+		DBName:   cfg.DB.Name,
+	})
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	defer dbConnection.Close()
+
+	transactionRepo := repository.NewPostgresTransactionRepository(dbConnection)
+
+	if *rebuild {
+		if err := transactionRepo.RebuildMonthlyTotals(*userID); err != nil {
+			log.Fatalf("Could not rebuild monthly totals: %v", err)
+		}
+		log.Println("Monthly totals rebuilt")
+	}
+
+	if *check {
+		mismatches, err := transactionRepo.CheckMonthlyTotals()
+		if err != nil {
+			log.Fatalf("Could not check monthly totals: %v", err)
+		}
+		for _, m := range mismatches {
+			log.Printf("user %d %04d-%02d %s %q: expected %.2f (%d), rollup has %.2f (%d)",
+				m.UserID, m.Year, m.Month, m.Type, m.Category,
+				m.ExpectedTotal, m.ExpectedCount, m.RollupTotal, m.RollupCount)
+		}
+		if len(mismatches) > 0 {
+			dbConnection.Close()
+			log.Fatalf("%d monthly total(s) out of sync", len(mismatches))
+		}
+		log.Println("Monthly totals are consistent")
+	}
+}
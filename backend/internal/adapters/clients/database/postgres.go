@@ -15,11 +15,15 @@ type Config struct {
 	DBName   string
 }
 
-func NewPostgresConnection(cfg Config) (*sql.DB, error) {
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+// ConnString is the lib/pq connection string for cfg; LISTEN needs its own
+// connection outside the pool.
+func (cfg Config) ConnString() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+}
 
-	db, err := sql.Open("postgres", connStr)
+func NewPostgresConnection(cfg Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.ConnString())
 	if err != nil {
 		return nil, err
 	}
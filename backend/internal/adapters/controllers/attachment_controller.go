@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type AttachmentController struct {
+	attachmentService ports.AttachmentService
+	maxUploadSize     int64
+}
+
+func NewAttachmentController(attachmentService ports.AttachmentService, maxUploadSize int64) *AttachmentController {
+	return &AttachmentController{
+		attachmentService: attachmentService,
+		maxUploadSize:     maxUploadSize,
+	}
+}
+
+func (c *AttachmentController) Upload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	transactionID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	// Leave room for the multipart envelope around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, c.maxUploadSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, services.ErrAttachmentTooLarge)
+			return
+		}
+		writeError(w, invalidParam("file", "a file is required"))
+		return
+	}
+	defer file.Close()
+
+	attachment, err := c.attachmentService.Upload(r.Context(), userID, transactionID, header.Filename, file)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+func (c *AttachmentController) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	transactionID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	attachments, err := c.attachmentService.ListAttachments(userID, transactionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+func (c *AttachmentController) Download(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, false)
+}
+
+func (c *AttachmentController) Thumbnail(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, true)
+}
+
+func (c *AttachmentController) serve(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	attachment, body, err := c.attachmentService.Open(userID, id, thumbnail)
+	if err != nil {
+		// A row whose file is gone is as missing as one never stored.
+		if errors.Is(err, ports.ErrBlobNotFound) {
+			err = domain.ErrNotFound
+		}
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+
+	disposition := "attachment"
+	if thumbnail {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !thumbnail {
+		w.Header().Set("Content-Length", fmt.Sprint(attachment.Size))
+	}
+	io.Copy(w, body)
+}
+
+func (c *AttachmentController) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.attachmentService.DeleteAttachment(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Attachment deleted"}`))
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var auditEntities = map[string]bool{
+	domain.AuditEntityTransaction: true,
+	domain.AuditEntityGoal:        true,
+	domain.AuditEntityRule:        true,
+	domain.AuditEntityAttachment:  true,
+	domain.AuditEntityBudget:      true,
+	domain.AuditEntityEnvelope:    true,
+	domain.AuditEntityAsset:       true,
+	domain.AuditEntityHolding:     true,
+	domain.AuditEntityDebt:        true,
+	domain.AuditEntityBill:        true,
+	domain.AuditEntityWebhook:     true,
+
+	domain.AuditEntityNotificationSettings: true,
+}
+
+type AuditController struct {
+	auditService ports.AuditService
+}
+
+func NewAuditController(auditService ports.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+func (c *AuditController) History(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	entity := queryParams.Get("entity")
+	if !auditEntities[entity] {
+		writeError(w, invalidParam("entity", "unknown entity"))
+		return
+	}
+
+	id, err := strconv.Atoi(queryParams.Get("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	events, err := c.auditService.History(userID, entity, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if events == nil {
+		events = []domain.AuditEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
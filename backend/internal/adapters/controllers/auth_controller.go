@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -28,14 +29,14 @@ type AuthRequest struct {
 
 func (h *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 	var req AuthRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	token, err := h.authService.Register(req.Email, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
@@ -45,14 +46,14 @@ func (h *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 
 func (h *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 	var req AuthRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	token, err := h.authService.Login(req.Email, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, err)
 		return
 	}
 
@@ -73,31 +74,27 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			writeError(w, errUnauthorized)
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+			writeError(w, errUnauthorized)
 			return
 		}
 
 		tokenStr := parts[1]
-		claims := &Claims{}
 
 		jwtKey := []byte(os.Getenv("JWT_SECRET"))
 		if len(jwtKey) == 0 {
-			http.Error(w, "Server configuration error: JWT_SECRET not set", http.StatusInternalServerError)
+			writeError(w, errors.New("JWT_SECRET not set"))
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		claims, ok := parseToken(tokenStr, jwtKey)
+		if !ok {
+			writeError(w, errInvalidToken)
 			return
 		}
 
@@ -105,3 +102,29 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
+
+func parseToken(tokenStr string, jwtKey []byte) (*Claims, bool) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	return claims, true
+}
+
+// bearerUserID returns the user of the request's bearer token, for code
+// that runs before AuthMiddleware and must not reject the request itself.
+func bearerUserID(r *http.Request) (int, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	jwtKey := []byte(os.Getenv("JWT_SECRET"))
+	if len(parts) != 2 || parts[0] != "Bearer" || len(jwtKey) == 0 {
+		return 0, false
+	}
+	claims, ok := parseToken(parts[1], jwtKey)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
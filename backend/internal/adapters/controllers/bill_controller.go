@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type BillController struct {
+	billService ports.BillService
+}
+
+func NewBillController(billService ports.BillService) *BillController {
+	return &BillController{billService: billService}
+}
+
+type BillRequest struct {
+	Payee      string    `json:"payee"`
+	Amount     float64   `json:"amount"`
+	Estimated  bool      `json:"estimated"`
+	Category   string    `json:"category"`
+	Account    string    `json:"account"`
+	DueDate    time.Time `json:"due_date"`
+	Recurrence string    `json:"recurrence"`
+}
+
+func (req BillRequest) bill() domain.Bill {
+	return domain.Bill{
+		Payee:      req.Payee,
+		Amount:     req.Amount,
+		Estimated:  req.Estimated,
+		Category:   req.Category,
+		Account:    req.Account,
+		DueDate:    req.DueDate,
+		Recurrence: req.Recurrence,
+	}
+}
+
+func (c *BillController) CreateBill(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req BillRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	bill, err := c.billService.CreateBill(r.Context(), userID, req.bill())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bill)
+}
+
+// ListBills returns every bill, or those with ?status=open|paid|overdue.
+func (c *BillController) ListBills(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	bills, err := c.billService.ListBills(userID, r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bills)
+}
+
+func (c *BillController) UpdateBill(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req BillRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.billService.UpdateBill(r.Context(), userID, id, req.bill()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Bill updated"}`))
+}
+
+func (c *BillController) DeleteBill(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.billService.DeleteBill(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Bill deleted"}`))
+}
+
+// PayBillRequest may leave the amount out to pay the bill amount, and the
+// date out to pay today.
+type PayBillRequest struct {
+	Amount float64   `json:"amount"`
+	Date   time.Time `json:"date"`
+}
+
+func (c *BillController) MarkPaid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req PayBillRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	bill, err := c.billService.MarkPaid(r.Context(), userID, id, req.Amount, req.Date)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bill)
+}
+
+// Upcoming returns overdue bills and those payable in the next ?days=
+// (7 by default).
+func (c *BillController) Upcoming(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	days := 0
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d < 1 {
+			writeError(w, invalidParam("days", "days must be a positive number"))
+			return
+		}
+		days = d
+	}
+
+	upcoming, err := c.billService.Upcoming(userID, days)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upcoming)
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type BudgetController struct {
+	budgetService ports.BudgetService
+}
+
+func NewBudgetController(budgetService ports.BudgetService) *BudgetController {
+	return &BudgetController{budgetService: budgetService}
+}
+
+type BudgetRequest struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+	Month    int     `json:"month"`
+	Year     int     `json:"year"`
+	Rollover bool    `json:"rollover"`
+}
+
+func (req BudgetRequest) toDomain() domain.Budget {
+	return domain.Budget{
+		Category: req.Category,
+		Amount:   req.Amount,
+		Month:    req.Month,
+		Year:     req.Year,
+		Rollover: req.Rollover,
+	}
+}
+
+func (c *BudgetController) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req BudgetRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	budget, err := c.budgetService.CreateBudget(r.Context(), userID, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget)
+}
+
+func (c *BudgetController) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	budgets, err := c.budgetService.ListBudgets(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if budgets == nil {
+		budgets = []domain.Budget{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budgets)
+}
+
+// Report returns budgeted vs. spent vs. remaining for ?month=&year=
+// (defaults to the current month).
+func (c *BudgetController) Report(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	month, year, err := monthFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	report, err := c.budgetService.Report(userID, month, year)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (c *BudgetController) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req BudgetRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.budgetService.UpdateBudget(r.Context(), userID, id, req.toDomain()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Budget updated"}`))
+}
+
+func (c *BudgetController) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.budgetService.DeleteBudget(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Budget deleted"}`))
+}
+
+// monthFromQuery reads ?month=&year=, defaulting to the current month.
+func monthFromQuery(r *http.Request) (month, year int, err error) {
+	queryParams := r.URL.Query()
+	now := time.Now()
+	month, year = int(now.Month()), now.Year()
+
+	if monthStr := queryParams.Get("month"); monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			return 0, 0, invalidParam("month", "month must be between 1 and 12")
+		}
+		month = m
+	}
+	if yearStr := queryParams.Get("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil {
+			return 0, 0, invalidParam("year", "year must be a number")
+		}
+		year = y
+	}
+	return month, year, nil
+}
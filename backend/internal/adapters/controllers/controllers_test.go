@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,19 +14,20 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 )
 
 type MockTransactionService struct {
 	mock.Mock
 }
 
-func (m *MockTransactionService) CreateIncome(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error) {
-	args := m.Called(userID, amount, category, description, date)
+func (m *MockTransactionService) CreateIncome(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error) {
+	args := m.Called(userID, amount, category, description, account, date)
 	return args.Get(0).(domain.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) CreateExpense(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error) {
-	args := m.Called(userID, amount, category, description, date)
+func (m *MockTransactionService) CreateExpense(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error) {
+	args := m.Called(userID, amount, category, description, account, date)
 	return args.Get(0).(domain.Transaction), args.Error(1)
 }
 
@@ -34,21 +36,31 @@ func (m *MockTransactionService) ListTransactions(userID, month, year int) ([]do
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) ResetData(userID int) error {
+func (m *MockTransactionService) ResetData(ctx context.Context, userID int) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
-func (m *MockTransactionService) UpdateTransaction(userID, id int, amount float64, category, description string, date time.Time, typeStr string) error {
-	args := m.Called(userID, id, amount, category, description, date, typeStr)
-	return args.Error(0)
+func (m *MockTransactionService) GetTransaction(userID, id int) (domain.Transaction, error) {
+	args := m.Called(userID, id)
+	return args.Get(0).(domain.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) DeleteTransaction(userID, id int) error {
-	args := m.Called(userID, id)
+func (m *MockTransactionService) UpdateTransaction(ctx context.Context, userID, id, version int, amount float64, category, description, account string, date time.Time, typeStr string) (domain.Transaction, error) {
+	args := m.Called(userID, id, version, amount, category, description, account, date, typeStr)
+	return args.Get(0).(domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) DeleteTransaction(ctx context.Context, userID, id, version int) error {
+	args := m.Called(userID, id, version)
 	return args.Error(0)
 }
 
+func (m *MockTransactionService) ApplyBatch(ctx context.Context, userID int, mode string, ops []domain.BatchOperation) ([]domain.BatchResult, error) {
+	args := m.Called(userID, mode, ops)
+	return args.Get(0).([]domain.BatchResult), args.Error(1)
+}
+
 func TestCreateIncome_Controller_Success(t *testing.T) {
 	mockService := new(MockTransactionService)
 	controller := NewTransactionController(mockService)
@@ -64,11 +76,12 @@ func TestCreateIncome_Controller_Success(t *testing.T) {
 	req = req.WithContext(ctx)
 
 	w := httptest.NewRecorder()
-	mockService.On("CreateIncome", 1, 100.0, "Salary", "", mock.AnythingOfType("time.Time")).Return(domain.Transaction{ID: 1, Amount: 100}, nil)
+	mockService.On("CreateIncome", 1, 100.0, "Salary", "", "", mock.AnythingOfType("time.Time")).Return(domain.Transaction{ID: 1, Amount: 100, Version: 1}, nil)
 
 	controller.CreateIncome(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
 	mockService.AssertExpectations(t)
 }
 
@@ -84,6 +97,79 @@ func TestCreateIncome_Controller_Unauthorized(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestGetTransaction_Controller_ConditionalGet(t *testing.T) {
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+	mockService.On("GetTransaction", 1, 7).Return(domain.Transaction{ID: 7, Amount: 50, Version: 3}, nil)
+
+	req := httptest.NewRequest("GET", "/api/transactions/7", nil)
+	req.SetPathValue("id", "7")
+	req = req.WithContext(context.WithValue(req.Context(), UserIDKey, 1))
+	w := httptest.NewRecorder()
+	controller.GetTransaction(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	req.Header.Set("If-None-Match", `"3"`)
+	w = httptest.NewRecorder()
+	controller.GetTransaction(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestUpdateTransaction_Controller_IfMatch(t *testing.T) {
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	mockService.On("UpdateTransaction", 1, 7, 3, 80.0, "Mercado", "", "", date, "expense").Return(domain.Transaction{ID: 7, Version: 4}, nil)
+	mockService.On("UpdateTransaction", 1, 7, 2, 80.0, "Mercado", "", "", date, "expense").Return(domain.Transaction{}, ports.ErrVersionConflict)
+	mockService.On("UpdateTransaction", 1, 7, -1, 80.0, "Mercado", "", "", date, "expense").Return(domain.Transaction{}, ports.ErrVersionConflict)
+
+	update := func(ifMatch string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateTransactionRequest{Amount: 80, Category: "Mercado", Date: date, Type: "expense"})
+		req := httptest.NewRequest("PUT", "/api/transactions/7", bytes.NewBuffer(body))
+		req.SetPathValue("id", "7")
+		req.Header.Set("If-Match", ifMatch)
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, 1))
+		w := httptest.NewRecorder()
+		controller.UpdateTransaction(w, req)
+		return w
+	}
+
+	w := update(`"3"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusPreconditionFailed, update(`"2"`).Code)
+	assert.Equal(t, http.StatusPreconditionFailed, update(`W/"3"`).Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListTransactions_Controller_IfNoneMatch(t *testing.T) {
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+	mockService.On("ListTransactions", 1, 3, 2025).Return([]domain.Transaction{{ID: 7, Version: 1}}, nil).Once()
+	mockService.On("ListTransactions", 1, 3, 2025).Return([]domain.Transaction{{ID: 7, Version: 2}}, nil).Once()
+
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/transactions?month=3&year=2025", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, 1))
+		w := httptest.NewRecorder()
+		controller.ListTransactions(w, req)
+		return w
+	}
+
+	first := list("")
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.NotEmpty(t, etag)
+	changed := list(etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
 type MockAuthService struct {
 	mock.Mock
 }
@@ -98,6 +184,39 @@ func (m *MockAuthService) Login(email, password string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func TestBatchTransactions_Controller_AtomicFailure(t *testing.T) {
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+	ops := []domain.BatchOperation{{Op: "delete", ID: 5}, {Op: "delete", ID: 6}}
+	mockService.On("ApplyBatch", 1, "atomic", ops).Return([]domain.BatchResult{
+		{Index: 0, Op: "delete", Status: "failed", ID: 5, Error: "transaction not found"},
+		{Index: 1, Op: "delete", Status: "skipped", ID: 6},
+	}, nil)
+	mockService.On("ApplyBatch", 1, "partial", ops).Return([]domain.BatchResult{
+		{Index: 0, Op: "delete", Status: "failed", ID: 5, Error: "transaction not found"},
+		{Index: 1, Op: "delete", Status: "applied", ID: 6},
+	}, nil)
+
+	batch := func(mode string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(BatchTransactionsRequest{Mode: mode, Operations: ops})
+		req := httptest.NewRequest("POST", "/api/transactions/batch", bytes.NewBuffer(body))
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, 1))
+		w := httptest.NewRecorder()
+		controller.BatchTransactions(w, req)
+		return w
+	}
+
+	w := batch("")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response BatchTransactionsResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "atomic", response.Mode)
+	assert.Len(t, response.Results, 2)
+
+	assert.Equal(t, http.StatusOK, batch("partial").Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestLogin_Controller_Success(t *testing.T) {
 	mockService := new(MockAuthService)
 	controller := NewAuthController(mockService)
@@ -114,3 +233,18 @@ func TestLogin_Controller_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "token123")
 }
+
+func TestRegister_Controller_BodyTooLarge(t *testing.T) {
+	mockService := new(MockAuthService)
+	controller := NewAuthController(mockService)
+
+	body := `{"email":"` + strings.Repeat("a", maxJSONBodyBytes) + `"}`
+	req := httptest.NewRequest("POST", "/api/register", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	controller.Register(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "body_too_large")
+	mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+}
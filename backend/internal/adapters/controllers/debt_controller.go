@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type DebtController struct {
+	debtService ports.DebtService
+}
+
+func NewDebtController(debtService ports.DebtService) *DebtController {
+	return &DebtController{debtService: debtService}
+}
+
+type DebtRequest struct {
+	Name               string    `json:"name"`
+	Principal          float64   `json:"principal"`
+	InterestRate       float64   `json:"interest_rate"`
+	AmortizationSystem string    `json:"amortization_system"`
+	Installments       int       `json:"installments"`
+	FirstDueDate       time.Time `json:"first_due_date"`
+}
+
+func (c *DebtController) CreateDebt(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req DebtRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	debt, err := c.debtService.CreateDebt(r.Context(), userID, domain.Debt{
+		Name:               req.Name,
+		Principal:          req.Principal,
+		InterestRate:       req.InterestRate,
+		AmortizationSystem: req.AmortizationSystem,
+		Installments:       req.Installments,
+		FirstDueDate:       req.FirstDueDate,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(debt)
+}
+
+// ListDebts returns every debt with its schedule and outstanding balance.
+func (c *DebtController) ListDebts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	debts, err := c.debtService.ListDebts(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(debts)
+}
+
+func (c *DebtController) DeleteDebt(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.debtService.DeleteDebt(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Debt deleted"}`))
+}
+
+func (c *DebtController) Schedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	schedule, err := c.debtService.Schedule(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// DebtPaymentRequest links a transaction to an installment; leaving the
+// installment out pays the first one still open.
+type DebtPaymentRequest struct {
+	TransactionID int `json:"transaction_id"`
+	Installment   int `json:"installment"`
+}
+
+func (c *DebtController) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req DebtPaymentRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	payment, err := c.debtService.RecordPayment(r.Context(), userID, id, req.Installment, req.TransactionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+func (c *DebtController) DeletePayment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+	installment, err := strconv.Atoi(r.PathValue("installment"))
+	if err != nil {
+		writeError(w, invalidParam("installment", "installment must be a number"))
+		return
+	}
+
+	if err := c.debtService.DeletePayment(r.Context(), userID, id, installment); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Payment removed"}`))
+}
+
+// Plan compares snowball and avalanche payoff for ?extra= more a month.
+func (c *DebtController) Plan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	extra := 0.0
+	if extraStr := r.URL.Query().Get("extra"); extraStr != "" {
+		e, err := strconv.ParseFloat(extraStr, 64)
+		if err != nil {
+			writeError(w, invalidParam("extra", "extra must be a number"))
+			return
+		}
+		extra = e
+	}
+
+	plan, err := c.debtService.Plan(userID, extra)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
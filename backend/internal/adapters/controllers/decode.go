@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// maxJSONBodyBytes bounds every JSON request body; batches and sync pushes
+// are the largest and stay well under it.
+const maxJSONBodyBytes = 1 << 20
+
+// decodeJSON reads the request body into v, answering errBodyTooLarge past
+// maxJSONBodyBytes and errInvalidBody for anything that is not valid JSON.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)).Decode(v)
+	if err == nil {
+		return nil
+	}
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return errBodyTooLarge
+	}
+	return errInvalidBody
+}
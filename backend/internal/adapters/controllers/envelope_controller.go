@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type EnvelopeController struct {
+	envelopeService ports.EnvelopeService
+}
+
+func NewEnvelopeController(envelopeService ports.EnvelopeService) *EnvelopeController {
+	return &EnvelopeController{envelopeService: envelopeService}
+}
+
+func (c *EnvelopeController) SetMode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	settings, err := c.envelopeService.SetMode(r.Context(), userID, req.Enabled)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+type EnvelopeRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	GoalID   *int   `json:"goal_id"`
+}
+
+func (c *EnvelopeController) CreateEnvelope(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req EnvelopeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	envelope, err := c.envelopeService.CreateEnvelope(r.Context(), userID, domain.Envelope{
+		Name:     req.Name,
+		Category: req.Category,
+		GoalID:   req.GoalID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+func (c *EnvelopeController) ListEnvelopes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	envelopes, err := c.envelopeService.ListEnvelopes(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(envelopes)
+}
+
+func (c *EnvelopeController) DeleteEnvelope(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.envelopeService.DeleteEnvelope(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Envelope archived"}`))
+}
+
+func (c *EnvelopeController) Assign(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	transfer, err := c.envelopeService.Assign(r.Context(), userID, id, req.Amount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+type MoveRequest struct {
+	FromEnvelopeID *int    `json:"from_envelope_id"`
+	ToEnvelopeID   *int    `json:"to_envelope_id"`
+	Amount         float64 `json:"amount"`
+	Note           string  `json:"note"`
+}
+
+// Move transfers money between envelopes; an omitted side is the
+// "to be assigned" pool.
+func (c *EnvelopeController) Move(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req MoveRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	transfer, err := c.envelopeService.Move(r.Context(), userID, domain.EnvelopeTransfer{
+		FromEnvelopeID: req.FromEnvelopeID,
+		ToEnvelopeID:   req.ToEnvelopeID,
+		Amount:         req.Amount,
+		Note:           req.Note,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+func (c *EnvelopeController) ListMoves(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	transfers, err := c.envelopeService.ListTransfers(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+func (c *EnvelopeController) Summary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	month, year, err := monthFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	summary, err := c.envelopeService.Summary(userID, month, year)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
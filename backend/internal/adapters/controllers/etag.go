@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionETag is the strong ETag of a transaction or goal at version.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads If-Match as the row version an update or delete
+// applies to: 0, for any version, without the header or with "*", and -1,
+// which no row has, for a tag that is not one of ours.
+func ifMatchVersion(r *http.Request) int {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return -1
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return -1
+	}
+	return version
+}
+
+// writeVersionedJSON writes a single row with its version as the ETag.
+func writeVersionedJSON(w http.ResponseWriter, r *http.Request, version int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeWithETag(w, r, versionETag(version), body)
+}
+
+// writeCreatedJSON answers 201 Created with the new row and its version as
+// the ETag, so a client can send it back in If-Match right away.
+func writeCreatedJSON(w http.ResponseWriter, version int, v any) {
+	w.Header().Set("ETag", versionETag(version))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeListJSON writes a list with a weak ETag of its content, so clients
+// polling with If-None-Match get 304 Not Modified until something changes.
+func writeListJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	writeWithETag(w, r, `W/"`+hex.EncodeToString(sum[:16])+`"`, body)
+}
+
+func writeWithETag(w http.ResponseWriter, r *http.Request, etag string, body []byte) {
+	w.Header().Set("ETag", etag)
+	if r.Method == http.MethodGet && noneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// noneMatch tells whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 asks.
+func noneMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
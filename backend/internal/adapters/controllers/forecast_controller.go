@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+const defaultForecastMonths = 3
+
+type ForecastController struct {
+	forecastService ports.ForecastService
+}
+
+func NewForecastController(forecastService ports.ForecastService) *ForecastController {
+	return &ForecastController{forecastService: forecastService}
+}
+
+func (c *ForecastController) Forecast(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	months := defaultForecastMonths
+	if monthsStr := r.URL.Query().Get("months"); monthsStr != "" {
+		m, err := strconv.Atoi(monthsStr)
+		if err != nil || m < 1 {
+			writeError(w, invalidParam("months", "months must be a positive number"))
+			return
+		}
+		months = m
+	}
+
+	forecast, err := c.forecastService.Forecast(userID, months)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(forecast)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 )
 
@@ -33,91 +34,119 @@ type AddProgressRequest struct {
 	Amount float64 `json:"amount"`
 }
 
+type SetInvestmentRequest struct {
+	Index   string  `json:"index"`
+	Percent float64 `json:"percent"`
+	Spread  float64 `json:"spread"`
+}
+
 func (c *GoalController) CreateGoal(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	var req CreateGoalRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	goal, err := c.goalService.CreateGoal(userID, req.Name, req.TargetAmount, req.Deadline)
+	goal, err := c.goalService.CreateGoal(r.Context(), userID, req.Name, req.TargetAmount, req.Deadline)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(goal)
+	writeCreatedJSON(w, goal.Version, goal)
 }
 
 func (c *GoalController) ListGoals(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	goals, err := c.goalService.ListGoals(userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(goals)
+	writeListJSON(w, r, goals)
+}
+
+// GetGoal returns the goal with its version as the ETag, to send back in
+// If-Match when updating or deleting it.
+func (c *GoalController) GetGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	goal, err := c.goalService.GetGoal(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersionedJSON(w, r, goal.Version, goal)
 }
 
 func (c *GoalController) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id must be a number"))
 		return
 	}
 
 	var req UpdateGoalRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	if err := c.goalService.UpdateGoal(userID, id, req.Name, req.TargetAmount, req.Deadline); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	goal, err := c.goalService.UpdateGoal(r.Context(), userID, id, ifMatchVersion(r), req.Name, req.TargetAmount, req.Deadline)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message":"Goal updated"}`))
+	writeVersionedJSON(w, r, goal.Version, goal)
 }
 
 func (c *GoalController) DeleteGoal(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id must be a number"))
 		return
 	}
 
-	if err := c.goalService.DeleteGoal(userID, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := c.goalService.DeleteGoal(r.Context(), userID, id, ifMatchVersion(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -128,28 +157,84 @@ func (c *GoalController) DeleteGoal(w http.ResponseWriter, r *http.Request) {
 func (c *GoalController) AddProgress(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id must be a number"))
 		return
 	}
 
 	var req AddProgressRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	if err := c.goalService.AddProgress(userID, id, req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := c.goalService.AddProgress(r.Context(), userID, id, req.Amount); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"message":"Progress added"}`))
 }
+
+func (c *GoalController) SetInvestment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req SetInvestmentRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	investment, err := c.goalService.SetInvestment(r.Context(), userID, id, domain.GoalInvestment{
+		Index:   req.Index,
+		Percent: req.Percent,
+		Spread:  req.Spread,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(investment)
+}
+
+func (c *GoalController) Yield(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	yield, err := c.goalService.Yield(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(yield)
+}
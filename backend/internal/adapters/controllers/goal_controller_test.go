@@ -3,6 +3,7 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,18 +20,23 @@ type MockGoalService struct {
 	mock.Mock
 }
 
-func (m *MockGoalService) CreateGoal(userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+func (m *MockGoalService) CreateGoal(ctx context.Context, userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
 	args := m.Called(userID, name, targetAmount, deadline)
 	return args.Get(0).(domain.Goal), args.Error(1)
 }
 
-func (m *MockGoalService) UpdateGoal(userID, id int, name string, targetAmount float64, deadline time.Time) error {
-	args := m.Called(userID, id, name, targetAmount, deadline)
-	return args.Error(0)
+func (m *MockGoalService) GetGoal(userID, id int) (domain.Goal, error) {
+	args := m.Called(userID, id)
+	return args.Get(0).(domain.Goal), args.Error(1)
 }
 
-func (m *MockGoalService) DeleteGoal(userID, id int) error {
-	args := m.Called(userID, id)
+func (m *MockGoalService) UpdateGoal(ctx context.Context, userID, id, version int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+	args := m.Called(userID, id, version, name, targetAmount, deadline)
+	return args.Get(0).(domain.Goal), args.Error(1)
+}
+
+func (m *MockGoalService) DeleteGoal(ctx context.Context, userID, id, version int) error {
+	args := m.Called(userID, id, version)
 	return args.Error(0)
 }
 
@@ -38,11 +45,21 @@ func (m *MockGoalService) ListGoals(userID int) ([]domain.Goal, error) {
 	return args.Get(0).([]domain.Goal), args.Error(1)
 }
 
-func (m *MockGoalService) AddProgress(userID, goalID int, amount float64) error {
+func (m *MockGoalService) AddProgress(ctx context.Context, userID, goalID int, amount float64) error {
 	args := m.Called(userID, goalID, amount)
 	return args.Error(0)
 }
 
+func (m *MockGoalService) SetInvestment(ctx context.Context, userID, goalID int, investment domain.GoalInvestment) (domain.GoalInvestment, error) {
+	args := m.Called(userID, goalID, investment)
+	return args.Get(0).(domain.GoalInvestment), args.Error(1)
+}
+
+func (m *MockGoalService) Yield(userID, goalID int) (domain.GoalYield, error) {
+	args := m.Called(userID, goalID)
+	return args.Get(0).(domain.GoalYield), args.Error(1)
+}
+
 func TestCreateGoal_Controller_Success(t *testing.T) {
 	mockService := new(MockGoalService)
 	controller := NewGoalController(mockService)
@@ -56,6 +73,7 @@ func TestCreateGoal_Controller_Success(t *testing.T) {
 		CurrentAmount: 0,
 		Deadline:      deadline,
 		CreatedAt:     time.Now(),
+		Version:       1,
 	}
 
 	mockService.On("CreateGoal", 1, "Viagem", 5000.0, mock.AnythingOfType("time.Time")).
@@ -77,7 +95,8 @@ func TestCreateGoal_Controller_Success(t *testing.T) {
 
 	controller.CreateGoal(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
 
 	var response domain.Goal
 	json.Unmarshal(w.Body.Bytes(), &response)
@@ -150,8 +169,8 @@ func TestUpdateGoal_Controller_Success(t *testing.T) {
 
 	deadline := time.Now().AddDate(1, 0, 0)
 
-	mockService.On("UpdateGoal", 1, 1, "Viagem Europa", 8000.0, mock.AnythingOfType("time.Time")).
-		Return(nil)
+	mockService.On("UpdateGoal", 1, 1, 0, "Viagem Europa", 8000.0, mock.AnythingOfType("time.Time")).
+		Return(domain.Goal{ID: 1, Name: "Viagem Europa", Version: 2}, nil)
 
 	reqBody := map[string]interface{}{
 		"name":          "Viagem Europa",
@@ -171,6 +190,7 @@ func TestUpdateGoal_Controller_Success(t *testing.T) {
 	controller.UpdateGoal(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	mockService.AssertExpectations(t)
 }
@@ -179,7 +199,7 @@ func TestDeleteGoal_Controller_Success(t *testing.T) {
 	mockService := new(MockGoalService)
 	controller := NewGoalController(mockService)
 
-	mockService.On("DeleteGoal", 1, 1).Return(nil)
+	mockService.On("DeleteGoal", 1, 1, 0).Return(nil)
 
 	req := httptest.NewRequest("DELETE", "/api/goals/1", nil)
 	req.SetPathValue("id", "1")
@@ -220,3 +240,41 @@ func TestAddProgress_Controller_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestGoalYield_Controller_NotFound(t *testing.T) {
+	mockService := new(MockGoalService)
+	controller := NewGoalController(mockService)
+
+	mockService.On("Yield", 1, 9).Return(domain.GoalYield{}, sql.ErrNoRows)
+
+	req := httptest.NewRequest("GET", "/api/goals/9/yield", nil)
+	req.SetPathValue("id", "9")
+	ctx := context.WithValue(req.Context(), UserIDKey, 1)
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	controller.Yield(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetInvestment_Controller_Invalid(t *testing.T) {
+	mockService := new(MockGoalService)
+	controller := NewGoalController(mockService)
+
+	investment := domain.GoalInvestment{Index: "poupanca", Percent: 100}
+	mockService.On("SetInvestment", 1, 1, investment).Return(domain.GoalInvestment{}, services.ErrInvalidGoalInvestment)
+
+	body, _ := json.Marshal(map[string]interface{}{"index": "poupanca", "percent": 100})
+	req := httptest.NewRequest("PUT", "/api/goals/1/investment", bytes.NewBuffer(body))
+	req.SetPathValue("id", "1")
+	ctx := context.WithValue(req.Context(), UserIDKey, 1)
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	controller.SetInvestment(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
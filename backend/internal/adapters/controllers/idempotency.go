@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+// Responses larger than maxIdempotentResponse are not kept; the key is
+// released instead and a retry runs the request again.
+const maxIdempotentResponse = 1 << 20
+
+// replayedHeaders are the response headers kept with the body, so a replay
+// answers with the same ones as the original.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyMiddleware makes POST, PUT, PATCH and DELETE requests that
+// carry an Idempotency-Key safe to retry: the first request with a key runs,
+// and later ones with the same key get its response back, marked with
+// Idempotent-Replayed. Keys are per user, so requests without a valid token
+// (login, register) pass through untouched.
+type IdempotencyMiddleware struct {
+	service      ports.IdempotencyService
+	maxBodyBytes int64
+}
+
+// NewIdempotencyMiddleware reads request bodies of up to maxBodyBytes to
+// fingerprint them; larger keyed requests are refused.
+func NewIdempotencyMiddleware(service ports.IdempotencyService, maxBodyBytes int64) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{service: service, maxBodyBytes: maxBodyBytes}
+}
+
+func (m *IdempotencyMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || !isMutation(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, ok := bearerUserID(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, m.maxBodyBytes))
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, errBodyTooLarge)
+			return
+		}
+		if err != nil {
+			writeError(w, errUnreadableBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := m.service.Begin(userID, key, requestFingerprint(r, body))
+		if err != nil {
+			writeIdempotencyError(w, err)
+			return
+		}
+		if record != nil {
+			for name, value := range record.Headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(record.ResponseStatus)
+			w.Write(record.ResponseBody)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if !completed {
+				if err := m.service.Release(userID, key); err != nil {
+					log.Printf("idempotency: could not release key for user %d: %v", userID, err)
+				}
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		// Server errors are not replayed: the write most likely rolled back
+		// and a retry should get another chance.
+		status := recorder.statusCode()
+		if status >= http.StatusInternalServerError || recorder.overflow {
+			return
+		}
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := m.service.Complete(userID, key, status, headers, recorder.body.Bytes()); err != nil {
+			log.Printf("idempotency: could not store response for user %d: %v", userID, err)
+			return
+		}
+		completed = true
+	})
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint tells requests apart by method, URL and body, so a key
+// reused for anything else is caught.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeIdempotencyError answers a reused key with 422, as the IETF draft
+// on Idempotency-Key asks, rather than the 409 of other conflicts.
+func writeIdempotencyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		writeProblem(w, http.StatusUnprocessableEntity, services.ErrIdempotencyKeyReused.Code, services.ErrIdempotencyKeyReused.Message, nil)
+	case errors.Is(err, services.ErrIdempotencyInProgress):
+		w.Header().Set("Retry-After", "1")
+		writeError(w, err)
+	default:
+		writeError(w, err)
+	}
+}
+
+// responseRecorder keeps a copy of the response while writing it through.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxIdempotentResponse {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
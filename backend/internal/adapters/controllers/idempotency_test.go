@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+// fakeIdempotencyService holds one record per key the way the real service
+// does, without expiry.
+type fakeIdempotencyService struct {
+	records  map[string]*domain.IdempotencyRecord
+	released []string
+}
+
+func (f *fakeIdempotencyService) Begin(userID int, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	record, ok := f.records[key]
+	switch {
+	case !ok:
+		f.records[key] = &domain.IdempotencyRecord{UserID: userID, Key: key, Fingerprint: fingerprint, Status: domain.IdempotencyInProgress}
+		return nil, nil
+	case record.Fingerprint != fingerprint:
+		return nil, services.ErrIdempotencyKeyReused
+	case record.Status == domain.IdempotencyInProgress:
+		return nil, services.ErrIdempotencyInProgress
+	}
+	return record, nil
+}
+
+func (f *fakeIdempotencyService) Complete(userID int, key string, status int, headers map[string]string, body []byte) error {
+	record := f.records[key]
+	record.Status = domain.IdempotencyCompleted
+	record.ResponseStatus = status
+	record.Headers = headers
+	record.ResponseBody = body
+	return nil
+}
+
+func (f *fakeIdempotencyService) Release(userID int, key string) error {
+	delete(f.records, key)
+	f.released = append(f.released, key)
+	return nil
+}
+
+func signedRequest(t *testing.T, method, body, key string) *http.Request {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1}).SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+	req := httptest.NewRequest(method, "/api/expense", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Idempotency-Key", key)
+	return req
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	service := &fakeIdempotencyService{records: map[string]*domain.IdempotencyRecord{}}
+	calls := 0
+	handler := NewIdempotencyMiddleware(service, 1<<20).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"1"`)
+		w.Header().Set("Location", "/api/transactions/7")
+		w.Header().Set("X-Request-ID", "first")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":7}`))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, signedRequest(t, "POST", `{"amount":10}`, "k1"))
+	retry := httptest.NewRecorder()
+	handler.ServeHTTP(retry, signedRequest(t, "POST", `{"amount":10}`, "k1"))
+	reused := httptest.NewRecorder()
+	handler.ServeHTTP(reused, signedRequest(t, "POST", `{"amount":99}`, "k1"))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, `{"id":7}`, retry.Body.String())
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	assert.Equal(t, `"1"`, retry.Header().Get("ETag"))
+	assert.Equal(t, "/api/transactions/7", retry.Header().Get("Location"))
+	assert.Empty(t, retry.Header().Get("X-Request-ID"))
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+}
+
+func TestIdempotency_InFlightDuplicateAndServerErrors(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	service := &fakeIdempotencyService{records: map[string]*domain.IdempotencyRecord{}}
+	var duplicate *httptest.ResponseRecorder
+	var handler http.Handler
+	handler = NewIdempotencyMiddleware(service, 1<<20).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The retry arrives while the first request is still running.
+		duplicate = httptest.NewRecorder()
+		handler.ServeHTTP(duplicate, signedRequest(t, "POST", `{}`, "k2"))
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(t, "POST", `{}`, "k2"))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusConflict, duplicate.Code)
+	assert.Equal(t, "1", duplicate.Header().Get("Retry-After"))
+	assert.Equal(t, []string{"k2"}, service.released)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestIdempotency_BodyErrors(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	service := &fakeIdempotencyService{records: map[string]*domain.IdempotencyRecord{}}
+	handler := NewIdempotencyMiddleware(service, 8).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tooLarge := httptest.NewRecorder()
+	handler.ServeHTTP(tooLarge, signedRequest(t, "POST", `{"amount":10}`, "k4"))
+	broken := signedRequest(t, "POST", "", "k4")
+	broken.Body = io.NopCloser(failingReader{})
+	unreadable := httptest.NewRecorder()
+	handler.ServeHTTP(unreadable, broken)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, tooLarge.Code)
+	assert.Equal(t, http.StatusBadRequest, unreadable.Code)
+	assert.Equal(t, "unreadable_body", decodeProblem(t, unreadable).Code)
+	assert.Empty(t, service.records)
+}
+
+func TestIdempotency_PassesThroughUnkeyedRequests(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	service := &fakeIdempotencyService{records: map[string]*domain.IdempotencyRecord{}}
+	calls := 0
+	handler := NewIdempotencyMiddleware(service, 1<<20).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), signedRequest(t, "GET", "", "k3"))
+	anonymous := signedRequest(t, "POST", "", "k3")
+	anonymous.Header.Del("Authorization")
+	handler.ServeHTTP(httptest.NewRecorder(), anonymous)
+	unkeyed := signedRequest(t, "POST", "", "")
+	handler.ServeHTTP(httptest.NewRecorder(), unkeyed)
+
+	assert.Equal(t, 3, calls)
+	assert.Empty(t, service.records)
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type NetWorthController struct {
+	netWorthService ports.NetWorthService
+}
+
+func NewNetWorthController(netWorthService ports.NetWorthService) *NetWorthController {
+	return &NetWorthController{netWorthService: netWorthService}
+}
+
+// History returns the monthly net worth for ?from=YYYY-MM&to=YYYY-MM
+// (defaults to the last 12 months).
+func (c *NetWorthController) History(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	from, to, err := reportRangeFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	series, err := c.netWorthService.History(userID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// Current returns today's net worth with one line per account, goal, asset
+// and liability.
+func (c *NetWorthController) Current(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	worth, err := c.netWorthService.Current(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(worth)
+}
+
+type AssetRequest struct {
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	Category string     `json:"category"`
+	Value    float64    `json:"value"`
+	ValuedAt *time.Time `json:"valued_at"`
+}
+
+func (req AssetRequest) toDomain() domain.Asset {
+	return domain.Asset{
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Category: req.Category,
+		Value:    req.Value,
+		ValuedAt: req.ValuedAt,
+	}
+}
+
+func (c *NetWorthController) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req AssetRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	asset, err := c.netWorthService.CreateAsset(r.Context(), userID, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(asset)
+}
+
+func (c *NetWorthController) ListAssets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	assets, err := c.netWorthService.ListAssets(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assets)
+}
+
+func (c *NetWorthController) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req AssetRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.netWorthService.UpdateAsset(r.Context(), userID, id, req.toDomain()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Asset updated"}`))
+}
+
+func (c *NetWorthController) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.netWorthService.DeleteAsset(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Asset archived"}`))
+}
+
+type ValuationRequest struct {
+	Value float64   `json:"value"`
+	Date  time.Time `json:"date"`
+}
+
+func (c *NetWorthController) AddValuation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req ValuationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	valuation, err := c.netWorthService.AddValuation(r.Context(), userID, id, req.Value, req.Date)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(valuation)
+}
+
+func (c *NetWorthController) ListValuations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	valuations, err := c.netWorthService.ListValuations(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuations)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type NotificationController struct {
+	notificationService ports.NotificationService
+	vapidPublicKey      string
+}
+
+// NewNotificationController takes the VAPID public key the PWA subscribes
+// with; it is empty when Web Push is not configured.
+func NewNotificationController(notificationService ports.NotificationService, vapidPublicKey string) *NotificationController {
+	return &NotificationController{notificationService: notificationService, vapidPublicKey: vapidPublicKey}
+}
+
+// Inbox returns the latest notifications, or only unread ones with
+// ?unread=true, with the unread count.
+func (c *NotificationController) Inbox(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	inbox, err := c.notificationService.Inbox(userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inbox)
+}
+
+// MarkReadRequest with no ids marks every notification read.
+type MarkReadRequest struct {
+	IDs []int `json:"ids"`
+}
+
+func (c *NotificationController) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req MarkReadRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	updated, err := c.notificationService.MarkRead(userID, req.IDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"updated": updated})
+}
+
+func (c *NotificationController) Settings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	settings, err := c.notificationService.Settings(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+func (c *NotificationController) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req domain.NotificationSettings
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	settings, err := c.notificationService.UpdateSettings(r.Context(), userID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// PushSubscriptionRequest is the browser's PushSubscription.toJSON().
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+func (c *NotificationController) SubscribePush(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req PushSubscriptionRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	subscription := domain.PushSubscription{Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if err := c.notificationService.SubscribePush(r.Context(), userID, subscription); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(`{"message":"Subscribed"}`))
+}
+
+func (c *NotificationController) UnsubscribePush(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req PushSubscriptionRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Endpoint == "" {
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	if err := c.notificationService.UnsubscribePush(r.Context(), userID, req.Endpoint); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Unsubscribed"}`))
+}
+
+var errPushNotConfigured = domain.NotFound("push_not_configured", "Web Push is not configured")
+
+// VAPIDPublicKey returns the applicationServerKey for pushManager.subscribe.
+func (c *NotificationController) VAPIDPublicKey(w http.ResponseWriter, r *http.Request) {
+	if c.vapidPublicKey == "" {
+		writeError(w, errPushNotConfigured)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"public_key": c.vapidPublicKey})
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// maxPriceImportSize bounds a price CSV upload.
+const maxPriceImportSize = 5 << 20
+
+type PortfolioController struct {
+	portfolioService ports.PortfolioService
+}
+
+func NewPortfolioController(portfolioService ports.PortfolioService) *PortfolioController {
+	return &PortfolioController{portfolioService: portfolioService}
+}
+
+// Portfolio returns every position with gains and the allocation by asset
+// class.
+func (c *PortfolioController) Portfolio(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	portfolio, err := c.portfolioService.Portfolio(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(portfolio)
+}
+
+type HoldingRequest struct {
+	Ticker     string `json:"ticker"`
+	Name       string `json:"name"`
+	AssetClass string `json:"asset_class"`
+}
+
+func (c *PortfolioController) CreateHolding(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req HoldingRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	holding, err := c.portfolioService.CreateHolding(r.Context(), userID, domain.Holding{
+		Ticker:     req.Ticker,
+		Name:       req.Name,
+		AssetClass: req.AssetClass,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(holding)
+}
+
+func (c *PortfolioController) ListHoldings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	holdings, err := c.portfolioService.ListHoldings(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(holdings)
+}
+
+func (c *PortfolioController) DeleteHolding(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.portfolioService.DeleteHolding(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Holding deleted"}`))
+}
+
+type OperationRequest struct {
+	HoldingID int       `json:"holding_id"`
+	Type      string    `json:"type"`
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Amount    float64   `json:"amount"`
+	Fees      float64   `json:"fees"`
+	Date      time.Time `json:"date"`
+}
+
+func (c *PortfolioController) RecordOperation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req OperationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	operation, err := c.portfolioService.RecordOperation(r.Context(), userID, domain.InvestmentOperation{
+		HoldingID: req.HoldingID,
+		Type:      req.Type,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		Amount:    req.Amount,
+		Fees:      req.Fees,
+		Date:      req.Date,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(operation)
+}
+
+// ListOperations returns all operations, or those of ?holding= only.
+func (c *PortfolioController) ListOperations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	holdingID := 0
+	if holdingStr := r.URL.Query().Get("holding"); holdingStr != "" {
+		id, err := strconv.Atoi(holdingStr)
+		if err != nil {
+			writeError(w, invalidParam("holding", "holding must be a number"))
+			return
+		}
+		holdingID = id
+	}
+
+	operations, err := c.portfolioService.ListOperations(userID, holdingID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(operations)
+}
+
+func (c *PortfolioController) DeleteOperation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.portfolioService.DeleteOperation(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Operation deleted"}`))
+}
+
+type PriceRequest struct {
+	HoldingID int       `json:"holding_id"`
+	Price     float64   `json:"price"`
+	Date      time.Time `json:"date"`
+}
+
+func (c *PortfolioController) SetPrice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req PriceRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	snapshot, err := c.portfolioService.SetPrice(r.Context(), userID, req.HoldingID, req.Price, req.Date)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// ImportPrices accepts the CSV either as a multipart "file" field or as the
+// raw request body.
+func (c *PortfolioController) ImportPrices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPriceImportSize)
+	var file io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, invalidParam("file", "a file is required"))
+			return
+		}
+		defer part.Close()
+		file = part
+	}
+
+	result, err := c.portfolioService.ImportPrices(r.Context(), userID, file)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, errBodyTooLarge)
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
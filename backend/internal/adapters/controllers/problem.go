@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+// Problem is an RFC 7807 problem details body. Code is stable for clients
+// to branch on; Detail is meant for people and may change.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Code      string              `json:"code"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+var (
+	errUnauthorized    = domain.Unauthorized("unauthorized", "a valid bearer token is required")
+	errInvalidToken    = domain.Unauthorized("invalid_token", "the bearer token is invalid or expired")
+	errInvalidBody     = domain.Invalid("invalid_body", "request body is not valid JSON for this endpoint")
+	errInvalidArgument = domain.Invalid("invalid_parameter", "a request parameter is invalid")
+	errBodyTooLarge    = domain.NewError(domain.KindTooLarge, "body_too_large", "request body is too large")
+	errUnreadableBody  = domain.Invalid("unreadable_body", "request body could not be read")
+)
+
+// invalidParam reports a bad path or query parameter.
+func invalidParam(name, message string) error {
+	return errInvalidArgument.WithField(name, message)
+}
+
+// writeError answers with the problem for err. Errors meant for the client
+// (domain.Error, and sql.ErrNoRows from repositories as not found) are
+// answered with their own message, the chain wrapping them being only
+// logged; any other is logged and answered with a bare 500, so no internal
+// detail reaches the client.
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		err = domain.ErrNotFound
+	}
+	e, ok := domain.AsError(err)
+	if !ok {
+		log.Printf("request %s failed: %v", w.Header().Get("X-Request-ID"), err)
+		writeProblem(w, http.StatusInternalServerError, "internal_error", "an unexpected error occurred", nil)
+		return
+	}
+	if err != error(e) {
+		log.Printf("request %s: %v", w.Header().Get("X-Request-ID"), err)
+	}
+	writeProblem(w, problemStatus(e.Kind), e.Code, e.Message, e.Fields)
+}
+
+func problemStatus(kind domain.ErrorKind) int {
+	switch kind {
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindValidation:
+		return http.StatusBadRequest
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case domain.KindUnauthorized:
+		return http.StatusUnauthorized
+	case domain.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusInternalServerError
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail string, fields []domain.FieldError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		Errors:    fields,
+		RequestID: w.Header().Get("X-Request-ID"),
+	})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeProblem(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	return problem
+}
+
+func TestWriteError_MapsDomainErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{services.ErrInvalidBatch.WithField("mode", "must be one of atomic, partial"), http.StatusBadRequest, "invalid_batch"},
+		{ports.ErrVersionConflict, http.StatusPreconditionFailed, "version_conflict"},
+		{services.ErrBillPaid, http.StatusConflict, "bill_paid"},
+		{services.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{fmt.Errorf("load goal: %w", sql.ErrNoRows), http.StatusNotFound, "not_found"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		writeError(w, tc.err)
+
+		assert.Equal(t, tc.status, w.Code, tc.code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, tc.status, problem.Status)
+		assert.Equal(t, tc.code, problem.Code)
+		assert.Equal(t, http.StatusText(tc.status), problem.Title)
+	}
+}
+
+func TestWriteError_FieldDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, services.ErrInvalidRule.WithField("description_regex", "invalid regular expression"))
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "invalid_rule", problem.Code)
+	assert.Equal(t, []domain.FieldError{{Field: "description_regex", Message: "invalid regular expression"}}, problem.Errors)
+	assert.ErrorIs(t, services.ErrInvalidRule.WithField("x", "y"), services.ErrInvalidRule)
+}
+
+func TestWriteError_DetailIsTheDomainMessage(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, fmt.Errorf("%w: %v", services.ErrInvalidSyncMutation, errors.New("json: cannot unmarshal string into Go value of type float64")))
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "invalid_sync_mutation", problem.Code)
+	assert.Equal(t, services.ErrInvalidSyncMutation.Message, problem.Detail)
+}
+
+func TestWriteError_HidesInternalErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Request-ID", "req-1")
+	writeError(w, errors.New(`pq: relation "transactions" does not exist`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.Equal(t, "req-1", problem.RequestID)
+	assert.NotContains(t, problem.Detail, "pq")
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type ReportController struct {
+	reportService ports.ReportService
+}
+
+func NewReportController(reportService ports.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
+}
+
+// Monthly returns income, expenses and savings rate per month for
+// ?from=YYYY-MM&to=YYYY-MM (defaults to the last 12 months).
+func (c *ReportController) Monthly(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	from, to, err := reportRangeFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reports, err := c.reportService.Monthly(userID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+func (c *ReportController) Categories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	from, to, err := reportRangeFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reports, err := c.reportService.Categories(userID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// YearOverYear compares ?year= (defaults to the current year) with the
+// year before.
+func (c *ReportController) YearOverYear(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	year := time.Now().Year()
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil {
+			writeError(w, invalidParam("year", "year must be a number"))
+			return
+		}
+		year = y
+	}
+
+	report, err := c.reportService.YearOverYear(userID, year)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func reportRangeFromQuery(r *http.Request) (from, to time.Time, err error) {
+	queryParams := r.URL.Query()
+	to = time.Now()
+	if toStr := queryParams.Get("to"); toStr != "" {
+		if to, err = time.Parse("2006-01", toStr); err != nil {
+			return from, to, invalidParam("to", "to must be a month as YYYY-MM")
+		}
+	}
+	from = to.AddDate(0, -11, 0)
+	if fromStr := queryParams.Get("from"); fromStr != "" {
+		if from, err = time.Parse("2006-01", fromStr); err != nil {
+			return from, to, invalidParam("from", "from must be a month as YYYY-MM")
+		}
+	}
+	return from, to, nil
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+const maxRequestIDLength = 128
+
+// RequestMetaMiddleware tags every request with an ID (the client's
+// X-Request-ID when present) and the caller's IP so services can record
+// where a change came from.
+type RequestMetaMiddleware struct {
+	trustedProxies []*net.IPNet
+}
+
+// NewRequestMetaMiddleware believes X-Forwarded-For only on requests coming
+// from trustedProxies, given as IPs or CIDRs; invalid entries are skipped.
+func NewRequestMetaMiddleware(trustedProxies []string) *RequestMetaMiddleware {
+	m := &RequestMetaMiddleware{}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Printf("request meta: ignoring trusted proxy %q: %v", proxy, err)
+			continue
+		}
+		m.trustedProxies = append(m.trustedProxies, network)
+	}
+	return m
+}
+
+func (m *RequestMetaMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		ctx := domain.WithRequestMeta(r.Context(), domain.RequestMeta{
+			RequestID: requestID,
+			IP:        m.clientIP(r),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientIP is the peer address, or when the peer is a trusted proxy, the
+// last X-Forwarded-For hop that is not one. A hop that is not an IP ends
+// the walk at the address before it. The result is a valid IP or "".
+func (m *RequestMetaMiddleware) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0 && m.trusted(ip); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
+
+func (m *RequestMetaMiddleware) trusted(ip net.IP) bool {
+	for _, network := range m.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+func TestRequestMeta_ClientIP(t *testing.T) {
+	middleware := NewRequestMetaMiddleware([]string{"10.0.0.0/8", "192.168.1.5", "not-a-proxy"})
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:4000", "", "203.0.113.7"},
+		{"untrusted peer cannot spoof", "203.0.113.7:4000", "1.2.3.4", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:4000", "198.51.100.9", "198.51.100.9"},
+		{"chain of trusted proxies", "192.168.1.5:4000", "198.51.100.9, 10.0.0.2", "198.51.100.9"},
+		{"spoofed left hops are ignored", "10.1.2.3:4000", "1.2.3.4, 198.51.100.9", "198.51.100.9"},
+		{"garbage hop falls back", "10.1.2.3:4000", "'; DROP TABLE audit_events; --", "10.1.2.3"},
+		{"unparseable peer", "somewhere", "", ""},
+	}
+	for _, c := range cases {
+		var got domain.RequestMeta
+		handler := middleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = domain.RequestMetaFrom(r.Context())
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		req.RemoteAddr = c.remoteAddr
+		if c.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, c.want, got.IP, c.name)
+	}
+}
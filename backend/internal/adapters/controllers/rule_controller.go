@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type RuleController struct {
+	ruleService ports.RuleService
+}
+
+func NewRuleController(ruleService ports.RuleService) *RuleController {
+	return &RuleController{ruleService: ruleService}
+}
+
+type RuleRequest struct {
+	Name                string   `json:"name"`
+	Priority            int      `json:"priority"`
+	Enabled             *bool    `json:"enabled"`
+	DescriptionContains string   `json:"description_contains"`
+	DescriptionRegex    string   `json:"description_regex"`
+	MinAmount           *float64 `json:"min_amount"`
+	MaxAmount           *float64 `json:"max_amount"`
+	Account             string   `json:"account"`
+	TransactionType     string   `json:"transaction_type"`
+	DayOfMonthFrom      int      `json:"day_of_month_from"`
+	DayOfMonthTo        int      `json:"day_of_month_to"`
+	SetCategory         string   `json:"set_category"`
+	SetTags             []string `json:"set_tags"`
+	SetBucket           string   `json:"set_bucket"`
+}
+
+func (req RuleRequest) toDomain() domain.CategoryRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return domain.CategoryRule{
+		Name:                req.Name,
+		Priority:            req.Priority,
+		Enabled:             enabled,
+		DescriptionContains: req.DescriptionContains,
+		DescriptionRegex:    req.DescriptionRegex,
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		Account:             req.Account,
+		TransactionType:     req.TransactionType,
+		DayOfMonthFrom:      req.DayOfMonthFrom,
+		DayOfMonthTo:        req.DayOfMonthTo,
+		SetCategory:         req.SetCategory,
+		SetTags:             req.SetTags,
+		SetBucket:           req.SetBucket,
+	}
+}
+
+func (c *RuleController) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req RuleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	rule, err := c.ruleService.CreateRule(r.Context(), userID, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (c *RuleController) ListRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	rules, err := c.ruleService.ListRules(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (c *RuleController) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req RuleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.ruleService.UpdateRule(r.Context(), userID, id, req.toDomain()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Rule updated"}`))
+}
+
+func (c *RuleController) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.ruleService.DeleteRule(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Rule deleted"}`))
+}
+
+// PreviewRule is a dry run: it reports which historical transactions the rule
+// in the body would change without saving anything.
+func (c *RuleController) PreviewRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req RuleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	matches, err := c.ruleService.PreviewRule(userID, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+func (c *RuleController) ApplyRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	updated, err := c.ruleService.ApplyRuleRetroactively(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"updated": updated})
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// streamHeartbeat keeps idle connections open through proxies; streamRetry
+// is how long browsers wait before reconnecting.
+const (
+	streamHeartbeat = 25 * time.Second
+	streamRetry     = 3 * time.Second
+)
+
+type StreamController struct {
+	stream    ports.EventStream
+	heartbeat time.Duration
+}
+
+func NewStreamController(stream ports.EventStream) *StreamController {
+	return &StreamController{stream: stream, heartbeat: streamHeartbeat}
+}
+
+// Stream sends the user's transaction and goal changes as Server-Sent
+// Events named after the event type, with the event as data and its id as
+// the SSE id. A client resuming with Last-Event-ID (or ?lastEventId=) first
+// gets what it missed, or a "reset" event when it should reload instead.
+func (c *StreamController) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var since int64
+	if lastEventID != "" {
+		var err error
+		if since, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			writeError(w, invalidParam("Last-Event-ID", "Last-Event-ID must be a number"))
+			return
+		}
+	}
+
+	sub := c.stream.Subscribe(userID, since)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if sub.Reset {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		writeStreamEvent(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			writeStreamEvent(w, event)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event domain.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// TokenFromQuery lets EventSource, which cannot set headers, pass the JWT
+// as ?access_token=. Only use it on routes that need it: the token ends up
+// in URLs and access logs.
+func TokenFromQuery(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next(w, r)
+	}
+}
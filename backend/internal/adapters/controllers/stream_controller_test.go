@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+func TestStream_Controller_ReplaysAndStreams(t *testing.T) {
+	hub := services.NewStreamHub(0)
+	hub.Broadcast(domain.Event{ID: 1, Type: domain.EventGoalCreated, UserID: 1})
+	hub.Broadcast(domain.Event{ID: 2, Type: domain.EventGoalProgressAdded, UserID: 1})
+	controller := NewStreamController(hub)
+	controller.heartbeat = 20 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.Stream(w, r.WithContext(context.WithValue(r.Context(), UserIDKey, 1)))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() string {
+		lines.Scan()
+		return lines.Text()
+	}
+	assert.Equal(t, "retry: 3000", next())
+	next()
+	assert.Equal(t, "id: 2", next())
+	assert.Equal(t, "event: goal.progress_added", next())
+	assert.True(t, strings.HasPrefix(next(), `data: {"id":2,"type":"goal.progress_added","user_id":1`))
+	next()
+	assert.Equal(t, ": ping", next())
+	next()
+
+	hub.Broadcast(domain.Event{ID: 3, Type: domain.EventTransactionDeleted, UserID: 2})
+	hub.Broadcast(domain.Event{ID: 4, Type: domain.EventTransactionCreated, UserID: 1})
+	for line := next(); line != "id: 4"; line = next() {
+		assert.Equal(t, ": ping", line, "only the user's own events are streamed")
+		next()
+	}
+	assert.Equal(t, "event: transaction.created", next())
+}
+
+func TestStream_Controller_AsksStaleClientsToReload(t *testing.T) {
+	controller := NewStreamController(services.NewStreamHub(50))
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), UserIDKey, 1))
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream?lastEventId=20", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	controller.Stream(w, req)
+
+	assert.Equal(t, "retry: 3000\n\nevent: reset\ndata: {}\n\n", w.Body.String())
+}
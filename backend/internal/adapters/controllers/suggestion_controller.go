@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type SuggestionController struct {
+	suggestionService ports.CategorySuggestionService
+}
+
+func NewSuggestionController(suggestionService ports.CategorySuggestionService) *SuggestionController {
+	return &SuggestionController{suggestionService: suggestionService}
+}
+
+func (c *SuggestionController) SuggestCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	description := queryParams.Get("description")
+
+	var amount float64
+	if amountStr := queryParams.Get("amount"); amountStr != "" {
+		a, err := strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			writeError(w, invalidParam("amount", "amount must be a number"))
+			return
+		}
+		amount = a
+	}
+
+	suggestions, err := c.suggestionService.Suggest(userID, description, amount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type SyncController struct {
+	syncService ports.SyncService
+}
+
+func NewSyncController(syncService ports.SyncService) *SyncController {
+	return &SyncController{syncService: syncService}
+}
+
+// PushRequest carries the token from the client's last sync and the changes
+// it made offline, in the order it made them.
+type PushRequest struct {
+	Token     string                `json:"token"`
+	Mutations []domain.SyncMutation `json:"mutations"`
+}
+
+// Pull returns what changed since ?token=, or everything without one, and
+// the token to pull from next time.
+func (c *SyncController) Pull(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	changes, err := c.syncService.Pull(userID, r.URL.Query().Get("token"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+// Push applies the mutations and responds with the outcome of each, then
+// the changes since the request's token as Pull would.
+func (c *SyncController) Push(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req PushRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response, err := c.syncService.Push(r.Context(), userID, req.Token, req.Mutations)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -3,9 +3,11 @@ package controllers
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 )
 
@@ -32,74 +34,73 @@ type CreateTransactionRequest struct {
 	Amount      float64   `json:"amount"`
 	Category    string    `json:"category"`
 	Description string    `json:"description"`
+	Account     string    `json:"account"`
 	Date        time.Time `json:"date"`
 }
 
 func (h *TransactionController) CreateIncome(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	var req CreateTransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	transaction, err := h.transactionService.CreateIncome(userID, req.Amount, req.Category, req.Description, req.Date)
+	transaction, err := h.transactionService.CreateIncome(r.Context(), userID, req.Amount, req.Category, req.Description, req.Account, req.Date)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transaction)
+	writeCreatedJSON(w, transaction.Version, transaction)
 }
 
 func (h *TransactionController) CreateExpense(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	var req CreateTransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	transaction, err := h.transactionService.CreateExpense(userID, req.Amount, req.Category, req.Description, req.Date)
+	transaction, err := h.transactionService.CreateExpense(r.Context(), userID, req.Amount, req.Category, req.Description, req.Account, req.Date)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transaction)
+	writeCreatedJSON(w, transaction.Version, transaction)
 }
 
 func (h *TransactionController) ListTransactions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
@@ -124,29 +125,52 @@ func (h *TransactionController) ListTransactions(w http.ResponseWriter, r *http.
 
 	transactions, err := h.transactionService.ListTransactions(userID, month, year)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transactions)
+	writeListJSON(w, r, transactions)
+}
+
+// GetTransaction returns the transaction with its version as the ETag, to
+// send back in If-Match when updating or deleting it.
+func (h *TransactionController) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransaction(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersionedJSON(w, r, transaction.Version, transaction)
 }
 
 func (h *TransactionController) ResetData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
-	err := h.transactionService.ResetData(userID)
+	err := h.transactionService.ResetData(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -156,31 +180,31 @@ func (h *TransactionController) ResetData(w http.ResponseWriter, r *http.Request
 
 func (h *TransactionController) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	idStr := r.PathValue("id")
 	if idStr == "" {
-		http.Error(w, "Missing ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id is required"))
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id must be a number"))
 		return
 	}
 
-	err = h.transactionService.DeleteTransaction(userID, id)
+	err = h.transactionService.DeleteTransaction(r.Context(), userID, id, ifMatchVersion(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -192,45 +216,91 @@ type UpdateTransactionRequest struct {
 	Amount      float64   `json:"amount"`
 	Category    string    `json:"category"`
 	Description string    `json:"description"`
+	Account     string    `json:"account"`
 	Date        time.Time `json:"date"`
 	Type        string    `json:"type"`
 }
 
 func (h *TransactionController) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "PUT" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID, ok := r.Context().Value(UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, errUnauthorized)
 		return
 	}
 
 	idStr := r.PathValue("id")
 	if idStr == "" {
-		http.Error(w, "Missing ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id is required"))
 		return
 	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, invalidParam("id", "id must be a number"))
 		return
 	}
 
 	var req UpdateTransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	err = h.transactionService.UpdateTransaction(userID, id, req.Amount, req.Category, req.Description, req.Date, req.Type)
+	transaction, err := h.transactionService.UpdateTransaction(r.Context(), userID, id, ifMatchVersion(r), req.Amount, req.Category, req.Description, req.Account, req.Date, req.Type)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Transaction updated"})
+	writeVersionedJSON(w, r, transaction.Version, transaction)
+}
+
+type BatchTransactionsRequest struct {
+	Mode       string                  `json:"mode"`
+	Operations []domain.BatchOperation `json:"operations"`
+}
+
+type BatchTransactionsResponse struct {
+	Mode    string               `json:"mode"`
+	Results []domain.BatchResult `json:"results"`
+}
+
+// BatchTransactions applies mixed creates, updates and deletes. An atomic
+// batch that was not applied answers 422 with the operation that failed;
+// a partial batch answers 200 with the outcome of each operation.
+func (h *TransactionController) BatchTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req BatchTransactionsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = domain.BatchModeAtomic
+	}
+
+	results, err := h.transactionService.ApplyBatch(r.Context(), userID, req.Mode, req.Operations)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if req.Mode == domain.BatchModeAtomic && slices.ContainsFunc(results, func(result domain.BatchResult) bool {
+		return result.Status == domain.BatchFailed
+	}) {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(BatchTransactionsResponse{Mode: req.Mode, Results: results})
 }
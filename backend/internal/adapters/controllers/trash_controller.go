@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type TrashController struct {
+	trashService ports.TrashService
+}
+
+func NewTrashController(trashService ports.TrashService) *TrashController {
+	return &TrashController{trashService: trashService}
+}
+
+func (c *TrashController) ListTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	trash, err := c.trashService.ListTrash(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trash)
+}
+
+func (c *TrashController) RestoreTransaction(w http.ResponseWriter, r *http.Request) {
+	c.restore(w, r, c.trashService.RestoreTransaction, "Transaction restored")
+}
+
+func (c *TrashController) RestoreGoal(w http.ResponseWriter, r *http.Request) {
+	c.restore(w, r, c.trashService.RestoreGoal, "Goal restored")
+}
+
+func (c *TrashController) restore(w http.ResponseWriter, r *http.Request, restoreFn func(ctx context.Context, userID, id int) error, message string) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := restoreFn(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func (c *TrashController) UndoReset(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	restored, err := c.trashService.UndoReset(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"restored": restored})
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type WebhookController struct {
+	webhookService ports.WebhookService
+}
+
+func NewWebhookController(webhookService ports.WebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+// WebhookRequest with no event types subscribes to every event.
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+func (req WebhookRequest) toDomain() domain.Webhook {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return domain.Webhook{URL: req.URL, EventTypes: req.EventTypes, Active: active}
+}
+
+// CreateWebhook responds with the signing secret, which is not shown again.
+func (c *WebhookController) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	var req WebhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhook, err := c.webhookService.CreateWebhook(r.Context(), userID, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+func (c *WebhookController) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	webhooks, err := c.webhookService.ListWebhooks(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// UpdateWebhook also turns a webhook that was disabled after repeated
+// failures back on, with "active": true.
+func (c *WebhookController) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	var req WebhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhook, err := c.webhookService.UpdateWebhook(r.Context(), userID, id, req.toDomain())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+func (c *WebhookController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	if err := c.webhookService.DeleteWebhook(r.Context(), userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Webhook deleted"}`))
+}
+
+// ListDeliveries returns the delivery log with each attempt's response code.
+func (c *WebhookController) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+
+	deliveries, err := c.webhookService.ListDeliveries(userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// Redeliver sends a delivery again and responds with the new delivery and
+// the outcome of its first attempt.
+func (c *WebhookController) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int)
+	if !ok {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, invalidParam("id", "id must be a number"))
+		return
+	}
+	deliveryID, err := strconv.Atoi(r.PathValue("deliveryID"))
+	if err != nil {
+		writeError(w, invalidParam("deliveryID", "delivery id must be a number"))
+		return
+	}
+
+	delivery, err := c.webhookService.Redeliver(r.Context(), userID, id, deliveryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+// SMTPChannel sends notifications by email. smtp.SendMail upgrades to TLS
+// when the server offers STARTTLS.
+type SMTPChannel struct {
+	cfg SMTPConfig
+	now func() time.Time
+}
+
+func NewSMTPChannel(cfg SMTPConfig) *SMTPChannel {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTPChannel{cfg: cfg, now: time.Now}
+}
+
+func (c *SMTPChannel) Name() string {
+	return domain.ChannelEmail
+}
+
+func (c *SMTPChannel) Deliver(ctx context.Context, to domain.NotificationRecipient, n domain.Notification) error {
+	if to.Email == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if c.cfg.User != "" {
+		auth = smtp.PlainAuth("", c.cfg.User, c.cfg.Password, c.cfg.Host)
+	}
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	return smtp.SendMail(addr, auth, c.cfg.From, []string{to.Email}, emailMessage(c.cfg.From, to.Email, n, c.now()))
+}
+
+// CaptureChannel writes each email as an .eml file instead of sending it,
+// for local development.
+type CaptureChannel struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+func NewCaptureChannel(dir, from string) (*CaptureChannel, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &CaptureChannel{dir: dir, from: from, now: time.Now}, nil
+}
+
+func (c *CaptureChannel) Name() string {
+	return domain.ChannelEmail
+}
+
+func (c *CaptureChannel) Deliver(ctx context.Context, to domain.NotificationRecipient, n domain.Notification) error {
+	if to.Email == "" {
+		return nil
+	}
+	now := c.now()
+	name := fmt.Sprintf("%s-%d-%d.eml", now.UTC().Format("20060102T150405"), to.UserID, n.ID)
+	return os.WriteFile(filepath.Join(c.dir, name), emailMessage(c.from, to.Email, n, now), 0o644)
+}
+
+func emailMessage(from, to string, n domain.Notification, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(n.Body)
+	msg.WriteString("\r\n")
+	return msg.Bytes()
+}
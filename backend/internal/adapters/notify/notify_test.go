@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// browser plays the user agent side of a push subscription.
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) browser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return browser{key: key, auth: auth}
+}
+
+func (b browser) subscription(endpoint string) domain.PushSubscription {
+	return domain.PushSubscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt reverses encryptPayload as RFC 8291 tells the browser to.
+func (b browser) decrypt(t *testing.T, body []byte) []byte {
+	salt, rs, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	assert.Equal(t, uint32(recordSize), rs)
+	asPublicBytes := body[21 : 21+idLen]
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	assert.NoError(t, err)
+	shared, _ := b.key.ECDH(asPublic)
+
+	keyInfo := "WebPush: info\x00" + string(b.key.PublicKey().Bytes()) + string(asPublicBytes)
+	ikm, _ := hkdf.Key(sha256.New, shared, b.auth, keyInfo, 32)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil || plaintext[len(plaintext)-1] != 0x02 {
+		t.Errorf("could not decrypt push payload: %v", err)
+		return nil
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func newTestChannel(t *testing.T) *WebPushChannel {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	channel, err := NewWebPushChannel(VAPIDConfig{
+		PrivateKey: base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		Subject:    "mailto:ops@plena.app",
+	})
+	assert.NoError(t, err)
+	return channel
+}
+
+func TestWebPushChannel_EncryptsAndSignsForTheSubscription(t *testing.T) {
+	channel := newTestChannel(t)
+	b := newBrowser(t)
+
+	var got pushMessage
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.NotEmpty(t, r.Header.Get("TTL"))
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(b.decrypt(t, body), &got))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	channel.client = server.Client()
+
+	to := domain.NotificationRecipient{UserID: 1, PushSubscriptions: []domain.PushSubscription{b.subscription(server.URL + "/push/abc")}}
+	err := channel.Deliver(context.Background(), to, domain.Notification{Title: "Luz is overdue"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Luz is overdue", got.Title)
+
+	// The VAPID token is signed by the key the PWA subscribed with and is
+	// scoped to the push service's origin.
+	fields := strings.Split(strings.TrimPrefix(authorization, "vapid "), ", ")
+	if !assert.Len(t, fields, 2) {
+		return
+	}
+	assert.Equal(t, "k="+channel.PublicKey(), fields[1])
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(strings.TrimPrefix(fields[0], "t="), claims, func(*jwt.Token) (any, error) {
+		return &channel.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, claims["aud"])
+	assert.Equal(t, "mailto:ops@plena.app", claims["sub"])
+}
+
+func TestWebPushChannel_ReportsExpiredSubscriptions(t *testing.T) {
+	channel := newTestChannel(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	channel.client = server.Client()
+
+	to := domain.NotificationRecipient{UserID: 1, PushSubscriptions: []domain.PushSubscription{
+		newBrowser(t).subscription(server.URL + "/live"),
+		newBrowser(t).subscription(server.URL + "/gone"),
+	}}
+	err := channel.Deliver(context.Background(), to, domain.Notification{Title: "t"})
+
+	var expired *ports.PushExpiredError
+	assert.ErrorAs(t, err, &expired)
+	assert.Equal(t, []string{server.URL + "/gone"}, expired.Endpoints)
+}
+
+func TestCaptureChannel_WritesEmail(t *testing.T) {
+	dir := t.TempDir()
+	channel, err := NewCaptureChannel(dir, "Plena <no-reply@plena.app>")
+	assert.NoError(t, err)
+
+	to := domain.NotificationRecipient{UserID: 7, Email: "ana@example.com"}
+	err = channel.Deliver(context.Background(), to, domain.Notification{ID: 3, Title: "Meta concluída", Body: "Parabéns!"})
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if !assert.Len(t, files, 1) {
+		return
+	}
+	msg, _ := os.ReadFile(files[0])
+	assert.Contains(t, string(msg), "To: ana@example.com\r\n")
+	assert.Contains(t, string(msg), "Subject: =?utf-8?q?Meta_conclu=C3=ADda?=\r\n")
+	assert.True(t, strings.HasSuffix(string(msg), "\r\n\r\nParabéns!\r\n"))
+}
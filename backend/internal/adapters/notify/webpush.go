@@ -0,0 +1,244 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// pushTTL is how long the push service keeps a message for an offline
+// device.
+const pushTTL = 24 * time.Hour
+
+// recordSize is the aes128gcm record size; a notification fits in one.
+const recordSize = 4096
+
+type VAPIDConfig struct {
+	// PrivateKey is the base64url P-256 private scalar, as printed by
+	// `npx web-push generate-vapid-keys`.
+	PrivateKey string
+	// Subject is a mailto: or https: contact for the push service.
+	Subject string
+}
+
+// WebPushChannel sends notifications to the installed PWA through each
+// browser's push service, identified with VAPID (RFC 8292) and encrypted
+// with aes128gcm (RFC 8291).
+type WebPushChannel struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewWebPushChannel(cfg VAPIDConfig) (*WebPushChannel, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	private, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public := private.PublicKey().Bytes()
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	return &WebPushChannel{
+		key:       key,
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		subject:   cfg.Subject,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}, nil
+}
+
+// PublicKey is the applicationServerKey the PWA subscribes with.
+func (c *WebPushChannel) PublicKey() string {
+	return c.publicKey
+}
+
+func (c *WebPushChannel) Name() string {
+	return domain.ChannelPush
+}
+
+type pushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	ID    int    `json:"id"`
+}
+
+// Deliver sends to every subscription of the recipient. Subscriptions the
+// push service reports gone come back in a ports.PushExpiredError.
+func (c *WebPushChannel) Deliver(ctx context.Context, to domain.NotificationRecipient, n domain.Notification) error {
+	payload, err := json.Marshal(pushMessage{Title: n.Title, Body: n.Body, URL: n.URL, ID: n.ID})
+	if err != nil {
+		return err
+	}
+
+	var failures []error
+	expired := &ports.PushExpiredError{}
+	for _, sub := range to.PushSubscriptions {
+		status, err := c.send(ctx, sub, payload)
+		switch {
+		case err != nil:
+			failures = append(failures, err)
+		case status == http.StatusNotFound || status == http.StatusGone:
+			expired.Endpoints = append(expired.Endpoints, sub.Endpoint)
+		case status >= 300:
+			failures = append(failures, fmt.Errorf("push service returned %d", status))
+		}
+	}
+	if len(expired.Endpoints) > 0 {
+		if len(failures) == 0 {
+			return expired
+		}
+		failures = append(failures, expired)
+	}
+	return errors.Join(failures...)
+}
+
+func (c *WebPushChannel) send(ctx context.Context, sub domain.PushSubscription, payload []byte) (int, error) {
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return 0, err
+	}
+	authorization, err := c.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// vapidAuthorization signs a token for the push service's origin.
+func (c *WebPushChannel) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": c.now().Add(12 * time.Hour).Unix(),
+		"sub": c.subject,
+	})
+	signed, err := token.SignedString(c.key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, c.publicKey), nil
+}
+
+// encryptPayload builds an aes128gcm body (RFC 8188) keyed as RFC 8291
+// describes: a fresh ECDH key per message, mixed with the subscription's
+// auth secret.
+func encryptPayload(sub domain.PushSubscription, payload []byte) ([]byte, error) {
+	uaPublicBytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.P256dh, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.Auth, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublicBytes) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// A single record ends with the 0x02 delimiter and no padding.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if len(plaintext)+gcm.Overhead() > recordSize {
+		return nil, errors.New("push payload too large")
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,95 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+const eventChannel = "plena_events"
+
+// maxNotifyPayload keeps messages under Postgres' 8000 byte NOTIFY limit.
+const maxNotifyPayload = 7900
+
+// PostgresEventChannel passes domain events between API instances with
+// LISTEN/NOTIFY.
+type PostgresEventChannel struct {
+	db      *sql.DB
+	connStr string
+}
+
+// NewPostgresEventChannel publishes on db and listens on a dedicated
+// connection opened with connStr.
+func NewPostgresEventChannel(db *sql.DB, connStr string) *PostgresEventChannel {
+	return &PostgresEventChannel{db: db, connStr: connStr}
+}
+
+// Publish sends the event to every listener. An event too large for NOTIFY
+// goes out without its payload; clients then fetch the row themselves.
+func (c *PostgresEventChannel) Publish(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxNotifyPayload {
+		event.Payload = nil
+		if data, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	_, err = c.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventChannel, string(data))
+	return err
+}
+
+// Listen hands every published event to deliver until stop is called.
+// Notifications sent while the connection was down are lost, so reconnected
+// runs once it is back.
+func (c *PostgresEventChannel) Listen(deliver func(domain.Event), reconnected func()) (stop func(), err error) {
+	listener := pq.NewListener(c.connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener: %v", err)
+		}
+	})
+	if err := listener.Listen(eventChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ping := time.NewTicker(time.Minute)
+		defer ping.Stop()
+		for {
+			select {
+			case n := <-listener.Notify:
+				// A nil notification means the connection was re-established.
+				if n == nil {
+					reconnected()
+					continue
+				}
+				var event domain.Event
+				if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+					log.Printf("Event listener: bad notification: %v", err)
+					continue
+				}
+				deliver(event)
+			case <-ping.C:
+				// Pinging notices a dead connection sooner than waiting for the next event.
+				listener.Ping()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		listener.Close()
+	}, nil
+}
@@ -0,0 +1,34 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+func TestPublish_DropsOversizedPayload(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	channel := NewPostgresEventChannel(db, "")
+
+	event := domain.Event{
+		ID:         12,
+		Type:       domain.EventTransactionUpdated,
+		UserID:     3,
+		Payload:    json.RawMessage(`{"description":"` + strings.Repeat("x", maxNotifyPayload) + `"}`),
+		OccurredAt: time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC),
+	}
+	mock.ExpectExec(`SELECT pg_notify`).
+		WithArgs(eventChannel, `{"id":12,"type":"transaction.updated","user_id":3,"aggregate_type":"","aggregate_id":0,"payload":null,"occurred_at":"2026-05-04T09:00:00Z"}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, channel.Publish(context.Background(), event))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+// monthly_category_totals (migration 022) holds, per user, month, type and
+// category, the sum and count of active (not trashed) transactions. The
+// transaction repository keeps it current inside the same database
+// transaction as every write, so reports never have to scan the
+// transactions table.
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// adjustMonthlyTotals adds (sign 1) or removes (sign -1) one transaction.
+func adjustMonthlyTotals(tx execer, t domain.Transaction, sign int) error {
+	year, month := t.Date.Year(), int(t.Date.Month())
+	query := `
+		INSERT INTO monthly_category_totals (user_id, year, month, type, category, total, count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, year, month, type, category) DO UPDATE
+		SET total = monthly_category_totals.total + EXCLUDED.total,
+			count = monthly_category_totals.count + EXCLUDED.count
+	`
+	if _, err := tx.Exec(query, t.UserID, year, month, t.Type, t.Category, float64(sign)*t.Amount, sign); err != nil {
+		return err
+	}
+	if sign > 0 {
+		return nil
+	}
+	_, err := tx.Exec(`DELETE FROM monthly_category_totals
+		WHERE user_id = $1 AND year = $2 AND month = $3 AND type = $4 AND category = $5 AND count <= 0`,
+		t.UserID, year, month, t.Type, t.Category)
+	return err
+}
+
+// rebuildMonthlyTotals recomputes the rollup of one user, or of everyone
+// when userID is 0.
+func rebuildMonthlyTotals(tx execer, userID int) error {
+	if _, err := tx.Exec(`DELETE FROM monthly_category_totals WHERE $1 = 0 OR user_id = $1`, userID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO monthly_category_totals (user_id, year, month, type, category, total, count)
+		SELECT user_id, EXTRACT(YEAR FROM date)::int, EXTRACT(MONTH FROM date)::int, type, COALESCE(category, ''), SUM(amount), COUNT(*)
+		FROM transactions
+		WHERE deleted_at IS NULL AND user_id IS NOT NULL AND ($1 = 0 OR user_id = $1)
+		GROUP BY 1, 2, 3, 4, 5
+	`, userID)
+	return err
+}
+
+// RebuildMonthlyTotals recomputes the rollup from the transactions table for
+// one user, or for every user when userID is 0.
+func (r *PostgresTransactionRepository) RebuildMonthlyTotals(userID int) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		return rebuildMonthlyTotals(tx, userID)
+	})
+}
+
+// CheckMonthlyTotals compares the rollup with a fresh aggregation of the
+// transactions table and returns every row that differs.
+func (r *PostgresTransactionRepository) CheckMonthlyTotals() ([]domain.RollupMismatch, error) {
+	query := `
+		WITH actual AS (
+			SELECT user_id, EXTRACT(YEAR FROM date)::int AS year, EXTRACT(MONTH FROM date)::int AS month,
+				type, COALESCE(category, '') AS category, SUM(amount) AS total, COUNT(*)::int AS count
+			FROM transactions
+			WHERE deleted_at IS NULL AND user_id IS NOT NULL
+			GROUP BY 1, 2, 3, 4, 5
+		)
+		SELECT user_id, year, month, type, category,
+			COALESCE(a.total, 0), COALESCE(a.count, 0), COALESCE(m.total, 0), COALESCE(m.count, 0)
+		FROM actual a
+		FULL OUTER JOIN monthly_category_totals m USING (user_id, year, month, type, category)
+		WHERE a.total IS DISTINCT FROM m.total OR a.count IS DISTINCT FROM m.count
+		ORDER BY user_id, year, month, type, category
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []domain.RollupMismatch
+	for rows.Next() {
+		var m domain.RollupMismatch
+		if err := rows.Scan(&m.UserID, &m.Year, &m.Month, &m.Type, &m.Category,
+			&m.ExpectedTotal, &m.ExpectedCount, &m.RollupTotal, &m.RollupCount); err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
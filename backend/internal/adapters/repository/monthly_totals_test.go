@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var transactionRowColumns = []string{"id", "user_id", "type", "amount", "category", "description", "account", "tags", "bucket", "date", "created_at", "deleted_at", "client_id", "version"}
+
+func TestTransactionRepository_SaveUpdatesRollup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &PostgresTransactionRepository{db: db}
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(7, 1))
+	mock.ExpectExec("INSERT INTO monthly_category_totals (.+) ON CONFLICT").
+		WithArgs(1, 2025, 3, "expense", "Mercado", 120.0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	saved, err := repo.Save(domain.Transaction{UserID: 1, Type: "expense", Amount: 120, Category: "Mercado", Date: date})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, saved.ID)
+	assert.Equal(t, 1, saved.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionRepository_UpdateMovesRollupBetweenMonths(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &PostgresTransactionRepository{db: db}
+	oldDate := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	newDate := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = (.+) FOR UPDATE").
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(transactionRowColumns).
+			AddRow(7, 1, "expense", 120.0, "Mercado", "", "", "{}", "", oldDate, oldDate, nil, "", 3))
+	mock.ExpectExec("UPDATE transactions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO monthly_category_totals").
+		WithArgs(1, 2025, 3, "expense", "Mercado", -120.0, -1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM monthly_category_totals (.+) count <= 0").
+		WithArgs(1, 2025, 3, "expense", "Mercado").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO monthly_category_totals").
+		WithArgs(1, 2025, 4, "expense", "Mercado", 150.0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Update(domain.Transaction{ID: 7, UserID: 1, Type: "expense", Amount: 150, Category: "Mercado", Date: newDate})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionRepository_DeleteMissingRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &PostgresTransactionRepository{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE transactions SET deleted_at = NOW\\(\\)").
+		WithArgs(7, 1, 0).
+		WillReturnRows(sqlmock.NewRows(transactionRowColumns))
+	mock.ExpectRollback()
+
+	err = repo.Delete(7, 1, 0)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionRepository_UpdateStaleVersionConflicts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &PostgresTransactionRepository{db: db}
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions (.+) FOR UPDATE").
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(transactionRowColumns).
+			AddRow(7, 1, "expense", 120.0, "Mercado", "", "", "{}", "", date, date, nil, "", 4))
+	mock.ExpectRollback()
+
+	err = repo.Update(domain.Transaction{ID: 7, UserID: 1, Type: "expense", Amount: 150, Category: "Mercado", Date: date, Version: 3})
+
+	assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionRepository_ApplyBatchRollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &PostgresTransactionRepository{db: db}
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(7, 1))
+	mock.ExpectExec("INSERT INTO monthly_category_totals").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE transactions SET deleted_at = NOW\\(\\)").
+		WithArgs(9, 1, 2).
+		WillReturnRows(sqlmock.NewRows(transactionRowColumns))
+	mock.ExpectQuery("SELECT 1 FROM transactions").
+		WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectRollback()
+
+	written, err := repo.ApplyBatch([]ports.TransactionWrite{
+		{Transaction: domain.Transaction{UserID: 1, Type: "expense", Amount: 120, Category: "Mercado", Date: date}},
+		{Transaction: domain.Transaction{ID: 9, UserID: 1, Version: 2}, Delete: true},
+	})
+
+	var batchErr *ports.BatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.Equal(t, 1, batchErr.Index)
+	}
+	assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	assert.Nil(t, written)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
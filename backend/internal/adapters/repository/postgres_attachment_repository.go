@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresAttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAttachmentRepository(db *sql.DB) *PostgresAttachmentRepository {
+	return &PostgresAttachmentRepository{db: db}
+}
+
+func (r *PostgresAttachmentRepository) Save(a domain.Attachment, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO attachments (user_id, transaction_id, file_name, content_type, size, storage_key, thumbnail_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(
+			query,
+			a.UserID,
+			a.TransactionID,
+			a.FileName,
+			a.ContentType,
+			a.Size,
+			a.StorageKey,
+			a.ThumbnailKey,
+			time.Now(),
+		).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *PostgresAttachmentRepository) GetByID(id, userID int) (domain.Attachment, error) {
+	query := `
+		SELECT id, user_id, transaction_id, file_name, content_type, size, storage_key, COALESCE(thumbnail_key, ''), created_at
+		FROM attachments
+		WHERE id = $1 AND user_id = $2
+	`
+	var a domain.Attachment
+	err := r.db.QueryRow(query, id, userID).Scan(
+		&a.ID, &a.UserID, &a.TransactionID, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.ThumbnailKey, &a.CreatedAt,
+	)
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return a, err
+}
+
+func (r *PostgresAttachmentRepository) ListByTransactionID(transactionID, userID int) ([]domain.Attachment, error) {
+	query := `
+		SELECT id, user_id, transaction_id, file_name, content_type, size, storage_key, COALESCE(thumbnail_key, ''), created_at
+		FROM attachments
+		WHERE transaction_id = $1 AND user_id = $2
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(query, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAttachments(rows)
+}
+
+func (r *PostgresAttachmentRepository) Delete(id, userID int, events ...domain.Event) error {
+	query := `DELETE FROM attachments WHERE id = $1 AND user_id = $2`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, id, userID))
+	})
+	return err
+}
+
+// DeleteByTransactionID removes the rows and returns them so the caller can
+// clean up the stored blobs.
+func (r *PostgresAttachmentRepository) DeleteByTransactionID(transactionID, userID int) ([]domain.Attachment, error) {
+	query := `
+		DELETE FROM attachments
+		WHERE transaction_id = $1 AND user_id = $2
+		RETURNING id, user_id, transaction_id, file_name, content_type, size, storage_key, COALESCE(thumbnail_key, ''), created_at
+	`
+	rows, err := r.db.Query(query, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAttachments(rows)
+}
+
+func scanAttachments(rows *sql.Rows) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	for rows.Next() {
+		var a domain.Attachment
+		err := rows.Scan(&a.ID, &a.UserID, &a.TransactionID, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.ThumbnailKey, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		a.HasThumbnail = a.ThumbnailKey != ""
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// appendAudit writes the audit event an EventAuditRecorded event carries
+// inside the caller's database transaction. On a create, the event records
+// the row the transaction just created: it gets its id as the entity id and
+// as the id of the after snapshot.
+func appendAudit(tx execer, payload []byte, createdID int) error {
+	var e domain.AuditEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return err
+	}
+	if change, ok := e.Changes["id"]; ok && createdID != 0 && change.Before == nil && change.After == float64(0) {
+		change.After = createdID
+		e.Changes["id"] = change
+		e.After = withPayloadID(e.After, createdID)
+	}
+	if e.EntityID == 0 {
+		e.EntityID = createdID
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_events (user_id, actor_id, entity_type, entity_id, action, before, after, changes, request_id, ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = tx.Exec(
+		query,
+		e.UserID,
+		e.ActorID,
+		e.EntityType,
+		e.EntityID,
+		e.Action,
+		nullableJSON(e.Before),
+		nullableJSON(e.After),
+		changes,
+		e.RequestID,
+		e.IP,
+		time.Now(),
+	)
+	return err
+}
+
+func (r *PostgresAuditRepository) ListByEntity(userID int, entityType string, entityID int) ([]domain.AuditEvent, error) {
+	query := `
+		SELECT id, user_id, actor_id, entity_type, entity_id, action, before, after, changes,
+			COALESCE(request_id, ''), COALESCE(ip, ''), created_at
+		FROM audit_events
+		WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := r.db.Query(query, userID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		var e domain.AuditEvent
+		var before, after, changes []byte
+		err := rows.Scan(&e.ID, &e.UserID, &e.ActorID, &e.EntityType, &e.EntityID, &e.Action, &before, &after, &changes, &e.RequestID, &e.IP, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.Before = before
+		e.After = after
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func auditEvent(entityID int, payload string) domain.Event {
+	return domain.Event{Type: domain.EventAuditRecorded, UserID: 1, AggregateType: domain.AuditEntityRule, AggregateID: entityID, Payload: []byte(payload)}
+}
+
+func TestRuleRepository_SaveWritesAuditWithTheCreatedID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRuleRepository(db)
+	event := auditEvent(0, `{"user_id":1,"actor_id":1,"entity_type":"rule","entity_id":0,"action":"create",`+
+		`"after":{"id":0,"name":"Mercado"},"changes":{"id":{"before":null,"after":0},"name":{"before":null,"after":"Mercado"}},`+
+		`"request_id":"req-1","ip":"10.0.0.1"}`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO category_rules").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs(1, 1, domain.AuditEntityRule, 9, domain.AuditActionCreate, nil, []byte(`{"id":9,"name":"Mercado"}`),
+			[]byte(`{"id":{"before":null,"after":9},"name":{"before":null,"after":"Mercado"}}`), "req-1", "10.0.0.1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	id, err := repo.Save(domain.CategoryRule{UserID: 1, Name: "Mercado"}, event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRuleRepository_DeleteRollsBackWhenAuditFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRuleRepository(db)
+	auditDown := errors.New("audit_events unavailable")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM category_rules").
+		WithArgs(4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WillReturnError(auditDown)
+	mock.ExpectRollback()
+
+	err = repo.Delete(4, 1, auditEvent(4, `{"user_id":1,"actor_id":1,"entity_type":"rule","entity_id":4,"action":"delete","before":{"id":4},"changes":{}}`))
+
+	assert.ErrorIs(t, err, auditDown)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
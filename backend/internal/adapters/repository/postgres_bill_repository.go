@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresBillRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresBillRepository(db *sql.DB) *PostgresBillRepository {
+	return &PostgresBillRepository{db: db}
+}
+
+func (r *PostgresBillRepository) Save(b domain.Bill, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO bills (user_id, payee, amount, estimated, category, account, due_date, due_day, recurrence, status, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, b.UserID, b.Payee, b.Amount, b.Estimated, b.Category, b.Account,
+			b.DueDate, b.DueDay, b.Recurrence, b.Status, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+// Update leaves paid bills alone.
+func (r *PostgresBillRepository) Update(b domain.Bill, events ...domain.Event) error {
+	query := `
+		UPDATE bills
+		SET payee = $1, amount = $2, estimated = $3, category = NULLIF($4, ''), account = NULLIF($5, ''),
+			due_date = $6, due_day = $7, recurrence = $8, status = $9
+		WHERE id = $10 AND user_id = $11 AND status <> 'paid'
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, b.Payee, b.Amount, b.Estimated, b.Category, b.Account,
+			b.DueDate, b.DueDay, b.Recurrence, b.Status, b.ID, b.UserID))
+	})
+	return err
+}
+
+func (r *PostgresBillRepository) Delete(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(`DELETE FROM bills WHERE id = $1 AND user_id = $2`, id, userID))
+	})
+	return err
+}
+
+const billColumns = `id, user_id, payee, amount, estimated, COALESCE(category, ''), COALESCE(account, ''),
+	due_date, due_day, recurrence, status, COALESCE(paid_amount, 0), paid_at, transaction_id, created_at`
+
+func scanBill(row interface{ Scan(...any) error }) (domain.Bill, error) {
+	var b domain.Bill
+	var paidAt sql.NullTime
+	var transactionID sql.NullInt64
+	err := row.Scan(&b.ID, &b.UserID, &b.Payee, &b.Amount, &b.Estimated, &b.Category, &b.Account,
+		&b.DueDate, &b.DueDay, &b.Recurrence, &b.Status, &b.PaidAmount, &paidAt, &transactionID, &b.CreatedAt)
+	if paidAt.Valid {
+		b.PaidAt = &paidAt.Time
+	}
+	if transactionID.Valid {
+		id := int(transactionID.Int64)
+		b.TransactionID = &id
+	}
+	return b, err
+}
+
+func scanBills(rows *sql.Rows) ([]domain.Bill, error) {
+	defer rows.Close()
+
+	var bills []domain.Bill
+	for rows.Next() {
+		b, err := scanBill(rows)
+		if err != nil {
+			return nil, err
+		}
+		bills = append(bills, b)
+	}
+	return bills, rows.Err()
+}
+
+func (r *PostgresBillRepository) GetByID(id, userID int) (domain.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bills WHERE id = $1 AND user_id = $2`
+	return scanBill(r.db.QueryRow(query, id, userID))
+}
+
+func (r *PostgresBillRepository) ListByUserID(userID int) ([]domain.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bills WHERE user_id = $1 ORDER BY due_date ASC, id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBills(rows)
+}
+
+// MarkPaid records the payment only if the bill is still unpaid, so a
+// retried request cannot pay it twice.
+func (r *PostgresBillRepository) MarkPaid(b domain.Bill, events ...domain.Event) error {
+	query := `
+		UPDATE bills
+		SET status = 'paid', paid_amount = $1, paid_at = $2, transaction_id = $3
+		WHERE id = $4 AND user_id = $5 AND status <> 'paid'
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, b.PaidAmount, b.PaidAt, b.TransactionID, b.ID, b.UserID))
+	})
+	return err
+}
+
+// ListUnpaidDueBefore returns every user's unpaid bills due before cutoff.
+func (r *PostgresBillRepository) ListUnpaidDueBefore(cutoff time.Time) ([]domain.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bills WHERE status <> 'paid' AND due_date < $1 ORDER BY user_id ASC, due_date ASC`
+	rows, err := r.db.Query(query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	return scanBills(rows)
+}
+
+func (r *PostgresBillRepository) SetStatus(ids []int, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.Exec(`UPDATE bills SET status = $1 WHERE id = ANY($2) AND status <> 'paid'`, status, pq.Array(ids))
+	return err
+}
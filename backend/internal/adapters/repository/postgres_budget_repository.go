@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type PostgresBudgetRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresBudgetRepository(db *sql.DB) *PostgresBudgetRepository {
+	return &PostgresBudgetRepository{db: db}
+}
+
+const budgetColumns = `id, user_id, category, amount, month, year, rollover, created_at`
+
+func (r *PostgresBudgetRepository) Save(b domain.Budget, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO budgets (user_id, category, amount, month, year, rollover, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, b.UserID, b.Category, b.Amount, b.Month, b.Year, b.Rollover, time.Now()).Scan(&id)
+		return id, budgetError(err)
+	})
+}
+
+func (r *PostgresBudgetRepository) Update(b domain.Budget, events ...domain.Event) error {
+	query := `
+		UPDATE budgets
+		SET category = $1, amount = $2, month = $3, year = $4, rollover = $5
+		WHERE id = $6 AND user_id = $7
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		result, err := q.Exec(query, b.Category, b.Amount, b.Month, b.Year, b.Rollover, b.ID, b.UserID)
+		return 0, affectedOne(result, budgetError(err))
+	})
+	return err
+}
+
+func (r *PostgresBudgetRepository) Delete(id, userID int, events ...domain.Event) error {
+	query := `DELETE FROM budgets WHERE id = $1 AND user_id = $2`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, id, userID))
+	})
+	return err
+}
+
+func (r *PostgresBudgetRepository) GetByID(id, userID int) (domain.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1 AND user_id = $2`
+	var b domain.Budget
+	err := r.db.QueryRow(query, id, userID).Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Month, &b.Year, &b.Rollover, &b.CreatedAt)
+	return b, err
+}
+
+func (r *PostgresBudgetRepository) ListByUserID(userID int) ([]domain.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 ORDER BY category ASC, year ASC, month ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []domain.Budget
+	for rows.Next() {
+		var b domain.Budget
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Month, &b.Year, &b.Rollover, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// SaveAlert stores an alert unless the same threshold already fired for the
+// category and month; created reports whether a row was inserted.
+func (r *PostgresBudgetRepository) SaveAlert(a domain.BudgetAlert) (bool, error) {
+	query := `
+		INSERT INTO budget_alerts (user_id, category, month, year, threshold, spent, available, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, category, year, month, threshold) DO NOTHING
+	`
+	result, err := r.db.Exec(query, a.UserID, a.Category, a.Month, a.Year, a.Threshold, a.Spent, a.Available, time.Now())
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *PostgresBudgetRepository) ListAlerts(userID, month, year int) ([]domain.BudgetAlert, error) {
+	query := `
+		SELECT id, user_id, category, month, year, threshold, spent, available, created_at
+		FROM budget_alerts
+		WHERE user_id = $1 AND month = $2 AND year = $3
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(query, userID, month, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []domain.BudgetAlert
+	for rows.Next() {
+		var a domain.BudgetAlert
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Category, &a.Month, &a.Year, &a.Threshold, &a.Spent, &a.Available, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+func budgetError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ports.ErrBudgetExists
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type PostgresDebtRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDebtRepository(db *sql.DB) *PostgresDebtRepository {
+	return &PostgresDebtRepository{db: db}
+}
+
+func (r *PostgresDebtRepository) SaveDebt(d domain.Debt, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO debts (user_id, name, principal, interest_rate, amortization_system, installments, first_due_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, d.UserID, d.Name, d.Principal, d.InterestRate, d.AmortizationSystem, d.Installments, d.FirstDueDate, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+const debtColumns = `id, user_id, name, principal, interest_rate, amortization_system, installments, first_due_date, created_at`
+
+func scanDebt(row interface{ Scan(...any) error }) (domain.Debt, error) {
+	var d domain.Debt
+	err := row.Scan(&d.ID, &d.UserID, &d.Name, &d.Principal, &d.InterestRate, &d.AmortizationSystem, &d.Installments, &d.FirstDueDate, &d.CreatedAt)
+	return d, err
+}
+
+func (r *PostgresDebtRepository) GetDebt(id, userID int) (domain.Debt, error) {
+	query := `SELECT ` + debtColumns + ` FROM debts WHERE id = $1 AND user_id = $2`
+	return scanDebt(r.db.QueryRow(query, id, userID))
+}
+
+func (r *PostgresDebtRepository) ListDebts(userID int) ([]domain.Debt, error) {
+	query := `SELECT ` + debtColumns + ` FROM debts WHERE user_id = $1 ORDER BY first_due_date ASC, id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var debts []domain.Debt
+	for rows.Next() {
+		d, err := scanDebt(rows)
+		if err != nil {
+			return nil, err
+		}
+		debts = append(debts, d)
+	}
+	return debts, rows.Err()
+}
+
+// DeleteDebt also removes its payment links; the transactions stay.
+func (r *PostgresDebtRepository) DeleteDebt(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(`DELETE FROM debts WHERE id = $1 AND user_id = $2`, id, userID))
+	})
+	return err
+}
+
+func (r *PostgresDebtRepository) SavePayment(p domain.DebtPayment, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO debt_payments (debt_id, user_id, installment, transaction_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, p.DebtID, p.UserID, p.Installment, p.TransactionID, time.Now()).Scan(&id)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return 0, ports.ErrDebtPaymentExists
+			}
+			return 0, err
+		}
+		return id, nil
+	})
+}
+
+func (r *PostgresDebtRepository) DeletePayment(debtID, userID, installment int, events ...domain.Event) error {
+	query := `DELETE FROM debt_payments WHERE debt_id = $1 AND user_id = $2 AND installment = $3`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, debtID, userID, installment))
+	})
+	return err
+}
+
+func (r *PostgresDebtRepository) ListPayments(userID int) ([]domain.DebtPayment, error) {
+	query := `
+		SELECT p.id, p.debt_id, p.user_id, p.installment, p.transaction_id, t.amount, t.date, p.created_at
+		FROM debt_payments p
+		JOIN transactions t ON t.id = p.transaction_id
+		WHERE p.user_id = $1 AND t.deleted_at IS NULL
+		ORDER BY p.debt_id ASC, p.installment ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []domain.DebtPayment
+	for rows.Next() {
+		var p domain.DebtPayment
+		if err := rows.Scan(&p.ID, &p.DebtID, &p.UserID, &p.Installment, &p.TransactionID, &p.Amount, &p.Date, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type PostgresEnvelopeRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresEnvelopeRepository(db *sql.DB) *PostgresEnvelopeRepository {
+	return &PostgresEnvelopeRepository{db: db}
+}
+
+func (r *PostgresEnvelopeRepository) GetSettings(userID int) (domain.EnvelopeSettings, error) {
+	query := `SELECT user_id, enabled, start_month, start_year FROM envelope_settings WHERE user_id = $1`
+	var s domain.EnvelopeSettings
+	err := r.db.QueryRow(query, userID).Scan(&s.UserID, &s.Enabled, &s.StartMonth, &s.StartYear)
+	return s, err
+}
+
+func (r *PostgresEnvelopeRepository) SaveSettings(s domain.EnvelopeSettings, events ...domain.Event) error {
+	query := `
+		INSERT INTO envelope_settings (user_id, enabled, start_month, start_year)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, start_month = EXCLUDED.start_month, start_year = EXCLUDED.start_year
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		_, err := q.Exec(query, s.UserID, s.Enabled, s.StartMonth, s.StartYear)
+		return 0, err
+	})
+	return err
+}
+
+func (r *PostgresEnvelopeRepository) Save(e domain.Envelope, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO envelopes (user_id, name, category, goal_id, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, e.UserID, e.Name, e.Category, e.GoalID, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *PostgresEnvelopeRepository) Archive(id, userID int, events ...domain.Event) error {
+	query := `UPDATE envelopes SET archived_at = NOW() WHERE id = $1 AND user_id = $2 AND archived_at IS NULL`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, id, userID))
+	})
+	return err
+}
+
+const envelopeColumns = `id, user_id, name, COALESCE(category, ''), goal_id, created_at, archived_at`
+
+func (r *PostgresEnvelopeRepository) GetByID(id, userID int) (domain.Envelope, error) {
+	query := `SELECT ` + envelopeColumns + ` FROM envelopes WHERE id = $1 AND user_id = $2`
+	return scanEnvelope(r.db.QueryRow(query, id, userID))
+}
+
+// ListByUserID includes archived envelopes; their history still counts.
+func (r *PostgresEnvelopeRepository) ListByUserID(userID int) ([]domain.Envelope, error) {
+	query := `SELECT ` + envelopeColumns + ` FROM envelopes WHERE user_id = $1 ORDER BY name ASC, id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var envelopes []domain.Envelope
+	for rows.Next() {
+		e, err := scanEnvelope(rows)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, e)
+	}
+	return envelopes, rows.Err()
+}
+
+func scanEnvelope(row interface{ Scan(...any) error }) (domain.Envelope, error) {
+	var e domain.Envelope
+	var goalID sql.NullInt64
+	var archivedAt sql.NullTime
+	err := row.Scan(&e.ID, &e.UserID, &e.Name, &e.Category, &goalID, &e.CreatedAt, &archivedAt)
+	if goalID.Valid {
+		id := int(goalID.Int64)
+		e.GoalID = &id
+	}
+	if archivedAt.Valid {
+		e.ArchivedAt = &archivedAt.Time
+	}
+	return e, err
+}
+
+// SaveTransfer locks the user's envelope settings row, so transfers of one
+// user run one at a time and check sees every transfer committed before.
+// The transfer, its goal progress and events commit together.
+func (r *PostgresEnvelopeRepository) SaveTransfer(t domain.EnvelopeTransfer, check func() error, progress []ports.GoalProgress, events ...domain.Event) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	id, err := saveTransfer(tx, t, check, progress)
+	if err == nil {
+		err = appendEvents(tx, events, id)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func saveTransfer(tx *sql.Tx, t domain.EnvelopeTransfer, check func() error, progress []ports.GoalProgress) (int, error) {
+	var locked int
+	if err := tx.QueryRow(`SELECT user_id FROM envelope_settings WHERE user_id = $1 FOR UPDATE`, t.UserID).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if err := check(); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO envelope_transfers (user_id, from_envelope_id, to_envelope_id, amount, note, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id
+	`
+	var id int
+	if err := tx.QueryRow(query, t.UserID, t.FromEnvelopeID, t.ToEnvelopeID, t.Amount, t.Note, t.CreatedAt).Scan(&id); err != nil {
+		return 0, err
+	}
+	for _, p := range progress {
+		if err := addGoalProgress(tx, p.GoalID, t.UserID, p.Amount); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+func (r *PostgresEnvelopeRepository) ListTransfers(userID int) ([]domain.EnvelopeTransfer, error) {
+	query := `
+		SELECT id, user_id, from_envelope_id, to_envelope_id, amount, COALESCE(note, ''), created_at
+		FROM envelope_transfers
+		WHERE user_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []domain.EnvelopeTransfer
+	for rows.Next() {
+		var t domain.EnvelopeTransfer
+		var from, to sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.UserID, &from, &to, &t.Amount, &t.Note, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if from.Valid {
+			id := int(from.Int64)
+			t.FromEnvelopeID = &id
+		}
+		if to.Valid {
+			id := int(to.Int64)
+			t.ToEnvelopeID = &id
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
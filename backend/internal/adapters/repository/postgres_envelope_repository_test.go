@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeRepository_SaveTransferWithGoalProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresEnvelopeRepository(db)
+	from := 3
+	transfer := domain.EnvelopeTransfer{UserID: 1, FromEnvelopeID: &from, Amount: 250, CreatedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id FROM envelope_settings WHERE user_id = (.+) FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO envelope_transfers").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectExec("UPDATE goals SET current_amount").
+		WithArgs(-250.0, 7, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	checked := false
+	id, err := repo.SaveTransfer(transfer, func() error { checked = true; return nil },
+		[]ports.GoalProgress{{GoalID: 7, Amount: -250}},
+		domain.Event{Type: domain.EventGoalProgressAdded, AggregateType: domain.AggregateGoal, AggregateID: 7})
+
+	assert.NoError(t, err)
+	assert.True(t, checked)
+	assert.Equal(t, 11, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnvelopeRepository_SaveTransferRollsBackWhenCheckFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresEnvelopeRepository(db)
+	insufficient := errors.New("insufficient funds")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id FROM envelope_settings WHERE user_id = (.+) FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err = repo.SaveTransfer(domain.EnvelopeTransfer{UserID: 1, Amount: 50}, func() error { return insufficient }, nil)
+
+	assert.ErrorIs(t, err, insufficient)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
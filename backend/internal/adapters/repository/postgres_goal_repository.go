@@ -2,9 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 )
 
 type PostgresGoalRepository struct {
@@ -15,47 +19,80 @@ func NewPostgresGoalRepository(db *sql.DB) *PostgresGoalRepository {
 	return &PostgresGoalRepository{db: db}
 }
 
-func (r *PostgresGoalRepository) Save(goal domain.Goal) (int, error) {
+// Save returns goal with the id and version the database gave it, or
+// ports.ErrClientIDExists when the user already has a goal with its ClientID.
+func (r *PostgresGoalRepository) Save(goal domain.Goal, events ...domain.Event) (domain.Goal, error) {
 	query := `
-		INSERT INTO goals (user_id, name, target_amount, current_amount, deadline, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id
+		INSERT INTO goals (user_id, name, target_amount, current_amount, deadline, created_at, client_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+		RETURNING id, version
 	`
-	var id int
-	err := r.db.QueryRow(
-		query,
-		goal.UserID,
-		goal.Name,
-		goal.TargetAmount,
-		goal.CurrentAmount,
-		goal.Deadline,
-		time.Now(),
-	).Scan(&id)
-
-	return id, err
+	id, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(
+			query,
+			goal.UserID,
+			goal.Name,
+			goal.TargetAmount,
+			goal.CurrentAmount,
+			goal.Deadline,
+			time.Now(),
+			goal.ClientID,
+		).Scan(&id, &goal.Version)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, ports.ErrClientIDExists
+		}
+		return id, err
+	})
+	if err != nil {
+		return domain.Goal{}, err
+	}
+	goal.ID = id
+	return goal, nil
 }
 
-func (r *PostgresGoalRepository) Update(goal domain.Goal) error {
+// Update applies only to goal.Version of the row, unless it is 0.
+func (r *PostgresGoalRepository) Update(goal domain.Goal, events ...domain.Event) error {
 	query := `
 		UPDATE goals
 		SET name = $1, target_amount = $2, deadline = $3
-		WHERE id = $4 AND user_id = $5
+		WHERE id = $4 AND user_id = $5 AND deleted_at IS NULL AND ($6 = 0 OR version = $6)
 	`
-	_, err := r.db.Exec(query, goal.Name, goal.TargetAmount, goal.Deadline, goal.ID, goal.UserID)
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		result, err := q.Exec(query, goal.Name, goal.TargetAmount, goal.Deadline, goal.ID, goal.UserID, goal.Version)
+		if err != nil {
+			return 0, err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return 0, versionConflict(q, `SELECT 1 FROM goals WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, goal.ID, goal.UserID)
+		}
+		return goal.ID, nil
+	})
 	return err
 }
 
-func (r *PostgresGoalRepository) Delete(id, userID int) error {
-	query := `DELETE FROM goals WHERE id = $1 AND user_id = $2`
-	_, err := r.db.Exec(query, id, userID)
+// Delete moves the goal to the trash; PurgeDeletedBefore removes it.
+func (r *PostgresGoalRepository) Delete(id, userID, version int, events ...domain.Event) error {
+	query := `UPDATE goals SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		result, err := q.Exec(query, id, userID, version)
+		if err != nil {
+			return 0, err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return 0, versionConflict(q, `SELECT 1 FROM goals WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, id, userID)
+		}
+		return id, nil
+	})
 	return err
 }
 
 func (r *PostgresGoalRepository) ListByUserID(userID int) ([]domain.Goal, error) {
 	query := `
-		SELECT id, user_id, name, target_amount, current_amount, deadline, created_at
+		SELECT id, user_id, name, target_amount, current_amount, deadline, created_at, COALESCE(client_id::text, ''), version
 		FROM goals
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 	rows, err := r.db.Query(query, userID)
@@ -67,7 +104,7 @@ func (r *PostgresGoalRepository) ListByUserID(userID int) ([]domain.Goal, error)
 	var goals []domain.Goal
 	for rows.Next() {
 		var g domain.Goal
-		err := rows.Scan(&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt)
+		err := rows.Scan(&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt, &g.ClientID, &g.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -79,23 +116,151 @@ func (r *PostgresGoalRepository) ListByUserID(userID int) ([]domain.Goal, error)
 
 func (r *PostgresGoalRepository) GetByID(id, userID int) (domain.Goal, error) {
 	query := `
-		SELECT id, user_id, name, target_amount, current_amount, deadline, created_at
+		SELECT id, user_id, name, target_amount, current_amount, deadline, created_at, COALESCE(client_id::text, ''), version
 		FROM goals
-		WHERE id = $1 AND user_id = $2
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 	`
 	var g domain.Goal
 	err := r.db.QueryRow(query, id, userID).Scan(
-		&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt,
+		&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt, &g.ClientID, &g.Version,
 	)
 	return g, err
 }
 
-func (r *PostgresGoalRepository) AddProgress(id, userID int, amount float64) error {
+// AddProgress also records the contribution so yield can accrue from its
+// date; both happen in one statement.
+func (r *PostgresGoalRepository) AddProgress(id, userID int, amount float64, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return id, addGoalProgress(q, id, userID, amount)
+	})
+	return err
+}
+
+// addGoalProgress adds a signed amount to a goal; envelope transfers run it
+// inside their own transaction.
+func addGoalProgress(q execer, id, userID int, amount float64) error {
 	query := `
-		UPDATE goals
-		SET current_amount = current_amount + $1
-		WHERE id = $2 AND user_id = $3
+		WITH updated AS (
+			UPDATE goals
+			SET current_amount = current_amount + $1
+			WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL
+			RETURNING id
+		)
+		INSERT INTO goal_contributions (goal_id, user_id, amount, created_at)
+		SELECT id, $3, $1, NOW() FROM updated
+	`
+	result, err := q.Exec(query, amount, id, userID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresGoalRepository) ListContributions(id, userID int) ([]domain.GoalContribution, error) {
+	query := `
+		SELECT id, goal_id, user_id, amount, created_at
+		FROM goal_contributions
+		WHERE goal_id = $1 AND user_id = $2
+		ORDER BY created_at ASC, id ASC
 	`
-	_, err := r.db.Exec(query, amount, id, userID)
+	rows, err := r.db.Query(query, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributions []domain.GoalContribution
+	for rows.Next() {
+		var c domain.GoalContribution
+		if err := rows.Scan(&c.ID, &c.GoalID, &c.UserID, &c.Amount, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, c)
+	}
+	return contributions, rows.Err()
+}
+
+func (r *PostgresGoalRepository) GetInvestment(id, userID int) (domain.GoalInvestment, error) {
+	query := `SELECT goal_id, user_id, index, percent, spread FROM goal_investments WHERE goal_id = $1 AND user_id = $2`
+	var i domain.GoalInvestment
+	err := r.db.QueryRow(query, id, userID).Scan(&i.GoalID, &i.UserID, &i.Index, &i.Percent, &i.Spread)
+	return i, err
+}
+
+func (r *PostgresGoalRepository) SaveInvestment(i domain.GoalInvestment, events ...domain.Event) error {
+	query := `
+		INSERT INTO goal_investments (goal_id, user_id, index, percent, spread)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (goal_id) DO UPDATE
+		SET index = EXCLUDED.index, percent = EXCLUDED.percent, spread = EXCLUDED.spread
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		_, err := q.Exec(query, i.GoalID, i.UserID, i.Index, i.Percent, i.Spread)
+		return 0, err
+	})
 	return err
 }
+
+func (r *PostgresGoalRepository) DeleteInvestment(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		_, err := q.Exec(`DELETE FROM goal_investments WHERE goal_id = $1 AND user_id = $2`, id, userID)
+		return 0, err
+	})
+	return err
+}
+
+func (r *PostgresGoalRepository) ListDeleted(userID int) ([]domain.Goal, error) {
+	query := `
+		SELECT id, user_id, name, target_amount, current_amount, deadline, created_at, deleted_at
+		FROM goals
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []domain.Goal
+	for rows.Next() {
+		var g domain.Goal
+		var deletedAt time.Time
+		err := rows.Scan(&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		g.DeletedAt = &deletedAt
+		goals = append(goals, g)
+	}
+
+	return goals, nil
+}
+
+func (r *PostgresGoalRepository) Restore(id, userID int, events ...domain.Event) error {
+	query := `UPDATE goals SET deleted_at = NULL WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		result, err := q.Exec(query, id, userID)
+		if err != nil {
+			return 0, err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return 0, sql.ErrNoRows
+		}
+		return id, nil
+	})
+	return err
+}
+
+func (r *PostgresGoalRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	query := `DELETE FROM goals WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+	result, err := r.db.Exec(query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
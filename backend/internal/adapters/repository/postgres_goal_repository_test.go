@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,13 +27,64 @@ func TestGoalRepository_Save(t *testing.T) {
 	}
 
 	mock.ExpectQuery("INSERT INTO goals").
-		WithArgs(goal.UserID, goal.Name, goal.TargetAmount, goal.CurrentAmount, sqlmock.AnyArg(), sqlmock.AnyArg()).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WithArgs(goal.UserID, goal.Name, goal.TargetAmount, goal.CurrentAmount, sqlmock.AnyArg(), sqlmock.AnyArg(), "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
 
-	id, err := repo.Save(goal)
+	saved, err := repo.Save(goal)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1, id)
+	assert.Equal(t, 1, saved.ID)
+	assert.Equal(t, 1, saved.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGoalRepository_SaveWritesEventsInTheSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+	goal := domain.Goal{UserID: 1, Name: "Viagem", TargetAmount: 5000.0, Deadline: time.Now().AddDate(0, 6, 0)}
+	occurred := time.Now()
+	event := domain.Event{
+		Type:          domain.EventGoalCreated,
+		UserID:        1,
+		AggregateType: domain.AggregateGoal,
+		Payload:       []byte(`{"id":0,"name":"Viagem"}`),
+		OccurredAt:    occurred,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO goals").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(7, 1))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(domain.EventGoalCreated, 1, domain.AggregateGoal, 7, []byte(`{"id":7,"name":"Viagem"}`), occurred).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	saved, err := repo.Save(goal, event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, saved.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGoalRepository_AddProgressRollsBackEventsForMissingGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE goals SET current_amount").
+		WithArgs(500.0, 9, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.AddProgress(9, 1, 500.0, domain.Event{Type: domain.EventGoalProgressAdded, AggregateID: 9})
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -43,9 +96,9 @@ func TestGoalRepository_ListByUserID(t *testing.T) {
 	repo := NewPostgresGoalRepository(db)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "target_amount", "current_amount", "deadline", "created_at"}).
-		AddRow(1, 1, "Viagem", 5000.0, 1000.0, deadline, time.Now()).
-		AddRow(2, 1, "Carro", 30000.0, 5000.0, deadline, time.Now())
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "target_amount", "current_amount", "deadline", "created_at", "client_id", "version"}).
+		AddRow(1, 1, "Viagem", 5000.0, 1000.0, deadline, time.Now(), "", 1).
+		AddRow(2, 1, "Carro", 30000.0, 5000.0, deadline, time.Now(), "", 2)
 
 	mock.ExpectQuery("SELECT (.+) FROM goals WHERE user_id").
 		WithArgs(1).
@@ -76,7 +129,7 @@ func TestGoalRepository_Update(t *testing.T) {
 	}
 
 	mock.ExpectExec("UPDATE goals SET").
-		WithArgs(goal.Name, goal.TargetAmount, goal.Deadline, goal.ID, goal.UserID).
+		WithArgs(goal.Name, goal.TargetAmount, goal.Deadline, goal.ID, goal.UserID, 0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err = repo.Update(goal)
@@ -85,6 +138,40 @@ func TestGoalRepository_Update(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGoalRepository_UpdateWritesEventsInTheSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+	goal := domain.Goal{ID: 3, UserID: 1, Name: "Viagem", TargetAmount: 5000.0, Deadline: time.Now().AddDate(0, 6, 0), Version: 4}
+	event := domain.Event{Type: domain.EventGoalUpdated, UserID: 1, AggregateType: domain.AggregateGoal, AggregateID: 3, Payload: []byte(`{"id":3}`)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE goals").
+		WithArgs(goal.Name, goal.TargetAmount, goal.Deadline, 3, 1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT 1 FROM goals").
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Update(goal, event), ports.ErrVersionConflict)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE goals SET deleted_at").
+		WithArgs(3, 1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(domain.EventGoalDeleted, 1, domain.AggregateGoal, 3, []byte(`{"id":3}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	event.Type = domain.EventGoalDeleted
+	assert.NoError(t, repo.Delete(3, 1, 4, event))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGoalRepository_Delete(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -92,16 +179,41 @@ func TestGoalRepository_Delete(t *testing.T) {
 
 	repo := NewPostgresGoalRepository(db)
 
-	mock.ExpectExec("DELETE FROM goals WHERE").
-		WithArgs(1, 1).
+	mock.ExpectExec("UPDATE goals SET deleted_at = NOW\\(\\) WHERE").
+		WithArgs(1, 1, 0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(1, 1)
+	err = repo.Delete(1, 1, 0)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGoalRepository_DeleteStaleVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+
+	mock.ExpectExec("UPDATE goals SET deleted_at").
+		WithArgs(1, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT 1 FROM goals").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectExec("UPDATE goals SET deleted_at").
+		WithArgs(2, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT 1 FROM goals").
+		WithArgs(2, 1).
+		WillReturnError(sql.ErrNoRows)
+
+	assert.ErrorIs(t, repo.Delete(1, 1, 2), ports.ErrVersionConflict)
+	assert.ErrorIs(t, repo.Delete(2, 1, 2), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGoalRepository_AddProgress(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -129,8 +241,8 @@ func TestGoalRepository_GetByID(t *testing.T) {
 	deadline := time.Now().AddDate(0, 6, 0)
 	createdAt := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "target_amount", "current_amount", "deadline", "created_at"}).
-		AddRow(1, 1, "Viagem", 5000.0, 1000.0, deadline, createdAt)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "target_amount", "current_amount", "deadline", "created_at", "client_id", "version"}).
+		AddRow(1, 1, "Viagem", 5000.0, 1000.0, deadline, createdAt, "1b4e28ba-2fa1-11d2-883f-0016d3cca427", 3)
 
 	mock.ExpectQuery("SELECT (.+) FROM goals WHERE id").
 		WithArgs(1, 1).
@@ -143,5 +255,45 @@ func TestGoalRepository_GetByID(t *testing.T) {
 	assert.Equal(t, "Viagem", goal.Name)
 	assert.Equal(t, 5000.0, goal.TargetAmount)
 	assert.Equal(t, 1000.0, goal.CurrentAmount)
+	assert.Equal(t, "1b4e28ba-2fa1-11d2-883f-0016d3cca427", goal.ClientID)
+	assert.Equal(t, 3, goal.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGoalRepository_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+
+	mock.ExpectExec("UPDATE goals SET deleted_at = NULL").
+		WithArgs(1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Restore(1, 1)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGoalRepository_RestoreWritesEventsInTheSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresGoalRepository(db)
+	event := domain.Event{Type: domain.EventGoalRestored, UserID: 1, AggregateType: domain.AggregateGoal, AggregateID: 5, Payload: []byte(`{"id":5}`)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE goals SET deleted_at = NULL").
+		WithArgs(5, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(domain.EventGoalRestored, 1, domain.AggregateGoal, 5, []byte(`{"id":5}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Restore(5, 1, event))
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
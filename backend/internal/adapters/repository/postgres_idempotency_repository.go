@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresIdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresIdempotencyRepository(db *sql.DB) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{db: db}
+}
+
+// Claim relies on the primary key: of two requests racing for a key only
+// one inserts or takes it over, and the other reads the winner's record.
+func (r *PostgresIdempotencyRepository) Claim(record domain.IdempotencyRecord, staleBefore time.Time) (domain.IdempotencyRecord, bool, error) {
+	claim := `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = EXCLUDED.status, response_status = 0,
+			response_headers = '{}', response_body = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		OR (idempotency_keys.status = 'in_progress' AND idempotency_keys.created_at < $7)
+		RETURNING user_id
+	`
+	existing := `
+		SELECT user_id, key, fingerprint, status, response_status, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+	// The record found may expire and be purged before it is read; claim
+	// again then.
+	for attempt := 0; ; attempt++ {
+		var userID int
+		err := r.db.QueryRow(claim, record.UserID, record.Key, record.Fingerprint, record.Status, record.CreatedAt, record.ExpiresAt, staleBefore).Scan(&userID)
+		if err == nil {
+			return record, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return domain.IdempotencyRecord{}, false, err
+		}
+
+		var found domain.IdempotencyRecord
+		var headers []byte
+		err = r.db.QueryRow(existing, record.UserID, record.Key).Scan(
+			&found.UserID, &found.Key, &found.Fingerprint, &found.Status, &found.ResponseStatus,
+			&headers, &found.ResponseBody, &found.CreatedAt, &found.ExpiresAt,
+		)
+		if err == sql.ErrNoRows && attempt == 0 {
+			continue
+		}
+		if err == nil {
+			err = json.Unmarshal(headers, &found.Headers)
+		}
+		return found, false, err
+	}
+}
+
+func (r *PostgresIdempotencyRepository) Complete(record domain.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = 'completed', response_status = $1, response_headers = $2, response_body = $3
+		WHERE user_id = $4 AND key = $5 AND status = 'in_progress'
+	`
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec(query, record.ResponseStatus, headers, record.ResponseBody, record.UserID, record.Key)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Release frees a key whose request did not complete, so a retry runs it.
+func (r *PostgresIdempotencyRepository) Release(userID int, key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status = 'in_progress'`, userID, key)
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) DeleteExpired(now time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresIndexRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresIndexRepository(db *sql.DB) *PostgresIndexRepository {
+	return &PostgresIndexRepository{db: db}
+}
+
+// SaveRates stores all rates atomically; reloading a series overwrites the
+// rates already stored for the same dates.
+func (r *PostgresIndexRepository) SaveRates(rates []domain.IndexRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO index_rates (index, date, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (index, date) DO UPDATE SET rate = EXCLUDED.rate
+	`
+	for _, rate := range rates {
+		if _, err := tx.Exec(query, rate.Index, rate.Date, rate.Rate); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresIndexRepository) ListRates(index string, from, to time.Time) ([]domain.IndexRate, error) {
+	query := `
+		SELECT index, date, rate
+		FROM index_rates
+		WHERE index = $1 AND date >= $2 AND date <= $3
+		ORDER BY date ASC
+	`
+	rows, err := r.db.Query(query, index, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []domain.IndexRate
+	for rows.Next() {
+		var rate domain.IndexRate
+		if err := rows.Scan(&rate.Index, &rate.Date, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
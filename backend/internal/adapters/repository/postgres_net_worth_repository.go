@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresNetWorthRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNetWorthRepository(db *sql.DB) *PostgresNetWorthRepository {
+	return &PostgresNetWorthRepository{db: db}
+}
+
+func (r *PostgresNetWorthRepository) SaveAsset(a domain.Asset, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO assets (user_id, name, kind, category, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, a.UserID, a.Name, a.Kind, a.Category, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *PostgresNetWorthRepository) UpdateAsset(a domain.Asset, events ...domain.Event) error {
+	query := `
+		UPDATE assets SET name = $1, kind = $2, category = NULLIF($3, '')
+		WHERE id = $4 AND user_id = $5 AND archived_at IS NULL
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, a.Name, a.Kind, a.Category, a.ID, a.UserID))
+	})
+	return err
+}
+
+func (r *PostgresNetWorthRepository) ArchiveAsset(id, userID int, events ...domain.Event) error {
+	query := `UPDATE assets SET archived_at = NOW() WHERE id = $1 AND user_id = $2 AND archived_at IS NULL`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, id, userID))
+	})
+	return err
+}
+
+// assetColumns joins the latest valuation so Value and ValuedAt are filled.
+const assetColumns = `a.id, a.user_id, a.name, a.kind, COALESCE(a.category, ''), COALESCE(v.value, 0), v.date, a.created_at, a.archived_at
+	FROM assets a
+	LEFT JOIN LATERAL (
+		SELECT value, date FROM asset_valuations
+		WHERE asset_id = a.id ORDER BY date DESC, id DESC LIMIT 1
+	) v ON TRUE`
+
+func (r *PostgresNetWorthRepository) GetAsset(id, userID int) (domain.Asset, error) {
+	query := `SELECT ` + assetColumns + ` WHERE a.id = $1 AND a.user_id = $2`
+	return scanAsset(r.db.QueryRow(query, id, userID))
+}
+
+// ListAssets includes archived assets; their history still counts.
+func (r *PostgresNetWorthRepository) ListAssets(userID int) ([]domain.Asset, error) {
+	query := `SELECT ` + assetColumns + ` WHERE a.user_id = $1 ORDER BY a.kind ASC, a.name ASC, a.id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []domain.Asset
+	for rows.Next() {
+		a, err := scanAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	return assets, rows.Err()
+}
+
+func scanAsset(row interface{ Scan(...any) error }) (domain.Asset, error) {
+	var a domain.Asset
+	var valuedAt, archivedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.UserID, &a.Name, &a.Kind, &a.Category, &a.Value, &valuedAt, &a.CreatedAt, &archivedAt)
+	if valuedAt.Valid {
+		a.ValuedAt = &valuedAt.Time
+	}
+	if archivedAt.Valid {
+		a.ArchivedAt = &archivedAt.Time
+	}
+	return a, err
+}
+
+func (r *PostgresNetWorthRepository) SaveValuation(v domain.AssetValuation, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO asset_valuations (asset_id, user_id, value, date, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, v.AssetID, v.UserID, v.Value, v.Date, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+// ListValuations returns every valuation of the user's assets, oldest first.
+func (r *PostgresNetWorthRepository) ListValuations(userID int) ([]domain.AssetValuation, error) {
+	query := `
+		SELECT id, asset_id, user_id, value, date, created_at
+		FROM asset_valuations
+		WHERE user_id = $1
+		ORDER BY date ASC, id ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var valuations []domain.AssetValuation
+	for rows.Next() {
+		var v domain.AssetValuation
+		if err := rows.Scan(&v.ID, &v.AssetID, &v.UserID, &v.Value, &v.Date, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		valuations = append(valuations, v)
+	}
+	return valuations, rows.Err()
+}
+
+func (r *PostgresNetWorthRepository) SaveSnapshot(s domain.NetWorthSnapshot) error {
+	query := `
+		INSERT INTO net_worth_snapshots (user_id, year, month, accounts, goals, assets, liabilities, net_worth, taken_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, year, month) DO UPDATE
+		SET accounts = EXCLUDED.accounts, goals = EXCLUDED.goals, assets = EXCLUDED.assets,
+			liabilities = EXCLUDED.liabilities, net_worth = EXCLUDED.net_worth, taken_at = EXCLUDED.taken_at
+	`
+	_, err := r.db.Exec(query, s.UserID, s.Year, s.Month, s.Accounts, s.Goals, s.Assets, s.Liabilities, s.NetWorth, s.TakenAt)
+	return err
+}
+
+// ListSnapshots returns the snapshots of months starting in [from, to).
+func (r *PostgresNetWorthRepository) ListSnapshots(userID int, from, to time.Time) ([]domain.NetWorthSnapshot, error) {
+	query := `
+		SELECT user_id, year, month, accounts, goals, assets, liabilities, net_worth, taken_at
+		FROM net_worth_snapshots
+		WHERE user_id = $1 AND make_date(year, month, 1) >= $2 AND make_date(year, month, 1) < $3
+		ORDER BY year, month
+	`
+	rows, err := r.db.Query(query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []domain.NetWorthSnapshot
+	for rows.Next() {
+		var s domain.NetWorthSnapshot
+		if err := rows.Scan(&s.UserID, &s.Year, &s.Month, &s.Accounts, &s.Goals, &s.Assets, &s.Liabilities, &s.NetWorth, &s.TakenAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNotificationRepository(db *sql.DB) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{db: db}
+}
+
+func (r *PostgresNotificationRepository) SaveNotification(n domain.Notification, inApp bool) (int, bool, error) {
+	query := `
+		INSERT INTO notifications (user_id, event_type, title, body, url, dedup_key, in_app, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		ON CONFLICT (user_id, dedup_key) DO NOTHING
+		RETURNING id
+	`
+	var id int
+	err := r.db.QueryRow(query, n.UserID, n.EventType, n.Title, n.Body, n.URL, n.DedupKey, inApp, n.CreatedAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+const notificationColumns = `id, user_id, event_type, title, body, COALESCE(url, ''), dedup_key, read_at, created_at`
+
+func scanNotification(row interface{ Scan(...any) error }) (domain.Notification, error) {
+	var n domain.Notification
+	var readAt sql.NullTime
+	err := row.Scan(&n.ID, &n.UserID, &n.EventType, &n.Title, &n.Body, &n.URL, &n.DedupKey, &readAt, &n.CreatedAt)
+	if readAt.Valid {
+		n.ReadAt = &readAt.Time
+	}
+	return n, err
+}
+
+// notificationInboxLimit bounds the inbox to the most recent notifications.
+const notificationInboxLimit = 100
+
+func (r *PostgresNotificationRepository) ListNotifications(userID int, unreadOnly bool) ([]domain.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1 AND in_app AND ($2 = FALSE OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, userID, unreadOnly, notificationInboxLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *PostgresNotificationRepository) CountUnread(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND in_app AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks the given notifications read, or all of them when ids is
+// empty.
+func (r *PostgresNotificationRepository) MarkRead(userID int, ids []int) (int, error) {
+	query := `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL AND (cardinality($2::int[]) = 0 OR id = ANY($2))
+	`
+	result, err := r.db.Exec(query, userID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// GetSettings returns empty settings, not sql.ErrNoRows, for users who
+// never saved any.
+func (r *PostgresNotificationRepository) GetSettings(userID int) (domain.NotificationSettings, error) {
+	settings := domain.NotificationSettings{UserID: userID}
+	query := `
+		SELECT COALESCE(quiet_hours_start, ''), COALESCE(quiet_hours_end, ''), timezone
+		FROM notification_settings WHERE user_id = $1
+	`
+	err := r.db.QueryRow(query, userID).Scan(&settings.QuietHoursStart, &settings.QuietHoursEnd, &settings.Timezone)
+	if err != nil && err != sql.ErrNoRows {
+		return settings, err
+	}
+
+	rows, err := r.db.Query(`SELECT event_type, channel, enabled FROM notification_preferences WHERE user_id = $1 ORDER BY event_type, channel`, userID)
+	if err != nil {
+		return settings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p domain.NotificationPreference
+		if err := rows.Scan(&p.EventType, &p.Channel, &p.Enabled); err != nil {
+			return settings, err
+		}
+		settings.Preferences = append(settings.Preferences, p)
+	}
+	return settings, rows.Err()
+}
+
+// SaveSettings replaces the quiet hours and every preference at once.
+func (r *PostgresNotificationRepository) SaveSettings(s domain.NotificationSettings, events ...domain.Event) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO notification_settings (user_id, quiet_hours_start, quiet_hours_end, timezone)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET quiet_hours_start = EXCLUDED.quiet_hours_start, quiet_hours_end = EXCLUDED.quiet_hours_end, timezone = EXCLUDED.timezone
+	`, s.UserID, s.QuietHoursStart, s.QuietHoursEnd, s.Timezone)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM notification_preferences WHERE user_id = $1`, s.UserID); err != nil {
+		return err
+	}
+	for _, p := range s.Preferences {
+		_, err := tx.Exec(`INSERT INTO notification_preferences (user_id, event_type, channel, enabled) VALUES ($1, $2, $3, $4)`,
+			s.UserID, p.EventType, p.Channel, p.Enabled)
+		if err != nil {
+			return err
+		}
+	}
+	if err := appendEvents(tx, events, 0); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SavePushSubscription moves an endpoint to the user who subscribed last,
+// since a shared device re-subscribes with the same endpoint.
+func (r *PostgresNotificationRepository) SavePushSubscription(p domain.PushSubscription) error {
+	query := `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+	`
+	_, err := r.db.Exec(query, p.UserID, p.Endpoint, p.P256dh, p.Auth, time.Now())
+	return err
+}
+
+func (r *PostgresNotificationRepository) DeletePushSubscriptions(userID int, endpoints []string) error {
+	_, err := r.db.Exec(`DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = ANY($2)`, userID, pq.Array(endpoints))
+	return err
+}
+
+func (r *PostgresNotificationRepository) ListPushSubscriptions(userID int) ([]domain.PushSubscription, error) {
+	rows, err := r.db.Query(`SELECT id, user_id, endpoint, p256dh, auth, created_at FROM push_subscriptions WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []domain.PushSubscription
+	for rows.Next() {
+		var p domain.PushSubscription
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Endpoint, &p.P256dh, &p.Auth, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, p)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (r *PostgresNotificationRepository) SaveDelivery(d domain.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (notification_id, channel, send_after, status)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(query, d.Notification.ID, d.Channel, d.SendAfter, d.Status)
+	return err
+}
+
+// ListDueDeliveries returns pending deliveries whose time has come, oldest
+// first, with their notification.
+func (r *PostgresNotificationRepository) ListDueDeliveries(now time.Time, limit int) ([]domain.NotificationDelivery, error) {
+	query := `
+		SELECT d.id, d.channel, d.send_after, d.attempts, d.status, COALESCE(d.last_error, ''),
+			n.id, n.user_id, n.event_type, n.title, n.body, COALESCE(n.url, ''), n.dedup_key, n.read_at, n.created_at
+		FROM notification_deliveries d
+		JOIN notifications n ON n.id = d.notification_id
+		WHERE d.status = 'pending' AND d.send_after <= $1
+		ORDER BY d.send_after ASC, d.id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.NotificationDelivery
+	for rows.Next() {
+		var d domain.NotificationDelivery
+		var readAt sql.NullTime
+		n := &d.Notification
+		err := rows.Scan(&d.ID, &d.Channel, &d.SendAfter, &d.Attempts, &d.Status, &d.LastError,
+			&n.ID, &n.UserID, &n.EventType, &n.Title, &n.Body, &n.URL, &n.DedupKey, &readAt, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PostgresNotificationRepository) UpdateDelivery(d domain.NotificationDelivery) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $1, attempts = $2, send_after = $3, last_error = NULLIF($4, '')
+		WHERE id = $5
+	`
+	_, err := r.db.Exec(query, d.Status, d.Attempts, d.SendAfter, d.LastError, d.ID)
+	return err
+}
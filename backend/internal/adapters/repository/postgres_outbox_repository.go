@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+// appendEvents writes events to the outbox inside the caller's database
+// transaction. Events without an AggregateID belong to the row the
+// transaction just created: they get its id, also as the payload's "id",
+// and a new user is the user of its own events. Audit events go to
+// audit_events instead.
+func appendEvents(tx execer, events []domain.Event, createdID int) error {
+	query := `
+		INSERT INTO outbox_events (event_type, user_id, aggregate_type, aggregate_id, payload, occurred_at, next_attempt_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $6)
+	`
+	for _, e := range events {
+		if e.Type == domain.EventAuditRecorded {
+			if err := appendAudit(tx, e.Payload, createdID); err != nil {
+				return err
+			}
+			continue
+		}
+		if e.AggregateID == 0 {
+			e.AggregateID = createdID
+			e.Payload = withPayloadID(e.Payload, createdID)
+			if e.AggregateType == domain.AggregateUser && e.UserID == 0 {
+				e.UserID = createdID
+			}
+		}
+		if _, err := tx.Exec(query, e.Type, e.UserID, e.AggregateType, e.AggregateID, []byte(e.Payload), e.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func withPayloadID(payload json.RawMessage, id int) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload
+	}
+	if _, ok := fields["id"]; !ok {
+		return payload
+	}
+	fields["id"] = json.RawMessage(strconv.Itoa(id))
+	patched, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return patched
+}
+
+// withEvents runs write in a database transaction with the outbox rows for
+// events. write returns the id of the row it created, or 0. Without events
+// write runs directly on db.
+func withEvents(db *sql.DB, events []domain.Event, write func(q queryer) (int, error)) (int, error) {
+	if len(events) == 0 {
+		return write(db)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	id, err := write(tx)
+	if err == nil {
+		err = appendEvents(tx, events, id)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// affectedOne turns the result of a statement that matched no row into
+// sql.ErrNoRows.
+func affectedOne(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+type queryer interface {
+	execer
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type PostgresOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresOutboxRepository(db *sql.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+func (r *PostgresOutboxRepository) LatestEventID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM outbox_events`).Scan(&id)
+	return id, err
+}
+
+// ClaimDue returns pending events whose next attempt is due, oldest first,
+// and pushes their next attempt lease ahead so the dispatchers of other
+// instances skip them meanwhile. Rows another instance is claiming right
+// now are skipped rather than waited for.
+func (r *PostgresOutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEntry, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbox_events
+			SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT id
+				FROM outbox_events
+				WHERE status = 'pending' AND next_attempt_at <= $1
+				ORDER BY id ASC
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT id, event_type, COALESCE(user_id, 0), aggregate_type, aggregate_id, payload, occurred_at,
+			status, attempts, next_attempt_at, delivered_to, COALESCE(last_error, '')
+		FROM claimed
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.OutboxEntry
+	for rows.Next() {
+		var e domain.OutboxEntry
+		var payload []byte
+		err := rows.Scan(&e.ID, &e.Type, &e.UserID, &e.AggregateType, &e.AggregateID, &payload, &e.OccurredAt,
+			&e.Status, &e.Attempts, &e.NextAttemptAt, pq.Array(&e.DeliveredTo), &e.LastError)
+		if err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *PostgresOutboxRepository) UpdateEntry(e domain.OutboxEntry) error {
+	query := `
+		UPDATE outbox_events
+		SET status = $1, attempts = $2, next_attempt_at = $3, delivered_to = $4, last_error = NULLIF($5, ''),
+			processed_at = CASE WHEN $1 = 'pending' THEN NULL ELSE NOW() END
+		WHERE id = $6
+	`
+	_, err := r.db.Exec(query, e.Status, e.Attempts, e.NextAttemptAt, pq.Array(e.DeliveredTo), e.LastError, e.ID)
+	return err
+}
+
+// PurgeProcessedBefore deletes delivered and failed events processed before
+// cutoff.
+func (r *PostgresOutboxRepository) PurgeProcessedBefore(cutoff time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM outbox_events WHERE status <> 'pending' AND processed_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository_ClaimDueLocksAndLeasesRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresOutboxRepository(db)
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`UPDATE outbox_events\s+SET next_attempt_at = \$2(.|\n)*FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, now.Add(time.Minute), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "user_id", "aggregate_type", "aggregate_id", "payload",
+			"occurred_at", "status", "attempts", "next_attempt_at", "delivered_to", "last_error"}).
+			AddRow(3, "goal.created", 1, "goal", 7, []byte(`{"id":7}`), now, "pending", 0, now.Add(time.Minute), "{sse}", ""))
+
+	entries, err := repo.ClaimDue(now, time.Minute, 10)
+
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, int64(3), entries[0].ID)
+		assert.Equal(t, now.Add(time.Minute), entries[0].NextAttemptAt)
+		assert.Equal(t, []string{"sse"}, entries[0].DeliveredTo)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
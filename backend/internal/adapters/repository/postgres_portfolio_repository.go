@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type PostgresPortfolioRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPortfolioRepository(db *sql.DB) *PostgresPortfolioRepository {
+	return &PostgresPortfolioRepository{db: db}
+}
+
+func (r *PostgresPortfolioRepository) SaveHolding(h domain.Holding, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO holdings (user_id, ticker, name, asset_class, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, h.UserID, h.Ticker, h.Name, h.AssetClass, time.Now()).Scan(&id)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return 0, ports.ErrHoldingExists
+			}
+			return 0, err
+		}
+		return id, nil
+	})
+}
+
+const holdingColumns = `id, user_id, ticker, COALESCE(name, ''), asset_class, created_at`
+
+func (r *PostgresPortfolioRepository) GetHolding(id, userID int) (domain.Holding, error) {
+	query := `SELECT ` + holdingColumns + ` FROM holdings WHERE id = $1 AND user_id = $2`
+	var h domain.Holding
+	err := r.db.QueryRow(query, id, userID).Scan(&h.ID, &h.UserID, &h.Ticker, &h.Name, &h.AssetClass, &h.CreatedAt)
+	return h, err
+}
+
+func (r *PostgresPortfolioRepository) ListHoldings(userID int) ([]domain.Holding, error) {
+	query := `SELECT ` + holdingColumns + ` FROM holdings WHERE user_id = $1 ORDER BY ticker ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holdings []domain.Holding
+	for rows.Next() {
+		var h domain.Holding
+		if err := rows.Scan(&h.ID, &h.UserID, &h.Ticker, &h.Name, &h.AssetClass, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, rows.Err()
+}
+
+// DeleteHolding also removes its operations and prices.
+func (r *PostgresPortfolioRepository) DeleteHolding(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(`DELETE FROM holdings WHERE id = $1 AND user_id = $2`, id, userID))
+	})
+	return err
+}
+
+func (r *PostgresPortfolioRepository) SaveOperation(o domain.InvestmentOperation, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO investment_operations (holding_id, user_id, type, quantity, price, amount, fees, date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, o.HoldingID, o.UserID, o.Type, o.Quantity, o.Price, o.Amount, o.Fees, o.Date, time.Now()).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *PostgresPortfolioRepository) DeleteOperation(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(`DELETE FROM investment_operations WHERE id = $1 AND user_id = $2`, id, userID))
+	})
+	return err
+}
+
+// ListOperations returns every operation of the user in the order they
+// must be replayed.
+func (r *PostgresPortfolioRepository) ListOperations(userID int) ([]domain.InvestmentOperation, error) {
+	query := `
+		SELECT id, holding_id, user_id, type, quantity, price, amount, fees, date, created_at
+		FROM investment_operations
+		WHERE user_id = $1
+		ORDER BY date ASC, id ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var operations []domain.InvestmentOperation
+	for rows.Next() {
+		var o domain.InvestmentOperation
+		if err := rows.Scan(&o.ID, &o.HoldingID, &o.UserID, &o.Type, &o.Quantity, &o.Price, &o.Amount, &o.Fees, &o.Date, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		operations = append(operations, o)
+	}
+	return operations, rows.Err()
+}
+
+// SavePrices stores all prices atomically; a second price for the same
+// holding and day replaces the first.
+func (r *PostgresPortfolioRepository) SavePrices(prices []domain.PriceSnapshot) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO price_snapshots (holding_id, user_id, price, date, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (holding_id, date) DO UPDATE SET price = EXCLUDED.price, source = EXCLUDED.source
+	`
+	for _, p := range prices {
+		if _, err := tx.Exec(query, p.HoldingID, p.UserID, p.Price, p.Date, p.Source); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LatestPrices returns the most recent price of each holding.
+func (r *PostgresPortfolioRepository) LatestPrices(userID int) ([]domain.PriceSnapshot, error) {
+	query := `
+		SELECT DISTINCT ON (holding_id) id, holding_id, user_id, price, date, source
+		FROM price_snapshots
+		WHERE user_id = $1
+		ORDER BY holding_id, date DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []domain.PriceSnapshot
+	for rows.Next() {
+		var p domain.PriceSnapshot
+		if err := rows.Scan(&p.ID, &p.HoldingID, &p.UserID, &p.Price, &p.Date, &p.Source); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+	return prices, rows.Err()
+}
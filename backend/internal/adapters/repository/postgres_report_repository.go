@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+// PostgresReportRepository reads the monthly_category_totals rollup kept by
+// PostgresTransactionRepository instead of scanning transactions.
+type PostgresReportRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresReportRepository(db *sql.DB) *PostgresReportRepository {
+	return &PostgresReportRepository{db: db}
+}
+
+func (r *PostgresReportRepository) MonthlyTotals(userID int, from, to time.Time) ([]domain.MonthlyTotal, error) {
+	query := `
+		SELECT year, month,
+			COALESCE(SUM(total) FILTER (WHERE type = 'income'), 0) AS income,
+			COALESCE(SUM(total) FILTER (WHERE type = 'expense'), 0) AS expense
+		FROM monthly_category_totals
+		WHERE user_id = $1 AND make_date(year, month, 1) >= $2 AND make_date(year, month, 1) < $3
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+	rows, err := r.db.Query(query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.MonthlyTotal
+	for rows.Next() {
+		var t domain.MonthlyTotal
+		if err := rows.Scan(&t.Year, &t.Month, &t.Income, &t.Expense); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *PostgresReportRepository) CategoryTotals(userID int, from, to time.Time) ([]domain.CategoryTotal, error) {
+	query := `
+		SELECT year, month, category, total
+		FROM monthly_category_totals
+		WHERE user_id = $1 AND type = 'expense' AND make_date(year, month, 1) >= $2 AND make_date(year, month, 1) < $3
+		ORDER BY 3, 1, 2
+	`
+	rows, err := r.db.Query(query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.CategoryTotal
+	for rows.Next() {
+		var t domain.CategoryTotal
+		if err := rows.Scan(&t.Year, &t.Month, &t.Category, &t.Total); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *PostgresReportRepository) CategorySpent(userID int, category string, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(total), 0)
+		FROM monthly_category_totals
+		WHERE user_id = $1 AND type = 'expense' AND LOWER(TRIM(category)) = LOWER(TRIM($2))
+			AND make_date(year, month, 1) >= $3 AND make_date(year, month, 1) < $4
+	`
+	var spent float64
+	err := r.db.QueryRow(query, userID, category, from, to).Scan(&spent)
+	return spent, err
+}
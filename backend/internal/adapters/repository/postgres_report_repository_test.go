@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRepository_MonthlyTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresReportRepository(db)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"year", "month", "income", "expense"}).
+		AddRow(2025, 1, 5000.0, 3200.0).
+		AddRow(2025, 2, 5000.0, 4100.0)
+	mock.ExpectQuery("SELECT (.+) FROM monthly_category_totals WHERE user_id = (.+) GROUP BY 1, 2").
+		WithArgs(1, from, to).
+		WillReturnRows(rows)
+
+	totals, err := repo.MonthlyTotals(1, from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, totals, 2)
+	assert.Equal(t, 2, totals[1].Month)
+	assert.Equal(t, 4100.0, totals[1].Expense)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_CategoryTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresReportRepository(db)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"year", "month", "category", "total"}).
+		AddRow(2025, 1, "Mercado", 900.0)
+	mock.ExpectQuery("SELECT (.+) FROM monthly_category_totals WHERE (.+) type = 'expense' (.+) ORDER BY 3, 1, 2").
+		WithArgs(1, from, to).
+		WillReturnRows(rows)
+
+	totals, err := repo.CategoryTotals(1, from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, totals, 1)
+	assert.Equal(t, "Mercado", totals[0].Category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_CategorySpent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresReportRepository(db)
+	from := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(total\\), 0\\) FROM monthly_category_totals WHERE (.+) LOWER\\(TRIM\\(category\\)\\) = LOWER\\(TRIM\\(\\$2\\)\\)").
+		WithArgs(1, "Mercado", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(3200.0))
+
+	spent, err := repo.CategorySpent(1, "Mercado", from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3200.0, spent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
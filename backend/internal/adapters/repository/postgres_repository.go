@@ -2,9 +2,14 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"log"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 )
 
 type PostgresTransactionRepository struct {
@@ -37,55 +42,160 @@ func (r *PostgresTransactionRepository) ensureSchema() {
 	r.db.Exec(`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description TEXT;`)
 }
 
-func (r *PostgresTransactionRepository) Save(t domain.Transaction) (int, error) {
-	query := `
-		INSERT INTO transactions (user_id, type, amount, category, description, date, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-		RETURNING id`
+const transactionColumns = `id, user_id, type, amount, category, COALESCE(description, ''), COALESCE(account, ''), COALESCE(tags, '{}'), COALESCE(bucket, ''), date, created_at, deleted_at, COALESCE(client_id::text, ''), version`
+
+func scanTransaction(row interface{ Scan(...any) error }) (domain.Transaction, error) {
+	var t domain.Transaction
+	var deletedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.UserID, &t.Type, &t.Amount, &t.Category, &t.Description, &t.Account, pq.Array(&t.Tags), &t.Bucket, &t.Date, &t.CreatedAt, &deletedAt, &t.ClientID, &t.Version)
+	if deletedAt.Valid {
+		t.DeletedAt = &deletedAt.Time
+	}
+	return t, err
+}
 
-	var id int
-	err := r.db.QueryRow(query, t.UserID, t.Type, t.Amount, t.Category, t.Description, t.Date).Scan(&id)
+// Save returns t with the id and version the database gave it, or
+// ports.ErrClientIDExists when the user already has a transaction with t's
+// ClientID.
+func (r *PostgresTransactionRepository) Save(t domain.Transaction, events ...domain.Event) (domain.Transaction, error) {
+	err := r.withTx(func(tx *sql.Tx) error {
+		var err error
+		t.ID, t.Version, err = saveTransaction(tx, t, events)
+		return err
+	})
 	if err != nil {
-		return 0, err
+		return domain.Transaction{}, err
 	}
-	return id, nil
+	return t, nil
 }
 
-func (r *PostgresTransactionRepository) Update(t domain.Transaction) error {
+// Update also moves the transaction's amount between rollup rows when its
+// date, type or category changes. A t.Version other than 0 must still be
+// the row's version.
+func (r *PostgresTransactionRepository) Update(t domain.Transaction, events ...domain.Event) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		_, err := updateTransaction(tx, t, events)
+		return err
+	})
+}
+
+// Delete moves the transaction to the trash; PurgeDeletedBefore removes it.
+func (r *PostgresTransactionRepository) Delete(id, userID, version int, events ...domain.Event) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		return deleteTransaction(tx, id, userID, version, events)
+	})
+}
+
+// ApplyBatch runs the writes in one database transaction and returns each
+// written row's id and new version. When a write fails none are applied,
+// and the error is a *ports.BatchError with the write's index.
+func (r *PostgresTransactionRepository) ApplyBatch(writes []ports.TransactionWrite) ([]domain.Transaction, error) {
+	written := make([]domain.Transaction, len(writes))
+	err := r.withTx(func(tx *sql.Tx) error {
+		for i, w := range writes {
+			t := w.Transaction
+			var err error
+			switch {
+			case w.Delete:
+				err = deleteTransaction(tx, t.ID, t.UserID, t.Version, w.Events)
+			case t.ID == 0:
+				t.ID, t.Version, err = saveTransaction(tx, t, w.Events)
+			default:
+				t.Version, err = updateTransaction(tx, t, w.Events)
+			}
+			if err != nil {
+				return &ports.BatchError{Index: i, Err: err}
+			}
+			written[i] = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// saveTransaction returns the new row's id and version.
+func saveTransaction(tx *sql.Tx, t domain.Transaction, events []domain.Event) (int, int, error) {
+	query := `
+		INSERT INTO transactions (user_id, type, amount, category, description, account, tags, bucket, date, created_at, client_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NULLIF($10, '')::uuid)
+		RETURNING id, version`
+
+	var id, version int
+	if err := tx.QueryRow(query, t.UserID, t.Type, t.Amount, t.Category, t.Description, t.Account, pq.Array(t.Tags), t.Bucket, t.Date, t.ClientID).Scan(&id, &version); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, 0, ports.ErrClientIDExists
+		}
+		return 0, 0, err
+	}
+	if err := adjustMonthlyTotals(tx, t, 1); err != nil {
+		return 0, 0, err
+	}
+	return id, version, appendEvents(tx, events, id)
+}
+
+// updateTransaction returns the row's new version, which the sync trigger
+// bumps by one on every update.
+func updateTransaction(tx *sql.Tx, t domain.Transaction, events []domain.Event) (int, error) {
 	query := `
 		UPDATE transactions 
-		SET amount = $1, category = $2, description = $3, date = $4, type = $5
-		WHERE id = $6 AND user_id = $7
+		SET amount = $1, category = $2, description = $3, date = $4, type = $5, account = $6, tags = $7, bucket = $8
+		WHERE id = $9 AND user_id = $10 AND deleted_at IS NULL
 	`
-	result, err := r.db.Exec(query, t.Amount, t.Category, t.Description, t.Date, t.Type, t.ID, t.UserID)
+	old, err := scanTransaction(tx.QueryRow(`SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`, t.ID, t.UserID))
 	if err != nil {
-		return err
+		return 0, err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
+	if t.Version != 0 && t.Version != old.Version {
+		return 0, ports.ErrVersionConflict
 	}
-	return nil
+	if _, err := tx.Exec(query, t.Amount, t.Category, t.Description, t.Date, t.Type, t.Account, pq.Array(t.Tags), t.Bucket, t.ID, t.UserID); err != nil {
+		return 0, err
+	}
+	if err := adjustMonthlyTotals(tx, old, -1); err != nil {
+		return 0, err
+	}
+	if err := adjustMonthlyTotals(tx, t, 1); err != nil {
+		return 0, err
+	}
+	return old.Version + 1, appendEvents(tx, events, t.ID)
 }
 
-func (r *PostgresTransactionRepository) Delete(id, userID int) error {
-	query := `DELETE FROM transactions WHERE id = $1 AND user_id = $2`
-	result, err := r.db.Exec(query, id, userID)
+func deleteTransaction(tx *sql.Tx, id, userID, version int, events []domain.Event) error {
+	query := `UPDATE transactions SET deleted_at = NOW(), delete_batch = NULL
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)
+		RETURNING ` + transactionColumns
+	deleted, err := scanTransaction(tx.QueryRow(query, id, userID, version))
+	if err == sql.ErrNoRows && version != 0 {
+		err = versionConflict(tx, `SELECT 1 FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, id, userID)
+	}
 	if err != nil {
 		return err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
+	if err := adjustMonthlyTotals(tx, deleted, -1); err != nil {
+		return err
 	}
-	return nil
+	return appendEvents(tx, events, id)
+}
+
+func (r *PostgresTransactionRepository) GetByID(id, userID int) (domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`
+	return scanTransaction(r.db.QueryRow(query, id, userID))
 }
 
 func (r *PostgresTransactionRepository) ListByUserID(userID, month, year int) ([]domain.Transaction, error) {
-	query := `
-		SELECT id, user_id, type, amount, category, COALESCE(description, ''), date, created_at
+	query := `SELECT ` + transactionColumns + `
 		FROM transactions
-		WHERE user_id = $1 
+		WHERE user_id = $1 AND deleted_at IS NULL
 		AND EXTRACT(MONTH FROM date) = $2 
 		AND EXTRACT(YEAR FROM date) = $3
 		ORDER BY date DESC
@@ -96,19 +206,186 @@ func (r *PostgresTransactionRepository) ListByUserID(userID, month, year int) ([
 	}
 	defer rows.Close()
 
+	return scanTransactions(rows)
+}
+
+func (r *PostgresTransactionRepository) ListAllByUserID(userID int) ([]domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY date DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTransactions(rows)
+}
+
+func (r *PostgresTransactionRepository) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// versionConflict tells why a versioned write matched no row: exists finds
+// the row regardless of version. It returns ports.ErrVersionConflict when
+// the row is there and sql.ErrNoRows when it is not.
+func versionConflict(q queryer, exists string, args ...any) error {
+	var found int
+	if err := q.QueryRow(exists, args...).Scan(&found); err != nil {
+		return err
+	}
+	return ports.ErrVersionConflict
+}
+
+func scanTransactions(rows *sql.Rows) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
 	for rows.Next() {
-		var t domain.Transaction
-		if err := rows.Scan(&t.ID, &t.UserID, &t.Type, &t.Amount, &t.Category, &t.Description, &t.Date, &t.CreatedAt); err != nil {
+		t, err := scanTransaction(rows)
+		if err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, t)
 	}
-	return transactions, nil
+	return transactions, rows.Err()
 }
 
+// DeleteAllByUserID permanently removes every transaction of the user,
+// including the ones in the trash.
 func (r *PostgresTransactionRepository) DeleteAllByUserID(userID int) error {
 	query := `DELETE FROM transactions WHERE user_id = $1`
-	_, err := r.db.Exec(query, userID)
-	return err
+	return r.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM monthly_category_totals WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// SoftDeleteAllByUserID trashes every active transaction under one batch so
+// the whole operation can be restored together.
+func (r *PostgresTransactionRepository) SoftDeleteAllByUserID(userID int, batch string, events ...domain.Event) (int, error) {
+	query := `UPDATE transactions SET deleted_at = NOW(), delete_batch = $2 WHERE user_id = $1 AND deleted_at IS NULL`
+	var rows int64
+	err := r.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, userID, batch)
+		if err != nil {
+			return err
+		}
+		rows, _ = result.RowsAffected()
+		if _, err := tx.Exec(`DELETE FROM monthly_category_totals WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		return appendEvents(tx, events, 0)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
+
+func (r *PostgresTransactionRepository) ListDeleted(userID int) ([]domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTransactions(rows)
+}
+
+func (r *PostgresTransactionRepository) Restore(id, userID int, events ...domain.Event) (domain.Transaction, error) {
+	query := `UPDATE transactions SET deleted_at = NULL, delete_batch = NULL
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+		RETURNING ` + transactionColumns
+	var restored domain.Transaction
+	err := r.withTx(func(tx *sql.Tx) error {
+		t, err := scanTransaction(tx.QueryRow(query, id, userID))
+		if err != nil {
+			return err
+		}
+		restored = t
+		if err := adjustMonthlyTotals(tx, t, 1); err != nil {
+			return err
+		}
+		return appendEvents(tx, events, id)
+	})
+	return restored, err
+}
+
+func (r *PostgresTransactionRepository) LatestDeleteBatch(userID int) (string, error) {
+	query := `
+		SELECT delete_batch FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NOT NULL AND delete_batch IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT 1
+	`
+	var batch string
+	err := r.db.QueryRow(query, userID).Scan(&batch)
+	return batch, err
+}
+
+func (r *PostgresTransactionRepository) RestoreBatch(userID int, batch string, events ...domain.Event) ([]domain.Transaction, error) {
+	query := `UPDATE transactions SET deleted_at = NULL, delete_batch = NULL
+		WHERE user_id = $1 AND delete_batch = $2 AND deleted_at IS NOT NULL
+		RETURNING ` + transactionColumns
+	var restored []domain.Transaction
+	err := r.withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(query, userID, batch)
+		if err != nil {
+			return err
+		}
+		restored, err = scanTransactions(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if err := rebuildMonthlyTotals(tx, userID); err != nil {
+			return err
+		}
+		return appendEvents(tx, events, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (r *PostgresTransactionRepository) ListDeletedBefore(cutoff time.Time) ([]domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`
+	rows, err := r.db.Query(query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTransactions(rows)
+}
+
+func (r *PostgresTransactionRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	query := `DELETE FROM transactions WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+	result, err := r.db.Exec(query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
 }
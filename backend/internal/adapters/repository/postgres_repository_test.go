@@ -62,9 +62,10 @@ func TestPostgresTransactionRepository_Integration(t *testing.T) {
 			Date:        now,
 		}
 
-		id, err := repo.Save(tr)
+		saved, err := repo.Save(tr)
 		assert.NoError(t, err)
-		assert.Greater(t, id, 0)
+		assert.Greater(t, saved.ID, 0)
+		assert.Equal(t, 1, saved.Version)
 	})
 
 	t.Run("List Transactions Current Month", func(t *testing.T) {
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresRuleRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRuleRepository(db *sql.DB) *PostgresRuleRepository {
+	return &PostgresRuleRepository{db: db}
+}
+
+const ruleColumns = `id, user_id, name, priority, enabled,
+	COALESCE(description_contains, ''), COALESCE(description_regex, ''), min_amount, max_amount,
+	COALESCE(account, ''), COALESCE(transaction_type, ''), COALESCE(day_of_month_from, 0), COALESCE(day_of_month_to, 0),
+	COALESCE(set_category, ''), COALESCE(set_tags, '{}'), COALESCE(set_bucket, ''), created_at`
+
+func (r *PostgresRuleRepository) Save(rule domain.CategoryRule, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO category_rules (
+			user_id, name, priority, enabled,
+			description_contains, description_regex, min_amount, max_amount,
+			account, transaction_type, day_of_month_from, day_of_month_to,
+			set_category, set_tags, set_bucket, created_at
+		)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, 0), NULLIF($12, 0), NULLIF($13, ''), $14, NULLIF($15, ''), $16)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(
+			query,
+			rule.UserID,
+			rule.Name,
+			rule.Priority,
+			rule.Enabled,
+			rule.DescriptionContains,
+			rule.DescriptionRegex,
+			rule.MinAmount,
+			rule.MaxAmount,
+			rule.Account,
+			rule.TransactionType,
+			rule.DayOfMonthFrom,
+			rule.DayOfMonthTo,
+			rule.SetCategory,
+			pq.Array(rule.SetTags),
+			rule.SetBucket,
+			time.Now(),
+		).Scan(&id)
+		return id, err
+	})
+}
+
+func (r *PostgresRuleRepository) Update(rule domain.CategoryRule, events ...domain.Event) error {
+	query := `
+		UPDATE category_rules
+		SET name = $1, priority = $2, enabled = $3,
+			description_contains = NULLIF($4, ''), description_regex = NULLIF($5, ''), min_amount = $6, max_amount = $7,
+			account = NULLIF($8, ''), transaction_type = NULLIF($9, ''), day_of_month_from = NULLIF($10, 0), day_of_month_to = NULLIF($11, 0),
+			set_category = NULLIF($12, ''), set_tags = $13, set_bucket = NULLIF($14, '')
+		WHERE id = $15 AND user_id = $16
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		result, err := q.Exec(
+			query,
+			rule.Name,
+			rule.Priority,
+			rule.Enabled,
+			rule.DescriptionContains,
+			rule.DescriptionRegex,
+			rule.MinAmount,
+			rule.MaxAmount,
+			rule.Account,
+			rule.TransactionType,
+			rule.DayOfMonthFrom,
+			rule.DayOfMonthTo,
+			rule.SetCategory,
+			pq.Array(rule.SetTags),
+			rule.SetBucket,
+			rule.ID,
+			rule.UserID,
+		)
+		return 0, affectedOne(result, err)
+	})
+	return err
+}
+
+func (r *PostgresRuleRepository) Delete(id, userID int, events ...domain.Event) error {
+	query := `DELETE FROM category_rules WHERE id = $1 AND user_id = $2`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, id, userID))
+	})
+	return err
+}
+
+func (r *PostgresRuleRepository) GetByID(id, userID int) (domain.CategoryRule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM category_rules WHERE id = $1 AND user_id = $2`
+	return scanRule(r.db.QueryRow(query, id, userID))
+}
+
+func (r *PostgresRuleRepository) ListByUserID(userID int) ([]domain.CategoryRule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM category_rules WHERE user_id = $1 ORDER BY priority ASC, id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []domain.CategoryRule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func scanRule(row interface{ Scan(...any) error }) (domain.CategoryRule, error) {
+	var rule domain.CategoryRule
+	var minAmount, maxAmount sql.NullFloat64
+	err := row.Scan(
+		&rule.ID, &rule.UserID, &rule.Name, &rule.Priority, &rule.Enabled,
+		&rule.DescriptionContains, &rule.DescriptionRegex, &minAmount, &maxAmount,
+		&rule.Account, &rule.TransactionType, &rule.DayOfMonthFrom, &rule.DayOfMonthTo,
+		&rule.SetCategory, pq.Array(&rule.SetTags), &rule.SetBucket, &rule.CreatedAt,
+	)
+	if minAmount.Valid {
+		rule.MinAmount = &minAmount.Float64
+	}
+	if maxAmount.Valid {
+		rule.MaxAmount = &maxAmount.Float64
+	}
+	return rule, err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+const goalSyncColumns = `id, user_id, name, target_amount, current_amount, deadline, created_at, deleted_at, COALESCE(client_id::text, ''), version`
+
+type PostgresSyncRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSyncRepository(db *sql.DB) *PostgresSyncRepository {
+	return &PostgresSyncRepository{db: db}
+}
+
+// Changes reads in one snapshot. Its horizon is the oldest transaction
+// still running when the snapshot was taken: every change written before it
+// is visible now, and later ones are picked up from it on the next sync.
+func (r *PostgresSyncRepository) Changes(userID int, since int64) ([]domain.SyncChange, int64, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var horizon int64
+	if err := tx.QueryRow(`SELECT txid_snapshot_xmin(txid_current_snapshot())`).Scan(&horizon); err != nil {
+		return nil, 0, err
+	}
+
+	changes := []domain.SyncChange{}
+
+	rows, err := tx.Query(`SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE user_id = $1 AND change_txid >= $2 AND change_txid < $3
+		AND ($2 > 0 OR deleted_at IS NULL)
+		ORDER BY id`, userID, since, horizon)
+	if err != nil {
+		return nil, 0, err
+	}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		changes = append(changes, domain.TransactionChange(t))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err = tx.Query(`SELECT `+goalSyncColumns+`
+		FROM goals
+		WHERE user_id = $1 AND change_txid >= $2 AND change_txid < $3
+		AND ($2 > 0 OR deleted_at IS NULL)
+		ORDER BY id`, userID, since, horizon)
+	if err != nil {
+		return nil, 0, err
+	}
+	for rows.Next() {
+		g, err := scanSyncGoal(rows)
+		if err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		changes = append(changes, domain.GoalChange(g))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if since > 0 {
+		rows, err = tx.Query(`SELECT entity_type, entity_id, COALESCE(client_id::text, '')
+			FROM sync_tombstones
+			WHERE user_id = $1 AND change_txid >= $2 AND change_txid < $3
+			ORDER BY id`, userID, since, horizon)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			c := domain.SyncChange{Deleted: true}
+			if err := rows.Scan(&c.Entity, &c.ID, &c.ClientID); err != nil {
+				return nil, 0, err
+			}
+			changes = append(changes, c)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return changes, horizon, nil
+}
+
+// FindTransaction looks the transaction up by id, or by client id when id
+// is 0.
+func (r *PostgresSyncRepository) FindTransaction(userID, id int, clientID string) (domain.Transaction, error) {
+	return scanTransaction(r.db.QueryRow(`SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE user_id = $1 AND (id = $2 OR ($2 = 0 AND client_id = NULLIF($3, '')::uuid))`, userID, id, clientID))
+}
+
+// FindGoal looks the goal up by id, or by client id when id is 0.
+func (r *PostgresSyncRepository) FindGoal(userID, id int, clientID string) (domain.Goal, error) {
+	return scanSyncGoal(r.db.QueryRow(`SELECT `+goalSyncColumns+`
+		FROM goals
+		WHERE user_id = $1 AND (id = $2 OR ($2 = 0 AND client_id = NULLIF($3, '')::uuid))`, userID, id, clientID))
+}
+
+func scanSyncGoal(row interface{ Scan(...any) error }) (domain.Goal, error) {
+	var g domain.Goal
+	var deletedAt sql.NullTime
+	err := row.Scan(&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.CurrentAmount, &g.Deadline, &g.CreatedAt, &deletedAt, &g.ClientID, &g.Version)
+	if deletedAt.Valid {
+		g.DeletedAt = &deletedAt.Time
+	}
+	return g, err
+}
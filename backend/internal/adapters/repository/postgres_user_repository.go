@@ -30,14 +30,13 @@ func (r *PostgresUserRepository) ensureSchema() {
 	r.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS password TEXT;`)
 }
 
-func (r *PostgresUserRepository) Save(u domain.User) (int, error) {
+func (r *PostgresUserRepository) Save(u domain.User, events ...domain.Event) (int, error) {
 	query := `INSERT INTO users (email, password, created_at) VALUES ($1, $2, NOW()) RETURNING id`
-	var id int
-	err := r.db.QueryRow(query, u.Email, u.Password).Scan(&id)
-	if err != nil {
-		return 0, err
-	}
-	return id, nil
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, u.Email, u.Password).Scan(&id)
+		return id, err
+	})
 }
 
 func (r *PostgresUserRepository) GetByEmail(email string) (domain.User, error) {
@@ -52,3 +51,28 @@ func (r *PostgresUserRepository) GetByEmail(email string) (domain.User, error) {
 	}
 	return u, nil
 }
+
+func (r *PostgresUserRepository) GetByID(id int) (domain.User, error) {
+	query := `SELECT id, email, password, created_at FROM users WHERE id = $1`
+	var u domain.User
+	err := r.db.QueryRow(query, id).Scan(&u.ID, &u.Email, &u.Password, &u.CreatedAt)
+	return u, err
+}
+
+func (r *PostgresUserRepository) ListIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT id FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type PostgresWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresWebhookRepository(db *sql.DB) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+func (r *PostgresWebhookRepository) Save(w domain.Webhook, events ...domain.Event) (int, error) {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, event_types, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return withEvents(r.db, events, func(q queryer) (int, error) {
+		var id int
+		err := q.QueryRow(query, w.UserID, w.URL, w.Secret, pq.Array(w.EventTypes), w.Active).Scan(&id)
+		return id, err
+	})
+}
+
+// Update saves the URL, event types and active flag; the secret never
+// changes.
+func (r *PostgresWebhookRepository) Update(w domain.Webhook, events ...domain.Event) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, event_types = $2, active = $3, consecutive_failures = $4, disabled_at = $5
+		WHERE id = $6 AND user_id = $7
+	`
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(query, w.URL, pq.Array(w.EventTypes), w.Active, w.ConsecutiveFailures, w.DisabledAt, w.ID, w.UserID))
+	})
+	return err
+}
+
+func (r *PostgresWebhookRepository) Delete(id, userID int, events ...domain.Event) error {
+	_, err := withEvents(r.db, events, func(q queryer) (int, error) {
+		return 0, affectedOne(q.Exec(`DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID))
+	})
+	return err
+}
+
+const webhookColumns = `id, user_id, url, secret, event_types, active, consecutive_failures, disabled_at, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (domain.Webhook, error) {
+	var w domain.Webhook
+	var disabledAt sql.NullTime
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Active, &w.ConsecutiveFailures, &disabledAt, &w.CreatedAt)
+	if disabledAt.Valid {
+		w.DisabledAt = &disabledAt.Time
+	}
+	return w, err
+}
+
+func (r *PostgresWebhookRepository) GetByID(id, userID int) (domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+	return scanWebhook(r.db.QueryRow(query, id, userID))
+}
+
+func (r *PostgresWebhookRepository) ListByUserID(userID int) ([]domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id ASC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []domain.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) SaveDelivery(d domain.WebhookDelivery) (int, bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, user_id, event_id, event_type, payload, status, next_attempt_at, redelivery_of, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (webhook_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+		RETURNING id
+	`
+	var id int
+	err := r.db.QueryRow(query, d.WebhookID, d.UserID, d.EventID, d.EventType, []byte(d.Payload), d.Status,
+		d.NextAttemptAt, d.RedeliveryOf, d.CreatedAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.user_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, COALESCE(d.response_code, 0), COALESCE(d.response_body, ''), COALESCE(d.error, ''),
+	d.redelivery_of, d.created_at, d.delivered_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	var redeliveryOf sql.NullInt64
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.UserID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.ResponseCode, &d.ResponseBody, &d.Error, &redeliveryOf, &d.CreatedAt, &deliveredAt)
+	d.Payload = payload
+	if redeliveryOf.Valid {
+		id := int(redeliveryOf.Int64)
+		d.RedeliveryOf = &id
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, err
+}
+
+func (r *PostgresWebhookRepository) GetDelivery(id, webhookID, userID int) (domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d WHERE d.id = $1 AND d.webhook_id = $2 AND d.user_id = $3`
+	return scanWebhookDelivery(r.db.QueryRow(query, id, webhookID, userID))
+}
+
+// webhookDeliveryLogLimit bounds the delivery log to the most recent
+// deliveries.
+const webhookDeliveryLogLimit = 100
+
+func (r *PostgresWebhookRepository) ListDeliveries(webhookID, userID int) ([]domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1 AND d.user_id = $2
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3
+	`
+	return r.listDeliveries(query, webhookID, userID, webhookDeliveryLogLimit)
+}
+
+// ClaimDueDeliveries returns pending deliveries of active webhooks whose
+// next attempt is due, oldest first, and pushes their next attempt lease
+// ahead so other instances skip them meanwhile. Rows another instance is
+// claiming right now are skipped rather than waited for.
+func (r *PostgresWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT d.id
+				FROM webhook_deliveries d
+				JOIN webhooks w ON w.id = d.webhook_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND w.active
+				ORDER BY d.next_attempt_at ASC, d.id ASC
+				LIMIT $3
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `
+		FROM claimed d
+		ORDER BY d.id ASC
+	`
+	return r.listDeliveries(query, now, now.Add(lease), limit)
+}
+
+func (r *PostgresWebhookRepository) listDeliveries(query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) UpdateDelivery(d domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, response_code = NULLIF($4, 0),
+			response_body = NULLIF($5, ''), error = NULLIF($6, ''), delivered_at = $7
+		WHERE id = $8
+	`
+	_, err := r.db.Exec(query, d.Status, d.Attempts, d.NextAttemptAt, d.ResponseCode, d.ResponseBody, d.Error, d.DeliveredAt, d.ID)
+	return err
+}
+
+// RecordResult counts failures in a single statement so concurrent
+// deliveries to the same webhook cannot lose an update.
+func (r *PostgresWebhookRepository) RecordResult(webhookID int, succeeded bool, disableAfter int, now time.Time) (bool, error) {
+	query := `
+		UPDATE webhooks
+		SET consecutive_failures = CASE WHEN $2 THEN 0 ELSE consecutive_failures + 1 END,
+			active = active AND ($2 OR consecutive_failures + 1 < $3),
+			disabled_at = CASE WHEN active AND NOT $2 AND consecutive_failures + 1 >= $3 THEN $4 ELSE disabled_at END
+		WHERE id = $1
+		RETURNING active
+	`
+	var active bool
+	err := r.db.QueryRow(query, webhookID, succeeded, disableAfter, now).Scan(&active)
+	return active, err
+}
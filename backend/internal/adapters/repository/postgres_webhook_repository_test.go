@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository_ClaimDueDeliveriesLocksAndLeasesRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresWebhookRepository(db)
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	leased := now.Add(15 * time.Minute)
+
+	mock.ExpectQuery(`UPDATE webhook_deliveries\s+SET next_attempt_at = \$2(.|\n)*FOR UPDATE OF d SKIP LOCKED`).
+		WithArgs(now, leased, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "user_id", "event_id", "event_type", "payload", "status",
+			"attempts", "next_attempt_at", "response_code", "response_body", "error", "redelivery_of", "created_at", "delivered_at"}).
+			AddRow(4, 2, 1, 42, "goal.created", []byte(`{}`), "pending", 1, leased, 500, "", "", nil, now, nil))
+
+	deliveries, err := repo.ClaimDueDeliveries(now, 15*time.Minute, 50)
+
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, 4, deliveries[0].ID)
+		assert.Equal(t, leased, deliveries[0].NextAttemptAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
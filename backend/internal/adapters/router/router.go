@@ -8,19 +8,80 @@ import (
 	"github.com/larissasthefanny/plena-app/backend/internal/config"
 )
 
+// Controllers groups every HTTP controller the router mounts.
+type Controllers struct {
+	Transaction  *controllers.TransactionController
+	Auth         *controllers.AuthController
+	Goal         *controllers.GoalController
+	Attachment   *controllers.AttachmentController
+	Rule         *controllers.RuleController
+	Suggestion   *controllers.SuggestionController
+	Trash        *controllers.TrashController
+	Audit        *controllers.AuditController
+	Budget       *controllers.BudgetController
+	Envelope     *controllers.EnvelopeController
+	Forecast     *controllers.ForecastController
+	Report       *controllers.ReportController
+	NetWorth     *controllers.NetWorthController
+	Portfolio    *controllers.PortfolioController
+	Debt         *controllers.DebtController
+	Bill         *controllers.BillController
+	Notification *controllers.NotificationController
+	Webhook      *controllers.WebhookController
+	Stream       *controllers.StreamController
+	Sync         *controllers.SyncController
+	Idempotency  *controllers.IdempotencyMiddleware
+}
+
 type Router struct {
-	transController *controllers.TransactionController
-	authController  *controllers.AuthController
-	goalController  *controllers.GoalController
-	config          *config.AppConfig
+	transController        *controllers.TransactionController
+	authController         *controllers.AuthController
+	goalController         *controllers.GoalController
+	attachmentController   *controllers.AttachmentController
+	ruleController         *controllers.RuleController
+	suggestionController   *controllers.SuggestionController
+	trashController        *controllers.TrashController
+	auditController        *controllers.AuditController
+	budgetController       *controllers.BudgetController
+	envelopeController     *controllers.EnvelopeController
+	forecastController     *controllers.ForecastController
+	reportController       *controllers.ReportController
+	netWorthController     *controllers.NetWorthController
+	portfolioController    *controllers.PortfolioController
+	debtController         *controllers.DebtController
+	billController         *controllers.BillController
+	notificationController *controllers.NotificationController
+	webhookController      *controllers.WebhookController
+	streamController       *controllers.StreamController
+	syncController         *controllers.SyncController
+	idempotency            *controllers.IdempotencyMiddleware
+	config                 *config.AppConfig
 }
 
-func NewRouter(tc *controllers.TransactionController, ac *controllers.AuthController, gc *controllers.GoalController, cfg *config.AppConfig) *Router {
+func NewRouter(c Controllers, cfg *config.AppConfig) *Router {
 	return &Router{
-		transController: tc,
-		authController:  ac,
-		goalController:  gc,
-		config:          cfg,
+		transController:        c.Transaction,
+		authController:         c.Auth,
+		goalController:         c.Goal,
+		attachmentController:   c.Attachment,
+		ruleController:         c.Rule,
+		suggestionController:   c.Suggestion,
+		trashController:        c.Trash,
+		auditController:        c.Audit,
+		budgetController:       c.Budget,
+		envelopeController:     c.Envelope,
+		forecastController:     c.Forecast,
+		reportController:       c.Report,
+		netWorthController:     c.NetWorth,
+		portfolioController:    c.Portfolio,
+		debtController:         c.Debt,
+		billController:         c.Bill,
+		notificationController: c.Notification,
+		webhookController:      c.Webhook,
+		streamController:       c.Stream,
+		syncController:         c.Sync,
+		idempotency:            c.Idempotency,
+		config:                 cfg,
 	}
 }
 
@@ -35,18 +96,135 @@ func (router *Router) Setup() http.Handler {
 	mux.HandleFunc("/api/expense", controllers.AuthMiddleware(router.transController.CreateExpense))
 	mux.HandleFunc("/api/transactions", controllers.AuthMiddleware(router.transController.ListTransactions))
 	mux.HandleFunc("/api/reset", controllers.AuthMiddleware(router.transController.ResetData))
+	mux.HandleFunc("POST /api/reset/undo", controllers.AuthMiddleware(router.trashController.UndoReset))
 
+	mux.HandleFunc("GET /api/transactions/suggest-category", controllers.AuthMiddleware(router.suggestionController.SuggestCategory))
+	mux.HandleFunc("POST /api/transactions/batch", controllers.AuthMiddleware(router.transController.BatchTransactions))
+	mux.HandleFunc("GET /api/transactions/{id}", controllers.AuthMiddleware(router.transController.GetTransaction))
 	mux.HandleFunc("DELETE /api/transactions/{id}", controllers.AuthMiddleware(router.transController.DeleteTransaction))
 	mux.HandleFunc("PUT /api/transactions/{id}", controllers.AuthMiddleware(router.transController.UpdateTransaction))
 
+	// Attachment routes
+	mux.HandleFunc("POST /api/transactions/{id}/attachments", controllers.AuthMiddleware(router.attachmentController.Upload))
+	mux.HandleFunc("GET /api/transactions/{id}/attachments", controllers.AuthMiddleware(router.attachmentController.ListAttachments))
+	mux.HandleFunc("GET /api/attachments/{id}", controllers.AuthMiddleware(router.attachmentController.Download))
+	mux.HandleFunc("GET /api/attachments/{id}/thumbnail", controllers.AuthMiddleware(router.attachmentController.Thumbnail))
+	mux.HandleFunc("DELETE /api/attachments/{id}", controllers.AuthMiddleware(router.attachmentController.DeleteAttachment))
+
+	// Categorization rule routes
+	mux.HandleFunc("POST /api/rules", controllers.AuthMiddleware(router.ruleController.CreateRule))
+	mux.HandleFunc("GET /api/rules", controllers.AuthMiddleware(router.ruleController.ListRules))
+	mux.HandleFunc("PUT /api/rules/{id}", controllers.AuthMiddleware(router.ruleController.UpdateRule))
+	mux.HandleFunc("DELETE /api/rules/{id}", controllers.AuthMiddleware(router.ruleController.DeleteRule))
+	mux.HandleFunc("POST /api/rules/preview", controllers.AuthMiddleware(router.ruleController.PreviewRule))
+	mux.HandleFunc("POST /api/rules/{id}/apply", controllers.AuthMiddleware(router.ruleController.ApplyRule))
+
+	// Trash routes
+	mux.HandleFunc("GET /api/trash", controllers.AuthMiddleware(router.trashController.ListTrash))
+	mux.HandleFunc("POST /api/trash/transactions/{id}/restore", controllers.AuthMiddleware(router.trashController.RestoreTransaction))
+	mux.HandleFunc("POST /api/trash/goals/{id}/restore", controllers.AuthMiddleware(router.trashController.RestoreGoal))
+
+	// Audit routes
+	mux.HandleFunc("GET /api/audit", controllers.AuthMiddleware(router.auditController.History))
+
+	// Budget routes
+	mux.HandleFunc("GET /api/budgets", controllers.AuthMiddleware(router.budgetController.Report))
+	mux.HandleFunc("POST /api/budgets", controllers.AuthMiddleware(router.budgetController.CreateBudget))
+	mux.HandleFunc("GET /api/budgets/definitions", controllers.AuthMiddleware(router.budgetController.ListBudgets))
+	mux.HandleFunc("PUT /api/budgets/{id}", controllers.AuthMiddleware(router.budgetController.UpdateBudget))
+	mux.HandleFunc("DELETE /api/budgets/{id}", controllers.AuthMiddleware(router.budgetController.DeleteBudget))
+
+	// Envelope (zero-based budgeting) routes
+	mux.HandleFunc("PUT /api/envelopes/mode", controllers.AuthMiddleware(router.envelopeController.SetMode))
+	mux.HandleFunc("GET /api/envelopes", controllers.AuthMiddleware(router.envelopeController.ListEnvelopes))
+	mux.HandleFunc("POST /api/envelopes", controllers.AuthMiddleware(router.envelopeController.CreateEnvelope))
+	mux.HandleFunc("DELETE /api/envelopes/{id}", controllers.AuthMiddleware(router.envelopeController.DeleteEnvelope))
+	mux.HandleFunc("POST /api/envelopes/{id}/assign", controllers.AuthMiddleware(router.envelopeController.Assign))
+	mux.HandleFunc("POST /api/envelopes/moves", controllers.AuthMiddleware(router.envelopeController.Move))
+	mux.HandleFunc("GET /api/envelopes/moves", controllers.AuthMiddleware(router.envelopeController.ListMoves))
+	mux.HandleFunc("GET /api/envelopes/summary", controllers.AuthMiddleware(router.envelopeController.Summary))
+
+	// Forecast routes
+	mux.HandleFunc("GET /api/forecast", controllers.AuthMiddleware(router.forecastController.Forecast))
+
+	// Report routes
+	mux.HandleFunc("GET /api/reports/monthly", controllers.AuthMiddleware(router.reportController.Monthly))
+	mux.HandleFunc("GET /api/reports/categories", controllers.AuthMiddleware(router.reportController.Categories))
+	mux.HandleFunc("GET /api/reports/year-over-year", controllers.AuthMiddleware(router.reportController.YearOverYear))
+
+	// Net worth routes
+	mux.HandleFunc("GET /api/net-worth", controllers.AuthMiddleware(router.netWorthController.History))
+	mux.HandleFunc("GET /api/net-worth/current", controllers.AuthMiddleware(router.netWorthController.Current))
+	mux.HandleFunc("GET /api/assets", controllers.AuthMiddleware(router.netWorthController.ListAssets))
+	mux.HandleFunc("POST /api/assets", controllers.AuthMiddleware(router.netWorthController.CreateAsset))
+	mux.HandleFunc("PUT /api/assets/{id}", controllers.AuthMiddleware(router.netWorthController.UpdateAsset))
+	mux.HandleFunc("DELETE /api/assets/{id}", controllers.AuthMiddleware(router.netWorthController.DeleteAsset))
+	mux.HandleFunc("GET /api/assets/{id}/valuations", controllers.AuthMiddleware(router.netWorthController.ListValuations))
+	mux.HandleFunc("POST /api/assets/{id}/valuations", controllers.AuthMiddleware(router.netWorthController.AddValuation))
+
+	// Portfolio routes
+	mux.HandleFunc("GET /api/portfolio", controllers.AuthMiddleware(router.portfolioController.Portfolio))
+	mux.HandleFunc("GET /api/portfolio/holdings", controllers.AuthMiddleware(router.portfolioController.ListHoldings))
+	mux.HandleFunc("POST /api/portfolio/holdings", controllers.AuthMiddleware(router.portfolioController.CreateHolding))
+	mux.HandleFunc("DELETE /api/portfolio/holdings/{id}", controllers.AuthMiddleware(router.portfolioController.DeleteHolding))
+	mux.HandleFunc("GET /api/portfolio/operations", controllers.AuthMiddleware(router.portfolioController.ListOperations))
+	mux.HandleFunc("POST /api/portfolio/operations", controllers.AuthMiddleware(router.portfolioController.RecordOperation))
+	mux.HandleFunc("DELETE /api/portfolio/operations/{id}", controllers.AuthMiddleware(router.portfolioController.DeleteOperation))
+	mux.HandleFunc("POST /api/portfolio/prices", controllers.AuthMiddleware(router.portfolioController.SetPrice))
+	mux.HandleFunc("POST /api/portfolio/prices/import", controllers.AuthMiddleware(router.portfolioController.ImportPrices))
+
+	// Debt routes
+	mux.HandleFunc("GET /api/debts", controllers.AuthMiddleware(router.debtController.ListDebts))
+	mux.HandleFunc("POST /api/debts", controllers.AuthMiddleware(router.debtController.CreateDebt))
+	mux.HandleFunc("GET /api/debts/plan", controllers.AuthMiddleware(router.debtController.Plan))
+	mux.HandleFunc("DELETE /api/debts/{id}", controllers.AuthMiddleware(router.debtController.DeleteDebt))
+	mux.HandleFunc("GET /api/debts/{id}/schedule", controllers.AuthMiddleware(router.debtController.Schedule))
+	mux.HandleFunc("POST /api/debts/{id}/payments", controllers.AuthMiddleware(router.debtController.RecordPayment))
+	mux.HandleFunc("DELETE /api/debts/{id}/payments/{installment}", controllers.AuthMiddleware(router.debtController.DeletePayment))
+
+	// Bill routes
+	mux.HandleFunc("GET /api/bills", controllers.AuthMiddleware(router.billController.ListBills))
+	mux.HandleFunc("POST /api/bills", controllers.AuthMiddleware(router.billController.CreateBill))
+	mux.HandleFunc("GET /api/bills/upcoming", controllers.AuthMiddleware(router.billController.Upcoming))
+	mux.HandleFunc("PUT /api/bills/{id}", controllers.AuthMiddleware(router.billController.UpdateBill))
+	mux.HandleFunc("DELETE /api/bills/{id}", controllers.AuthMiddleware(router.billController.DeleteBill))
+	mux.HandleFunc("POST /api/bills/{id}/pay", controllers.AuthMiddleware(router.billController.MarkPaid))
+
+	// Notification routes
+	mux.HandleFunc("GET /api/notifications", controllers.AuthMiddleware(router.notificationController.Inbox))
+	mux.HandleFunc("POST /api/notifications/read", controllers.AuthMiddleware(router.notificationController.MarkRead))
+	mux.HandleFunc("GET /api/notifications/settings", controllers.AuthMiddleware(router.notificationController.Settings))
+	mux.HandleFunc("PUT /api/notifications/settings", controllers.AuthMiddleware(router.notificationController.UpdateSettings))
+	mux.HandleFunc("GET /api/notifications/vapid-public-key", controllers.AuthMiddleware(router.notificationController.VAPIDPublicKey))
+	mux.HandleFunc("POST /api/notifications/push-subscriptions", controllers.AuthMiddleware(router.notificationController.SubscribePush))
+	mux.HandleFunc("DELETE /api/notifications/push-subscriptions", controllers.AuthMiddleware(router.notificationController.UnsubscribePush))
+
+	// Real-time updates; EventSource passes the token in the query string
+	mux.HandleFunc("GET /api/stream", controllers.TokenFromQuery(controllers.AuthMiddleware(router.streamController.Stream)))
+
+	// Offline sync
+	mux.HandleFunc("GET /api/sync", controllers.AuthMiddleware(router.syncController.Pull))
+	mux.HandleFunc("POST /api/sync", controllers.AuthMiddleware(router.syncController.Push))
+
+	// Webhook routes
+	mux.HandleFunc("GET /api/webhooks", controllers.AuthMiddleware(router.webhookController.ListWebhooks))
+	mux.HandleFunc("POST /api/webhooks", controllers.AuthMiddleware(router.webhookController.CreateWebhook))
+	mux.HandleFunc("PUT /api/webhooks/{id}", controllers.AuthMiddleware(router.webhookController.UpdateWebhook))
+	mux.HandleFunc("DELETE /api/webhooks/{id}", controllers.AuthMiddleware(router.webhookController.DeleteWebhook))
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", controllers.AuthMiddleware(router.webhookController.ListDeliveries))
+	mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{deliveryID}/redeliver", controllers.AuthMiddleware(router.webhookController.Redeliver))
+
 	// Goal routes
 	mux.HandleFunc("POST /api/goals", controllers.AuthMiddleware(router.goalController.CreateGoal))
 	mux.HandleFunc("GET /api/goals", controllers.AuthMiddleware(router.goalController.ListGoals))
+	mux.HandleFunc("GET /api/goals/{id}", controllers.AuthMiddleware(router.goalController.GetGoal))
 	mux.HandleFunc("PUT /api/goals/{id}", controllers.AuthMiddleware(router.goalController.UpdateGoal))
 	mux.HandleFunc("DELETE /api/goals/{id}", controllers.AuthMiddleware(router.goalController.DeleteGoal))
 	mux.HandleFunc("POST /api/goals/{id}/progress", controllers.AuthMiddleware(router.goalController.AddProgress))
+	mux.HandleFunc("PUT /api/goals/{id}/investment", controllers.AuthMiddleware(router.goalController.SetInvestment))
+	mux.HandleFunc("GET /api/goals/{id}/yield", controllers.AuthMiddleware(router.goalController.Yield))
 
-	return router.enableCORS(mux)
+	return router.enableCORS(controllers.NewRequestMetaMiddleware(router.config.TrustedProxies).Wrap(router.idempotency.Wrap(mux)))
 }
 
 func (router *Router) enableCORS(next http.Handler) http.Handler {
@@ -79,7 +257,8 @@ func (router *Router) enableCORS(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, Last-Event-ID, Idempotency-Key, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Idempotent-Replayed, ETag")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
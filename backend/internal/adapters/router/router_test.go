@@ -1,6 +1,7 @@
 package router_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/controllers"
 	"github.com/larissasthefanny/plena-app/backend/internal/adapters/router"
+	"github.com/larissasthefanny/plena-app/backend/internal/config"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 )
 
@@ -26,43 +28,84 @@ type MockTransService struct {
 	mock.Mock
 }
 
-func (m *MockTransService) CreateIncome(userID int, amount float64, c, d string, t time.Time) (domain.Transaction, error) {
+func (m *MockTransService) CreateIncome(ctx context.Context, userID int, amount float64, c, d, a string, t time.Time) (domain.Transaction, error) {
 	return domain.Transaction{}, nil
 }
-func (m *MockTransService) CreateExpense(userID int, amount float64, c, d string, t time.Time) (domain.Transaction, error) {
+func (m *MockTransService) CreateExpense(ctx context.Context, userID int, amount float64, c, d, a string, t time.Time) (domain.Transaction, error) {
 	return domain.Transaction{}, nil
 }
 func (m *MockTransService) ListTransactions(userID, month, year int) ([]domain.Transaction, error) {
 	return []domain.Transaction{}, nil
 }
-func (m *MockTransService) ResetData(userID int) error { return nil }
-func (m *MockTransService) UpdateTransaction(userID, id int, amount float64, category, description string, date time.Time, typeStr string) error {
+func (m *MockTransService) ResetData(ctx context.Context, userID int) error { return nil }
+func (m *MockTransService) GetTransaction(userID, id int) (domain.Transaction, error) {
+	return domain.Transaction{}, nil
+}
+func (m *MockTransService) UpdateTransaction(ctx context.Context, userID, id, version int, amount float64, category, description, account string, date time.Time, typeStr string) (domain.Transaction, error) {
+	return domain.Transaction{}, nil
+}
+func (m *MockTransService) DeleteTransaction(ctx context.Context, userID, id, version int) error {
 	return nil
 }
-func (m *MockTransService) DeleteTransaction(userID, id int) error { return nil }
+func (m *MockTransService) ApplyBatch(ctx context.Context, userID int, mode string, ops []domain.BatchOperation) ([]domain.BatchResult, error) {
+	return nil, nil
+}
 
 type MockGoalService struct {
 	mock.Mock
 }
 
-func (m *MockGoalService) CreateGoal(userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+func (m *MockGoalService) CreateGoal(ctx context.Context, userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
 	return domain.Goal{}, nil
 }
-func (m *MockGoalService) UpdateGoal(userID, id int, name string, targetAmount float64, deadline time.Time) error {
-	return nil
+func (m *MockGoalService) GetGoal(userID, id int) (domain.Goal, error) {
+	return domain.Goal{}, nil
 }
-func (m *MockGoalService) DeleteGoal(userID, id int) error { return nil }
+func (m *MockGoalService) UpdateGoal(ctx context.Context, userID, id, version int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+	return domain.Goal{}, nil
+}
+func (m *MockGoalService) DeleteGoal(ctx context.Context, userID, id, version int) error { return nil }
 func (m *MockGoalService) ListGoals(userID int) ([]domain.Goal, error) {
 	return []domain.Goal{}, nil
 }
-func (m *MockGoalService) AddProgress(userID, goalID int, amount float64) error { return nil }
+func (m *MockGoalService) AddProgress(ctx context.Context, userID, goalID int, amount float64) error {
+	return nil
+}
+func (m *MockGoalService) SetInvestment(ctx context.Context, userID, goalID int, investment domain.GoalInvestment) (domain.GoalInvestment, error) {
+	return investment, nil
+}
+func (m *MockGoalService) Yield(userID, goalID int) (domain.GoalYield, error) {
+	return domain.GoalYield{}, nil
+}
 
 func TestRouter_HealthCheck(t *testing.T) {
 	tc := controllers.NewTransactionController(&MockTransService{})
 	ac := controllers.NewAuthController(&MockAuthService{})
 	gc := controllers.NewGoalController(&MockGoalService{})
 
-	r := router.NewRouter(tc, ac, gc)
+	r := router.NewRouter(router.Controllers{
+		Transaction:  tc,
+		Auth:         ac,
+		Goal:         gc,
+		Attachment:   controllers.NewAttachmentController(nil, 10<<20),
+		Rule:         controllers.NewRuleController(nil),
+		Suggestion:   controllers.NewSuggestionController(nil),
+		Trash:        controllers.NewTrashController(nil),
+		Audit:        controllers.NewAuditController(nil),
+		Budget:       controllers.NewBudgetController(nil),
+		Envelope:     controllers.NewEnvelopeController(nil),
+		Forecast:     controllers.NewForecastController(nil),
+		Report:       controllers.NewReportController(nil),
+		NetWorth:     controllers.NewNetWorthController(nil),
+		Portfolio:    controllers.NewPortfolioController(nil),
+		Debt:         controllers.NewDebtController(nil),
+		Bill:         controllers.NewBillController(nil),
+		Notification: controllers.NewNotificationController(nil, ""),
+		Webhook:      controllers.NewWebhookController(nil),
+		Stream:       controllers.NewStreamController(nil),
+		Sync:         controllers.NewSyncController(nil),
+		Idempotency:  controllers.NewIdempotencyMiddleware(nil, 1<<20),
+	}, &config.AppConfig{})
 	handler := r.Setup()
 
 	req := httptest.NewRequest("GET", "/api/health", nil)
@@ -81,7 +124,29 @@ func TestRouter_AuthMiddleware_BlocksRequest(t *testing.T) {
 	ac := controllers.NewAuthController(&MockAuthService{})
 	gc := controllers.NewGoalController(&MockGoalService{})
 
-	r := router.NewRouter(tc, ac, gc)
+	r := router.NewRouter(router.Controllers{
+		Transaction:  tc,
+		Auth:         ac,
+		Goal:         gc,
+		Attachment:   controllers.NewAttachmentController(nil, 10<<20),
+		Rule:         controllers.NewRuleController(nil),
+		Suggestion:   controllers.NewSuggestionController(nil),
+		Trash:        controllers.NewTrashController(nil),
+		Audit:        controllers.NewAuditController(nil),
+		Budget:       controllers.NewBudgetController(nil),
+		Envelope:     controllers.NewEnvelopeController(nil),
+		Forecast:     controllers.NewForecastController(nil),
+		Report:       controllers.NewReportController(nil),
+		NetWorth:     controllers.NewNetWorthController(nil),
+		Portfolio:    controllers.NewPortfolioController(nil),
+		Debt:         controllers.NewDebtController(nil),
+		Bill:         controllers.NewBillController(nil),
+		Notification: controllers.NewNotificationController(nil, ""),
+		Webhook:      controllers.NewWebhookController(nil),
+		Stream:       controllers.NewStreamController(nil),
+		Sync:         controllers.NewSyncController(nil),
+		Idempotency:  controllers.NewIdempotencyMiddleware(nil, 1<<20),
+	}, &config.AppConfig{})
 	handler := r.Setup()
 
 	req := httptest.NewRequest("GET", "/api/transactions", nil)
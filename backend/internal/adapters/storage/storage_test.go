@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// fakeS3 is a minimal in-memory stand-in for MinIO that only understands
+// path-style object PUT/GET/DELETE.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestLocalBlobStorage_RoundTrip(t *testing.T) {
+	s, err := NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	err = s.Put("users/1/receipt.jpg", []byte("data"), "image/jpeg")
+	assert.NoError(t, err)
+
+	rc, err := s.Get("users/1/receipt.jpg")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "data", string(body))
+
+	assert.NoError(t, s.Delete("users/1/receipt.jpg"))
+	_, err = s.Get("users/1/receipt.jpg")
+	assert.ErrorIs(t, err, ports.ErrBlobNotFound)
+}
+
+func TestLocalBlobStorage_RejectsTraversal(t *testing.T) {
+	s, err := NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	err = s.Put("../outside", []byte("x"), "text/plain")
+	assert.Error(t, err)
+}
+
+func TestS3BlobStorage_RoundTrip(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := NewS3BlobStorage(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "plena",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+	})
+
+	err := s.Put("users/1/nota fiscal.pdf", []byte("%PDF"), "application/pdf")
+	assert.NoError(t, err)
+	assert.Contains(t, fake.objects, "/plena/users/1/nota fiscal.pdf")
+
+	rc, err := s.Get("users/1/nota fiscal.pdf")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "%PDF", string(body))
+
+	assert.NoError(t, s.Delete("users/1/nota fiscal.pdf"))
+	_, err = s.Get("users/1/nota fiscal.pdf")
+	assert.ErrorIs(t, err, ports.ErrBlobNotFound)
+
+	for _, header := range fake.auth {
+		assert.True(t, strings.HasPrefix(header, "AWS4-HMAC-SHA256 Credential=minio/"))
+		assert.Contains(t, header, "SignedHeaders=")
+	}
+}
@@ -18,11 +18,59 @@ type DBConfig struct {
 	Name     string
 }
 
+type StorageConfig struct {
+	Driver         string
+	LocalPath      string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	MaxUploadBytes int64
+}
+
+type TrashConfig struct {
+	RetentionDays    int
+	PurgeIntervalMin int
+}
+
+// NotificationConfig configures the email and Web Push channels. Email goes
+// through SMTP, or is written to CapturePath with the "capture" driver; push
+// is off until a VAPID key pair is set.
+type NotificationConfig struct {
+	EmailDriver     string
+	CapturePath     string
+	SMTPHost        string
+	SMTPPort        int
+	SMTPUser        string
+	SMTPPassword    string
+	EmailFrom       string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+	DispatchSeconds int
+}
+
+// PasswordConfig is the policy for passwords chosen at registration.
+type PasswordConfig struct {
+	MinLength     int
+	RequireLetter bool
+	RequireDigit  bool
+}
+
 type AppConfig struct {
 	DB             DBConfig
 	Port           string
 	JWTSecret      string
 	AllowedOrigins []string
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-For header is believed.
+	TrustedProxies []string
+	Storage        StorageConfig
+	Trash          TrashConfig
+	// IndexDataDir holds cdi.csv, selic.csv and ipca.csv, loaded at startup.
+	IndexDataDir  string
+	Notifications NotificationConfig
+	Password      PasswordConfig
 }
 
 func Load() *AppConfig {
@@ -56,6 +104,101 @@ func Load() *AppConfig {
 		Port:           getEnv("PORT", "8080"),
 		JWTSecret:      getEnv("JWT_SECRET", ""),
 		AllowedOrigins: allowedOrigins,
+		TrustedProxies: loadTrustedProxies(),
+		Storage:        loadStorageConfig(),
+		Trash:          loadTrashConfig(),
+		IndexDataDir:   getEnv("INDEX_DATA_DIR", "./data/indexes"),
+		Notifications:  loadNotificationConfig(),
+		Password:       loadPasswordConfig(),
+	}
+}
+
+func loadTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+func loadStorageConfig() StorageConfig {
+	maxUpload, err := strconv.ParseInt(getEnv("ATTACHMENT_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil || maxUpload <= 0 {
+		maxUpload = 10 << 20
+	}
+
+	return StorageConfig{
+		Driver:         getEnv("STORAGE_DRIVER", "local"),
+		LocalPath:      getEnv("STORAGE_LOCAL_PATH", "./data/attachments"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       getEnv("S3_BUCKET", "plena-attachments"),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		MaxUploadBytes: maxUpload,
+	}
+}
+
+func loadTrashConfig() TrashConfig {
+	retention, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || retention <= 0 {
+		retention = 30
+	}
+	interval, err := strconv.Atoi(getEnv("TRASH_PURGE_INTERVAL_MINUTES", "60"))
+	if err != nil || interval <= 0 {
+		interval = 60
+	}
+
+	return TrashConfig{
+		RetentionDays:    retention,
+		PurgeIntervalMin: interval,
+	}
+}
+
+func loadNotificationConfig() NotificationConfig {
+	smtpPort, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil || smtpPort <= 0 {
+		smtpPort = 587
+	}
+	dispatch, err := strconv.Atoi(getEnv("NOTIFICATION_DISPATCH_SECONDS", "60"))
+	if err != nil || dispatch <= 0 {
+		dispatch = 60
+	}
+
+	return NotificationConfig{
+		EmailDriver:     getEnv("EMAIL_DRIVER", "capture"),
+		CapturePath:     getEnv("EMAIL_CAPTURE_PATH", "./data/outbox"),
+		SMTPHost:        getEnv("SMTP_HOST", ""),
+		SMTPPort:        smtpPort,
+		SMTPUser:        getEnv("SMTP_USER", ""),
+		SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+		EmailFrom:       getEnv("EMAIL_FROM", "Plena <no-reply@plena.app>"),
+		VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:    getEnv("VAPID_SUBJECT", "mailto:no-reply@plena.app"),
+		DispatchSeconds: dispatch,
+	}
+}
+
+func loadPasswordConfig() PasswordConfig {
+	minLength, err := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+	if err != nil || minLength <= 0 {
+		minLength = 8
+	}
+	requireLetter, err := strconv.ParseBool(getEnv("PASSWORD_REQUIRE_LETTER", "true"))
+	if err != nil {
+		requireLetter = true
+	}
+	requireDigit, err := strconv.ParseBool(getEnv("PASSWORD_REQUIRE_DIGIT", "true"))
+	if err != nil {
+		requireDigit = true
+	}
+
+	return PasswordConfig{
+		MinLength:     minLength,
+		RequireLetter: requireLetter,
+		RequireDigit:  requireDigit,
 	}
 }
 
@@ -103,5 +246,11 @@ func parseDatabaseURL(dbURL string) *AppConfig {
 		Port:           getEnv("PORT", "8080"),
 		JWTSecret:      getEnv("JWT_SECRET", "secret_key_plena_app_2025"),
 		AllowedOrigins: allowedOrigins,
+		TrustedProxies: loadTrustedProxies(),
+		Storage:        loadStorageConfig(),
+		Trash:          loadTrashConfig(),
+		IndexDataDir:   getEnv("INDEX_DATA_DIR", "./data/indexes"),
+		Notifications:  loadNotificationConfig(),
+		Password:       loadPasswordConfig(),
 	}
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type Attachment struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	TransactionID int       `json:"transaction_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	StorageKey    string    `json:"-"`
+	ThumbnailKey  string    `json:"-"`
+	HasThumbnail  bool      `json:"has_thumbnail"`
+	CreatedAt     time.Time `json:"created_at"`
+}
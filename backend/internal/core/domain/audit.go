@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityTransaction = "transaction"
+	AuditEntityGoal        = "goal"
+	AuditEntityRule        = "rule"
+	AuditEntityAttachment  = "attachment"
+	AuditEntityBudget      = "budget"
+	AuditEntityEnvelope    = "envelope"
+	AuditEntityAsset       = "asset"
+	AuditEntityHolding     = "holding"
+	AuditEntityDebt        = "debt"
+	AuditEntityBill        = "bill"
+	AuditEntityWebhook     = "webhook"
+
+	AuditEntityNotificationSettings = "notification_settings"
+
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionReset   = "reset"
+)
+
+// FieldChange holds the previous and new value of a single changed field.
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditEvent struct {
+	ID         int                    `json:"id"`
+	UserID     int                    `json:"user_id"`
+	ActorID    int                    `json:"actor_id"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	Action     string                 `json:"action"`
+	Before     json.RawMessage        `json:"before,omitempty"`
+	After      json.RawMessage        `json:"after,omitempty"`
+	Changes    map[string]FieldChange `json:"changes"`
+	RequestID  string                 `json:"request_id"`
+	IP         string                 `json:"ip"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
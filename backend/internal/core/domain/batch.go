@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	BatchModeAtomic  = "atomic"
+	BatchModePartial = "partial"
+
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+
+	BatchApplied = "applied"
+	BatchFailed  = "failed"
+	BatchSkipped = "skipped"
+)
+
+// BatchOperation is one write of a transaction batch. A create or update
+// carries the whole transaction, as the single-item endpoints do; Version,
+// when set, must still be the row's version for an update or delete.
+type BatchOperation struct {
+	Op          string    `json:"op"`
+	ID          int       `json:"id,omitempty"`
+	Version     int       `json:"version,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Amount      float64   `json:"amount,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Account     string    `json:"account,omitempty"`
+	Date        time.Time `json:"date,omitempty"`
+}
+
+// BatchResult is the outcome of the operation at Index. In atomic mode one
+// failure leaves the other operations skipped.
+type BatchResult struct {
+	Index   int          `json:"index"`
+	Op      string       `json:"op"`
+	Status  string       `json:"status"`
+	ID      int          `json:"id,omitempty"`
+	Version int          `json:"version,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+const (
+	BillStatusOpen    = "open"
+	BillStatusPaid    = "paid"
+	BillStatusOverdue = "overdue"
+
+	RecurrenceNone    = "none"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+	RecurrenceYearly  = "yearly"
+)
+
+// Bill is one occurrence of a boleto or utility bill. Paying a recurring
+// bill opens its next occurrence; DueDay keeps monthly and yearly bills on
+// the same day of the month after a shorter month. PayableDate is DueDate
+// moved to the next business day, when a boleto can still be paid without
+// a late fee; a bill is overdue once that day has passed.
+type Bill struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	Payee         string     `json:"payee"`
+	Amount        float64    `json:"amount"`
+	Estimated     bool       `json:"estimated"`
+	Category      string     `json:"category"`
+	Account       string     `json:"account"`
+	DueDate       time.Time  `json:"due_date"`
+	DueDay        int        `json:"due_day"`
+	PayableDate   time.Time  `json:"payable_date"`
+	Recurrence    string     `json:"recurrence"`
+	Status        string     `json:"status"`
+	PaidAmount    float64    `json:"paid_amount,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// UpcomingBills lists open bills payable up to To and every overdue bill.
+// Total adds both, using estimates where the amount is not known yet.
+type UpcomingBills struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Overdue  []Bill    `json:"overdue"`
+	Upcoming []Bill    `json:"upcoming"`
+	Total    float64   `json:"total"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// Budget caps spending on a category. It takes effect in Month/Year and
+// stays in force for later months until another budget for the same
+// category starts.
+type Budget struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Category  string    `json:"category"`
+	Amount    float64   `json:"amount"`
+	Month     int       `json:"month"`
+	Year      int       `json:"year"`
+	Rollover  bool      `json:"rollover"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BudgetStatus is a budget evaluated against one month of expenses.
+// CarriedOver is the previous month's remainder (negative when it was
+// overspent) and is only non-zero for rollover budgets.
+type BudgetStatus struct {
+	BudgetID    int     `json:"budget_id"`
+	Category    string  `json:"category"`
+	Budgeted    float64 `json:"budgeted"`
+	CarriedOver float64 `json:"carried_over"`
+	Available   float64 `json:"available"`
+	Spent       float64 `json:"spent"`
+	Remaining   float64 `json:"remaining"`
+	PercentUsed float64 `json:"percent_used"`
+	Rollover    bool    `json:"rollover"`
+}
+
+type BudgetReport struct {
+	Month          int            `json:"month"`
+	Year           int            `json:"year"`
+	Budgets        []BudgetStatus `json:"budgets"`
+	TotalAvailable float64        `json:"total_available"`
+	TotalSpent     float64        `json:"total_spent"`
+	TotalRemaining float64        `json:"total_remaining"`
+	Alerts         []BudgetAlert  `json:"alerts"`
+}
+
+// BudgetAlert is raised once per category, month and threshold (a
+// percentage of the available amount) when an expense crosses it.
+type BudgetAlert struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Category  string    `json:"category"`
+	Month     int       `json:"month"`
+	Year      int       `json:"year"`
+	Threshold int       `json:"threshold"`
+	Spent     float64   `json:"spent"`
+	Available float64   `json:"available"`
+	CreatedAt time.Time `json:"created_at"`
+}
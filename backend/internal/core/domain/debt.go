@@ -0,0 +1,95 @@
+package domain
+
+import "time"
+
+const (
+	AmortizationSAC   = "sac"
+	AmortizationPrice = "price"
+
+	PayoffStrategyMinimum   = "minimum"
+	PayoffStrategySnowball  = "snowball"
+	PayoffStrategyAvalanche = "avalanche"
+)
+
+// Debt is a loan or financing repaid in monthly installments. InterestRate
+// is monthly, in percent, as Brazilian lenders quote it (1.49% a.m.). With
+// SAC every installment amortizes the same amount, so payments fall over
+// time; with Price (Tabela Price) every payment is the same.
+type Debt struct {
+	ID                 int       `json:"id"`
+	UserID             int       `json:"user_id"`
+	Name               string    `json:"name"`
+	Principal          float64   `json:"principal"`
+	InterestRate       float64   `json:"interest_rate"`
+	AmortizationSystem string    `json:"amortization_system"`
+	Installments       int       `json:"installments"`
+	FirstDueDate       time.Time `json:"first_due_date"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// DebtPayment links an expense transaction to the installment it paid.
+type DebtPayment struct {
+	ID            int       `json:"id"`
+	DebtID        int       `json:"debt_id"`
+	UserID        int       `json:"user_id"`
+	Installment   int       `json:"installment"`
+	TransactionID int       `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Date          time.Time `json:"date"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DebtInstallment is one row of the amortization schedule. Balance is what
+// is still owed once the installment is paid.
+type DebtInstallment struct {
+	Number       int          `json:"number"`
+	DueDate      time.Time    `json:"due_date"`
+	Payment      float64      `json:"payment"`
+	Interest     float64      `json:"interest"`
+	Amortization float64      `json:"amortization"`
+	Balance      float64      `json:"balance"`
+	Paid         *DebtPayment `json:"paid,omitempty"`
+}
+
+// DebtSchedule is a debt with its full schedule. Outstanding is the
+// principal not yet amortized by paid installments.
+type DebtSchedule struct {
+	Debt             Debt              `json:"debt"`
+	Installments     []DebtInstallment `json:"installments"`
+	TotalInterest    float64           `json:"total_interest"`
+	TotalPayments    float64           `json:"total_payments"`
+	PaidInstallments int               `json:"paid_installments"`
+	Outstanding      float64           `json:"outstanding"`
+}
+
+type DebtPayoff struct {
+	DebtID     int       `json:"debt_id"`
+	Name       string    `json:"name"`
+	Months     int       `json:"months"`
+	PayoffDate time.Time `json:"payoff_date"`
+	Interest   float64   `json:"interest"`
+}
+
+// PayoffPlan is the outcome of repaying every debt with one strategy. Debts
+// lists them in the order they are paid off.
+type PayoffPlan struct {
+	Strategy      string       `json:"strategy"`
+	Months        int          `json:"months"`
+	PayoffDate    time.Time    `json:"payoff_date"`
+	TotalInterest float64      `json:"total_interest"`
+	TotalPaid     float64      `json:"total_paid"`
+	Debts         []DebtPayoff `json:"debts"`
+}
+
+// PayoffComparison puts the same monthly budget, the installments due this
+// month plus ExtraMonthly, through snowball (smallest balance first) and
+// avalanche (highest rate first). Minimum is the plan without extra money
+// for reference. As each debt is paid off its installment joins the extra.
+type PayoffComparison struct {
+	ExtraMonthly  float64    `json:"extra_monthly"`
+	MonthlyBudget float64    `json:"monthly_budget"`
+	Minimum       PayoffPlan `json:"minimum"`
+	Snowball      PayoffPlan `json:"snowball"`
+	Avalanche     PayoffPlan `json:"avalanche"`
+	Recommended   string     `json:"recommended"`
+}
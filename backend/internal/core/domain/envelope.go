@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// EnvelopeSettings switches a user to zero-based budgeting. Income and
+// expenses dated before the start month are ignored by the envelopes.
+type EnvelopeSettings struct {
+	UserID     int  `json:"user_id"`
+	Enabled    bool `json:"enabled"`
+	StartMonth int  `json:"start_month"`
+	StartYear  int  `json:"start_year"`
+}
+
+// Envelope holds money assigned to a spending category or to a goal.
+// Expenses in the category draw it down; goal envelopes only receive money.
+// Envelopes are archived rather than deleted so past months still add up.
+type Envelope struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Category   string     `json:"category,omitempty"`
+	GoalID     *int       `json:"goal_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// EnvelopeTransfer moves money between envelopes. A nil side is the
+// "to be assigned" pool, so assignments are transfers from nil.
+type EnvelopeTransfer struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	FromEnvelopeID *int      `json:"from_envelope_id"`
+	ToEnvelopeID   *int      `json:"to_envelope_id"`
+	Amount         float64   `json:"amount"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type EnvelopeBalance struct {
+	Envelope  Envelope `json:"envelope"`
+	Carried   float64  `json:"carried"`
+	Assigned  float64  `json:"assigned"`
+	Spent     float64  `json:"spent"`
+	Balance   float64  `json:"balance"`
+	Overdrawn bool     `json:"overdrawn"`
+}
+
+// EnvelopeSummary is the state of every envelope at the end of a month.
+// Balances carry forward, so Carried is last month's closing balance.
+type EnvelopeSummary struct {
+	Enabled            bool              `json:"enabled"`
+	Month              int               `json:"month"`
+	Year               int               `json:"year"`
+	Income             float64           `json:"income"`
+	UnenvelopedSpent   float64           `json:"unenveloped_spent"`
+	ToBeAssigned       float64           `json:"to_be_assigned"`
+	Envelopes          []EnvelopeBalance `json:"envelopes"`
+	OverdrawnEnvelopes []EnvelopeBalance `json:"overdrawn_envelopes"`
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "errors"
+
+// ErrorKind says what a client can do about an Error; adapters map each
+// kind to their own status codes.
+type ErrorKind string
+
+const (
+	KindNotFound           ErrorKind = "not_found"
+	KindValidation         ErrorKind = "validation"
+	KindConflict           ErrorKind = "conflict"
+	KindPreconditionFailed ErrorKind = "precondition_failed"
+	KindUnauthorized       ErrorKind = "unauthorized"
+	KindTooLarge           ErrorKind = "too_large"
+	KindUnsupported        ErrorKind = "unsupported"
+)
+
+// Error is an error meant for the client: Code identifies it for good,
+// Message is safe to show and Fields points at the input at fault. Errors
+// of any other type are internal and never shown.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError is what is wrong with one field of the input.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error { return NewError(KindNotFound, code, message) }
+
+func Invalid(code, message string) *Error { return NewError(KindValidation, code, message) }
+
+func Conflict(code, message string) *Error { return NewError(KindConflict, code, message) }
+
+func Unauthorized(code, message string) *Error { return NewError(KindUnauthorized, code, message) }
+
+func (e *Error) Error() string { return e.Message }
+
+// Is matches errors by code, so a copy carrying its own message or fields
+// still matches the variable it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField returns a copy of e pointing at field.
+func (e *Error) WithField(field, message string) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldError(nil), e.Fields...), FieldError{Field: field, Message: message})
+	return &copied
+}
+
+var ErrNotFound = NotFound("not_found", "not found")
+
+// AsError returns the client error in err's chain, if any.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
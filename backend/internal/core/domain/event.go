@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain events, named <aggregate>.<what happened>.
+const (
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionUpdated  = "transaction.updated"
+	EventTransactionDeleted  = "transaction.deleted"
+	EventTransactionRestored = "transaction.restored"
+	EventGoalCreated         = "goal.created"
+	EventGoalUpdated         = "goal.updated"
+	EventGoalDeleted         = "goal.deleted"
+	EventGoalRestored        = "goal.restored"
+	EventGoalProgressAdded   = "goal.progress_added"
+	EventUserRegistered      = "user.registered"
+	EventDataReset           = "data.reset"
+	EventDataResetUndone     = "data.reset_undone"
+
+	// EventAuditRecorded carries an AuditEvent into the repository write it
+	// records. The repository stores it in audit_events, in the same
+	// transaction as the write, instead of the outbox: it is never delivered.
+	EventAuditRecorded = "audit.recorded"
+)
+
+// EventTypes lists every event subscribers can filter on.
+var EventTypes = []string{
+	EventTransactionCreated,
+	EventTransactionUpdated,
+	EventTransactionDeleted,
+	EventTransactionRestored,
+	EventGoalCreated,
+	EventGoalUpdated,
+	EventGoalDeleted,
+	EventGoalRestored,
+	EventGoalProgressAdded,
+	EventUserRegistered,
+	EventDataReset,
+	EventDataResetUndone,
+}
+
+// StreamEventTypes are the events pushed to the user's open dashboards.
+var StreamEventTypes = []string{
+	EventTransactionCreated,
+	EventTransactionUpdated,
+	EventTransactionDeleted,
+	EventTransactionRestored,
+	EventGoalCreated,
+	EventGoalUpdated,
+	EventGoalDeleted,
+	EventGoalRestored,
+	EventGoalProgressAdded,
+	EventDataReset,
+	EventDataResetUndone,
+}
+
+const (
+	AggregateTransaction = "transaction"
+	AggregateGoal        = "goal"
+	AggregateUser        = "user"
+)
+
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+	OutboxFailed    = "failed"
+)
+
+// Event is something that happened to an aggregate. Events raised by a
+// creation carry AggregateID 0 until the repository knows the new id.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	UserID        int             `json:"user_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// OutboxEntry is an event waiting in the outbox. DeliveredTo lists the
+// subscribers that already handled it, so a retry only reaches the others.
+type OutboxEntry struct {
+	Event
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	DeliveredTo   []string  `json:"delivered_to"`
+	LastError     string    `json:"last_error,omitempty"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	ForecastSourceRecurring   = "recurring"
+	ForecastSourceInstallment = "installment"
+	ForecastSourceGoal        = "goal"
+)
+
+// ForecastItem is a dated income or outflow the forecast expects.
+type ForecastItem struct {
+	Source      string    `json:"source"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Amount      float64   `json:"amount"`
+	Date        time.Time `json:"date"`
+}
+
+// CategoryBaseline is the average monthly variable spending of a category.
+type CategoryBaseline struct {
+	Category       string  `json:"category"`
+	MonthlyAverage float64 `json:"monthly_average"`
+}
+
+type ForecastDay struct {
+	Date    time.Time `json:"date"`
+	Income  float64   `json:"income"`
+	Expense float64   `json:"expense"`
+	Balance float64   `json:"balance"`
+}
+
+type Forecast struct {
+	StartingBalance   float64            `json:"starting_balance"`
+	Months            int                `json:"months"`
+	LowestBalance     float64            `json:"lowest_balance"`
+	LowestBalanceDate time.Time          `json:"lowest_balance_date"`
+	GoesNegative      bool               `json:"goes_negative"`
+	Days              []ForecastDay      `json:"days"`
+	Scheduled         []ForecastItem     `json:"scheduled"`
+	Baseline          []CategoryBaseline `json:"baseline"`
+}
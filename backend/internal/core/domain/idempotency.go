@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord ties an Idempotency-Key to the request that first used
+// it, by fingerprint, and to its response once it completes. Headers holds
+// the response headers a replay sends back.
+type IdempotencyRecord struct {
+	UserID         int
+	Key            string
+	Fingerprint    string
+	Status         string
+	ResponseStatus int
+	Headers        map[string]string
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
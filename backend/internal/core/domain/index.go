@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Economic indexes. CDI and Selic are daily rates published for business
+// days; IPCA is a monthly rate dated on the first day of its month. Rates
+// are percentages per period, as published by the Banco Central (SGS).
+const (
+	IndexCDI   = "cdi"
+	IndexSelic = "selic"
+	IndexIPCA  = "ipca"
+)
+
+type IndexRate struct {
+	Index string    `json:"index"`
+	Date  time.Time `json:"date"`
+	Rate  float64   `json:"rate"`
+}
+
+// GoalInvestment says how a goal's money is invested: Percent of an index
+// (110% of CDI) plus an optional fixed annual Spread (IPCA + 6%).
+type GoalInvestment struct {
+	GoalID  int     `json:"goal_id"`
+	UserID  int     `json:"user_id"`
+	Index   string  `json:"index"`
+	Percent float64 `json:"percent"`
+	Spread  float64 `json:"spread"`
+}
+
+// GoalContribution is one AddProgress call; yield accrues from its date.
+type GoalContribution struct {
+	ID        int       `json:"id"`
+	GoalID    int       `json:"goal_id"`
+	UserID    int       `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GoalYield is a goal's progress once yield and inflation are counted.
+// Inflation is the IPCA accumulated since the goal was created, in percent;
+// AdjustedTarget is the target in today's money. DataUntil is the last index
+// date available, since published series lag a few days.
+type GoalYield struct {
+	GoalID          int             `json:"goal_id"`
+	Investment      *GoalInvestment `json:"investment,omitempty"`
+	Contributed     float64         `json:"contributed"`
+	AccruedYield    float64         `json:"accrued_yield"`
+	Balance         float64         `json:"balance"`
+	Inflation       float64         `json:"inflation"`
+	AdjustedTarget  float64         `json:"adjusted_target"`
+	NominalProgress float64         `json:"nominal_progress"`
+	RealProgress    float64         `json:"real_progress"`
+	DataUntil       *time.Time      `json:"data_until,omitempty"`
+	AsOf            time.Time       `json:"as_of"`
+}
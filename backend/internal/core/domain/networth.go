@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+const (
+	AssetKindAsset     = "asset"
+	AssetKindLiability = "liability"
+)
+
+// Asset is something the user owns (property, vehicle, investments) or, when
+// Kind is liability, owes (loans, financing). Its worth on a given day is the
+// latest valuation dated on or before it; Value and ValuedAt mirror the most
+// recent one. Assets are archived rather than deleted so history still adds up.
+type Asset struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Category   string     `json:"category"`
+	Value      float64    `json:"value"`
+	ValuedAt   *time.Time `json:"valued_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+type AssetValuation struct {
+	ID        int       `json:"id"`
+	AssetID   int       `json:"asset_id"`
+	UserID    int       `json:"user_id"`
+	Value     float64   `json:"value"`
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NetWorthLine is one contribution to net worth: an account balance derived
+// from transactions, a goal balance, or an asset or liability valuation.
+// Liabilities are reported as positive values.
+type NetWorthLine struct {
+	Source string  `json:"source"`
+	ID     int     `json:"id,omitempty"`
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+}
+
+// NetWorth is Accounts + Goals + Assets - Liabilities at a point in time.
+type NetWorth struct {
+	Date        time.Time      `json:"date"`
+	Accounts    float64        `json:"accounts"`
+	Goals       float64        `json:"goals"`
+	Assets      float64        `json:"assets"`
+	Liabilities float64        `json:"liabilities"`
+	NetWorth    float64        `json:"net_worth"`
+	Lines       []NetWorthLine `json:"lines"`
+}
+
+// NetWorthSnapshot is the net worth stored for a month. The snapshot job
+// overwrites the current month until it ends, so past months keep their
+// closing value.
+type NetWorthSnapshot struct {
+	UserID      int       `json:"user_id"`
+	Year        int       `json:"year"`
+	Month       int       `json:"month"`
+	Accounts    float64   `json:"accounts"`
+	Goals       float64   `json:"goals"`
+	Assets      float64   `json:"assets"`
+	Liabilities float64   `json:"liabilities"`
+	NetWorth    float64   `json:"net_worth"`
+	TakenAt     time.Time `json:"taken_at"`
+}
@@ -0,0 +1,108 @@
+package domain
+
+import "time"
+
+// Events that raise notifications.
+const (
+	NotificationBudgetAlert   = "budget_alert"
+	NotificationGoalCompleted = "goal_completed"
+	NotificationBillDue       = "bill_due"
+	NotificationBillOverdue   = "bill_overdue"
+)
+
+// Channels a notification can go out on. In-app notifications are the
+// inbox itself; the others are delivered by a NotificationChannel.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+var (
+	NotificationEvents   = []string{NotificationBudgetAlert, NotificationGoalCompleted, NotificationBillDue, NotificationBillOverdue}
+	NotificationChannels = []string{ChannelInApp, ChannelEmail, ChannelPush}
+)
+
+// Notification is one event told to a user. DedupKey identifies the event
+// (e.g. "bill_overdue:42") so it is raised once however often it is seen.
+type Notification struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	EventType string     `json:"event_type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	URL       string     `json:"url,omitempty"`
+	DedupKey  string     `json:"-"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationInbox struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+}
+
+// NotificationPreference turns one event off or on for one channel. Every
+// combination without a preference is on.
+type NotificationPreference struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// NotificationSettings holds a user's preferences and quiet hours. Quiet
+// hours are "HH:MM" in Timezone and may wrap past midnight (22:00-07:00);
+// email and push raised inside them wait until they end, the inbox does
+// not. Empty start and end mean no quiet hours.
+type NotificationSettings struct {
+	UserID          int                      `json:"user_id"`
+	QuietHoursStart string                   `json:"quiet_hours_start"`
+	QuietHoursEnd   string                   `json:"quiet_hours_end"`
+	Timezone        string                   `json:"timezone"`
+	Preferences     []NotificationPreference `json:"preferences"`
+}
+
+// Enabled reports whether the event goes out on the channel.
+func (s NotificationSettings) Enabled(eventType, channel string) bool {
+	for _, p := range s.Preferences {
+		if p.EventType == eventType && p.Channel == channel {
+			return p.Enabled
+		}
+	}
+	return true
+}
+
+// PushSubscription is a browser's Web Push endpoint with the keys from its
+// PushSubscription.toJSON().
+type PushSubscription struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationRecipient is what a channel needs to reach a user.
+type NotificationRecipient struct {
+	UserID            int                `json:"user_id"`
+	Email             string             `json:"email"`
+	PushSubscriptions []PushSubscription `json:"push_subscriptions"`
+}
+
+// NotificationDelivery queues a notification for an external channel.
+type NotificationDelivery struct {
+	ID           int          `json:"id"`
+	Notification Notification `json:"notification"`
+	Channel      string       `json:"channel"`
+	SendAfter    time.Time    `json:"send_after"`
+	Attempts     int          `json:"attempts"`
+	Status       string       `json:"status"`
+	LastError    string       `json:"last_error,omitempty"`
+}
@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+const (
+	AssetClassTesouroDireto = "tesouro_direto"
+	AssetClassCDB           = "cdb"
+	AssetClassStock         = "acoes"
+	AssetClassFII           = "fii"
+	AssetClassOther         = "outros"
+
+	OperationBuy      = "buy"
+	OperationSell     = "sell"
+	OperationDividend = "dividend"
+
+	PriceSourceManual = "manual"
+	PriceSourceCSV    = "csv"
+)
+
+// Holding is an investment the user trades, identified by its ticker
+// (e.g. PETR4, HGLG11, "Tesouro Selic 2029").
+type Holding struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Ticker     string    `json:"ticker"`
+	Name       string    `json:"name"`
+	AssetClass string    `json:"asset_class"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InvestmentOperation is a buy, sell or dividend. Amount is the gross value:
+// Quantity * Price for trades, the amount received for dividends. Fees
+// (brokerage, B3 fees, withheld tax) are always positive.
+type InvestmentOperation struct {
+	ID        int       `json:"id"`
+	HoldingID int       `json:"holding_id"`
+	UserID    int       `json:"user_id"`
+	Type      string    `json:"type"`
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Amount    float64   `json:"amount"`
+	Fees      float64   `json:"fees"`
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PriceSnapshot is the unit price of a holding on a day; one per day.
+type PriceSnapshot struct {
+	ID        int       `json:"id"`
+	HoldingID int       `json:"holding_id"`
+	UserID    int       `json:"user_id"`
+	Price     float64   `json:"price"`
+	Date      time.Time `json:"date"`
+	Source    string    `json:"source"`
+}
+
+// Position is a holding valued at average cost: buy fees raise the cost,
+// sells take out average cost and realize the difference to their net
+// proceeds. Without a price snapshot the last trade price is used.
+type Position struct {
+	Holding        Holding    `json:"holding"`
+	Quantity       float64    `json:"quantity"`
+	AverageCost    float64    `json:"average_cost"`
+	CostBasis      float64    `json:"cost_basis"`
+	Price          float64    `json:"price"`
+	PricedAt       *time.Time `json:"priced_at,omitempty"`
+	MarketValue    float64    `json:"market_value"`
+	UnrealizedGain float64    `json:"unrealized_gain"`
+	RealizedGain   float64    `json:"realized_gain"`
+	Dividends      float64    `json:"dividends"`
+	Fees           float64    `json:"fees"`
+}
+
+type AllocationSlice struct {
+	AssetClass  string  `json:"asset_class"`
+	MarketValue float64 `json:"market_value"`
+	Percent     float64 `json:"percent"`
+}
+
+type Portfolio struct {
+	Positions      []Position        `json:"positions"`
+	Allocation     []AllocationSlice `json:"allocation"`
+	CostBasis      float64           `json:"cost_basis"`
+	MarketValue    float64           `json:"market_value"`
+	UnrealizedGain float64           `json:"unrealized_gain"`
+	RealizedGain   float64           `json:"realized_gain"`
+	Dividends      float64           `json:"dividends"`
+}
+
+type PriceImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// PriceImport reports a CSV import. Bad lines are skipped, not fatal.
+type PriceImport struct {
+	Imported int                `json:"imported"`
+	Errors   []PriceImportError `json:"errors"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+// MonthlyTotal is one month of income and expenses as aggregated by the
+// database.
+type MonthlyTotal struct {
+	Year    int     `json:"year"`
+	Month   int     `json:"month"`
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+}
+
+// CategoryTotal is one month of expenses in a category.
+type CategoryTotal struct {
+	Year     int     `json:"year"`
+	Month    int     `json:"month"`
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+type MonthlyReport struct {
+	Year        int     `json:"year"`
+	Month       int     `json:"month"`
+	Income      float64 `json:"income"`
+	Expense     float64 `json:"expense"`
+	Net         float64 `json:"net"`
+	SavingsRate float64 `json:"savings_rate"`
+}
+
+// CategoryMonth is a category's total in a month and its change from the
+// previous month. DeltaPercent is nil when the previous month was zero.
+type CategoryMonth struct {
+	Year         int      `json:"year"`
+	Month        int      `json:"month"`
+	Total        float64  `json:"total"`
+	Delta        float64  `json:"delta"`
+	DeltaPercent *float64 `json:"delta_percent"`
+}
+
+type CategoryReport struct {
+	Category string          `json:"category"`
+	Total    float64         `json:"total"`
+	Months   []CategoryMonth `json:"months"`
+}
+
+// YearOverYearMonth compares a month with the same month a year earlier.
+// Change percentages are nil when the earlier value was zero.
+type YearOverYearMonth struct {
+	Month           int      `json:"month"`
+	Income          float64  `json:"income"`
+	Expense         float64  `json:"expense"`
+	PreviousIncome  float64  `json:"previous_income"`
+	PreviousExpense float64  `json:"previous_expense"`
+	IncomeChange    *float64 `json:"income_change"`
+	ExpenseChange   *float64 `json:"expense_change"`
+}
+
+type YearOverYear struct {
+	Year            int                 `json:"year"`
+	Months          []YearOverYearMonth `json:"months"`
+	Income          float64             `json:"income"`
+	Expense         float64             `json:"expense"`
+	PreviousIncome  float64             `json:"previous_income"`
+	PreviousExpense float64             `json:"previous_expense"`
+	IncomeChange    *float64            `json:"income_change"`
+	ExpenseChange   *float64            `json:"expense_change"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+// RequestMeta identifies the HTTP request that triggered a change.
+type RequestMeta struct {
+	RequestID string
+	IP        string
+}
+
+type requestMetaKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func RequestMetaFrom(ctx context.Context) RequestMeta {
+	if ctx == nil {
+		return RequestMeta{}
+	}
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
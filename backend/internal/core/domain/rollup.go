@@ -0,0 +1,15 @@
+package domain
+
+// RollupMismatch is a monthly_category_totals row that disagrees with the
+// transactions it summarizes.
+type RollupMismatch struct {
+	UserID        int     `json:"user_id"`
+	Year          int     `json:"year"`
+	Month         int     `json:"month"`
+	Type          string  `json:"type"`
+	Category      string  `json:"category"`
+	ExpectedTotal float64 `json:"expected_total"`
+	ExpectedCount int     `json:"expected_count"`
+	RollupTotal   float64 `json:"rollup_total"`
+	RollupCount   int     `json:"rollup_count"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// CategoryRule assigns category, tags and budget bucket to transactions whose
+// fields match every condition that is set. Lower priority values run first.
+type CategoryRule struct {
+	ID       int    `json:"id"`
+	UserID   int    `json:"user_id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Enabled  bool   `json:"enabled"`
+
+	DescriptionContains string   `json:"description_contains,omitempty"`
+	DescriptionRegex    string   `json:"description_regex,omitempty"`
+	MinAmount           *float64 `json:"min_amount,omitempty"`
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Account             string   `json:"account,omitempty"`
+	TransactionType     string   `json:"transaction_type,omitempty"`
+	DayOfMonthFrom      int      `json:"day_of_month_from,omitempty"`
+	DayOfMonthTo        int      `json:"day_of_month_to,omitempty"`
+
+	SetCategory string   `json:"set_category,omitempty"`
+	SetTags     []string `json:"set_tags,omitempty"`
+	SetBucket   string   `json:"set_bucket,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RuleMatch describes how a rule would change an existing transaction.
+type RuleMatch struct {
+	Transaction Transaction `json:"transaction"`
+	Category    string      `json:"category"`
+	Tags        []string    `json:"tags"`
+	Bucket      string      `json:"bucket"`
+}
@@ -0,0 +1,6 @@
+package domain
+
+type CategorySuggestion struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "encoding/json"
+
+const (
+	SyncEntityTransaction = "transaction"
+	SyncEntityGoal        = "goal"
+
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncRejected = "rejected"
+)
+
+// SyncChange is a row written since the client's token. Rows in the trash
+// or removed for good are tombstones: Deleted, with only their ids.
+type SyncChange struct {
+	Entity      string       `json:"entity"`
+	ID          int          `json:"id"`
+	ClientID    string       `json:"client_id,omitempty"`
+	Version     int          `json:"version,omitempty"`
+	Deleted     bool         `json:"deleted"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Goal        *Goal        `json:"goal,omitempty"`
+}
+
+// SyncChanges holds the changes since a token and the token to send next.
+type SyncChanges struct {
+	Token   string       `json:"token"`
+	Changes []SyncChange `json:"changes"`
+}
+
+// SyncMutation is a change made offline to the row with ID or ClientID; an
+// upsert with a new ClientID creates the row. Changes holds the new value
+// of each changed field and Base its value at BaseVersion, when the client
+// last synced the row.
+type SyncMutation struct {
+	Entity      string                     `json:"entity"`
+	Op          string                     `json:"op"`
+	ID          int                        `json:"id,omitempty"`
+	ClientID    string                     `json:"client_id,omitempty"`
+	BaseVersion int                        `json:"base_version,omitempty"`
+	Base        map[string]json.RawMessage `json:"base,omitempty"`
+	Changes     map[string]json.RawMessage `json:"changes,omitempty"`
+}
+
+// SyncResult is the outcome of the mutation at Index. On a conflict Current
+// is the row as the server keeps it and Conflicts lists the fields whose
+// offline value was not applied.
+type SyncResult struct {
+	Index     int          `json:"index"`
+	Status    string       `json:"status"`
+	ID        int          `json:"id,omitempty"`
+	ClientID  string       `json:"client_id,omitempty"`
+	Version   int          `json:"version,omitempty"`
+	Conflicts []string     `json:"conflicts,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Current   *SyncChange  `json:"current,omitempty"`
+}
+
+type SyncResponse struct {
+	Results []SyncResult `json:"results"`
+	SyncChanges
+}
+
+// TransactionChange is the change that syncs t, a tombstone when it is in
+// the trash.
+func TransactionChange(t Transaction) SyncChange {
+	c := SyncChange{Entity: SyncEntityTransaction, ID: t.ID, ClientID: t.ClientID, Version: t.Version}
+	if t.DeletedAt != nil {
+		c.Deleted = true
+	} else {
+		c.Transaction = &t
+	}
+	return c
+}
+
+// GoalChange is the change that syncs g, a tombstone when it is in the
+// trash.
+func GoalChange(g Goal) SyncChange {
+	c := SyncChange{Entity: SyncEntityGoal, ID: g.ID, ClientID: g.ClientID, Version: g.Version}
+	if g.DeletedAt != nil {
+		c.Deleted = true
+	} else {
+		c.Goal = &g
+	}
+	return c
+}
@@ -3,12 +3,18 @@ package domain
 import "time"
 
 type Transaction struct {
-	ID          int       `json:"id"`
-	UserID      int       `json:"user_id"`
-	Type        string    `json:"type"`
-	Amount      float64   `json:"amount"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Type        string     `json:"type"`
+	Amount      float64    `json:"amount"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	Account     string     `json:"account"`
+	Tags        []string   `json:"tags"`
+	Bucket      string     `json:"bucket"`
+	Date        time.Time  `json:"date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ClientID    string     `json:"client_id,omitempty"`
+	Version     int        `json:"version"`
 }
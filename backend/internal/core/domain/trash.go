@@ -0,0 +1,9 @@
+package domain
+
+// Trash lists soft-deleted rows that can still be restored before the
+// retention window purges them for good.
+type Trash struct {
+	Transactions  []Transaction `json:"transactions"`
+	Goals         []Goal        `json:"goals"`
+	RetentionDays int           `json:"retention_days"`
+}
@@ -1,31 +1,79 @@
 package ports
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 )
 
+// ErrVersionConflict is returned by writes that carry a row version when the
+// row has changed since.
+var ErrVersionConflict = domain.NewError(domain.KindPreconditionFailed, "version_conflict", "the record was changed since it was read")
+
+// ErrClientIDExists is returned when a row with the same client-generated id
+// was already created.
+var ErrClientIDExists = domain.Conflict("client_id_exists", "a record with this client id already exists")
+
+// Repository writes that take events append them to the outbox in the same
+// database transaction as the change. Update and Delete only apply to the
+// given version of the row, unless it is 0.
 type TransactionRepository interface {
-	Save(transaction domain.Transaction) (int, error)
-	Update(transaction domain.Transaction) error
-	Delete(id, userID int) error
+	Save(transaction domain.Transaction, events ...domain.Event) (domain.Transaction, error)
+	Update(transaction domain.Transaction, events ...domain.Event) error
+	Delete(id, userID, version int, events ...domain.Event) error
+	ApplyBatch(writes []TransactionWrite) ([]domain.Transaction, error)
+	GetByID(id, userID int) (domain.Transaction, error)
 	ListByUserID(userID, month, year int) ([]domain.Transaction, error)
+	ListAllByUserID(userID int) ([]domain.Transaction, error)
 	DeleteAllByUserID(userID int) error
+	SoftDeleteAllByUserID(userID int, batch string, events ...domain.Event) (int, error)
+	ListDeleted(userID int) ([]domain.Transaction, error)
+	Restore(id, userID int, events ...domain.Event) (domain.Transaction, error)
+	LatestDeleteBatch(userID int) (string, error)
+	RestoreBatch(userID int, batch string, events ...domain.Event) ([]domain.Transaction, error)
+	ListDeletedBefore(cutoff time.Time) ([]domain.Transaction, error)
+	PurgeDeletedBefore(cutoff time.Time) (int, error)
 }
 
 type UserRepository interface {
-	Save(user domain.User) (int, error)
+	Save(user domain.User, events ...domain.Event) (int, error)
 	GetByEmail(email string) (domain.User, error)
+	GetByID(id int) (domain.User, error)
+	ListIDs() ([]int, error)
+}
+
+// TransactionWrite is one write of a batch: Transaction is created when its
+// ID is 0 and updated otherwise, or moved to the trash with Delete.
+type TransactionWrite struct {
+	Transaction domain.Transaction
+	Delete      bool
+	Events      []domain.Event
 }
 
+// BatchError tells which write of a batch failed.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string { return e.Err.Error() }
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// TransactionService and GoalService updates and deletes only apply to the
+// given version of the row, unless it is 0, and fail with ErrVersionConflict
+// otherwise. Updates return the row with its new version.
 type TransactionService interface {
-	CreateIncome(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error)
-	CreateExpense(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error)
-	UpdateTransaction(userID, id int, amount float64, category, description string, date time.Time, typeStr string) error
-	DeleteTransaction(userID, id int) error
+	CreateIncome(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error)
+	CreateExpense(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error)
+	GetTransaction(userID, id int) (domain.Transaction, error)
+	UpdateTransaction(ctx context.Context, userID, id, version int, amount float64, category, description, account string, date time.Time, typeStr string) (domain.Transaction, error)
+	DeleteTransaction(ctx context.Context, userID, id, version int) error
+	ApplyBatch(ctx context.Context, userID int, mode string, ops []domain.BatchOperation) ([]domain.BatchResult, error)
 	ListTransactions(userID, month, year int) ([]domain.Transaction, error)
-	ResetData(userID int) error
+	ResetData(ctx context.Context, userID int) error
 }
 
 type AuthService interface {
@@ -34,18 +82,448 @@ type AuthService interface {
 }
 
 type GoalRepository interface {
-	Save(goal domain.Goal) (int, error)
-	Update(goal domain.Goal) error
-	Delete(id, userID int) error
+	Save(goal domain.Goal, events ...domain.Event) (domain.Goal, error)
+	Update(goal domain.Goal, events ...domain.Event) error
+	Delete(id, userID, version int, events ...domain.Event) error
 	ListByUserID(userID int) ([]domain.Goal, error)
 	GetByID(id, userID int) (domain.Goal, error)
-	AddProgress(id, userID int, amount float64) error
+	AddProgress(id, userID int, amount float64, events ...domain.Event) error
+	ListDeleted(userID int) ([]domain.Goal, error)
+	Restore(id, userID int, events ...domain.Event) error
+	PurgeDeletedBefore(cutoff time.Time) (int, error)
+	ListContributions(id, userID int) ([]domain.GoalContribution, error)
+	GetInvestment(id, userID int) (domain.GoalInvestment, error)
+	SaveInvestment(investment domain.GoalInvestment, events ...domain.Event) error
+	DeleteInvestment(id, userID int, events ...domain.Event) error
 }
 
 type GoalService interface {
-	CreateGoal(userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error)
-	UpdateGoal(userID, id int, name string, targetAmount float64, deadline time.Time) error
-	DeleteGoal(userID, id int) error
+	CreateGoal(ctx context.Context, userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error)
+	GetGoal(userID, id int) (domain.Goal, error)
+	UpdateGoal(ctx context.Context, userID, id, version int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error)
+	DeleteGoal(ctx context.Context, userID, id, version int) error
 	ListGoals(userID int) ([]domain.Goal, error)
-	AddProgress(userID, goalID int, amount float64) error
+	AddProgress(ctx context.Context, userID, goalID int, amount float64) error
+	SetInvestment(ctx context.Context, userID, goalID int, investment domain.GoalInvestment) (domain.GoalInvestment, error)
+	Yield(userID, goalID int) (domain.GoalYield, error)
+}
+
+// ErrBlobNotFound is returned by BlobStorage.Get when the key does not exist.
+var ErrBlobNotFound = domain.NotFound("blob_not_found", "blob not found")
+
+type BlobStorage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type AttachmentRepository interface {
+	Save(attachment domain.Attachment, events ...domain.Event) (int, error)
+	GetByID(id, userID int) (domain.Attachment, error)
+	ListByTransactionID(transactionID, userID int) ([]domain.Attachment, error)
+	Delete(id, userID int, events ...domain.Event) error
+	DeleteByTransactionID(transactionID, userID int) ([]domain.Attachment, error)
+}
+
+type AttachmentService interface {
+	Upload(ctx context.Context, userID, transactionID int, fileName string, content io.Reader) (domain.Attachment, error)
+	ListAttachments(userID, transactionID int) ([]domain.Attachment, error)
+	Open(userID, id int, thumbnail bool) (domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, userID, id int) error
+	DeleteByTransaction(userID, transactionID int) error
+}
+
+type RuleRepository interface {
+	Save(rule domain.CategoryRule, events ...domain.Event) (int, error)
+	Update(rule domain.CategoryRule, events ...domain.Event) error
+	Delete(id, userID int, events ...domain.Event) error
+	GetByID(id, userID int) (domain.CategoryRule, error)
+	ListByUserID(userID int) ([]domain.CategoryRule, error)
+}
+
+type RuleService interface {
+	CreateRule(ctx context.Context, userID int, rule domain.CategoryRule) (domain.CategoryRule, error)
+	UpdateRule(ctx context.Context, userID, id int, rule domain.CategoryRule) error
+	DeleteRule(ctx context.Context, userID, id int) error
+	ListRules(userID int) ([]domain.CategoryRule, error)
+	ApplyRules(transaction domain.Transaction) (domain.Transaction, error)
+	PreviewRule(userID int, rule domain.CategoryRule) ([]domain.RuleMatch, error)
+	ApplyRuleRetroactively(ctx context.Context, userID, id int) (int, error)
+}
+
+type CategorySuggestionService interface {
+	Suggest(userID int, description string, amount float64) ([]domain.CategorySuggestion, error)
+	Learn(transaction domain.Transaction)
+	Forget(transaction domain.Transaction)
+	Reset(userID int)
+}
+
+type TrashService interface {
+	ListTrash(userID int) (domain.Trash, error)
+	RestoreTransaction(ctx context.Context, userID, id int) error
+	RestoreGoal(ctx context.Context, userID, id int) error
+	UndoReset(ctx context.Context, userID int) (int, error)
+	PurgeExpired() error
+}
+
+type AuditRepository interface {
+	ListByEntity(userID int, entityType string, entityID int) ([]domain.AuditEvent, error)
+}
+
+type AuditService interface {
+	Event(ctx context.Context, userID int, entityType string, entityID int, action string, before, after any) (domain.Event, error)
+	History(userID int, entityType string, entityID int) ([]domain.AuditEvent, error)
+}
+
+// ErrBudgetExists is returned by BudgetRepository when the category already
+// has a budget starting in the same month.
+var ErrBudgetExists = domain.Conflict("budget_exists", "a budget for this category already starts in that month")
+
+type BudgetRepository interface {
+	Save(budget domain.Budget, events ...domain.Event) (int, error)
+	Update(budget domain.Budget, events ...domain.Event) error
+	Delete(id, userID int, events ...domain.Event) error
+	GetByID(id, userID int) (domain.Budget, error)
+	ListByUserID(userID int) ([]domain.Budget, error)
+	SaveAlert(alert domain.BudgetAlert) (bool, error)
+	ListAlerts(userID, month, year int) ([]domain.BudgetAlert, error)
+}
+
+type BudgetService interface {
+	CreateBudget(ctx context.Context, userID int, budget domain.Budget) (domain.Budget, error)
+	UpdateBudget(ctx context.Context, userID, id int, budget domain.Budget) error
+	DeleteBudget(ctx context.Context, userID, id int) error
+	ListBudgets(userID int) ([]domain.Budget, error)
+	Report(userID, month, year int) (domain.BudgetReport, error)
+	CheckExpense(transaction domain.Transaction) ([]domain.BudgetAlert, error)
+	CheckExpenseChange(before, after domain.Transaction) ([]domain.BudgetAlert, error)
+}
+
+type EnvelopeRepository interface {
+	GetSettings(userID int) (domain.EnvelopeSettings, error)
+	SaveSettings(settings domain.EnvelopeSettings, events ...domain.Event) error
+	Save(envelope domain.Envelope, events ...domain.Event) (int, error)
+	Archive(id, userID int, events ...domain.Event) error
+	GetByID(id, userID int) (domain.Envelope, error)
+	ListByUserID(userID int) ([]domain.Envelope, error)
+	// SaveTransfer runs check, stores the transfer and applies progress in
+	// one transaction; transfers of the same user never run concurrently.
+	SaveTransfer(transfer domain.EnvelopeTransfer, check func() error, progress []GoalProgress, events ...domain.Event) (int, error)
+	ListTransfers(userID int) ([]domain.EnvelopeTransfer, error)
+}
+
+// GoalProgress is a signed change to a goal's saved amount.
+type GoalProgress struct {
+	GoalID int
+	Amount float64
+}
+
+type EnvelopeService interface {
+	SetMode(ctx context.Context, userID int, enabled bool) (domain.EnvelopeSettings, error)
+	CreateEnvelope(ctx context.Context, userID int, envelope domain.Envelope) (domain.Envelope, error)
+	ListEnvelopes(userID int) ([]domain.Envelope, error)
+	DeleteEnvelope(ctx context.Context, userID, id int) error
+	Assign(ctx context.Context, userID, envelopeID int, amount float64) (domain.EnvelopeTransfer, error)
+	Move(ctx context.Context, userID int, transfer domain.EnvelopeTransfer) (domain.EnvelopeTransfer, error)
+	ListTransfers(userID int) ([]domain.EnvelopeTransfer, error)
+	Summary(userID, month, year int) (domain.EnvelopeSummary, error)
+}
+
+type ForecastService interface {
+	Forecast(userID, months int) (domain.Forecast, error)
+}
+
+// ReportRepository reads per-month transaction totals. from and to are month
+// starts and the range is [from, to).
+type ReportRepository interface {
+	MonthlyTotals(userID int, from, to time.Time) ([]domain.MonthlyTotal, error)
+	CategoryTotals(userID int, from, to time.Time) ([]domain.CategoryTotal, error)
+	// CategorySpent sums one category's expenses, matched case-insensitively.
+	CategorySpent(userID int, category string, from, to time.Time) (float64, error)
+}
+
+type ReportService interface {
+	Monthly(userID int, from, to time.Time) ([]domain.MonthlyReport, error)
+	Categories(userID int, from, to time.Time) ([]domain.CategoryReport, error)
+	YearOverYear(userID, year int) (domain.YearOverYear, error)
+}
+
+type NetWorthRepository interface {
+	SaveAsset(asset domain.Asset, events ...domain.Event) (int, error)
+	UpdateAsset(asset domain.Asset, events ...domain.Event) error
+	ArchiveAsset(id, userID int, events ...domain.Event) error
+	GetAsset(id, userID int) (domain.Asset, error)
+	ListAssets(userID int) ([]domain.Asset, error)
+	SaveValuation(valuation domain.AssetValuation, events ...domain.Event) (int, error)
+	ListValuations(userID int) ([]domain.AssetValuation, error)
+	SaveSnapshot(snapshot domain.NetWorthSnapshot) error
+	ListSnapshots(userID int, from, to time.Time) ([]domain.NetWorthSnapshot, error)
+}
+
+type NetWorthService interface {
+	CreateAsset(ctx context.Context, userID int, asset domain.Asset) (domain.Asset, error)
+	UpdateAsset(ctx context.Context, userID, id int, asset domain.Asset) error
+	DeleteAsset(ctx context.Context, userID, id int) error
+	ListAssets(userID int) ([]domain.Asset, error)
+	AddValuation(ctx context.Context, userID, assetID int, value float64, date time.Time) (domain.AssetValuation, error)
+	ListValuations(userID, assetID int) ([]domain.AssetValuation, error)
+	Current(userID int) (domain.NetWorth, error)
+	History(userID int, from, to time.Time) ([]domain.NetWorthSnapshot, error)
+	SnapshotAll() error
+}
+
+// ErrHoldingExists is returned by PortfolioRepository when the user already
+// has a holding with the same ticker.
+var ErrHoldingExists = domain.Conflict("holding_exists", "a holding with this ticker already exists")
+
+type PortfolioRepository interface {
+	SaveHolding(holding domain.Holding, events ...domain.Event) (int, error)
+	GetHolding(id, userID int) (domain.Holding, error)
+	ListHoldings(userID int) ([]domain.Holding, error)
+	DeleteHolding(id, userID int, events ...domain.Event) error
+	SaveOperation(operation domain.InvestmentOperation, events ...domain.Event) (int, error)
+	DeleteOperation(id, userID int, events ...domain.Event) error
+	ListOperations(userID int) ([]domain.InvestmentOperation, error)
+	SavePrices(prices []domain.PriceSnapshot) error
+	LatestPrices(userID int) ([]domain.PriceSnapshot, error)
+}
+
+type PortfolioService interface {
+	CreateHolding(ctx context.Context, userID int, holding domain.Holding) (domain.Holding, error)
+	ListHoldings(userID int) ([]domain.Holding, error)
+	DeleteHolding(ctx context.Context, userID, id int) error
+	RecordOperation(ctx context.Context, userID int, operation domain.InvestmentOperation) (domain.InvestmentOperation, error)
+	DeleteOperation(ctx context.Context, userID, id int) error
+	ListOperations(userID, holdingID int) ([]domain.InvestmentOperation, error)
+	SetPrice(ctx context.Context, userID, holdingID int, price float64, date time.Time) (domain.PriceSnapshot, error)
+	ImportPrices(ctx context.Context, userID int, csv io.Reader) (domain.PriceImport, error)
+	Portfolio(userID int) (domain.Portfolio, error)
+}
+
+// IndexRepository stores economic index series shared by every user.
+// ListRates ranges are inclusive on both ends.
+type IndexRepository interface {
+	SaveRates(rates []domain.IndexRate) error
+	ListRates(index string, from, to time.Time) ([]domain.IndexRate, error)
+}
+
+type IndexService interface {
+	Import(index string, csv io.Reader) (int, error)
+	LoadDir(dir string) error
+}
+
+// ErrDebtPaymentExists is returned by DebtRepository when the installment
+// is already paid or the transaction already pays another installment.
+var ErrDebtPaymentExists = domain.Conflict("debt_payment_exists", "installment already paid or transaction already linked")
+
+// DebtRepository.ListPayments leaves out payments whose transaction was
+// deleted, and fills Amount and Date from the transaction.
+type DebtRepository interface {
+	SaveDebt(debt domain.Debt, events ...domain.Event) (int, error)
+	GetDebt(id, userID int) (domain.Debt, error)
+	ListDebts(userID int) ([]domain.Debt, error)
+	DeleteDebt(id, userID int, events ...domain.Event) error
+	SavePayment(payment domain.DebtPayment, events ...domain.Event) (int, error)
+	DeletePayment(debtID, userID, installment int, events ...domain.Event) error
+	ListPayments(userID int) ([]domain.DebtPayment, error)
+}
+
+type DebtService interface {
+	CreateDebt(ctx context.Context, userID int, debt domain.Debt) (domain.Debt, error)
+	ListDebts(userID int) ([]domain.DebtSchedule, error)
+	DeleteDebt(ctx context.Context, userID, id int) error
+	Schedule(userID, id int) (domain.DebtSchedule, error)
+	RecordPayment(ctx context.Context, userID, debtID, installment, transactionID int) (domain.DebtPayment, error)
+	DeletePayment(ctx context.Context, userID, debtID, installment int) error
+	Plan(userID int, extraMonthly float64) (domain.PayoffComparison, error)
+}
+
+type BillRepository interface {
+	Save(bill domain.Bill, events ...domain.Event) (int, error)
+	Update(bill domain.Bill, events ...domain.Event) error
+	Delete(id, userID int, events ...domain.Event) error
+	GetByID(id, userID int) (domain.Bill, error)
+	ListByUserID(userID int) ([]domain.Bill, error)
+	MarkPaid(bill domain.Bill, events ...domain.Event) error
+	ListUnpaidDueBefore(cutoff time.Time) ([]domain.Bill, error)
+	SetStatus(ids []int, status string) error
+}
+
+type BillService interface {
+	CreateBill(ctx context.Context, userID int, bill domain.Bill) (domain.Bill, error)
+	UpdateBill(ctx context.Context, userID, id int, bill domain.Bill) error
+	DeleteBill(ctx context.Context, userID, id int) error
+	ListBills(userID int, status string) ([]domain.Bill, error)
+	MarkPaid(ctx context.Context, userID, id int, amount float64, date time.Time) (domain.Bill, error)
+	Upcoming(userID, days int) (domain.UpcomingBills, error)
+	RefreshStatuses() error
+}
+
+// NotificationChannel delivers notifications outside the app. Name is the
+// channel it serves (domain.ChannelEmail, domain.ChannelPush).
+type NotificationChannel interface {
+	Name() string
+	Deliver(ctx context.Context, to domain.NotificationRecipient, notification domain.Notification) error
+}
+
+// PushExpiredError is returned by the push channel for subscriptions the
+// push service no longer accepts; they should be forgotten. It is joined with
+// the other failures when some subscriptions failed for other reasons.
+type PushExpiredError struct {
+	Endpoints []string
+}
+
+func (e *PushExpiredError) Error() string {
+	return fmt.Sprintf("%d push subscription(s) expired", len(e.Endpoints))
+}
+
+// NotificationRepository.SaveNotification returns false without saving when
+// the user already has a notification with the same dedup key.
+type NotificationRepository interface {
+	SaveNotification(notification domain.Notification, inApp bool) (int, bool, error)
+	ListNotifications(userID int, unreadOnly bool) ([]domain.Notification, error)
+	CountUnread(userID int) (int, error)
+	MarkRead(userID int, ids []int) (int, error)
+	GetSettings(userID int) (domain.NotificationSettings, error)
+	SaveSettings(settings domain.NotificationSettings, events ...domain.Event) error
+	SavePushSubscription(subscription domain.PushSubscription) error
+	DeletePushSubscriptions(userID int, endpoints []string) error
+	ListPushSubscriptions(userID int) ([]domain.PushSubscription, error)
+	SaveDelivery(delivery domain.NotificationDelivery) error
+	ListDueDeliveries(now time.Time, limit int) ([]domain.NotificationDelivery, error)
+	UpdateDelivery(delivery domain.NotificationDelivery) error
+}
+
+type NotificationService interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+	Inbox(userID int, unreadOnly bool) (domain.NotificationInbox, error)
+	MarkRead(userID int, ids []int) (int, error)
+	Settings(userID int) (domain.NotificationSettings, error)
+	UpdateSettings(ctx context.Context, userID int, settings domain.NotificationSettings) (domain.NotificationSettings, error)
+	SubscribePush(ctx context.Context, userID int, subscription domain.PushSubscription) error
+	UnsubscribePush(ctx context.Context, userID int, endpoint string) error
+}
+
+// EventHandler reacts to a domain event. Delivery is at least once, so
+// handlers must tolerate seeing the same event (same ID) again.
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+// OutboxRepository.LatestEventID returns the highest event id written so
+// far, 0 when the outbox is empty.
+type OutboxRepository interface {
+	LatestEventID() (int64, error)
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEntry, error)
+	UpdateEntry(entry domain.OutboxEntry) error
+	PurgeProcessedBefore(cutoff time.Time) (int, error)
+}
+
+// EventBus delivers outbox events to subscribers. A subscriber with no
+// event types receives every event.
+type EventBus interface {
+	Subscribe(name string, handler EventHandler, eventTypes ...string)
+}
+
+// WebhookRepository.SaveDelivery returns false without saving when the
+// webhook already has a delivery for the event. RecordResult updates the
+// webhook's run of consecutive failures, deactivating it once the run
+// reaches disableAfter, and reports whether it is still active.
+type WebhookRepository interface {
+	Save(webhook domain.Webhook, events ...domain.Event) (int, error)
+	Update(webhook domain.Webhook, events ...domain.Event) error
+	Delete(id, userID int, events ...domain.Event) error
+	GetByID(id, userID int) (domain.Webhook, error)
+	ListByUserID(userID int) ([]domain.Webhook, error)
+	SaveDelivery(delivery domain.WebhookDelivery) (int, bool, error)
+	GetDelivery(id, webhookID, userID int) (domain.WebhookDelivery, error)
+	ListDeliveries(webhookID, userID int) ([]domain.WebhookDelivery, error)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(delivery domain.WebhookDelivery) error
+	RecordResult(webhookID int, succeeded bool, disableAfter int, now time.Time) (bool, error)
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, userID int, webhook domain.Webhook) (domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, userID, id int, webhook domain.Webhook) (domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, id int) error
+	ListWebhooks(userID int) ([]domain.Webhook, error)
+	ListDeliveries(userID, webhookID int) ([]domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userID, webhookID, deliveryID int) (domain.WebhookDelivery, error)
+}
+
+// EventPublisher hands a domain event to every API instance, each of which
+// broadcasts it to its own stream clients.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// EventStream feeds a user's live connections. Subscribe replays the
+// buffered events after lastEventID (0 for none); when those can no longer
+// be replayed, Reset is set and the client should reload its data. Events is
+// closed when the stream drops the client, which should then reconnect.
+type EventStream interface {
+	Subscribe(userID int, lastEventID int64) *StreamSubscription
+}
+
+type StreamSubscription struct {
+	Reset  bool
+	Replay []domain.Event
+	Events <-chan domain.Event
+	Close  func()
+}
+
+// SyncRepository reads changes for offline sync. Changes returns the rows
+// written by database transactions from since up to a new horizon, which
+// becomes the next token; since 0 returns every live row. The Find methods
+// also return rows in the trash.
+type SyncRepository interface {
+	Changes(userID int, since int64) ([]domain.SyncChange, int64, error)
+	FindTransaction(userID, id int, clientID string) (domain.Transaction, error)
+	FindGoal(userID, id int, clientID string) (domain.Goal, error)
+}
+
+// TransactionSyncer and GoalSyncer write synced rows through the same rules,
+// audit and events as the rest of the API. Writes carrying a version fail
+// with ErrVersionConflict when the row has changed since.
+type TransactionSyncer interface {
+	CreateSynced(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error)
+	UpdateSynced(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error)
+	DeleteSynced(ctx context.Context, userID, id, version int) error
+}
+
+// TransactionRecategorizer writes a rule's matches to their transactions
+// for RuleService, with the same events, audit and suggestions as the rest
+// of the API.
+type TransactionRecategorizer interface {
+	Recategorize(ctx context.Context, userID int, matches []domain.RuleMatch) error
+}
+
+type GoalSyncer interface {
+	CreateSynced(ctx context.Context, goal domain.Goal) (domain.Goal, error)
+	UpdateSynced(ctx context.Context, goal domain.Goal) (domain.Goal, error)
+	DeleteSynced(ctx context.Context, userID, id, version int) error
+}
+
+type SyncService interface {
+	Pull(userID int, token string) (domain.SyncChanges, error)
+	Push(ctx context.Context, userID int, token string, mutations []domain.SyncMutation) (domain.SyncResponse, error)
+}
+
+// IdempotencyRepository stores Idempotency-Key records. Claim inserts the
+// record unless the user already holds the key; an expired record, or one
+// still in progress since before staleBefore, is taken over. It returns the
+// record now holding the key and whether it is the one given.
+type IdempotencyRepository interface {
+	Claim(record domain.IdempotencyRecord, staleBefore time.Time) (domain.IdempotencyRecord, bool, error)
+	Complete(record domain.IdempotencyRecord) error
+	Release(userID int, key string) error
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// IdempotencyService guards retried writes. Begin returns nil when the
+// caller holds the key and must Complete or Release it, or the completed
+// record whose response should be replayed.
+type IdempotencyService interface {
+	Begin(userID int, key, fingerprint string) (*domain.IdempotencyRecord, error)
+	Complete(userID int, key string, status int, headers map[string]string, body []byte) error
+	Release(userID int, key string) error
 }
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var (
+	ErrAttachmentTooLarge  = domain.NewError(domain.KindTooLarge, "attachment_too_large", "attachment exceeds the maximum allowed size")
+	ErrUnsupportedFileType = domain.NewError(domain.KindUnsupported, "unsupported_file_type", "unsupported file type")
+	ErrNoThumbnail         = domain.NotFound("no_thumbnail", "attachment has no thumbnail")
+)
+
+const thumbnailMaxSide = 256
+
+// thumbnailMaxPixels bounds the images decoded for a thumbnail: a small file
+// can declare dimensions whose decoded pixels take gigabytes. Larger images
+// are stored without one.
+const thumbnailMaxPixels = 50_000_000
+
+// allowedAttachmentTypes lists the sniffed MIME types accepted for receipts.
+// The client-provided Content-Type is never trusted.
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type AttachmentService struct {
+	repo            ports.AttachmentRepository
+	transactionRepo ports.TransactionRepository
+	storage         ports.BlobStorage
+	maxSize         int64
+	audit           ports.AuditService
+}
+
+func NewAttachmentService(repo ports.AttachmentRepository, transactionRepo ports.TransactionRepository, storage ports.BlobStorage, maxSize int64) *AttachmentService {
+	return &AttachmentService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		storage:         storage,
+		maxSize:         maxSize,
+	}
+}
+
+// SetAuditService records uploads and deletions.
+func (s *AttachmentService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, userID, transactionID int, fileName string, content io.Reader) (domain.Attachment, error) {
+	if _, err := s.transactionRepo.GetByID(transactionID, userID); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return domain.Attachment{}, ErrAttachmentTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !allowedAttachmentTypes[contentType] {
+		return domain.Attachment{}, ErrUnsupportedFileType
+	}
+
+	prefix, err := randomKey()
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	attachment := domain.Attachment{
+		UserID:        userID,
+		TransactionID: transactionID,
+		FileName:      sanitizeFileName(fileName),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		StorageKey:    fmt.Sprintf("users/%d/transactions/%d/%s", userID, transactionID, prefix),
+	}
+
+	if err := s.storage.Put(attachment.StorageKey, data, contentType); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	if thumb, ok := generateThumbnail(data); ok {
+		key := attachment.StorageKey + "-thumb.jpg"
+		if err := s.storage.Put(key, thumb, "image/jpeg"); err != nil {
+			log.Printf("attachment: could not store thumbnail: %v", err)
+		} else {
+			attachment.ThumbnailKey = key
+			attachment.HasThumbnail = true
+		}
+	}
+
+	id, err := s.repo.Save(attachment, auditEvents(s.audit, ctx, userID, domain.AuditEntityAttachment, 0, domain.AuditActionCreate, nil, attachment)...)
+	if err != nil {
+		s.removeBlobs(attachment)
+		return domain.Attachment{}, err
+	}
+
+	attachment.ID = id
+	return attachment, nil
+}
+
+func (s *AttachmentService) ListAttachments(userID, transactionID int) ([]domain.Attachment, error) {
+	return s.repo.ListByTransactionID(transactionID, userID)
+}
+
+func (s *AttachmentService) Open(userID, id int, thumbnail bool) (domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return domain.Attachment{}, nil, ErrNoThumbnail
+		}
+		key = attachment.ThumbnailKey
+		attachment.ContentType = "image/jpeg"
+	}
+
+	body, err := s.storage.Get(key)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	return attachment, body, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, userID, id int) error {
+	attachment, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityAttachment, id, domain.AuditActionDelete, attachment, nil)...); err != nil {
+		return err
+	}
+	s.removeBlobs(attachment)
+	return nil
+}
+
+func (s *AttachmentService) DeleteByTransaction(userID, transactionID int) error {
+	attachments, err := s.repo.DeleteByTransactionID(transactionID, userID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		s.removeBlobs(a)
+	}
+	return nil
+}
+
+// removeBlobs is best effort: a leftover object is preferable to failing a
+// delete the user already confirmed.
+func (s *AttachmentService) removeBlobs(a domain.Attachment) {
+	if err := s.storage.Delete(a.StorageKey); err != nil {
+		log.Printf("attachment: could not delete %s: %v", a.StorageKey, err)
+	}
+	if a.ThumbnailKey != "" {
+		if err := s.storage.Delete(a.ThumbnailKey); err != nil {
+			log.Printf("attachment: could not delete %s: %v", a.ThumbnailKey, err)
+		}
+	}
+}
+
+func generateThumbnail(data []byte) ([]byte, bool) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || int64(config.Width)*int64(config.Height) > thumbnailMaxPixels {
+		return nil, false
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, false
+	}
+
+	tw, th := w, h
+	if w > thumbnailMaxSide || h > thumbnailMaxSide {
+		if w >= h {
+			tw, th = thumbnailMaxSide, h*thumbnailMaxSide/w
+		} else {
+			tw, th = w*thumbnailMaxSide/h, thumbnailMaxSide
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		sy := bounds.Min.Y + y*h/th
+		for x := 0; x < tw; x++ {
+			sx := bounds.Min.X + x*w/tw
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
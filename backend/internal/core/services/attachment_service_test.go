@@ -0,0 +1,187 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type memoryBlobStorage struct {
+	objects map[string][]byte
+}
+
+func (m *memoryBlobStorage) Put(key string, data []byte, contentType string) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryBlobStorage) Get(key string) (io.ReadCloser, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ports.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryBlobStorage) Delete(key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+type memoryAttachmentRepository struct {
+	attachments []domain.Attachment
+}
+
+func (m *memoryAttachmentRepository) Save(a domain.Attachment, events ...domain.Event) (int, error) {
+	a.ID = len(m.attachments) + 1
+	m.attachments = append(m.attachments, a)
+	return a.ID, nil
+}
+
+func (m *memoryAttachmentRepository) GetByID(id, userID int) (domain.Attachment, error) {
+	for _, a := range m.attachments {
+		if a.ID == id && a.UserID == userID {
+			return a, nil
+		}
+	}
+	return domain.Attachment{}, sql.ErrNoRows
+}
+
+func (m *memoryAttachmentRepository) ListByTransactionID(transactionID, userID int) ([]domain.Attachment, error) {
+	var result []domain.Attachment
+	for _, a := range m.attachments {
+		if a.TransactionID == transactionID && a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryAttachmentRepository) Delete(id, userID int, events ...domain.Event) error {
+	for i, a := range m.attachments {
+		if a.ID == id && a.UserID == userID {
+			m.attachments = append(m.attachments[:i], m.attachments[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryAttachmentRepository) DeleteByTransactionID(transactionID, userID int) ([]domain.Attachment, error) {
+	return m.deleteWhere(func(a domain.Attachment) bool {
+		return a.TransactionID == transactionID && a.UserID == userID
+	}), nil
+}
+
+func (m *memoryAttachmentRepository) deleteWhere(match func(domain.Attachment) bool) []domain.Attachment {
+	var kept, removed []domain.Attachment
+	for _, a := range m.attachments {
+		if match(a) {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+	m.attachments = kept
+	return removed
+}
+
+func pngImage(w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// pngDeclaring returns a tiny PNG whose header claims w×h pixels.
+func pngDeclaring(w, h uint32) []byte {
+	data := pngImage(1, 1)
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:8], w)
+	binary.BigEndian.PutUint32(ihdr[8:12], h)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+func newAttachmentService(maxSize int64) (*services.AttachmentService, *memoryAttachmentRepository, *memoryBlobStorage, *MockTransactionRepository) {
+	repo := &memoryAttachmentRepository{}
+	store := &memoryBlobStorage{objects: map[string][]byte{}}
+	txRepo := new(MockTransactionRepository)
+	return services.NewAttachmentService(repo, txRepo, store, maxSize), repo, store, txRepo
+}
+
+func TestAttachmentUpload_ImageCreatesThumbnail(t *testing.T) {
+	service, _, store, txRepo := newAttachmentService(1 << 20)
+	txRepo.On("GetByID", 10, 1).Return(domain.Transaction{ID: 10, UserID: 1}, nil)
+
+	attachment, err := service.Upload(context.Background(), 1, 10, "../../recibo.png", bytes.NewReader(pngImage(800, 400)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, "recibo.png", attachment.FileName)
+	assert.True(t, attachment.HasThumbnail)
+	assert.Len(t, store.objects, 2)
+
+	_, body, err := service.Open(1, attachment.ID, true)
+	assert.NoError(t, err)
+	thumb, _, err := image.Decode(body)
+	assert.NoError(t, err)
+	assert.Equal(t, 256, thumb.Bounds().Dx())
+	assert.Equal(t, 128, thumb.Bounds().Dy())
+}
+
+func TestAttachmentUpload_SkipsThumbnailForHugeImage(t *testing.T) {
+	service, _, store, txRepo := newAttachmentService(1 << 20)
+	txRepo.On("GetByID", 10, 1).Return(domain.Transaction{ID: 10, UserID: 1}, nil)
+
+	attachment, err := service.Upload(context.Background(), 1, 10, "bomba.png", bytes.NewReader(pngDeclaring(50000, 50000)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.False(t, attachment.HasThumbnail)
+	assert.Len(t, store.objects, 1)
+}
+
+func TestAttachmentUpload_RejectsUnsupportedType(t *testing.T) {
+	service, _, store, txRepo := newAttachmentService(1 << 20)
+	txRepo.On("GetByID", 10, 1).Return(domain.Transaction{ID: 10, UserID: 1}, nil)
+
+	_, err := service.Upload(context.Background(), 1, 10, "receipt.pdf", bytes.NewReader([]byte("#!/bin/sh\necho hi")))
+
+	assert.ErrorIs(t, err, services.ErrUnsupportedFileType)
+	assert.Empty(t, store.objects)
+}
+
+func TestAttachmentUpload_RejectsOversizedFile(t *testing.T) {
+	service, _, _, txRepo := newAttachmentService(100)
+	txRepo.On("GetByID", 10, 1).Return(domain.Transaction{ID: 10, UserID: 1}, nil)
+
+	_, err := service.Upload(context.Background(), 1, 10, "big.png", bytes.NewReader(pngImage(300, 300)))
+
+	assert.ErrorIs(t, err, services.ErrAttachmentTooLarge)
+}
+
+func TestAttachmentUpload_RequiresOwnedTransaction(t *testing.T) {
+	service, _, _, txRepo := newAttachmentService(1 << 20)
+	txRepo.On("GetByID", 10, 2).Return(domain.Transaction{}, sql.ErrNoRows)
+
+	_, err := service.Upload(context.Background(), 2, 10, "recibo.png", bytes.NewReader(pngImage(10, 10)))
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type AuditService struct {
+	repo ports.AuditRepository
+}
+
+func NewAuditService(repo ports.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Event builds the audit event for a change. It is passed to the repository
+// write that makes the change, which stores it in the same transaction.
+// before and after are snapshots of the entity (nil on create and delete
+// respectively); an entityID of 0 stands for the row the write creates.
+func (s *AuditService) Event(ctx context.Context, userID int, entityType string, entityID int, action string, before, after any) (domain.Event, error) {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	changes, err := diffSnapshots(beforeJSON, afterJSON)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	meta := domain.RequestMetaFrom(ctx)
+	return newEvent(domain.EventAuditRecorded, userID, entityType, entityID, domain.AuditEvent{
+		UserID:     userID,
+		ActorID:    userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		Changes:    changes,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+	}), nil
+}
+
+func (s *AuditService) History(userID int, entityType string, entityID int) ([]domain.AuditEvent, error) {
+	return s.repo.ListByEntity(userID, entityType, entityID)
+}
+
+// auditEvents is used by the other services to add the audit event to the
+// events of a write. Auditing is optional; a snapshot that cannot be taken
+// is logged and the change goes ahead without it.
+func auditEvents(audit ports.AuditService, ctx context.Context, userID int, entityType string, entityID int, action string, before, after any) []domain.Event {
+	if audit == nil {
+		return nil
+	}
+	event, err := audit.Event(ctx, userID, entityType, entityID, action, before, after)
+	if err != nil {
+		log.Printf("audit: could not record %s %s %d: %v", action, entityType, entityID, err)
+		return nil
+	}
+	return []domain.Event{event}
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func diffSnapshots(before, after json.RawMessage) (map[string]domain.FieldChange, error) {
+	var b, a map[string]any
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make(map[string]domain.FieldChange)
+	for key, bv := range b {
+		if av, ok := a[key]; !ok || !reflect.DeepEqual(av, bv) {
+			changes[key] = domain.FieldChange{Before: bv, After: a[key]}
+		}
+	}
+	for key, av := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = domain.FieldChange{Before: nil, After: av}
+		}
+	}
+	return changes, nil
+}
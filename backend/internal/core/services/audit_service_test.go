@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+// stubAuditRepository serves no history: the audit rows these tests check
+// travel with the events of the repository write.
+type stubAuditRepository struct{}
+
+func (stubAuditRepository) ListByEntity(userID int, entityType string, entityID int) ([]domain.AuditEvent, error) {
+	return nil, nil
+}
+
+// auditRecords decodes the audit events a service handed to its repository.
+func auditRecords(t *testing.T, events []domain.Event) []domain.AuditEvent {
+	var records []domain.AuditEvent
+	for _, e := range events {
+		if e.Type != domain.EventAuditRecorded {
+			continue
+		}
+		var record domain.AuditEvent
+		assert.NoError(t, json.Unmarshal(e.Payload, &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestUpdateTransaction_RecordsAuditDiff(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	service := services.NewTransactionService(repo)
+	service.SetAuditService(services.NewAuditService(stubAuditRepository{}))
+
+	date := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+	repo.On("GetByID", 3, 1).Return(domain.Transaction{
+		ID: 3, UserID: 1, Amount: 50, Category: "Desejos", Description: "Cinema", Date: date, Type: "expense",
+	}, nil)
+	repo.On("Update", mock.AnythingOfType("domain.Transaction")).Return(nil)
+
+	ctx := domain.WithRequestMeta(context.Background(), domain.RequestMeta{RequestID: "req-1", IP: "10.0.0.1"})
+	_, err := service.UpdateTransaction(ctx, 1, 3, 0, 80, "Desejos", "Cinema", "", date, "expense")
+	assert.NoError(t, err)
+
+	records := auditRecords(t, repo.events)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	record := records[0]
+	assert.Equal(t, domain.AuditEntityTransaction, record.EntityType)
+	assert.Equal(t, 3, record.EntityID)
+	assert.Equal(t, domain.AuditActionUpdate, record.Action)
+	assert.Equal(t, 1, record.ActorID)
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.Equal(t, "10.0.0.1", record.IP)
+	assert.Equal(t, domain.FieldChange{Before: 50.0, After: 80.0}, record.Changes["amount"])
+	assert.NotContains(t, record.Changes, "category")
+}
+
+func TestDeleteTransaction_RecordsBeforeSnapshot(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	service := services.NewTransactionService(repo)
+	service.SetAuditService(services.NewAuditService(stubAuditRepository{}))
+
+	repo.On("GetByID", 4, 1).Return(domain.Transaction{ID: 4, UserID: 1, Amount: 20, Category: "Transporte"}, nil)
+	repo.On("Delete", 4, 1).Return(nil)
+
+	assert.NoError(t, service.DeleteTransaction(context.Background(), 1, 4, 0))
+
+	records := auditRecords(t, repo.events)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	assert.Equal(t, domain.AuditActionDelete, records[0].Action)
+	assert.NotEmpty(t, records[0].Before)
+	assert.Empty(t, records[0].After)
+	assert.Equal(t, domain.FieldChange{Before: "Transporte", After: nil}, records[0].Changes["category"])
+}
+
+func TestCreateExpense_AuditsTheRowTheRepositoryCreates(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	service := services.NewTransactionService(repo)
+	service.SetAuditService(services.NewAuditService(stubAuditRepository{}))
+	repo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(5, nil)
+
+	_, err := service.CreateExpense(context.Background(), 1, 30, "Mercado", "Feira", "", time.Now())
+	assert.NoError(t, err)
+
+	records := auditRecords(t, repo.events)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	assert.Equal(t, domain.AuditActionCreate, records[0].Action)
+	assert.Equal(t, 0, records[0].EntityID)
+	assert.Equal(t, domain.FieldChange{Before: nil, After: 0.0}, records[0].Changes["id"])
+}
@@ -1,7 +1,7 @@
 package services
 
 import (
-	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,29 +9,53 @@ import (
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidRegistration = domain.Invalid("invalid_registration", "registration is invalid")
+	ErrEmailRegistered     = domain.Conflict("email_registered", "email already registered")
+	ErrInvalidCredentials  = domain.Unauthorized("invalid_credentials", "invalid credentials")
 )
 
 type AuthService struct {
-	userRepo  ports.UserRepository
-	jwtSecret []byte
+	userRepo       ports.UserRepository
+	jwtSecret      []byte
+	passwordPolicy validation.PasswordPolicy
 }
 
 func NewAuthService(userRepo ports.UserRepository, jwtSecret string) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: []byte(jwtSecret),
+		userRepo:       userRepo,
+		jwtSecret:      []byte(jwtSecret),
+		passwordPolicy: validation.DefaultPasswordPolicy,
 	}
 }
 
+// SetPasswordPolicy replaces the default policy for new passwords; existing
+// passwords still log in.
+func (s *AuthService) SetPasswordPolicy(policy validation.PasswordPolicy) {
+	s.passwordPolicy = policy
+}
+
 type Claims struct {
 	UserID int `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
 func (s *AuthService) Register(email, password string) (string, error) {
-	_, err := s.userRepo.GetByEmail(email)
+	email = normalizeEmail(email)
+	err := validation.Validate(ErrInvalidRegistration,
+		validation.Field("email", email, validation.Required, validation.MaxLength(maxNameLength), validation.Email),
+		validation.Field("password", password, validation.Password(s.passwordPolicy)),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.userRepo.GetByEmail(email)
 	if err == nil {
-		return "", errors.New("email already registered")
+		return "", ErrEmailRegistered
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -44,7 +68,8 @@ func (s *AuthService) Register(email, password string) (string, error) {
 		Password: string(hashedPassword),
 	}
 
-	id, err := s.userRepo.Save(user)
+	registered := newEvent(domain.EventUserRegistered, 0, domain.AggregateUser, 0, map[string]any{"id": 0, "email": email})
+	id, err := s.userRepo.Save(user, registered)
 	if err != nil {
 		return "", err
 	}
@@ -53,19 +78,25 @@ func (s *AuthService) Register(email, password string) (string, error) {
 }
 
 func (s *AuthService) Login(email, password string) (string, error) {
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(normalizeEmail(email))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return "", ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return "", ErrInvalidCredentials
 	}
 
 	return s.generateToken(user.ID)
 }
 
+// normalizeEmail is applied to every email the users type, so one typed
+// with stray spaces at sign-up still matches at login.
+func normalizeEmail(email string) string {
+	return strings.TrimSpace(email)
+}
+
 func (s *AuthService) generateToken(userID int) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
@@ -10,13 +10,14 @@ import (
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
 )
 
 type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Save(user domain.User) (int, error) {
+func (m *MockUserRepository) Save(user domain.User, events ...domain.Event) (int, error) {
 	args := m.Called(user)
 	return args.Int(0), args.Error(1)
 }
@@ -26,6 +27,16 @@ func (m *MockUserRepository) GetByEmail(email string) (domain.User, error) {
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByID(id int) (domain.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListIDs() ([]int, error) {
+	args := m.Called()
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func TestRegister_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	secret := "mysecret"
@@ -79,3 +90,39 @@ func TestLogin_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 }
+
+func TestLogin_TrimsEmailLikeRegister(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewAuthService(mockRepo, "mysecret")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	mockRepo.On("GetByEmail", "test@example.com").Return(domain.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword)}, nil)
+
+	token, err := service.Login("  test@example.com ", "password123")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestRegister_RejectsInvalidInput(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewAuthService(mockRepo, "mysecret")
+
+	_, err := service.Register("not-an-email", "short")
+
+	assert.ErrorIs(t, err, services.ErrInvalidRegistration)
+	e, _ := domain.AsError(err)
+	assert.Len(t, e.Fields, 2)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestRegister_UsesConfiguredPasswordPolicy(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewAuthService(mockRepo, "mysecret")
+	service.SetPasswordPolicy(validation.PasswordPolicy{MinLength: 12, MaxBytes: 72})
+
+	_, err := service.Register("test@example.com", "password123")
+
+	assert.ErrorIs(t, err, services.ErrInvalidRegistration)
+	mockRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidBill = domain.Invalid("invalid_bill", "bill needs a payee, a due date, a positive amount unless estimated and a recurrence of none, weekly, monthly or yearly")
+	ErrBillPaid    = domain.Conflict("bill_paid", "bill is already paid")
+)
+
+// defaultUpcomingDays is how far ahead Upcoming looks when no window is given.
+const defaultUpcomingDays = 7
+
+// billDueNoticeDays is how many days before its payable date a bill raises
+// a bill_due notification.
+const billDueNoticeDays = 3
+
+var billRecurrences = []string{domain.RecurrenceNone, domain.RecurrenceWeekly, domain.RecurrenceMonthly, domain.RecurrenceYearly}
+
+type BillService struct {
+	repo          ports.BillRepository
+	transactions  ports.TransactionService
+	audit         ports.AuditService
+	notifications ports.NotificationService
+	now           func() time.Time
+}
+
+func NewBillService(repo ports.BillRepository, transactions ports.TransactionService) *BillService {
+	return &BillService{repo: repo, transactions: transactions, now: time.Now}
+}
+
+// SetAuditService records every change made through this service.
+func (s *BillService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetNotificationService tells users when bills come due and go overdue;
+// RefreshStatuses raises the notifications.
+func (s *BillService) SetNotificationService(notifications ports.NotificationService) {
+	s.notifications = notifications
+}
+
+func (s *BillService) CreateBill(ctx context.Context, userID int, bill domain.Bill) (domain.Bill, error) {
+	bill.UserID = userID
+	if err := normalizeBill(&bill); err != nil {
+		return domain.Bill{}, err
+	}
+	bill.Status = domain.BillStatusOpen
+	bill = s.withStatus(bill)
+
+	bill.CreatedAt = s.now()
+	id, err := s.repo.Save(bill, auditEvents(s.audit, ctx, userID, domain.AuditEntityBill, 0, domain.AuditActionCreate, nil, bill)...)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+	bill.ID = id
+	return bill, nil
+}
+
+// UpdateBill changes an unpaid bill; paid bills are history.
+func (s *BillService) UpdateBill(ctx context.Context, userID, id int, bill domain.Bill) error {
+	before, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if before.Status == domain.BillStatusPaid {
+		return ErrBillPaid
+	}
+
+	bill.ID = id
+	bill.UserID = userID
+	if err := normalizeBill(&bill); err != nil {
+		return err
+	}
+	bill.Status = domain.BillStatusOpen
+	bill = s.withStatus(bill)
+
+	return s.repo.Update(bill, auditEvents(s.audit, ctx, userID, domain.AuditEntityBill, id, domain.AuditActionUpdate, before, bill)...)
+}
+
+// DeleteBill removes the bill; the expense of a paid bill stays.
+func (s *BillService) DeleteBill(ctx context.Context, userID, id int) error {
+	bill, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityBill, id, domain.AuditActionDelete, bill, nil)...)
+}
+
+// ListBills returns every bill, or those with the given status.
+func (s *BillService) ListBills(userID int, status string) ([]domain.Bill, error) {
+	bills, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.Bill{}
+	for _, b := range bills {
+		b = s.withStatus(b)
+		if status == "" || b.Status == status {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+// MarkPaid records the expense transaction for the bill and, for recurring
+// bills, opens the next occurrence. amount defaults to the bill amount and
+// date to today.
+func (s *BillService) MarkPaid(ctx context.Context, userID, id int, amount float64, date time.Time) (domain.Bill, error) {
+	bill, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+	if bill.Status == domain.BillStatusPaid {
+		return domain.Bill{}, ErrBillPaid
+	}
+	if amount == 0 {
+		amount = bill.Amount
+	}
+	if err := validation.Validate(ErrInvalidBill, validation.Field("amount", amount, validation.Positive)); err != nil {
+		return domain.Bill{}, err
+	}
+	if date.IsZero() {
+		date = s.now()
+	}
+
+	transaction, err := s.transactions.CreateExpense(ctx, userID, amount, bill.Category, bill.Payee, bill.Account, date)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	before := bill
+	paidAt := s.now()
+	bill.Status = domain.BillStatusPaid
+	bill.PaidAmount = amount
+	bill.PaidAt = &paidAt
+	bill.TransactionID = &transaction.ID
+	if err := s.repo.MarkPaid(bill, auditEvents(s.audit, ctx, userID, domain.AuditEntityBill, id, domain.AuditActionUpdate, before, bill)...); err != nil {
+		// Paid concurrently: drop the duplicate expense.
+		if rollbackErr := s.transactions.DeleteTransaction(ctx, userID, transaction.ID, 0); rollbackErr != nil {
+			log.Printf("bills: could not remove expense %d of bill %d: %v", transaction.ID, id, rollbackErr)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Bill{}, ErrBillPaid
+		}
+		return domain.Bill{}, err
+	}
+
+	if bill.Recurrence != domain.RecurrenceNone {
+		next := bill
+		next.ID = 0
+		next.DueDate = nextDueDate(bill)
+		next.Status = domain.BillStatusOpen
+		next.PaidAmount = 0
+		next.PaidAt = nil
+		next.TransactionID = nil
+		next = s.withStatus(next)
+		if _, err := s.repo.Save(next, auditEvents(s.audit, ctx, userID, domain.AuditEntityBill, 0, domain.AuditActionCreate, nil, next)...); err != nil {
+			return domain.Bill{}, err
+		}
+	}
+	return s.withStatus(bill), nil
+}
+
+// Upcoming lists overdue bills and open bills payable in the next days.
+func (s *BillService) Upcoming(userID, days int) (domain.UpcomingBills, error) {
+	if days <= 0 {
+		days = defaultUpcomingDays
+	}
+	bills, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return domain.UpcomingBills{}, err
+	}
+
+	today := dayOf(s.now())
+	result := domain.UpcomingBills{
+		From:     today,
+		To:       today.AddDate(0, 0, days),
+		Overdue:  []domain.Bill{},
+		Upcoming: []domain.Bill{},
+	}
+	for _, b := range bills {
+		b = s.withStatus(b)
+		switch {
+		case b.Status == domain.BillStatusOverdue:
+			result.Overdue = append(result.Overdue, b)
+		case b.Status == domain.BillStatusOpen && !b.PayableDate.After(result.To):
+			result.Upcoming = append(result.Upcoming, b)
+		default:
+			continue
+		}
+		result.Total += b.Amount
+	}
+	result.Total = roundMoney(result.Total)
+	return result, nil
+}
+
+// RefreshStatuses marks every bill whose payable date has passed as overdue
+// and notifies about overdue bills and those payable within
+// billDueNoticeDays.
+func (s *BillService) RefreshStatuses() error {
+	today := dayOf(s.now())
+	cutoff := today
+	if s.notifications != nil {
+		cutoff = today.AddDate(0, 0, billDueNoticeDays+1)
+	}
+	bills, err := s.repo.ListUnpaidDueBefore(cutoff)
+	if err != nil {
+		return err
+	}
+
+	var overdue []int
+	for _, b := range bills {
+		current := s.withStatus(b)
+		if b.Status != domain.BillStatusOverdue && current.Status == domain.BillStatusOverdue {
+			overdue = append(overdue, b.ID)
+		}
+		s.notifyBill(current)
+	}
+	if err := s.repo.SetStatus(overdue, domain.BillStatusOverdue); err != nil {
+		return err
+	}
+	if len(overdue) > 0 {
+		log.Printf("bills: %d bill(s) became overdue", len(overdue))
+	}
+	return nil
+}
+
+// notifyBill raises bill_overdue or, for bills payable soon, bill_due. The
+// dedup keys make each fire once per bill.
+func (s *BillService) notifyBill(bill domain.Bill) {
+	if s.notifications == nil {
+		return
+	}
+	notification := domain.Notification{
+		UserID:    bill.UserID,
+		EventType: domain.NotificationBillDue,
+		Title:     fmt.Sprintf("%s is due on %s", bill.Payee, bill.PayableDate.Format("02/01")),
+		Body:      fmt.Sprintf("R$ %.2f to %s.", bill.Amount, bill.Payee),
+		DedupKey:  fmt.Sprintf("bill_due:%d", bill.ID),
+	}
+	switch {
+	case bill.Status == domain.BillStatusOverdue:
+		notification.EventType = domain.NotificationBillOverdue
+		notification.Title = fmt.Sprintf("%s is overdue", bill.Payee)
+		notification.Body = fmt.Sprintf("R$ %.2f to %s was payable on %s.", bill.Amount, bill.Payee, bill.PayableDate.Format("02/01/2006"))
+		notification.DedupKey = fmt.Sprintf("bill_overdue:%d", bill.ID)
+	case bill.Status == domain.BillStatusOpen && !bill.PayableDate.After(dayOf(s.now()).AddDate(0, 0, billDueNoticeDays)):
+	default:
+		return
+	}
+	notify(s.notifications, context.Background(), notification)
+}
+
+// StartStatusJob runs RefreshStatuses on every tick until stop is called.
+func (s *BillService) StartStatusJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			if err := s.RefreshStatuses(); err != nil {
+				log.Printf("Bill status job failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// withStatus fills PayableDate and works out whether an unpaid bill is
+// open or overdue today.
+func (s *BillService) withStatus(bill domain.Bill) domain.Bill {
+	bill.PayableDate = nextBusinessDay(bill.DueDate)
+	if bill.Status != domain.BillStatusPaid {
+		bill.Status = domain.BillStatusOpen
+		if dayOf(s.now()).After(bill.PayableDate) {
+			bill.Status = domain.BillStatusOverdue
+		}
+	}
+	return bill
+}
+
+func normalizeBill(bill *domain.Bill) error {
+	bill.Payee = strings.TrimSpace(bill.Payee)
+	bill.Category = strings.TrimSpace(bill.Category)
+	bill.Account = strings.TrimSpace(bill.Account)
+	bill.Recurrence = strings.ToLower(strings.TrimSpace(bill.Recurrence))
+	if bill.Recurrence == "" {
+		bill.Recurrence = domain.RecurrenceNone
+	}
+	err := validation.Validate(ErrInvalidBill,
+		validation.Field("payee", bill.Payee, validation.Required),
+		validation.Field("due_date", bill.DueDate, validation.Present),
+		validation.Field("recurrence", bill.Recurrence, validation.OneOf(billRecurrences...)),
+		validation.Field("amount", bill.Amount, validation.Min(0.0), func(amount float64) string {
+			if amount == 0 && !bill.Estimated {
+				return "must be positive unless the bill is estimated"
+			}
+			return ""
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	bill.DueDate = dayOf(bill.DueDate)
+	bill.DueDay = bill.DueDate.Day()
+	return nil
+}
+
+// nextDueDate keeps monthly and yearly bills on their DueDay, clamped to
+// the end of shorter months.
+func nextDueDate(bill domain.Bill) time.Time {
+	month := monthIndex(int(bill.DueDate.Month()), bill.DueDate.Year())
+	switch bill.Recurrence {
+	case domain.RecurrenceWeekly:
+		return bill.DueDate.AddDate(0, 0, 7)
+	case domain.RecurrenceYearly:
+		return dateInMonth(month+12, bill.DueDay)
+	default:
+		return dateInMonth(month+1, bill.DueDay)
+	}
+}
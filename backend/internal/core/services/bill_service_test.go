@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type memoryBillRepository struct {
+	bills []domain.Bill
+}
+
+func (m *memoryBillRepository) Save(b domain.Bill, events ...domain.Event) (int, error) {
+	b.ID = len(m.bills) + 1
+	m.bills = append(m.bills, b)
+	return b.ID, nil
+}
+
+func (m *memoryBillRepository) find(id, userID int) int {
+	for i, b := range m.bills {
+		if b.ID == id && b.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *memoryBillRepository) Update(b domain.Bill, events ...domain.Event) error {
+	i := m.find(b.ID, b.UserID)
+	if i < 0 || m.bills[i].Status == domain.BillStatusPaid {
+		return sql.ErrNoRows
+	}
+	m.bills[i] = b
+	return nil
+}
+
+func (m *memoryBillRepository) Delete(id, userID int, events ...domain.Event) error {
+	i := m.find(id, userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	m.bills = append(m.bills[:i], m.bills[i+1:]...)
+	return nil
+}
+
+func (m *memoryBillRepository) GetByID(id, userID int) (domain.Bill, error) {
+	if i := m.find(id, userID); i >= 0 {
+		return m.bills[i], nil
+	}
+	return domain.Bill{}, sql.ErrNoRows
+}
+
+func (m *memoryBillRepository) ListByUserID(userID int) ([]domain.Bill, error) {
+	var result []domain.Bill
+	for _, b := range m.bills {
+		if b.UserID == userID {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryBillRepository) MarkPaid(b domain.Bill, events ...domain.Event) error {
+	i := m.find(b.ID, b.UserID)
+	if i < 0 || m.bills[i].Status == domain.BillStatusPaid {
+		return sql.ErrNoRows
+	}
+	m.bills[i] = b
+	return nil
+}
+
+func (m *memoryBillRepository) ListUnpaidDueBefore(cutoff time.Time) ([]domain.Bill, error) {
+	var result []domain.Bill
+	for _, b := range m.bills {
+		if b.Status != domain.BillStatusPaid && b.DueDate.Before(cutoff) {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryBillRepository) SetStatus(ids []int, status string) error {
+	for _, id := range ids {
+		for i := range m.bills {
+			if m.bills[i].ID == id {
+				m.bills[i].Status = status
+			}
+		}
+	}
+	return nil
+}
+
+// expenseRecorder is a TransactionService that only keeps created expenses.
+type expenseRecorder struct {
+	ports.TransactionService
+	expenses []domain.Transaction
+}
+
+func (r *expenseRecorder) CreateExpense(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error) {
+	t := domain.Transaction{ID: len(r.expenses) + 1, UserID: userID, Type: "expense", Amount: amount, Category: category, Description: description, Account: account, Date: date}
+	r.expenses = append(r.expenses, t)
+	return t, nil
+}
+
+func newTestBillService(today time.Time) (*BillService, *memoryBillRepository, *expenseRecorder) {
+	repo := &memoryBillRepository{}
+	transactions := &expenseRecorder{}
+	service := NewBillService(repo, transactions)
+	service.now = func() time.Time { return today.Add(9 * time.Hour) }
+	return service, repo, transactions
+}
+
+func TestCreateBill_PayableOnNextBusinessDay(t *testing.T) {
+	service, _, _ := newTestBillService(utcDate(2025, 3, 1))
+
+	// Due on Carnival Monday.
+	bill, err := service.CreateBill(context.Background(), 1, domain.Bill{Payee: "Enel", Amount: 180.5, DueDate: utcDate(2025, 3, 3)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RecurrenceNone, bill.Recurrence)
+	assert.Equal(t, domain.BillStatusOpen, bill.Status)
+	assert.Equal(t, utcDate(2025, 3, 5), bill.PayableDate)
+
+	_, err = service.CreateBill(context.Background(), 1, domain.Bill{Payee: "Sabesp", DueDate: utcDate(2025, 3, 3)})
+	assert.ErrorIs(t, err, ErrInvalidBill)
+	_, err = service.CreateBill(context.Background(), 1, domain.Bill{Payee: "Sabesp", Amount: 90, DueDate: utcDate(2025, 3, 3), Recurrence: "daily"})
+	assert.ErrorIs(t, err, ErrInvalidBill)
+
+	_, err = service.CreateBill(context.Background(), 1, domain.Bill{Recurrence: "daily"})
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "payee", Message: "is required"},
+		{Field: "due_date", Message: "is required"},
+		{Field: "recurrence", Message: "must be one of none, weekly, monthly, yearly"},
+		{Field: "amount", Message: "must be positive unless the bill is estimated"},
+	}, e.Fields)
+}
+
+func TestMarkPaid_CreatesExpenseAndNextOccurrence(t *testing.T) {
+	service, repo, transactions := newTestBillService(utcDate(2025, 1, 30))
+	bill, _ := service.CreateBill(context.Background(), 1, domain.Bill{
+		Payee: "Aluguel", Amount: 2500, Category: "Moradia", DueDate: utcDate(2025, 1, 31), Recurrence: "monthly",
+	})
+
+	paid, err := service.MarkPaid(context.Background(), 1, bill.ID, 0, time.Time{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BillStatusPaid, paid.Status)
+	assert.Equal(t, 2500.0, paid.PaidAmount)
+	assert.Len(t, transactions.expenses, 1)
+	assert.Equal(t, "Aluguel", transactions.expenses[0].Description)
+	assert.Equal(t, "Moradia", transactions.expenses[0].Category)
+	assert.Equal(t, transactions.expenses[0].ID, *paid.TransactionID)
+
+	assert.Len(t, repo.bills, 2)
+	assert.Equal(t, utcDate(2025, 2, 28), repo.bills[1].DueDate)
+	assert.Equal(t, domain.BillStatusOpen, repo.bills[1].Status)
+
+	// February was short; March goes back to the 31st.
+	_, err = service.MarkPaid(context.Background(), 1, repo.bills[1].ID, 2600, utcDate(2025, 2, 27))
+	assert.NoError(t, err)
+	assert.Equal(t, utcDate(2025, 3, 31), repo.bills[2].DueDate)
+
+	_, err = service.MarkPaid(context.Background(), 1, bill.ID, 0, time.Time{})
+	assert.ErrorIs(t, err, ErrBillPaid)
+	assert.Len(t, transactions.expenses, 2)
+}
+
+func TestUpcomingAndOverdueBills(t *testing.T) {
+	service, repo, _ := newTestBillService(utcDate(2025, 6, 1))
+	service.CreateBill(context.Background(), 1, domain.Bill{Payee: "Internet", Amount: 120, DueDate: utcDate(2025, 6, 5)})
+	service.CreateBill(context.Background(), 1, domain.Bill{Payee: "IPTU", Amount: 300, DueDate: utcDate(2025, 6, 30)})
+	// Due on Saturday, payable on Monday the 16th.
+	service.CreateBill(context.Background(), 1, domain.Bill{Payee: "Condomínio", Amount: 700, Estimated: true, DueDate: utcDate(2025, 6, 14)})
+
+	service.now = func() time.Time { return utcDate(2025, 6, 16) }
+	upcoming, err := service.Upcoming(1, 7)
+
+	assert.NoError(t, err)
+	assert.Len(t, upcoming.Overdue, 1)
+	assert.Equal(t, "Internet", upcoming.Overdue[0].Payee)
+	assert.Len(t, upcoming.Upcoming, 1)
+	assert.Equal(t, "Condomínio", upcoming.Upcoming[0].Payee)
+	assert.Equal(t, 820.0, upcoming.Total)
+
+	assert.NoError(t, service.RefreshStatuses())
+	assert.Equal(t, domain.BillStatusOverdue, repo.bills[0].Status)
+	assert.Equal(t, domain.BillStatusOpen, repo.bills[2].Status)
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var ErrInvalidBudget = domain.Invalid("invalid_budget", "budget needs a category, a positive amount and a valid month")
+
+// budgetThresholds are the percentages of a category's available amount
+// that raise an alert when an expense crosses them.
+var budgetThresholds = []int{80, 100}
+
+// maxRolloverMonths bounds how far back rollover balances are carried.
+const maxRolloverMonths = 120
+
+type BudgetService struct {
+	budgetRepo    ports.BudgetRepository
+	totals        ports.ReportRepository
+	audit         ports.AuditService
+	notifications ports.NotificationService
+}
+
+func NewBudgetService(budgetRepo ports.BudgetRepository, totals ports.ReportRepository) *BudgetService {
+	return &BudgetService{
+		budgetRepo: budgetRepo,
+		totals:     totals,
+	}
+}
+
+// SetAuditService records every change made through this service.
+func (s *BudgetService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetNotificationService tells users when an expense crosses a threshold.
+func (s *BudgetService) SetNotificationService(notifications ports.NotificationService) {
+	s.notifications = notifications
+}
+
+func (s *BudgetService) CreateBudget(ctx context.Context, userID int, budget domain.Budget) (domain.Budget, error) {
+	budget.UserID = userID
+	budget, err := normalizeBudget(budget)
+	if err != nil {
+		return domain.Budget{}, err
+	}
+
+	budget.CreatedAt = time.Now()
+	id, err := s.budgetRepo.Save(budget, auditEvents(s.audit, ctx, userID, domain.AuditEntityBudget, 0, domain.AuditActionCreate, nil, budget)...)
+	if err != nil {
+		return domain.Budget{}, err
+	}
+
+	budget.ID = id
+	return budget, nil
+}
+
+func (s *BudgetService) UpdateBudget(ctx context.Context, userID, id int, budget domain.Budget) error {
+	budget.ID = id
+	budget.UserID = userID
+	budget, err := normalizeBudget(budget)
+	if err != nil {
+		return err
+	}
+
+	before, err := s.budgetRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	budget.CreatedAt = before.CreatedAt
+	return s.budgetRepo.Update(budget, auditEvents(s.audit, ctx, userID, domain.AuditEntityBudget, id, domain.AuditActionUpdate, before, budget)...)
+}
+
+func (s *BudgetService) DeleteBudget(ctx context.Context, userID, id int) error {
+	before, err := s.budgetRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.budgetRepo.Delete(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityBudget, id, domain.AuditActionDelete, before, nil)...)
+}
+
+func (s *BudgetService) ListBudgets(userID int) ([]domain.Budget, error) {
+	return s.budgetRepo.ListByUserID(userID)
+}
+
+// Report compares every budget in force in the month with what was spent.
+func (s *BudgetService) Report(userID, month, year int) (domain.BudgetReport, error) {
+	budgets, err := s.budgetRepo.ListByUserID(userID)
+	if err != nil {
+		return domain.BudgetReport{}, err
+	}
+
+	spending := newSpendingCache(s.totals, userID)
+	report := domain.BudgetReport{Month: month, Year: year, Budgets: []domain.BudgetStatus{}}
+	for _, chain := range groupBudgets(budgets) {
+		status, ok, err := evaluateBudget(chain, spending, monthIndex(month, year))
+		if err != nil {
+			return domain.BudgetReport{}, err
+		}
+		if !ok {
+			continue
+		}
+		report.Budgets = append(report.Budgets, status)
+		report.TotalAvailable += status.Available
+		report.TotalSpent += status.Spent
+		report.TotalRemaining += status.Remaining
+	}
+	report.TotalAvailable = roundMoney(report.TotalAvailable)
+	report.TotalSpent = roundMoney(report.TotalSpent)
+	report.TotalRemaining = roundMoney(report.TotalRemaining)
+
+	alerts, err := s.budgetRepo.ListAlerts(userID, month, year)
+	if err != nil {
+		return domain.BudgetReport{}, err
+	}
+	if alerts == nil {
+		alerts = []domain.BudgetAlert{}
+	}
+	report.Alerts = alerts
+	return report, nil
+}
+
+// CheckExpense raises the alerts for thresholds that the (already saved)
+// expense pushed its category past. Each threshold fires once per month.
+func (s *BudgetService) CheckExpense(t domain.Transaction) ([]domain.BudgetAlert, error) {
+	return s.CheckExpenseChange(domain.Transaction{}, t)
+}
+
+// CheckExpenseChange is CheckExpense for an expense edited from before to t.
+// A delete only lowers spending, so it never crosses a threshold and is not
+// checked.
+func (s *BudgetService) CheckExpenseChange(before, t domain.Transaction) ([]domain.BudgetAlert, error) {
+	if t.Type != "expense" || t.Category == "" {
+		return nil, nil
+	}
+
+	budgets, err := s.budgetRepo.ListByUserID(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	chain := groupBudgets(budgets)[budgetKey(t.Category)]
+	if len(chain) == 0 {
+		return nil, nil
+	}
+
+	month, year := int(t.Date.Month()), t.Date.Year()
+	status, ok, err := evaluateBudget(chain, newSpendingCache(s.totals, t.UserID), monthIndex(month, year))
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	spentBefore := status.Spent - t.Amount
+	if before.Type == "expense" && budgetKey(before.Category) == budgetKey(t.Category) &&
+		monthIndex(int(before.Date.Month()), before.Date.Year()) == monthIndex(month, year) {
+		spentBefore += before.Amount
+	}
+	usedBefore := percentUsed(spentBefore, status.Available)
+	var alerts []domain.BudgetAlert
+	for _, threshold := range budgetThresholds {
+		if status.PercentUsed < float64(threshold) || usedBefore >= float64(threshold) {
+			continue
+		}
+		alert := domain.BudgetAlert{
+			UserID:    t.UserID,
+			Category:  status.Category,
+			Month:     month,
+			Year:      year,
+			Threshold: threshold,
+			Spent:     status.Spent,
+			Available: status.Available,
+			CreatedAt: time.Now(),
+		}
+		created, err := s.budgetRepo.SaveAlert(alert)
+		if err != nil {
+			return alerts, err
+		}
+		if created {
+			log.Printf("Budget alert: user %d reached %d%% of %s in %02d/%d", t.UserID, threshold, status.Category, month, year)
+			alerts = append(alerts, alert)
+			notify(s.notifications, context.Background(), budgetAlertNotification(alert))
+		}
+	}
+	return alerts, nil
+}
+
+func budgetAlertNotification(alert domain.BudgetAlert) domain.Notification {
+	return domain.Notification{
+		UserID:    alert.UserID,
+		EventType: domain.NotificationBudgetAlert,
+		Title:     fmt.Sprintf("%s: %d%% of the budget used", alert.Category, alert.Threshold),
+		Body:      fmt.Sprintf("You spent R$ %.2f of R$ %.2f in %s for %02d/%d.", alert.Spent, alert.Available, alert.Category, alert.Month, alert.Year),
+		DedupKey:  fmt.Sprintf("budget_alert:%s:%04d-%02d:%d", budgetKey(alert.Category), alert.Year, alert.Month, alert.Threshold),
+	}
+}
+
+func normalizeBudget(b domain.Budget) (domain.Budget, error) {
+	b.Category = strings.TrimSpace(b.Category)
+	if b.Month == 0 && b.Year == 0 {
+		now := time.Now()
+		b.Month, b.Year = int(now.Month()), now.Year()
+	}
+	err := validation.Validate(ErrInvalidBudget,
+		validation.Field("category", b.Category, validation.Required),
+		validation.Field("amount", b.Amount, validation.Positive),
+		validation.Field("month", b.Month, validation.Min(1), validation.Max(12)),
+		validation.Field("year", b.Year, validation.Min(1)),
+	)
+	if err != nil {
+		return domain.Budget{}, err
+	}
+	return b, nil
+}
+
+// groupBudgets returns each category's budgets ordered by start month.
+func groupBudgets(budgets []domain.Budget) map[string][]domain.Budget {
+	chains := make(map[string][]domain.Budget)
+	for _, b := range budgets {
+		key := budgetKey(b.Category)
+		chains[key] = append(chains[key], b)
+	}
+	for _, chain := range chains {
+		sort.Slice(chain, func(i, j int) bool {
+			return monthIndex(chain[i].Month, chain[i].Year) < monthIndex(chain[j].Month, chain[j].Year)
+		})
+	}
+	return chains
+}
+
+// evaluateBudget compares the budget in force at target with its spending.
+// A rollover budget carries what was budgeted minus what was spent since the
+// last month whose budget did not roll over (that month's own remainder
+// included), read in one aggregate query. ok is false when no budget is in
+// force.
+func evaluateBudget(chain []domain.Budget, spending *spendingCache, target int) (domain.BudgetStatus, bool, error) {
+	start := monthIndex(chain[0].Month, chain[0].Year)
+	if start > target {
+		return domain.BudgetStatus{}, false, nil
+	}
+	if start < target-maxRolloverMonths {
+		start = target - maxRolloverMonths
+	}
+
+	budget, _ := budgetInForce(chain, target)
+	var carry float64
+	if budget.Rollover && start < target {
+		from, budgeted := target, 0.0
+		for from > start {
+			from--
+			b, _ := budgetInForce(chain, from)
+			budgeted += b.Amount
+			if !b.Rollover {
+				break
+			}
+		}
+		spent, err := spending.spentBetween(budget.Category, from, target)
+		if err != nil {
+			return domain.BudgetStatus{}, false, err
+		}
+		carry = budgeted - spent
+	}
+
+	spent, err := spending.spent(budget.Category, target)
+	if err != nil {
+		return domain.BudgetStatus{}, false, err
+	}
+	available := budget.Amount + carry
+	return domain.BudgetStatus{
+		BudgetID:    budget.ID,
+		Category:    budget.Category,
+		Budgeted:    budget.Amount,
+		CarriedOver: roundMoney(carry),
+		Available:   roundMoney(available),
+		Spent:       roundMoney(spent),
+		Remaining:   roundMoney(available - spent),
+		PercentUsed: percentUsed(spent, available),
+		Rollover:    budget.Rollover,
+	}, true, nil
+}
+
+func budgetInForce(chain []domain.Budget, index int) (domain.Budget, bool) {
+	var current domain.Budget
+	found := false
+	for _, b := range chain {
+		if monthIndex(b.Month, b.Year) > index {
+			break
+		}
+		current, found = b, true
+	}
+	return current, found
+}
+
+func percentUsed(spent, available float64) float64 {
+	if available <= 0 {
+		if spent > 0 {
+			return 100
+		}
+		return 0
+	}
+	return math.Round(spent/available*1000) / 10
+}
+
+func budgetKey(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+func monthIndex(month, year int) int {
+	return year*12 + month - 1
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// spendingCache loads each month's expenses once per report.
+type spendingCache struct {
+	repo   ports.ReportRepository
+	userID int
+	months map[int]map[string]float64
+}
+
+func newSpendingCache(repo ports.ReportRepository, userID int) *spendingCache {
+	return &spendingCache{repo: repo, userID: userID, months: make(map[int]map[string]float64)}
+}
+
+func (c *spendingCache) spent(category string, index int) (float64, error) {
+	totals, ok := c.months[index]
+	if !ok {
+		categories, err := c.repo.CategoryTotals(c.userID, monthStart(index), monthStart(index+1))
+		if err != nil {
+			return 0, err
+		}
+		totals = make(map[string]float64)
+		for _, t := range categories {
+			totals[budgetKey(t.Category)] += t.Total
+		}
+		c.months[index] = totals
+	}
+	return totals[budgetKey(category)], nil
+}
+
+// spentBetween sums a category's expenses over the months [from, to).
+func (c *spendingCache) spentBetween(category string, from, to int) (float64, error) {
+	return c.repo.CategorySpent(c.userID, category, monthStart(from), monthStart(to))
+}
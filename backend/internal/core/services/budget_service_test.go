@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type memoryBudgetRepository struct {
+	budgets []domain.Budget
+	alerts  []domain.BudgetAlert
+}
+
+func (m *memoryBudgetRepository) Save(b domain.Budget, events ...domain.Event) (int, error) {
+	b.ID = len(m.budgets) + 1
+	m.budgets = append(m.budgets, b)
+	return b.ID, nil
+}
+
+func (m *memoryBudgetRepository) Update(b domain.Budget, events ...domain.Event) error { return nil }
+
+func (m *memoryBudgetRepository) Delete(id, userID int, events ...domain.Event) error { return nil }
+
+func (m *memoryBudgetRepository) GetByID(id, userID int) (domain.Budget, error) {
+	return m.budgets[id-1], nil
+}
+
+func (m *memoryBudgetRepository) ListByUserID(userID int) ([]domain.Budget, error) {
+	return m.budgets, nil
+}
+
+func (m *memoryBudgetRepository) SaveAlert(a domain.BudgetAlert) (bool, error) {
+	for _, existing := range m.alerts {
+		if existing.Category == a.Category && existing.Month == a.Month && existing.Year == a.Year && existing.Threshold == a.Threshold {
+			return false, nil
+		}
+	}
+	m.alerts = append(m.alerts, a)
+	return true, nil
+}
+
+func (m *memoryBudgetRepository) ListAlerts(userID, month, year int) ([]domain.BudgetAlert, error) {
+	return m.alerts, nil
+}
+
+// memoryTotals aggregates its transactions the way the rollup table does.
+type memoryTotals struct {
+	stubReportRepository
+	transactions []domain.Transaction
+}
+
+func (m *memoryTotals) CategoryTotals(userID int, from, to time.Time) ([]domain.CategoryTotal, error) {
+	var totals []domain.CategoryTotal
+	for _, t := range m.transactions {
+		if t.UserID != userID || t.Type != "expense" || t.Date.Before(from) || !t.Date.Before(to) {
+			continue
+		}
+		totals = append(totals, domain.CategoryTotal{Year: t.Date.Year(), Month: int(t.Date.Month()), Category: t.Category, Total: t.Amount})
+	}
+	return totals, nil
+}
+
+func (m *memoryTotals) CategorySpent(userID int, category string, from, to time.Time) (float64, error) {
+	totals, _ := m.CategoryTotals(userID, from, to)
+	var spent float64
+	for _, t := range totals {
+		if strings.EqualFold(t.Category, category) {
+			spent += t.Total
+		}
+	}
+	return spent, nil
+}
+
+func expense(category string, amount float64, month int) domain.Transaction {
+	return domain.Transaction{UserID: 1, Type: "expense", Category: category, Amount: amount, Date: time.Date(2025, time.Month(month), 10, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestBudgetReport_Rollover(t *testing.T) {
+	totals := &memoryTotals{transactions: []domain.Transaction{
+		expense("Mercado", 1000, 1), expense("Restaurantes", 450, 1),
+		expense("mercado", 1300, 2), expense("Restaurantes", 100, 2),
+		expense("Mercado", 5000, 3),
+	}}
+
+	service := services.NewBudgetService(&memoryBudgetRepository{}, totals)
+	_, err := service.CreateBudget(context.Background(), 1, domain.Budget{Category: "Mercado", Amount: 1200, Month: 1, Year: 2025, Rollover: true})
+	assert.NoError(t, err)
+	_, err = service.CreateBudget(context.Background(), 1, domain.Budget{Category: "Restaurantes", Amount: 400, Month: 1, Year: 2025})
+	assert.NoError(t, err)
+
+	report, err := service.Report(1, 2, 2025)
+	assert.NoError(t, err)
+	assert.Len(t, report.Budgets, 2)
+
+	byCategory := map[string]domain.BudgetStatus{}
+	for _, b := range report.Budgets {
+		byCategory[b.Category] = b
+	}
+
+	mercado := byCategory["Mercado"]
+	assert.Equal(t, 200.0, mercado.CarriedOver)
+	assert.Equal(t, 1400.0, mercado.Available)
+	assert.Equal(t, 1300.0, mercado.Spent)
+	assert.Equal(t, 100.0, mercado.Remaining)
+
+	restaurantes := byCategory["Restaurantes"]
+	assert.Equal(t, 0.0, restaurantes.CarriedOver)
+	assert.Equal(t, 300.0, restaurantes.Remaining)
+	assert.Equal(t, 25.0, restaurantes.PercentUsed)
+
+	// January's 200 left over and February's 100 overspent carry into March.
+	report, err = service.Report(1, 3, 2025)
+	assert.NoError(t, err)
+	for _, b := range report.Budgets {
+		if b.Category == "Mercado" {
+			assert.Equal(t, 100.0, b.CarriedOver)
+			assert.Equal(t, 1300.0, b.Available)
+		}
+	}
+}
+
+func TestBudgetReport_BeforeFirstBudget(t *testing.T) {
+	service := services.NewBudgetService(&memoryBudgetRepository{}, &memoryTotals{})
+	service.CreateBudget(context.Background(), 1, domain.Budget{Category: "Mercado", Amount: 1200, Month: 3, Year: 2025})
+
+	report, err := service.Report(1, 2, 2025)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Budgets)
+}
+
+func TestCreateBudget_Validation(t *testing.T) {
+	service := services.NewBudgetService(&memoryBudgetRepository{}, &memoryTotals{})
+
+	_, err := service.CreateBudget(context.Background(), 1, domain.Budget{Category: "Mercado", Amount: 0})
+	assert.ErrorIs(t, err, services.ErrInvalidBudget)
+
+	_, err = service.CreateBudget(context.Background(), 1, domain.Budget{Category: "Mercado", Amount: 100, Month: 13, Year: 2025})
+	assert.ErrorIs(t, err, services.ErrInvalidBudget)
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{{Field: "month", Message: "must be at most 12"}}, e.Fields)
+}
+
+func TestCreateExpense_RaisesBudgetAlertsOnce(t *testing.T) {
+	budgetRepo := &memoryBudgetRepository{}
+	txRepo := new(MockTransactionRepository)
+	totals := &memoryTotals{}
+	budgets := services.NewBudgetService(budgetRepo, totals)
+	budgets.CreateBudget(context.Background(), 1, domain.Budget{Category: "Restaurantes", Amount: 400, Month: 4, Year: 2025})
+
+	service := services.NewTransactionService(txRepo)
+	service.SetBudgetService(budgets)
+	txRepo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(1, nil).Run(func(args mock.Arguments) {
+		totals.transactions = append(totals.transactions, args.Get(0).(domain.Transaction))
+	})
+
+	date := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	_, err := service.CreateExpense(context.Background(), 1, 330, "Restaurantes", "Jantar", "", date)
+	assert.NoError(t, err)
+	assert.Len(t, budgetRepo.alerts, 1)
+	assert.Equal(t, 80, budgetRepo.alerts[0].Threshold)
+
+	_, err = service.CreateExpense(context.Background(), 1, 20, "Restaurantes", "Café", "", date)
+	assert.NoError(t, err)
+	assert.Len(t, budgetRepo.alerts, 1)
+
+	_, err = service.CreateExpense(context.Background(), 1, 90, "Restaurantes", "Pizza", "", date)
+	assert.NoError(t, err)
+	assert.Len(t, budgetRepo.alerts, 2)
+	assert.Equal(t, 100, budgetRepo.alerts[1].Threshold)
+}
+
+func TestUpdateExpense_RaisesBudgetAlerts(t *testing.T) {
+	budgetRepo := &memoryBudgetRepository{}
+	txRepo := new(MockTransactionRepository)
+	date := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	existing := domain.Transaction{ID: 1, UserID: 1, Type: "expense", Category: "Restaurantes", Amount: 100, Date: date, Version: 1}
+	totals := &memoryTotals{transactions: []domain.Transaction{existing}}
+	budgets := services.NewBudgetService(budgetRepo, totals)
+	budgets.CreateBudget(context.Background(), 1, domain.Budget{Category: "Restaurantes", Amount: 400, Month: 4, Year: 2025})
+
+	service := services.NewTransactionService(txRepo)
+	service.SetBudgetService(budgets)
+	txRepo.On("GetByID", 1, 1).Return(existing, nil)
+	txRepo.On("Update", mock.AnythingOfType("domain.Transaction")).Return(nil).Run(func(args mock.Arguments) {
+		totals.transactions = []domain.Transaction{args.Get(0).(domain.Transaction)}
+	})
+
+	_, err := service.UpdateTransaction(context.Background(), 1, 1, 1, 350, "Restaurantes", "Jantar", "", date, "expense")
+	assert.NoError(t, err)
+	assert.Len(t, budgetRepo.alerts, 1)
+	assert.Equal(t, 80, budgetRepo.alerts[0].Threshold)
+}
@@ -0,0 +1,61 @@
+package services
+
+import "time"
+
+// Brazilian bank holidays: the national holidays plus Carnival and Corpus
+// Christi, when banks close and boletos roll over to the next business day.
+// Black Consciousness Day (Nov 20) is national since 2024.
+func isBankHoliday(date time.Time) bool {
+	year, month, day := date.Date()
+	switch {
+	case month == time.January && day == 1,
+		month == time.April && day == 21,
+		month == time.May && day == 1,
+		month == time.September && day == 7,
+		month == time.October && day == 12,
+		month == time.November && day == 2,
+		month == time.November && day == 15,
+		month == time.November && day == 20 && year >= 2024,
+		month == time.December && day == 25:
+		return true
+	}
+
+	easter := easterSunday(year)
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []int{-48, -47, -2, 60} { // Carnival Mon and Tue, Good Friday, Corpus Christi
+		if d.Equal(easter.AddDate(0, 0, offset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// easterSunday uses the anonymous Gregorian algorithm (Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func isBusinessDay(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !isBankHoliday(date)
+}
+
+// nextBusinessDay returns date itself when it is a business day.
+func nextBusinessDay(date time.Time) time.Time {
+	date = dayOf(date)
+	for !isBusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasterSunday(t *testing.T) {
+	assert.Equal(t, utcDate(2024, 3, 31), easterSunday(2024))
+	assert.Equal(t, utcDate(2025, 4, 20), easterSunday(2025))
+	assert.Equal(t, utcDate(2026, 4, 5), easterSunday(2026))
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	// Weekday, untouched.
+	assert.Equal(t, utcDate(2025, 6, 10), nextBusinessDay(utcDate(2025, 6, 10)))
+	// Saturday rolls to Monday.
+	assert.Equal(t, utcDate(2025, 6, 16), nextBusinessDay(utcDate(2025, 6, 14)))
+	// Carnival Monday and Tuesday, then Ash Wednesday.
+	assert.Equal(t, utcDate(2025, 3, 5), nextBusinessDay(utcDate(2025, 3, 3)))
+	// Good Friday into the weekend; Tiradentes on Monday.
+	assert.Equal(t, utcDate(2025, 4, 22), nextBusinessDay(utcDate(2025, 4, 18)))
+	// Corpus Christi.
+	assert.Equal(t, utcDate(2025, 6, 20), nextBusinessDay(utcDate(2025, 6, 19)))
+	// Black Consciousness Day only from 2024.
+	assert.True(t, isBankHoliday(utcDate(2024, 11, 20)))
+	assert.False(t, isBankHoliday(utcDate(2023, 11, 20)))
+	assert.Equal(t, time.Friday, nextBusinessDay(utcDate(2025, 12, 25)).Weekday())
+}
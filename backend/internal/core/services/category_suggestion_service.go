@@ -0,0 +1,286 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+const maxCategorySuggestions = 3
+
+// maxCachedModels bounds the models kept in memory; the least recently used
+// one is dropped and retrained from history on its next use.
+const maxCachedModels = 1000
+
+var descriptionStopwords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true,
+	"em": true, "no": true, "na": true, "para": true, "com": true, "por": true,
+	"o": true, "a": true, "os": true, "as": true, "um": true, "uma": true,
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "ê", "e", "è", "e", "ë", "e",
+	"í", "i", "î", "i", "ì", "i", "ï", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ò", "o", "ö", "o",
+	"ú", "u", "û", "u", "ù", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// CategorySuggestionService is a per-user multinomial naive Bayes classifier
+// over description tokens plus a coarse amount bucket. Models live in memory,
+// are trained lazily from the user's history and kept current incrementally.
+type CategorySuggestionService struct {
+	repo ports.TransactionRepository
+
+	mu     sync.Mutex // guards models and clock, never held while training
+	models map[int]*modelEntry
+	clock  uint64
+}
+
+// modelEntry holds one user's model. Its lock serializes training and use of
+// that model without blocking other users.
+type modelEntry struct {
+	mu    sync.Mutex
+	model *categoryModel // nil until trained
+	used  uint64
+}
+
+type categoryModel struct {
+	docs        map[string]int
+	tokenCounts map[string]map[string]int
+	tokenTotals map[string]int
+	vocabulary  map[string]int
+	totalDocs   int
+}
+
+func NewCategorySuggestionService(repo ports.TransactionRepository) *CategorySuggestionService {
+	return &CategorySuggestionService{
+		repo:   repo,
+		models: make(map[int]*modelEntry),
+	}
+}
+
+func (s *CategorySuggestionService) Suggest(userID int, description string, amount float64) ([]domain.CategorySuggestion, error) {
+	entry := s.entry(userID, true)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	model, err := s.train(userID, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := model.classify(features(description, amount))
+	if len(suggestions) > maxCategorySuggestions {
+		suggestions = suggestions[:maxCategorySuggestions]
+	}
+	return suggestions, nil
+}
+
+func (s *CategorySuggestionService) Learn(t domain.Transaction) {
+	s.update(t, 1)
+}
+
+func (s *CategorySuggestionService) Forget(t domain.Transaction) {
+	s.update(t, -1)
+}
+
+// update changes a trained model. Users without one pick the row up on their
+// first training.
+func (s *CategorySuggestionService) update(t domain.Transaction, delta int) {
+	entry := s.entry(t.UserID, false)
+	if entry == nil {
+		return
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.model != nil {
+		entry.model.add(t, delta)
+	}
+}
+
+func (s *CategorySuggestionService) Reset(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.models, userID)
+}
+
+// entry returns the user's entry, creating it when create is set, and marks
+// it as used. A new entry beyond maxCachedModels evicts the least recently
+// used one.
+func (s *CategorySuggestionService) entry(userID int, create bool) *modelEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.models[userID]
+	if !ok {
+		if !create {
+			return nil
+		}
+		if len(s.models) >= maxCachedModels {
+			s.evictOldest()
+		}
+		entry = &modelEntry{}
+		s.models[userID] = entry
+	}
+	s.clock++
+	entry.used = s.clock
+	return entry
+}
+
+func (s *CategorySuggestionService) evictOldest() {
+	oldest, oldestUsed := 0, uint64(math.MaxUint64)
+	for userID, entry := range s.models {
+		if entry.used < oldestUsed {
+			oldest, oldestUsed = userID, entry.used
+		}
+	}
+	delete(s.models, oldest)
+}
+
+// train loads the user's history into the entry on first use. The caller
+// holds entry.mu.
+func (s *CategorySuggestionService) train(userID int, entry *modelEntry) (*categoryModel, error) {
+	if entry.model != nil {
+		return entry.model, nil
+	}
+
+	transactions, err := s.repo.ListAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	model := newCategoryModel()
+	for _, t := range transactions {
+		model.add(t, 1)
+	}
+	entry.model = model
+	return model, nil
+}
+
+func newCategoryModel() *categoryModel {
+	return &categoryModel{
+		docs:        make(map[string]int),
+		tokenCounts: make(map[string]map[string]int),
+		tokenTotals: make(map[string]int),
+		vocabulary:  make(map[string]int),
+	}
+}
+
+// add trains (delta 1) or untrains (delta -1) a single transaction.
+func (m *categoryModel) add(t domain.Transaction, delta int) {
+	if t.Category == "" {
+		return
+	}
+
+	m.docs[t.Category] += delta
+	m.totalDocs += delta
+	if m.tokenCounts[t.Category] == nil {
+		m.tokenCounts[t.Category] = make(map[string]int)
+	}
+
+	for _, token := range features(t.Description, t.Amount) {
+		m.tokenCounts[t.Category][token] += delta
+		m.tokenTotals[t.Category] += delta
+		m.vocabulary[token] += delta
+		if m.vocabulary[token] <= 0 {
+			delete(m.vocabulary, token)
+		}
+	}
+
+	if m.docs[t.Category] <= 0 {
+		delete(m.docs, t.Category)
+		delete(m.tokenCounts, t.Category)
+		delete(m.tokenTotals, t.Category)
+	}
+}
+
+func (m *categoryModel) classify(tokens []string) []domain.CategorySuggestion {
+	if m.totalDocs <= 0 {
+		return []domain.CategorySuggestion{}
+	}
+
+	vocab := float64(len(m.vocabulary) + 1)
+	scores := make(map[string]float64, len(m.docs))
+	best := math.Inf(-1)
+	for category, docs := range m.docs {
+		score := math.Log(float64(docs) / float64(m.totalDocs))
+		denominator := float64(m.tokenTotals[category]) + vocab
+		for _, token := range tokens {
+			score += math.Log((float64(m.tokenCounts[category][token]) + 1) / denominator)
+		}
+		scores[category] = score
+		best = math.Max(best, score)
+	}
+
+	// Softmax over log scores turns them into comparable confidences.
+	var sum float64
+	for category, score := range scores {
+		scores[category] = math.Exp(score - best)
+		sum += scores[category]
+	}
+
+	suggestions := make([]domain.CategorySuggestion, 0, len(scores))
+	for category, score := range scores {
+		suggestions = append(suggestions, domain.CategorySuggestion{
+			Category:   category,
+			Confidence: math.Round(score/sum*1000) / 1000,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence == suggestions[j].Confidence {
+			return suggestions[i].Category < suggestions[j].Category
+		}
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	return suggestions
+}
+
+func features(description string, amount float64) []string {
+	tokens := tokenizeDescription(description)
+	if amount > 0 {
+		tokens = append(tokens, amountBucket(amount))
+	}
+	return tokens
+}
+
+func tokenizeDescription(description string) []string {
+	normalized := accentReplacer.Replace(strings.ToLower(description))
+	fields := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len(field) < 2 || descriptionStopwords[field] || isNumeric(field) {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// amountBucket groups values on a log scale (R$1-9, 10-99, 100-999...).
+func amountBucket(amount float64) string {
+	if amount < 1 {
+		return "amount:0"
+	}
+	return "amount:" + strings.Repeat("9", int(math.Log10(amount))+1)
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+func suggestionHistory() []domain.Transaction {
+	return []domain.Transaction{
+		{UserID: 1, Description: "Supermercado Pão de Açúcar", Amount: 420, Category: "Mercado"},
+		{UserID: 1, Description: "Carrefour supermercado", Amount: 310, Category: "Mercado"},
+		{UserID: 1, Description: "iFood restaurante japonês", Amount: 85, Category: "Restaurantes"},
+		{UserID: 1, Description: "Restaurante Outback", Amount: 160, Category: "Restaurantes"},
+		{UserID: 1, Description: "Uber viagem", Amount: 23, Category: "Transporte"},
+	}
+}
+
+func TestSuggestCategory_FromHistory(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	repo.On("ListAllByUserID", 1).Return(suggestionHistory(), nil)
+	service := services.NewCategorySuggestionService(repo)
+
+	suggestions, err := service.Suggest(1, "SUPERMERCADO EXTRA", 280)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, suggestions)
+	assert.Equal(t, "Mercado", suggestions[0].Category)
+	assert.Greater(t, suggestions[0].Confidence, 0.5)
+	assert.LessOrEqual(t, len(suggestions), 3)
+}
+
+func TestSuggestCategory_EmptyHistory(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	repo.On("ListAllByUserID", 2).Return([]domain.Transaction(nil), nil)
+	service := services.NewCategorySuggestionService(repo)
+
+	suggestions, err := service.Suggest(2, "qualquer coisa", 10)
+
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestSuggestCategory_LearnsIncrementally(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	repo.On("ListAllByUserID", 1).Return(suggestionHistory(), nil).Once()
+	repo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(10, nil)
+
+	suggestions := services.NewCategorySuggestionService(repo)
+	service := services.NewTransactionService(repo)
+	service.SetCategorySuggestionService(suggestions)
+
+	before, _ := suggestions.Suggest(1, "Netflix assinatura", 55)
+	assert.NotEqual(t, "Streaming", before[0].Category)
+
+	service.CreateExpense(context.Background(), 1, 55, "Streaming", "Netflix assinatura", "", time.Now())
+	service.CreateExpense(context.Background(), 1, 39, "Streaming", "Spotify assinatura", "", time.Now())
+
+	after, err := suggestions.Suggest(1, "netflix", 55)
+	assert.NoError(t, err)
+	assert.Equal(t, "Streaming", after[0].Category)
+	repo.AssertNumberOfCalls(t, "ListAllByUserID", 1)
+}
+
+func TestSuggestCategory_EvictsLeastRecentlyUsedModel(t *testing.T) {
+	repo := new(MockTransactionRepository)
+	repo.On("ListAllByUserID", mock.Anything).Return([]domain.Transaction(nil), nil)
+	service := services.NewCategorySuggestionService(repo)
+
+	// 1000 models fit; the 1001st user pushes out user 1, the oldest.
+	for userID := 1; userID <= 1001; userID++ {
+		_, err := service.Suggest(userID, "mercado", 10)
+		assert.NoError(t, err)
+	}
+	service.Suggest(1001, "mercado", 10)
+	repo.AssertNumberOfCalls(t, "ListAllByUserID", 1001)
+
+	service.Suggest(1, "mercado", 10)
+	repo.AssertNumberOfCalls(t, "ListAllByUserID", 1002)
+}
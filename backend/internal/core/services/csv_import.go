@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newImportReader reads CSV as exported by Brazilian banks, brokers and the
+// Banco Central: the separator is a semicolon when the first line has one,
+// a comma otherwise, and rows may have any number of fields.
+func newImportReader(content string) *csv.Reader {
+	reader := csv.NewReader(strings.NewReader(content))
+	if firstLine, _, _ := strings.Cut(content, "\n"); strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// parseImportDate accepts YYYY-MM-DD and DD/MM/YYYY.
+func parseImportDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// parseDecimal accepts "1605.10" and the Brazilian "1.605,10".
+func parseDecimal(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
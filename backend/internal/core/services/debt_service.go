@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidDebt        = domain.Invalid("invalid_debt", "debt needs a name, a positive principal and installments, a non-negative rate and sac or price")
+	ErrInvalidDebtPayment = domain.Invalid("invalid_debt_payment", "payment needs an expense transaction and an installment of the debt")
+	ErrInvalidExtra       = domain.Invalid("invalid_extra", "extra monthly amount must not be negative")
+)
+
+// maxPayoffMonths stops the planner on plans that would never end.
+const maxPayoffMonths = 1200
+
+type DebtService struct {
+	repo            ports.DebtRepository
+	transactionRepo ports.TransactionRepository
+	audit           ports.AuditService
+	now             func() time.Time
+}
+
+func NewDebtService(repo ports.DebtRepository, transactionRepo ports.TransactionRepository) *DebtService {
+	return &DebtService{repo: repo, transactionRepo: transactionRepo, now: time.Now}
+}
+
+// SetAuditService records every change made through this service.
+func (s *DebtService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// CreateDebt defaults the first due date to a month from today.
+func (s *DebtService) CreateDebt(ctx context.Context, userID int, debt domain.Debt) (domain.Debt, error) {
+	debt.UserID = userID
+	debt.Name = strings.TrimSpace(debt.Name)
+	debt.AmortizationSystem = strings.ToLower(strings.TrimSpace(debt.AmortizationSystem))
+	err := validation.Validate(ErrInvalidDebt,
+		validation.Field("name", debt.Name, validation.Required),
+		validation.Field("principal", debt.Principal, validation.Positive),
+		validation.Field("interest_rate", debt.InterestRate, validation.Min(0.0)),
+		validation.Field("installments", debt.Installments, validation.Positive),
+		validation.Field("amortization_system", debt.AmortizationSystem, validation.OneOf(domain.AmortizationSAC, domain.AmortizationPrice)),
+	)
+	if err != nil {
+		return domain.Debt{}, err
+	}
+	if debt.FirstDueDate.IsZero() {
+		debt.FirstDueDate = addMonthsClamped(dayOf(s.now()), 1)
+	}
+	debt.FirstDueDate = dayOf(debt.FirstDueDate)
+
+	debt.CreatedAt = s.now()
+	id, err := s.repo.SaveDebt(debt, auditEvents(s.audit, ctx, userID, domain.AuditEntityDebt, 0, domain.AuditActionCreate, nil, debt)...)
+	if err != nil {
+		return domain.Debt{}, err
+	}
+	debt.ID = id
+	return debt, nil
+}
+
+func (s *DebtService) ListDebts(userID int) ([]domain.DebtSchedule, error) {
+	debts, err := s.repo.ListDebts(userID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.repo.ListPayments(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]domain.DebtSchedule, 0, len(debts))
+	for _, d := range debts {
+		schedules = append(schedules, buildSchedule(d, payments))
+	}
+	return schedules, nil
+}
+
+// DeleteDebt removes the debt and its payment links; the transactions stay.
+func (s *DebtService) DeleteDebt(ctx context.Context, userID, id int) error {
+	debt, err := s.repo.GetDebt(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteDebt(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityDebt, id, domain.AuditActionDelete, debt, nil)...)
+}
+
+func (s *DebtService) Schedule(userID, id int) (domain.DebtSchedule, error) {
+	debt, err := s.repo.GetDebt(id, userID)
+	if err != nil {
+		return domain.DebtSchedule{}, err
+	}
+	payments, err := s.repo.ListPayments(userID)
+	if err != nil {
+		return domain.DebtSchedule{}, err
+	}
+	return buildSchedule(debt, payments), nil
+}
+
+// RecordPayment links an expense transaction to an installment. An
+// installment of 0 means the first one still unpaid.
+func (s *DebtService) RecordPayment(ctx context.Context, userID, debtID, installment, transactionID int) (domain.DebtPayment, error) {
+	schedule, err := s.Schedule(userID, debtID)
+	if err != nil {
+		return domain.DebtPayment{}, err
+	}
+	if installment == 0 {
+		for _, i := range schedule.Installments {
+			if i.Paid == nil {
+				installment = i.Number
+				break
+			}
+		}
+	}
+	err = validation.Validate(ErrInvalidDebtPayment,
+		validation.Field("installment", installment, validation.Min(1), validation.Max(schedule.Debt.Installments)),
+	)
+	if err != nil {
+		return domain.DebtPayment{}, err
+	}
+
+	transaction, err := s.transactionRepo.GetByID(transactionID, userID)
+	if err != nil {
+		return domain.DebtPayment{}, err
+	}
+	err = validation.Validate(ErrInvalidDebtPayment,
+		validation.Field("transaction_id", transaction.Type, func(typ string) string {
+			if typ != "expense" {
+				return "must be an expense"
+			}
+			return ""
+		}),
+	)
+	if err != nil {
+		return domain.DebtPayment{}, err
+	}
+
+	payment := domain.DebtPayment{
+		DebtID:        debtID,
+		UserID:        userID,
+		Installment:   installment,
+		TransactionID: transactionID,
+		Amount:        transaction.Amount,
+		Date:          transaction.Date,
+	}
+	payment.CreatedAt = s.now()
+	id, err := s.repo.SavePayment(payment, auditEvents(s.audit, ctx, userID, domain.AuditEntityDebt, debtID, domain.AuditActionCreate, nil, payment)...)
+	if err != nil {
+		return domain.DebtPayment{}, err
+	}
+	payment.ID = id
+	return payment, nil
+}
+
+func (s *DebtService) DeletePayment(ctx context.Context, userID, debtID, installment int) error {
+	deleted := domain.DebtPayment{DebtID: debtID, UserID: userID, Installment: installment}
+	return s.repo.DeletePayment(debtID, userID, installment, auditEvents(s.audit, ctx, userID, domain.AuditEntityDebt, debtID, domain.AuditActionDelete, deleted, nil)...)
+}
+
+// Plan simulates paying off every open debt from next month on. The
+// recommendation is the strategy that pays less interest; ties go to
+// snowball, which clears the first debt sooner.
+func (s *DebtService) Plan(userID int, extraMonthly float64) (domain.PayoffComparison, error) {
+	if err := validation.Validate(ErrInvalidExtra, validation.Field("extra", extraMonthly, validation.Min(0.0))); err != nil {
+		return domain.PayoffComparison{}, err
+	}
+	schedules, err := s.ListDebts(userID)
+	if err != nil {
+		return domain.PayoffComparison{}, err
+	}
+
+	var open []payoffDebt
+	for _, schedule := range schedules {
+		if schedule.Outstanding > 0 {
+			open = append(open, newPayoffDebt(schedule))
+		}
+	}
+
+	budget := extraMonthly
+	for _, d := range open {
+		budget += d.due(d.balance)
+	}
+
+	start := dayOf(s.now())
+	snowball := append([]payoffDebt(nil), open...)
+	sort.SliceStable(snowball, func(i, j int) bool { return snowball[i].balance < snowball[j].balance })
+	avalanche := append([]payoffDebt(nil), open...)
+	sort.SliceStable(avalanche, func(i, j int) bool { return avalanche[i].rate > avalanche[j].rate })
+
+	comparison := domain.PayoffComparison{
+		ExtraMonthly:  extraMonthly,
+		MonthlyBudget: roundMoney(budget),
+		Minimum:       simulatePayoff(domain.PayoffStrategyMinimum, open, 0, start),
+		Snowball:      simulatePayoff(domain.PayoffStrategySnowball, snowball, budget, start),
+		Avalanche:     simulatePayoff(domain.PayoffStrategyAvalanche, avalanche, budget, start),
+	}
+	comparison.Recommended = domain.PayoffStrategySnowball
+	if comparison.Avalanche.TotalInterest < comparison.Snowball.TotalInterest {
+		comparison.Recommended = domain.PayoffStrategyAvalanche
+	}
+	return comparison, nil
+}
+
+// buildSchedule lays out every installment and marks the paid ones. Values
+// are kept in cents row by row so the last installment settles the balance
+// exactly.
+func buildSchedule(debt domain.Debt, payments []domain.DebtPayment) domain.DebtSchedule {
+	paid := make(map[int]domain.DebtPayment)
+	for _, p := range payments {
+		if p.DebtID == debt.ID {
+			paid[p.Installment] = p
+		}
+	}
+
+	rate := debt.InterestRate / 100
+	fixedPayment := priceInstallment(debt.Principal, rate, debt.Installments)
+	amortization := roundMoney(debt.Principal / float64(debt.Installments))
+
+	schedule := domain.DebtSchedule{Debt: debt, Installments: make([]domain.DebtInstallment, 0, debt.Installments)}
+	balance := debt.Principal
+	outstanding := debt.Principal
+	for n := 1; n <= debt.Installments; n++ {
+		interest := roundMoney(balance * rate)
+		principalPaid := amortization
+		if debt.AmortizationSystem == domain.AmortizationPrice {
+			principalPaid = roundMoney(fixedPayment - interest)
+		}
+		if n == debt.Installments || principalPaid > balance {
+			principalPaid = balance
+		}
+		balance = roundMoney(balance - principalPaid)
+
+		installment := domain.DebtInstallment{
+			Number:       n,
+			DueDate:      addMonthsClamped(debt.FirstDueDate, n-1),
+			Payment:      roundMoney(principalPaid + interest),
+			Interest:     interest,
+			Amortization: principalPaid,
+			Balance:      balance,
+		}
+		if p, ok := paid[n]; ok {
+			installment.Paid = &p
+			schedule.PaidInstallments++
+			outstanding -= principalPaid
+		}
+		schedule.Installments = append(schedule.Installments, installment)
+		schedule.TotalInterest += interest
+		schedule.TotalPayments += installment.Payment
+	}
+
+	schedule.TotalInterest = roundMoney(schedule.TotalInterest)
+	schedule.TotalPayments = roundMoney(schedule.TotalPayments)
+	schedule.Outstanding = roundMoney(math.Max(outstanding, 0))
+	return schedule
+}
+
+// priceInstallment is the fixed Tabela Price payment.
+func priceInstallment(principal, rate float64, installments int) float64 {
+	if rate == 0 {
+		return principal / float64(installments)
+	}
+	return principal * rate / (1 - math.Pow(1+rate, -float64(installments)))
+}
+
+// payoffDebt is a debt as the planner sees it: what is left and what each
+// month requires. Extra payments shorten the term, keeping the
+// installment (Price) or the amortization (SAC) of the contract.
+type payoffDebt struct {
+	id           int
+	name         string
+	system       string
+	rate         float64
+	balance      float64
+	fixedPayment float64
+	amortization float64
+}
+
+func newPayoffDebt(schedule domain.DebtSchedule) payoffDebt {
+	debt := schedule.Debt
+	rate := debt.InterestRate / 100
+	return payoffDebt{
+		id:           debt.ID,
+		name:         debt.Name,
+		system:       debt.AmortizationSystem,
+		rate:         rate,
+		balance:      schedule.Outstanding,
+		fixedPayment: priceInstallment(debt.Principal, rate, debt.Installments),
+		amortization: debt.Principal / float64(debt.Installments),
+	}
+}
+
+// due is the contractual payment for a month starting at balance.
+func (d payoffDebt) due(balance float64) float64 {
+	interest := balance * d.rate
+	payment := d.fixedPayment
+	if d.system == domain.AmortizationSAC {
+		payment = d.amortization + interest
+	}
+	return math.Min(payment, balance+interest)
+}
+
+// simulatePayoff pays every installment due each month and, when budget is
+// set, puts what is left of it on the debts in order.
+func simulatePayoff(strategy string, debts []payoffDebt, budget float64, start time.Time) domain.PayoffPlan {
+	plan := domain.PayoffPlan{Strategy: strategy, PayoffDate: start, Debts: []domain.DebtPayoff{}}
+	balances := make([]float64, len(debts))
+	interest := make([]float64, len(debts))
+	paidOff := make([]bool, len(debts))
+	for i, d := range debts {
+		balances[i] = d.balance
+	}
+
+	remaining := len(debts)
+	for month := 1; remaining > 0 && month <= maxPayoffMonths; month++ {
+		spent := 0.0
+		for i, d := range debts {
+			if paidOff[i] {
+				continue
+			}
+			payment := d.due(balances[i])
+			accrued := balances[i] * d.rate
+			interest[i] += accrued
+			balances[i] += accrued - payment
+			spent += payment
+		}
+
+		if budget > 0 {
+			left := budget - spent
+			for i := range debts {
+				if left <= 0 {
+					break
+				}
+				if paidOff[i] {
+					continue
+				}
+				payment := math.Min(left, balances[i])
+				balances[i] -= payment
+				left -= payment
+				spent += payment
+			}
+		}
+		plan.TotalPaid += spent
+
+		for i, d := range debts {
+			if paidOff[i] || balances[i] > 0.005 {
+				continue
+			}
+			paidOff[i] = true
+			remaining--
+			plan.Debts = append(plan.Debts, domain.DebtPayoff{
+				DebtID:     d.id,
+				Name:       d.name,
+				Months:     month,
+				PayoffDate: addMonthsClamped(start, month),
+				Interest:   roundMoney(interest[i]),
+			})
+			plan.Months = month
+			plan.PayoffDate = addMonthsClamped(start, month)
+		}
+	}
+
+	for _, i := range interest {
+		plan.TotalInterest += i
+	}
+	plan.TotalInterest = roundMoney(plan.TotalInterest)
+	plan.TotalPaid = roundMoney(plan.TotalPaid)
+	return plan
+}
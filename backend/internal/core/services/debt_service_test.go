@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type memoryDebtRepository struct {
+	debts    []domain.Debt
+	payments []domain.DebtPayment
+}
+
+func (m *memoryDebtRepository) SaveDebt(d domain.Debt, events ...domain.Event) (int, error) {
+	d.ID = len(m.debts) + 1
+	m.debts = append(m.debts, d)
+	return d.ID, nil
+}
+
+func (m *memoryDebtRepository) GetDebt(id, userID int) (domain.Debt, error) {
+	for _, d := range m.debts {
+		if d.ID == id && d.UserID == userID {
+			return d, nil
+		}
+	}
+	return domain.Debt{}, sql.ErrNoRows
+}
+
+func (m *memoryDebtRepository) ListDebts(userID int) ([]domain.Debt, error) {
+	return m.debts, nil
+}
+
+func (m *memoryDebtRepository) DeleteDebt(id, userID int, events ...domain.Event) error {
+	for i, d := range m.debts {
+		if d.ID == id {
+			m.debts = append(m.debts[:i], m.debts[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryDebtRepository) SavePayment(p domain.DebtPayment, events ...domain.Event) (int, error) {
+	for _, existing := range m.payments {
+		if (existing.DebtID == p.DebtID && existing.Installment == p.Installment) || existing.TransactionID == p.TransactionID {
+			return 0, ports.ErrDebtPaymentExists
+		}
+	}
+	p.ID = len(m.payments) + 1
+	m.payments = append(m.payments, p)
+	return p.ID, nil
+}
+
+func (m *memoryDebtRepository) DeletePayment(debtID, userID, installment int, events ...domain.Event) error {
+	for i, p := range m.payments {
+		if p.DebtID == debtID && p.Installment == installment {
+			m.payments = append(m.payments[:i], m.payments[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryDebtRepository) ListPayments(userID int) ([]domain.DebtPayment, error) {
+	return m.payments, nil
+}
+
+func TestDebtSchedule_Price(t *testing.T) {
+	service := services.NewDebtService(&memoryDebtRepository{}, new(MockTransactionRepository))
+	debt, err := service.CreateDebt(context.Background(), 1, domain.Debt{
+		Name: "Carro", Principal: 10000, InterestRate: 1, AmortizationSystem: "Price", Installments: 12,
+		FirstDueDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	schedule, err := service.Schedule(1, debt.ID)
+
+	assert.NoError(t, err)
+	assert.Len(t, schedule.Installments, 12)
+	first := schedule.Installments[0]
+	assert.Equal(t, 888.49, first.Payment)
+	assert.Equal(t, 100.0, first.Interest)
+	assert.Equal(t, 788.49, first.Amortization)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), schedule.Installments[1].DueDate)
+	last := schedule.Installments[11]
+	assert.Equal(t, 0.0, last.Balance)
+	assert.InDelta(t, 888.49, last.Payment, 0.02)
+	assert.Equal(t, 10000.0, schedule.Outstanding)
+}
+
+func TestDebtSchedule_SAC(t *testing.T) {
+	service := services.NewDebtService(&memoryDebtRepository{}, new(MockTransactionRepository))
+	debt, _ := service.CreateDebt(context.Background(), 1, domain.Debt{
+		Name: "Faculdade", Principal: 12000, InterestRate: 1, AmortizationSystem: "sac", Installments: 12,
+	})
+
+	schedule, err := service.Schedule(1, debt.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1120.0, schedule.Installments[0].Payment)
+	assert.Equal(t, 1110.0, schedule.Installments[1].Payment)
+	assert.Equal(t, 1010.0, schedule.Installments[11].Payment)
+	assert.Equal(t, 780.0, schedule.TotalInterest)
+}
+
+func TestCreateDebt_Invalid(t *testing.T) {
+	service := services.NewDebtService(&memoryDebtRepository{}, new(MockTransactionRepository))
+
+	_, err := service.CreateDebt(context.Background(), 1, domain.Debt{Name: "Carro", Principal: 1000, Installments: 10, AmortizationSystem: "sacre"})
+	assert.ErrorIs(t, err, services.ErrInvalidDebt)
+	_, err = service.CreateDebt(context.Background(), 1, domain.Debt{Name: "Carro", Principal: 1000, AmortizationSystem: "sac"})
+	assert.ErrorIs(t, err, services.ErrInvalidDebt)
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{{Field: "installments", Message: "must be positive"}}, e.Fields)
+}
+
+func TestDebtPayment_LinksTransactionToNextInstallment(t *testing.T) {
+	repo := &memoryDebtRepository{}
+	transactions := new(MockTransactionRepository)
+	service := services.NewDebtService(repo, transactions)
+	debt, _ := service.CreateDebt(context.Background(), 1, domain.Debt{
+		Name: "Faculdade", Principal: 12000, InterestRate: 1, AmortizationSystem: "sac", Installments: 12,
+	})
+
+	transactions.On("GetByID", 7, 1).Return(domain.Transaction{ID: 7, UserID: 1, Type: "expense", Amount: 1120}, nil)
+	transactions.On("GetByID", 8, 1).Return(domain.Transaction{ID: 8, UserID: 1, Type: "income", Amount: 1110}, nil)
+
+	payment, err := service.RecordPayment(context.Background(), 1, debt.ID, 0, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, payment.Installment)
+	assert.Equal(t, 1120.0, payment.Amount)
+
+	_, err = service.RecordPayment(context.Background(), 1, debt.ID, 0, 8)
+	assert.ErrorIs(t, err, services.ErrInvalidDebtPayment)
+	_, err = service.RecordPayment(context.Background(), 1, debt.ID, 13, 7)
+	assert.ErrorIs(t, err, services.ErrInvalidDebtPayment)
+
+	schedule, _ := service.Schedule(1, debt.ID)
+	assert.Equal(t, 1, schedule.PaidInstallments)
+	assert.NotNil(t, schedule.Installments[0].Paid)
+	assert.Equal(t, 11000.0, schedule.Outstanding)
+
+	assert.NoError(t, service.DeletePayment(context.Background(), 1, debt.ID, 1))
+	schedule, _ = service.Schedule(1, debt.ID)
+	assert.Equal(t, 12000.0, schedule.Outstanding)
+}
+
+func TestDebtPlan_AvalanchePaysLessInterest(t *testing.T) {
+	service := services.NewDebtService(&memoryDebtRepository{}, new(MockTransactionRepository))
+	small, _ := service.CreateDebt(context.Background(), 1, domain.Debt{
+		Name: "Cartão parcelado", Principal: 1000, InterestRate: 1, AmortizationSystem: "price", Installments: 10,
+	})
+	expensive, _ := service.CreateDebt(context.Background(), 1, domain.Debt{
+		Name: "Empréstimo pessoal", Principal: 5000, InterestRate: 3, AmortizationSystem: "price", Installments: 24,
+	})
+
+	plan, err := service.Plan(1, 300)
+
+	assert.NoError(t, err)
+	assert.Equal(t, small.ID, plan.Snowball.Debts[0].DebtID)
+	assert.Len(t, plan.Avalanche.Debts, 2)
+	assert.Less(t, plan.Avalanche.TotalInterest, plan.Snowball.TotalInterest)
+	assert.Less(t, plan.Snowball.TotalInterest, plan.Minimum.TotalInterest)
+	assert.Less(t, plan.Avalanche.Months, plan.Minimum.Months)
+	assert.Equal(t, 24, plan.Minimum.Months)
+	assert.Equal(t, domain.PayoffStrategyAvalanche, plan.Recommended)
+	assert.Equal(t, expensive.ID, plan.Minimum.Debts[1].DebtID)
+
+	_, err = service.Plan(1, -1)
+	assert.ErrorIs(t, err, services.ErrInvalidExtra)
+}
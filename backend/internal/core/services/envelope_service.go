@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrEnvelopeModeDisabled      = domain.Conflict("envelope_mode_disabled", "zero-based budgeting is not enabled")
+	ErrInvalidEnvelope           = domain.Invalid("invalid_envelope", "envelope needs either a category or a goal")
+	ErrEnvelopeExists            = domain.Conflict("envelope_exists", "an envelope for this category already exists")
+	ErrEnvelopeNotEmpty          = domain.Conflict("envelope_not_empty", "move the envelope balance elsewhere before archiving it")
+	ErrInvalidEnvelopeTransfer   = domain.Invalid("invalid_envelope_transfer", "transfer needs a positive amount and two different sides")
+	ErrInsufficientEnvelopeFunds = domain.Conflict("insufficient_envelope_funds", "not enough money in the source envelope")
+)
+
+// poolID is the ledger key of the "to be assigned" pool.
+const poolID = 0
+
+type EnvelopeService struct {
+	envelopeRepo    ports.EnvelopeRepository
+	transactionRepo ports.TransactionRepository
+	reportRepo      ports.ReportRepository
+	audit           ports.AuditService
+	now             func() time.Time
+}
+
+func NewEnvelopeService(envelopeRepo ports.EnvelopeRepository, transactionRepo ports.TransactionRepository, reportRepo ports.ReportRepository) *EnvelopeService {
+	return &EnvelopeService{
+		envelopeRepo:    envelopeRepo,
+		transactionRepo: transactionRepo,
+		reportRepo:      reportRepo,
+		now:             time.Now,
+	}
+}
+
+// SetAuditService records every change made through this service.
+func (s *EnvelopeService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetMode turns zero-based budgeting on or off. The first activation fixes
+// the start month; turning it off and on again keeps the existing balances.
+func (s *EnvelopeService) SetMode(ctx context.Context, userID int, enabled bool) (domain.EnvelopeSettings, error) {
+	before, err := s.settings(userID)
+	if err != nil {
+		return domain.EnvelopeSettings{}, err
+	}
+
+	settings := before
+	settings.Enabled = enabled
+	if settings.StartYear == 0 {
+		now := s.now()
+		settings.StartMonth, settings.StartYear = int(now.Month()), now.Year()
+	}
+	if err := s.envelopeRepo.SaveSettings(settings, auditEvents(s.audit, ctx, userID, domain.AuditEntityEnvelope, 0, domain.AuditActionUpdate, before, settings)...); err != nil {
+		return domain.EnvelopeSettings{}, err
+	}
+	return settings, nil
+}
+
+func (s *EnvelopeService) CreateEnvelope(ctx context.Context, userID int, envelope domain.Envelope) (domain.Envelope, error) {
+	envelope.UserID = userID
+	envelope.Category = strings.TrimSpace(envelope.Category)
+	envelope.Name = strings.TrimSpace(envelope.Name)
+	if envelope.Name == "" {
+		envelope.Name = envelope.Category
+	}
+	err := validation.Validate(ErrInvalidEnvelope,
+		validation.Field("category", envelope.Category, func(category string) string {
+			if (category == "") == (envelope.GoalID == nil) {
+				return "exactly one of category and goal_id is required"
+			}
+			return ""
+		}),
+		validation.Field("name", envelope.Name, validation.Required),
+	)
+	if err != nil {
+		return domain.Envelope{}, err
+	}
+
+	if envelope.Category != "" {
+		existing, err := s.ListEnvelopes(userID)
+		if err != nil {
+			return domain.Envelope{}, err
+		}
+		for _, e := range existing {
+			if budgetKey(e.Category) == budgetKey(envelope.Category) {
+				return domain.Envelope{}, ErrEnvelopeExists
+			}
+		}
+	}
+
+	envelope.CreatedAt = s.now()
+	id, err := s.envelopeRepo.Save(envelope, auditEvents(s.audit, ctx, userID, domain.AuditEntityEnvelope, 0, domain.AuditActionCreate, nil, envelope)...)
+	if err != nil {
+		return domain.Envelope{}, err
+	}
+
+	envelope.ID = id
+	return envelope, nil
+}
+
+// ListEnvelopes returns the active envelopes.
+func (s *EnvelopeService) ListEnvelopes(userID int) ([]domain.Envelope, error) {
+	envelopes, err := s.envelopeRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := []domain.Envelope{}
+	for _, e := range envelopes {
+		if e.ArchivedAt == nil {
+			active = append(active, e)
+		}
+	}
+	return active, nil
+}
+
+// DeleteEnvelope archives an empty envelope. Later expenses in its category
+// come out of the "to be assigned" pool.
+func (s *EnvelopeService) DeleteEnvelope(ctx context.Context, userID, id int) error {
+	envelope, err := s.envelopeRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if envelope.ArchivedAt != nil {
+		return sql.ErrNoRows
+	}
+
+	balance, err := s.currentBalance(userID, id)
+	if err != nil {
+		return err
+	}
+	if roundMoney(balance) != 0 {
+		return ErrEnvelopeNotEmpty
+	}
+
+	return s.envelopeRepo.Archive(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityEnvelope, id, domain.AuditActionDelete, envelope, nil)...)
+}
+
+// Assign moves money from the "to be assigned" pool into an envelope.
+func (s *EnvelopeService) Assign(ctx context.Context, userID, envelopeID int, amount float64) (domain.EnvelopeTransfer, error) {
+	return s.Move(ctx, userID, domain.EnvelopeTransfer{ToEnvelopeID: &envelopeID, Amount: amount, Note: "assign"})
+}
+
+// Move transfers money between envelopes. Money moved into or out of a goal
+// envelope counts as progress on the goal, in the same database transaction.
+func (s *EnvelopeService) Move(ctx context.Context, userID int, transfer domain.EnvelopeTransfer) (domain.EnvelopeTransfer, error) {
+	settings, err := s.settings(userID)
+	if err != nil {
+		return domain.EnvelopeTransfer{}, err
+	}
+	if !settings.Enabled {
+		return domain.EnvelopeTransfer{}, ErrEnvelopeModeDisabled
+	}
+
+	from, to := envelopeKey(transfer.FromEnvelopeID), envelopeKey(transfer.ToEnvelopeID)
+	err = validation.Validate(ErrInvalidEnvelopeTransfer,
+		validation.Field("amount", transfer.Amount, validation.Positive),
+		validation.Field("to_envelope_id", to, func(to int) string {
+			if to == from {
+				return "must differ from from_envelope_id"
+			}
+			return ""
+		}),
+	)
+	if err != nil {
+		return domain.EnvelopeTransfer{}, err
+	}
+
+	var fromEnvelope, toEnvelope domain.Envelope
+	if from != poolID {
+		if fromEnvelope, err = s.activeEnvelope(userID, from); err != nil {
+			return domain.EnvelopeTransfer{}, err
+		}
+	}
+	if to != poolID {
+		if toEnvelope, err = s.activeEnvelope(userID, to); err != nil {
+			return domain.EnvelopeTransfer{}, err
+		}
+	}
+
+	transfer.UserID = userID
+	transfer.Amount = roundMoney(transfer.Amount)
+	transfer.CreatedAt = s.now()
+
+	var progress []ports.GoalProgress
+	var events []domain.Event
+	for _, side := range []struct {
+		goalID *int
+		sign   float64
+	}{{fromEnvelope.GoalID, -1}, {toEnvelope.GoalID, 1}} {
+		if side.goalID == nil {
+			continue
+		}
+		amount := side.sign * transfer.Amount
+		progress = append(progress, ports.GoalProgress{GoalID: *side.goalID, Amount: amount})
+		events = append(events, newEvent(domain.EventGoalProgressAdded, userID, domain.AggregateGoal, *side.goalID,
+			map[string]any{"goal_id": *side.goalID, "amount": amount}))
+	}
+
+	check := func() error {
+		available, err := s.currentBalance(userID, from)
+		if err != nil {
+			return err
+		}
+		if roundMoney(available) < transfer.Amount {
+			return ErrInsufficientEnvelopeFunds
+		}
+		return nil
+	}
+	id, err := s.envelopeRepo.SaveTransfer(transfer, check, progress, events...)
+	if err != nil {
+		return domain.EnvelopeTransfer{}, err
+	}
+	transfer.ID = id
+	return transfer, nil
+}
+
+func (s *EnvelopeService) ListTransfers(userID int) ([]domain.EnvelopeTransfer, error) {
+	transfers, err := s.envelopeRepo.ListTransfers(userID)
+	if err != nil {
+		return nil, err
+	}
+	if transfers == nil {
+		transfers = []domain.EnvelopeTransfer{}
+	}
+	return transfers, nil
+}
+
+// Summary reports every envelope at the end of the month, with balances
+// carried over from the previous months.
+func (s *EnvelopeService) Summary(userID, month, year int) (domain.EnvelopeSummary, error) {
+	summary := domain.EnvelopeSummary{
+		Month:              month,
+		Year:               year,
+		Envelopes:          []domain.EnvelopeBalance{},
+		OverdrawnEnvelopes: []domain.EnvelopeBalance{},
+	}
+
+	settings, err := s.settings(userID)
+	if err != nil {
+		return summary, err
+	}
+	summary.Enabled = settings.Enabled
+	if !settings.Enabled {
+		return summary, nil
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	ledger, err := s.buildLedger(userID, settings, monthStart, monthStart.AddDate(0, 1, 0))
+	if err != nil {
+		return summary, err
+	}
+
+	pool := ledger.flow(poolID)
+	summary.Income = roundMoney(pool.income)
+	summary.UnenvelopedSpent = roundMoney(pool.spent)
+	summary.ToBeAssigned = roundMoney(pool.balance())
+
+	for _, e := range ledger.envelopes {
+		f := ledger.flow(e.ID)
+		if e.ArchivedAt != nil && f.idle() {
+			continue
+		}
+		balance := domain.EnvelopeBalance{
+			Envelope:  e,
+			Carried:   roundMoney(f.carried),
+			Assigned:  roundMoney(f.assigned),
+			Spent:     roundMoney(f.spent),
+			Balance:   roundMoney(f.balance()),
+			Overdrawn: roundMoney(f.balance()) < 0,
+		}
+		summary.Envelopes = append(summary.Envelopes, balance)
+		if balance.Overdrawn {
+			summary.OverdrawnEnvelopes = append(summary.OverdrawnEnvelopes, balance)
+		}
+	}
+	return summary, nil
+}
+
+func (s *EnvelopeService) settings(userID int) (domain.EnvelopeSettings, error) {
+	settings, err := s.envelopeRepo.GetSettings(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.EnvelopeSettings{UserID: userID}, nil
+	}
+	return settings, err
+}
+
+func (s *EnvelopeService) activeEnvelope(userID, id int) (domain.Envelope, error) {
+	envelope, err := s.envelopeRepo.GetByID(id, userID)
+	if err != nil {
+		return domain.Envelope{}, err
+	}
+	if envelope.ArchivedAt != nil {
+		return domain.Envelope{}, sql.ErrNoRows
+	}
+	return envelope, nil
+}
+
+// currentBalance is the balance of an envelope (or the pool) right now;
+// future-dated transactions are not counted yet.
+func (s *EnvelopeService) currentBalance(userID, id int) (float64, error) {
+	settings, err := s.settings(userID)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now().Add(time.Nanosecond)
+	ledger, err := s.buildLedger(userID, settings, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return ledger.flow(id).balance(), nil
+}
+
+// envelopeFlow splits an envelope's activity into what happened before the
+// reported month (carried) and during it.
+type envelopeFlow struct {
+	carried  float64
+	income   float64
+	assigned float64
+	spent    float64
+}
+
+func (f *envelopeFlow) balance() float64 {
+	return f.carried + f.income + f.assigned - f.spent
+}
+
+func (f *envelopeFlow) idle() bool {
+	return roundMoney(f.carried) == 0 && f.income == 0 && f.assigned == 0 && f.spent == 0
+}
+
+type envelopeLedger struct {
+	envelopes []domain.Envelope
+	flows     map[int]*envelopeFlow
+}
+
+func (l *envelopeLedger) flow(id int) *envelopeFlow {
+	if l.flows[id] == nil {
+		l.flows[id] = &envelopeFlow{}
+	}
+	return l.flows[id]
+}
+
+// buildLedger replays income, expenses and transfers from the start month
+// up to end. Everything before monthStart is folded into carried balances.
+// Whole months come from the monthly rollup, so an archived envelope keeps
+// its category's expenses through the month it was archived in; only a
+// month cut by end is read transaction by transaction.
+func (s *EnvelopeService) buildLedger(userID int, settings domain.EnvelopeSettings, monthStart, end time.Time) (*envelopeLedger, error) {
+	envelopes, err := s.envelopeRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := s.envelopeRepo.ListTransfers(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(envelopes, func(i, j int) bool { return envelopes[i].Name < envelopes[j].Name })
+	ledger := &envelopeLedger{envelopes: envelopes, flows: make(map[int]*envelopeFlow)}
+	byCategory := make(map[string]domain.Envelope)
+	for _, e := range envelopes {
+		if e.Category != "" {
+			byCategory[budgetKey(e.Category)] = e
+		}
+	}
+	income := func(at time.Time, amount float64) {
+		f := ledger.flow(poolID)
+		if at.Before(monthStart) {
+			f.carried += amount
+		} else {
+			f.income += amount
+		}
+	}
+	expense := func(category string, at time.Time, amount float64) {
+		id := poolID
+		if e, ok := byCategory[budgetKey(category)]; ok && (e.ArchivedAt == nil || at.Before(*e.ArchivedAt)) {
+			id = e.ID
+		}
+		f := ledger.flow(id)
+		if at.Before(monthStart) {
+			f.carried -= amount
+		} else {
+			f.spent += amount
+		}
+	}
+
+	start := time.Date(settings.StartYear, time.Month(settings.StartMonth), 1, 0, 0, 0, 0, time.UTC)
+	partial := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if start.Before(partial) {
+		monthly, err := s.reportRepo.MonthlyTotals(userID, start, partial)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range monthly {
+			income(time.Date(m.Year, time.Month(m.Month), 1, 0, 0, 0, 0, time.UTC), m.Income)
+		}
+		categories, err := s.reportRepo.CategoryTotals(userID, start, partial)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			expense(c.Category, time.Date(c.Year, time.Month(c.Month), 1, 0, 0, 0, 0, time.UTC), c.Total)
+		}
+	}
+	if partial.Before(end) && !partial.Before(start) {
+		transactions, err := s.transactionRepo.ListByUserID(userID, int(partial.Month()), partial.Year())
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range transactions {
+			if !t.Date.Before(end) {
+				continue
+			}
+			switch t.Type {
+			case "income":
+				income(t.Date, t.Amount)
+			case "expense":
+				expense(t.Category, t.Date, t.Amount)
+			}
+		}
+	}
+
+	for _, tr := range transfers {
+		if !tr.CreatedAt.Before(end) {
+			continue
+		}
+		before := tr.CreatedAt.Before(monthStart)
+		for id, sign := range map[int]float64{envelopeKey(tr.FromEnvelopeID): -1, envelopeKey(tr.ToEnvelopeID): 1} {
+			f := ledger.flow(id)
+			if before {
+				f.carried += sign * tr.Amount
+			} else {
+				f.assigned += sign * tr.Amount
+			}
+		}
+	}
+	return ledger, nil
+}
+
+func envelopeKey(id *int) int {
+	if id == nil {
+		return poolID
+	}
+	return *id
+}
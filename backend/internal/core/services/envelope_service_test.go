@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type memoryEnvelopeRepository struct {
+	settings  *domain.EnvelopeSettings
+	envelopes []domain.Envelope
+	transfers []domain.EnvelopeTransfer
+	progress  []ports.GoalProgress
+	events    []domain.Event
+}
+
+func (m *memoryEnvelopeRepository) GetSettings(userID int) (domain.EnvelopeSettings, error) {
+	if m.settings == nil {
+		return domain.EnvelopeSettings{}, sql.ErrNoRows
+	}
+	return *m.settings, nil
+}
+
+func (m *memoryEnvelopeRepository) SaveSettings(s domain.EnvelopeSettings, events ...domain.Event) error {
+	m.settings = &s
+	return nil
+}
+
+func (m *memoryEnvelopeRepository) Save(e domain.Envelope, events ...domain.Event) (int, error) {
+	e.ID = len(m.envelopes) + 1
+	m.envelopes = append(m.envelopes, e)
+	return e.ID, nil
+}
+
+func (m *memoryEnvelopeRepository) Archive(id, userID int, events ...domain.Event) error {
+	now := time.Now()
+	m.envelopes[id-1].ArchivedAt = &now
+	return nil
+}
+
+func (m *memoryEnvelopeRepository) GetByID(id, userID int) (domain.Envelope, error) {
+	if id < 1 || id > len(m.envelopes) {
+		return domain.Envelope{}, sql.ErrNoRows
+	}
+	return m.envelopes[id-1], nil
+}
+
+func (m *memoryEnvelopeRepository) ListByUserID(userID int) ([]domain.Envelope, error) {
+	return append([]domain.Envelope(nil), m.envelopes...), nil
+}
+
+func (m *memoryEnvelopeRepository) SaveTransfer(t domain.EnvelopeTransfer, check func() error, progress []ports.GoalProgress, events ...domain.Event) (int, error) {
+	if err := check(); err != nil {
+		return 0, err
+	}
+	t.ID = len(m.transfers) + 1
+	m.transfers = append(m.transfers, t)
+	m.progress = append(m.progress, progress...)
+	m.events = append(m.events, events...)
+	return t.ID, nil
+}
+
+func (m *memoryEnvelopeRepository) ListTransfers(userID int) ([]domain.EnvelopeTransfer, error) {
+	return m.transfers, nil
+}
+
+// envelopeTransactions serves the listings of a TransactionRepository and
+// the rollup of a ReportRepository from the same transactions.
+type envelopeTransactions struct {
+	ports.TransactionRepository
+	transactions []domain.Transaction
+}
+
+func (e *envelopeTransactions) ListAllByUserID(userID int) ([]domain.Transaction, error) {
+	return e.transactions, nil
+}
+
+func (e *envelopeTransactions) ListByUserID(userID, month, year int) ([]domain.Transaction, error) {
+	var result []domain.Transaction
+	for _, t := range e.transactions {
+		if int(t.Date.Month()) == month && t.Date.Year() == year {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (e *envelopeTransactions) inRange(from, to time.Time) []domain.Transaction {
+	var result []domain.Transaction
+	for _, t := range e.transactions {
+		month := time.Date(t.Date.Year(), t.Date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if !month.Before(from) && month.Before(to) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (e *envelopeTransactions) MonthlyTotals(userID int, from, to time.Time) ([]domain.MonthlyTotal, error) {
+	var totals []domain.MonthlyTotal
+	for _, t := range e.inRange(from, to) {
+		m := domain.MonthlyTotal{Year: t.Date.Year(), Month: int(t.Date.Month())}
+		if t.Type == "income" {
+			m.Income = t.Amount
+		} else {
+			m.Expense = t.Amount
+		}
+		totals = append(totals, m)
+	}
+	return totals, nil
+}
+
+func (e *envelopeTransactions) CategorySpent(userID int, category string, from, to time.Time) (float64, error) {
+	return 0, nil
+}
+
+func (e *envelopeTransactions) CategoryTotals(userID int, from, to time.Time) ([]domain.CategoryTotal, error) {
+	var totals []domain.CategoryTotal
+	for _, t := range e.inRange(from, to) {
+		if t.Type == "expense" {
+			totals = append(totals, domain.CategoryTotal{Year: t.Date.Year(), Month: int(t.Date.Month()), Category: t.Category, Total: t.Amount})
+		}
+	}
+	return totals, nil
+}
+
+func day(month, d int) time.Time {
+	return time.Date(2025, time.Month(month), d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestEnvelopes_ZeroBasedMonth(t *testing.T) {
+	repo := &memoryEnvelopeRepository{}
+	txs := &envelopeTransactions{}
+	service := NewEnvelopeService(repo, txs, txs)
+	ctx := context.Background()
+
+	service.now = func() time.Time { return day(1, 1) }
+	_, err := service.SetMode(ctx, 1, true)
+	assert.NoError(t, err)
+
+	mercado, _ := service.CreateEnvelope(ctx, 1, domain.Envelope{Category: "Mercado"})
+	lazer, _ := service.CreateEnvelope(ctx, 1, domain.Envelope{Category: "Lazer"})
+
+	txs.transactions = []domain.Transaction{
+		{Type: "income", Amount: 3000, Date: day(1, 5)},
+		{Type: "expense", Category: "mercado", Amount: 900, Date: day(1, 20)},
+		{Type: "expense", Category: "Lazer", Amount: 250, Date: day(1, 22)},
+		{Type: "expense", Category: "Mercado", Amount: 300, Date: day(2, 3)},
+	}
+
+	service.now = func() time.Time { return day(1, 6) }
+	_, err = service.Assign(ctx, 1, mercado.ID, 1000)
+	assert.NoError(t, err)
+	_, err = service.Assign(ctx, 1, lazer.ID, 200)
+	assert.NoError(t, err)
+
+	_, err = service.Assign(ctx, 1, lazer.ID, 5000)
+	assert.ErrorIs(t, err, ErrInsufficientEnvelopeFunds)
+
+	january, err := service.Summary(1, 1, 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, 3000.0, january.Income)
+	assert.Equal(t, 1800.0, january.ToBeAssigned)
+	assert.Len(t, january.OverdrawnEnvelopes, 1)
+	assert.Equal(t, "Lazer", january.OverdrawnEnvelopes[0].Envelope.Name)
+	assert.Equal(t, -50.0, january.OverdrawnEnvelopes[0].Balance)
+
+	service.now = func() time.Time { return day(2, 1) }
+	_, err = service.Move(ctx, 1, domain.EnvelopeTransfer{FromEnvelopeID: &mercado.ID, ToEnvelopeID: &lazer.ID, Amount: 50})
+	assert.NoError(t, err)
+
+	february, err := service.Summary(1, 2, 2025)
+	assert.NoError(t, err)
+	assert.Len(t, february.OverdrawnEnvelopes, 1)
+	assert.Equal(t, mercado.ID, february.OverdrawnEnvelopes[0].Envelope.ID)
+	for _, b := range february.Envelopes {
+		switch b.Envelope.ID {
+		case mercado.ID:
+			assert.Equal(t, 100.0, b.Carried)
+			assert.Equal(t, -50.0, b.Assigned)
+			assert.Equal(t, 300.0, b.Spent)
+			assert.Equal(t, -250.0, b.Balance)
+		case lazer.ID:
+			assert.Equal(t, -50.0, b.Carried)
+			assert.Equal(t, 0.0, b.Balance)
+		}
+	}
+}
+
+func TestEnvelopes_GoalEnvelopeProgress(t *testing.T) {
+	repo := &memoryEnvelopeRepository{}
+	txs := &envelopeTransactions{transactions: []domain.Transaction{{Type: "income", Amount: 1000, Date: day(1, 2)}}}
+	service := NewEnvelopeService(repo, txs, txs)
+	service.now = func() time.Time { return day(1, 1) }
+	ctx := context.Background()
+
+	service.SetMode(ctx, 1, true)
+	goalID := 7
+	envelope, err := service.CreateEnvelope(ctx, 1, domain.Envelope{Name: "Viagem", GoalID: &goalID})
+	assert.NoError(t, err)
+
+	service.now = func() time.Time { return day(1, 10) }
+	_, err = service.Assign(ctx, 1, envelope.ID, 600)
+	assert.NoError(t, err)
+	_, err = service.Move(ctx, 1, domain.EnvelopeTransfer{FromEnvelopeID: &envelope.ID, Amount: 250})
+	assert.NoError(t, err)
+	_, err = service.Move(ctx, 1, domain.EnvelopeTransfer{FromEnvelopeID: &envelope.ID, Amount: 400})
+	assert.ErrorIs(t, err, ErrInsufficientEnvelopeFunds)
+
+	assert.Equal(t, []ports.GoalProgress{{GoalID: goalID, Amount: 600}, {GoalID: goalID, Amount: -250}}, repo.progress)
+	assert.Len(t, repo.transfers, 2)
+	assert.Len(t, repo.events, 2)
+	assert.Equal(t, domain.EventGoalProgressAdded, repo.events[1].Type)
+}
+
+func TestEnvelopes_RequireMode(t *testing.T) {
+	repo := &memoryEnvelopeRepository{}
+	service := NewEnvelopeService(repo, &envelopeTransactions{}, &envelopeTransactions{})
+
+	envelope, _ := service.CreateEnvelope(context.Background(), 1, domain.Envelope{Category: "Mercado"})
+	_, err := service.Assign(context.Background(), 1, envelope.ID, 10)
+	assert.ErrorIs(t, err, ErrEnvelopeModeDisabled)
+
+	summary, err := service.Summary(1, 1, 2025)
+	assert.NoError(t, err)
+	assert.False(t, summary.Enabled)
+
+	_, err = service.CreateEnvelope(context.Background(), 1, domain.Envelope{Category: "mercado"})
+	assert.ErrorIs(t, err, ErrEnvelopeExists)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// Failed deliveries are retried with a growing backoff capped at
+// maxEventBackoff; after maxEventAttempts the event is marked failed.
+// Processed events are kept for outboxRetention. A claimed event is left to
+// its dispatcher for eventClaimLease before another may take it over.
+const (
+	maxEventAttempts = 10
+	eventClaimLease  = 5 * time.Minute
+	eventBackoff     = 5 * time.Second
+	maxEventBackoff  = time.Hour
+	eventBatchSize   = 100
+	outboxRetention  = 7 * 24 * time.Hour
+)
+
+type subscriber struct {
+	name       string
+	handler    ports.EventHandler
+	eventTypes []string
+}
+
+func (s subscriber) wants(eventType string) bool {
+	return len(s.eventTypes) == 0 || slices.Contains(s.eventTypes, eventType)
+}
+
+// EventBus reads the outbox and hands each event to the subscribers that
+// want it, retrying those that fail until every one has handled it.
+type EventBus struct {
+	repo        ports.OutboxRepository
+	mu          sync.RWMutex
+	subscribers []subscriber
+	now         func() time.Time
+}
+
+func NewEventBus(repo ports.OutboxRepository) *EventBus {
+	return &EventBus{repo: repo, now: time.Now}
+}
+
+// Subscribe registers handler under a name that must stay stable across
+// restarts: the outbox remembers deliveries by subscriber name.
+func (b *EventBus) Subscribe(name string, handler ports.EventHandler, eventTypes ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber{name: name, handler: handler, eventTypes: eventTypes})
+}
+
+// Dispatch claims the events that are due, delivers them and returns how
+// many it processed. Dispatchers on several instances share the outbox.
+func (b *EventBus) Dispatch(ctx context.Context) (int, error) {
+	entries, err := b.repo.ClaimDue(b.now(), eventClaimLease, eventBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.RLock()
+	subscribers := slices.Clone(b.subscribers)
+	b.mu.RUnlock()
+
+	for _, entry := range entries {
+		var failures []string
+		for _, sub := range subscribers {
+			if !sub.wants(entry.Type) || slices.Contains(entry.DeliveredTo, sub.name) {
+				continue
+			}
+			if err := deliverEvent(ctx, sub, entry.Event); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", sub.name, err))
+				continue
+			}
+			entry.DeliveredTo = append(entry.DeliveredTo, sub.name)
+		}
+
+		entry.Attempts++
+		switch {
+		case len(failures) == 0:
+			entry.Status = domain.OutboxDelivered
+			entry.LastError = ""
+		case entry.Attempts >= maxEventAttempts:
+			entry.Status = domain.OutboxFailed
+			entry.LastError = strings.Join(failures, "; ")
+			log.Printf("events: giving up on %s %d after %d attempts: %s", entry.Type, entry.ID, entry.Attempts, entry.LastError)
+		default:
+			entry.NextAttemptAt = b.now().Add(backoff(entry.Attempts))
+			entry.LastError = strings.Join(failures, "; ")
+		}
+		if err := b.repo.UpdateEntry(entry); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+// StartDispatcher polls the outbox every interval, straight away again while
+// a full batch was processed, until stop is called.
+func (b *EventBus) StartDispatcher(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	purge := time.NewTicker(time.Hour)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			processed, err := b.Dispatch(context.Background())
+			if err != nil {
+				log.Printf("Event dispatcher failed: %v", err)
+			}
+			if processed == eventBatchSize {
+				continue
+			}
+			select {
+			case <-ticker.C:
+			case <-purge.C:
+				if _, err := b.repo.PurgeProcessedBefore(b.now().Add(-outboxRetention)); err != nil {
+					log.Printf("Outbox purge failed: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		purge.Stop()
+		close(done)
+	}
+}
+
+// deliverEvent turns a panicking handler into an error so one subscriber
+// cannot stop the dispatcher.
+func deliverEvent(ctx context.Context, sub subscriber, event domain.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handler(ctx, event)
+}
+
+func backoff(attempts int) time.Duration {
+	wait := eventBackoff << (attempts - 1)
+	if wait <= 0 || wait > maxEventBackoff {
+		return maxEventBackoff
+	}
+	return wait
+}
+
+// newEvent builds an event for a repository write. Pass aggregateID 0 for
+// a row being created; the repository fills in the new id.
+func newEvent(eventType string, userID int, aggregateType string, aggregateID int, payload any) domain.Event {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte("null")
+	}
+	return domain.Event{
+		Type:          eventType,
+		UserID:        userID,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		OccurredAt:    time.Now(),
+	}
+}
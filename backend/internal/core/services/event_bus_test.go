@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type memoryOutboxRepository struct {
+	entries []domain.OutboxEntry
+}
+
+func (m *memoryOutboxRepository) add(e domain.Event) {
+	e.ID = int64(len(m.entries) + 1)
+	m.entries = append(m.entries, domain.OutboxEntry{Event: e, Status: domain.OutboxPending, NextAttemptAt: e.OccurredAt})
+}
+
+func (m *memoryOutboxRepository) LatestEventID() (int64, error) {
+	return int64(len(m.entries)), nil
+}
+
+func (m *memoryOutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEntry, error) {
+	var result []domain.OutboxEntry
+	for i, e := range m.entries {
+		if e.Status == domain.OutboxPending && !e.NextAttemptAt.After(now) && len(result) < limit {
+			e.NextAttemptAt = now.Add(lease)
+			m.entries[i] = e
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryOutboxRepository) UpdateEntry(e domain.OutboxEntry) error {
+	m.entries[e.ID-1] = e
+	return nil
+}
+
+func (m *memoryOutboxRepository) PurgeProcessedBefore(cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestEventBus_DeliversToMatchingSubscribers(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &memoryOutboxRepository{}
+	repo.add(domain.Event{Type: domain.EventTransactionCreated, UserID: 1, AggregateID: 5, OccurredAt: now})
+	repo.add(domain.Event{Type: domain.EventGoalCreated, UserID: 1, AggregateID: 2, OccurredAt: now})
+
+	bus := NewEventBus(repo)
+	bus.now = func() time.Time { return now }
+	var all, transactions []string
+	bus.Subscribe("all", func(ctx context.Context, e domain.Event) error {
+		all = append(all, e.Type)
+		return nil
+	})
+	bus.Subscribe("transactions", func(ctx context.Context, e domain.Event) error {
+		transactions = append(transactions, e.Type)
+		return nil
+	}, domain.EventTransactionCreated, domain.EventTransactionDeleted)
+
+	processed, err := bus.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []string{domain.EventTransactionCreated, domain.EventGoalCreated}, all)
+	assert.Equal(t, []string{domain.EventTransactionCreated}, transactions)
+	assert.Equal(t, domain.OutboxDelivered, repo.entries[0].Status)
+	assert.Equal(t, domain.OutboxDelivered, repo.entries[1].Status)
+
+	processed, _ = bus.Dispatch(context.Background())
+	assert.Equal(t, 0, processed)
+}
+
+func TestEventBus_RetriesOnlyFailedSubscribers(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &memoryOutboxRepository{}
+	repo.add(domain.Event{Type: domain.EventUserRegistered, UserID: 3, AggregateID: 3, OccurredAt: now})
+
+	bus := NewEventBus(repo)
+	bus.now = func() time.Time { return now }
+	okCalls, flakyCalls := 0, 0
+	bus.Subscribe("ok", func(ctx context.Context, e domain.Event) error {
+		okCalls++
+		return nil
+	})
+	bus.Subscribe("flaky", func(ctx context.Context, e domain.Event) error {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	bus.Dispatch(context.Background())
+	entry := repo.entries[0]
+	assert.Equal(t, domain.OutboxPending, entry.Status)
+	assert.Equal(t, []string{"ok"}, entry.DeliveredTo)
+	assert.Equal(t, "flaky: timeout", entry.LastError)
+	assert.Equal(t, now.Add(eventBackoff), entry.NextAttemptAt)
+
+	// Not due yet.
+	bus.Dispatch(context.Background())
+	assert.Equal(t, 1, flakyCalls)
+
+	bus.now = func() time.Time { return now.Add(time.Minute) }
+	bus.Dispatch(context.Background())
+	assert.Equal(t, 1, okCalls)
+	assert.Equal(t, 2, flakyCalls)
+	assert.Equal(t, domain.OutboxDelivered, repo.entries[0].Status)
+	assert.Equal(t, 2, repo.entries[0].Attempts)
+}
+
+func TestEventBus_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &memoryOutboxRepository{}
+	repo.add(domain.Event{Type: domain.EventGoalProgressAdded, OccurredAt: now})
+
+	bus := NewEventBus(repo)
+	bus.Subscribe("broken", func(ctx context.Context, e domain.Event) error {
+		panic("nil map")
+	})
+
+	for i := 0; i < maxEventAttempts; i++ {
+		due := repo.entries[0].NextAttemptAt
+		bus.now = func() time.Time { return due }
+		_, err := bus.Dispatch(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, domain.OutboxFailed, repo.entries[0].Status)
+	assert.Equal(t, maxEventAttempts, repo.entries[0].Attempts)
+	assert.Equal(t, "broken: panic: nil map", repo.entries[0].LastError)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	assert.Equal(t, eventBackoff, backoff(1))
+	assert.Equal(t, 4*eventBackoff, backoff(3))
+	assert.Equal(t, maxEventBackoff, backoff(20))
+	assert.Equal(t, maxEventBackoff, backoff(80))
+}
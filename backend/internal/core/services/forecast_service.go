@@ -0,0 +1,382 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+const (
+	// MaxForecastMonths bounds GET /api/forecast?months=.
+	MaxForecastMonths = 24
+
+	recurringLookbackMonths = 6
+	recurringMinMonths      = 3
+	recurringAmountSpread   = 0.15
+	baselineMonths          = 3
+)
+
+// installmentPattern matches card installments such as "LOJA X 03/10" or
+// "Parcela 3/10".
+var installmentPattern = regexp.MustCompile(`(?i)(?:^|\s)(?:parcela\s+)?(\d{1,2})\s*/\s*(\d{1,2})(?:\s|$)`)
+
+// ForecastService projects daily balances. There are no account or
+// schedule entities, so everything is derived from history: the starting
+// balance is the net of all past transactions, recurring items are
+// descriptions seen on a monthly rhythm, installments are read from "n/N"
+// descriptions and goal contributions spread what is missing over the
+// months left until each deadline.
+type ForecastService struct {
+	transactionRepo ports.TransactionRepository
+	goalRepo        ports.GoalRepository
+	now             func() time.Time
+}
+
+func NewForecastService(transactionRepo ports.TransactionRepository, goalRepo ports.GoalRepository) *ForecastService {
+	return &ForecastService{
+		transactionRepo: transactionRepo,
+		goalRepo:        goalRepo,
+		now:             time.Now,
+	}
+}
+
+func (s *ForecastService) Forecast(userID, months int) (domain.Forecast, error) {
+	if months < 1 {
+		months = 1
+	}
+	if months > MaxForecastMonths {
+		months = MaxForecastMonths
+	}
+
+	transactions, err := s.transactionRepo.ListAllByUserID(userID)
+	if err != nil {
+		return domain.Forecast{}, err
+	}
+	goals, err := s.goalRepo.ListByUserID(userID)
+	if err != nil {
+		return domain.Forecast{}, err
+	}
+
+	today := dayOf(s.now())
+	end := today.AddDate(0, months, 0)
+
+	var balance float64
+	var past []domain.Transaction
+	for _, t := range transactions {
+		if dayOf(t.Date).After(today) {
+			continue
+		}
+		past = append(past, t)
+		balance += signedAmount(t)
+	}
+
+	installments, installmentIDs := projectInstallments(past, today, end)
+	recurring, recurringIDs := projectRecurring(past, installmentIDs, today, end)
+	scheduled := append(append(recurring, installments...), projectGoalContributions(goals, today, end)...)
+	scheduled = append(scheduled, futureTransactions(transactions, today, end)...)
+	sort.SliceStable(scheduled, func(i, j int) bool { return scheduled[i].Date.Before(scheduled[j].Date) })
+
+	baseline := variableBaseline(past, recurringIDs, installmentIDs, today)
+
+	forecast := domain.Forecast{
+		StartingBalance:   roundMoney(balance),
+		Months:            months,
+		LowestBalance:     roundMoney(balance),
+		LowestBalanceDate: today,
+		Days:              []domain.ForecastDay{},
+		Scheduled:         scheduled,
+		Baseline:          baseline,
+	}
+	if forecast.Scheduled == nil {
+		forecast.Scheduled = []domain.ForecastItem{}
+	}
+
+	byDay := make(map[time.Time][]domain.ForecastItem)
+	for _, item := range scheduled {
+		byDay[item.Date] = append(byDay[item.Date], item)
+	}
+
+	for day := today.AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		entry := domain.ForecastDay{Date: day}
+		for _, item := range byDay[day] {
+			if item.Type == "income" {
+				entry.Income += item.Amount
+			} else {
+				entry.Expense += item.Amount
+			}
+		}
+		daysInMonth := float64(daysIn(day))
+		for _, b := range baseline {
+			entry.Expense += b.MonthlyAverage / daysInMonth
+		}
+
+		balance += entry.Income - entry.Expense
+		entry.Income = roundMoney(entry.Income)
+		entry.Expense = roundMoney(entry.Expense)
+		entry.Balance = roundMoney(balance)
+		forecast.Days = append(forecast.Days, entry)
+
+		if entry.Balance < forecast.LowestBalance {
+			forecast.LowestBalance = entry.Balance
+			forecast.LowestBalanceDate = day
+		}
+	}
+	forecast.GoesNegative = forecast.LowestBalance < 0
+	return forecast, nil
+}
+
+// projectInstallments continues every installment plan whose latest
+// payment happened in the last two months.
+func projectInstallments(past []domain.Transaction, today, end time.Time) ([]domain.ForecastItem, map[int]bool) {
+	type plan struct {
+		latest       domain.Transaction
+		number, size int
+	}
+	plans := make(map[string]plan)
+	ids := make(map[int]bool)
+
+	for _, t := range past {
+		match := installmentPattern.FindStringSubmatchIndex(t.Description)
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(t.Description[match[2]:match[3]])
+		size, _ := strconv.Atoi(t.Description[match[4]:match[5]])
+		if n < 1 || size < 2 || n > size {
+			continue
+		}
+		ids[t.ID] = true
+
+		base := strings.Join(tokenizeDescription(t.Description[:match[0]]+" "+t.Description[match[1]:]), " ")
+		key := t.Type + "|" + base + "|" + strconv.Itoa(size)
+		if p, ok := plans[key]; !ok || n > p.number {
+			plans[key] = plan{latest: t, number: n, size: size}
+		}
+	}
+
+	cutoff := today.AddDate(0, -2, 0)
+	var items []domain.ForecastItem
+	for _, p := range plans {
+		if p.latest.Date.Before(cutoff) {
+			continue
+		}
+		for k := 1; k <= p.size-p.number; k++ {
+			date := addMonthsClamped(dayOf(p.latest.Date), k)
+			if !date.After(today) || date.After(end) {
+				continue
+			}
+			items = append(items, domain.ForecastItem{
+				Source:      domain.ForecastSourceInstallment,
+				Type:        p.latest.Type,
+				Description: p.latest.Description,
+				Category:    p.latest.Category,
+				Amount:      p.latest.Amount,
+				Date:        date,
+			})
+		}
+	}
+	return items, ids
+}
+
+// projectRecurring repeats descriptions that showed up with a similar amount
+// in at least three of the last six months.
+func projectRecurring(past []domain.Transaction, skip map[int]bool, today, end time.Time) ([]domain.ForecastItem, map[int]bool) {
+	since := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -recurringLookbackMonths, 0)
+	groups := make(map[string][]domain.Transaction)
+	for _, t := range past {
+		if skip[t.ID] || t.Date.Before(since) {
+			continue
+		}
+		key := strings.Join(tokenizeDescription(t.Description), " ")
+		if key == "" {
+			continue
+		}
+		groups[t.Type+"|"+key] = append(groups[t.Type+"|"+key], t)
+	}
+
+	var items []domain.ForecastItem
+	ids := make(map[int]bool)
+	for _, group := range groups {
+		monthsSeen := make(map[int]bool)
+		amounts := make([]float64, 0, len(group))
+		days := make([]float64, 0, len(group))
+		latest := group[0]
+		for _, t := range group {
+			monthsSeen[monthIndex(int(t.Date.Month()), t.Date.Year())] = true
+			amounts = append(amounts, t.Amount)
+			days = append(days, float64(t.Date.Day()))
+			if t.Date.After(latest.Date) {
+				latest = t
+			}
+		}
+		if len(monthsSeen) < recurringMinMonths {
+			continue
+		}
+		amount := median(amounts)
+		consistent := true
+		for _, a := range amounts {
+			if math.Abs(a-amount) > amount*recurringAmountSpread {
+				consistent = false
+				break
+			}
+		}
+		if !consistent {
+			continue
+		}
+		for _, t := range group {
+			ids[t.ID] = true
+		}
+
+		dayOfMonth := int(median(days))
+		lastMonth := monthIndex(int(latest.Date.Month()), latest.Date.Year())
+		for month := monthIndex(int(today.Month()), today.Year()); ; month++ {
+			date := dateInMonth(month, dayOfMonth)
+			if date.After(end) {
+				break
+			}
+			if !date.After(today) || month <= lastMonth {
+				continue
+			}
+			items = append(items, domain.ForecastItem{
+				Source:      domain.ForecastSourceRecurring,
+				Type:        latest.Type,
+				Description: latest.Description,
+				Category:    latest.Category,
+				Amount:      roundMoney(amount),
+				Date:        date,
+			})
+		}
+	}
+	return items, ids
+}
+
+// projectGoalContributions spreads each goal's missing amount evenly over
+// the first day of the months left until its deadline.
+func projectGoalContributions(goals []domain.Goal, today, end time.Time) []domain.ForecastItem {
+	var items []domain.ForecastItem
+	for _, g := range goals {
+		missing := g.TargetAmount - g.CurrentAmount
+		deadline := dayOf(g.Deadline)
+		if missing <= 0 || !deadline.After(today) {
+			continue
+		}
+
+		first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		var dates []time.Time
+		for date := first; !date.After(deadline); date = date.AddDate(0, 1, 0) {
+			dates = append(dates, date)
+		}
+		if len(dates) == 0 {
+			dates = []time.Time{deadline}
+		}
+
+		monthly := roundMoney(missing / float64(len(dates)))
+		for _, date := range dates {
+			if date.After(end) {
+				break
+			}
+			items = append(items, domain.ForecastItem{
+				Source:      domain.ForecastSourceGoal,
+				Type:        "expense",
+				Description: g.Name,
+				Amount:      monthly,
+				Date:        date,
+			})
+		}
+	}
+	return items
+}
+
+// futureTransactions keeps transactions the user already entered with a
+// future date.
+func futureTransactions(transactions []domain.Transaction, today, end time.Time) []domain.ForecastItem {
+	var items []domain.ForecastItem
+	for _, t := range transactions {
+		date := dayOf(t.Date)
+		if !date.After(today) || date.After(end) {
+			continue
+		}
+		items = append(items, domain.ForecastItem{
+			Type:        t.Type,
+			Description: t.Description,
+			Category:    t.Category,
+			Amount:      t.Amount,
+			Date:        date,
+		})
+	}
+	return items
+}
+
+// variableBaseline averages, per category, the expenses of the last full
+// months that are neither recurring nor installments.
+func variableBaseline(past []domain.Transaction, recurring, installments map[int]bool, today time.Time) []domain.CategoryBaseline {
+	end := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -baselineMonths, 0)
+
+	totals := make(map[string]float64)
+	names := make(map[string]string)
+	for _, t := range past {
+		if t.Type != "expense" || recurring[t.ID] || installments[t.ID] || t.Date.Before(start) || !t.Date.Before(end) {
+			continue
+		}
+		key := budgetKey(t.Category)
+		totals[key] += t.Amount
+		if names[key] == "" {
+			names[key] = t.Category
+		}
+	}
+
+	baseline := make([]domain.CategoryBaseline, 0, len(totals))
+	for key, total := range totals {
+		baseline = append(baseline, domain.CategoryBaseline{
+			Category:       names[key],
+			MonthlyAverage: roundMoney(total / baselineMonths),
+		})
+	}
+	sort.Slice(baseline, func(i, j int) bool { return baseline[i].MonthlyAverage > baseline[j].MonthlyAverage })
+	return baseline
+}
+
+func signedAmount(t domain.Transaction) float64 {
+	if t.Type == "income" {
+		return t.Amount
+	}
+	return -t.Amount
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// dateInMonth returns day of the month at index, clamped to its last day.
+func dateInMonth(index, day int) time.Time {
+	first := time.Date(index/12, time.Month(index%12+1), 1, 0, 0, 0, 0, time.UTC)
+	if last := daysIn(first); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func addMonthsClamped(date time.Time, months int) time.Time {
+	return dateInMonth(monthIndex(int(date.Month()), date.Year())+months, date.Day())
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+func TestForecast_ProjectsScheduleAndLowestBalance(t *testing.T) {
+	date := func(month, d int) time.Time { return time.Date(2025, time.Month(month), d, 0, 0, 0, 0, time.UTC) }
+
+	var history []domain.Transaction
+	id := 0
+	add := func(tr domain.Transaction) {
+		id++
+		tr.ID = id
+		history = append(history, tr)
+	}
+	for month := 1; month <= 5; month++ {
+		add(domain.Transaction{Type: "income", Amount: 5000, Description: "Salario ACME", Category: "Salário", Date: date(month, 5)})
+		add(domain.Transaction{Type: "expense", Amount: 1800, Description: "Aluguel apartamento", Category: "Moradia", Date: date(month, 10)})
+		add(domain.Transaction{Type: "expense", Amount: float64(400 + 100*month), Description: "Supermercado", Category: "Mercado", Date: date(month, 10+month)})
+	}
+	add(domain.Transaction{Type: "expense", Amount: 300, Description: "MAGAZINE TV 04/06", Category: "Casa", Date: date(5, 12)})
+
+	goals := &MockGoalRepository{}
+	goals.Save(domain.Goal{UserID: 1, Name: "Viagem", TargetAmount: 3000, CurrentAmount: 1000, Deadline: date(8, 20)})
+
+	service := NewForecastService(&envelopeTransactions{transactions: history}, goals)
+	service.now = func() time.Time { return date(5, 31) }
+
+	forecast, err := service.Forecast(1, 3)
+	assert.NoError(t, err)
+
+	// 5 × (5000 − 1800) − (500 + 600 + 700 + 800 + 900) − 300
+	assert.Equal(t, 12200.0, forecast.StartingBalance)
+	assert.Len(t, forecast.Days, 92)
+
+	sources := map[string]int{}
+	for _, item := range forecast.Scheduled {
+		sources[item.Source]++
+	}
+	// Salary and rent for June, July and August.
+	assert.Equal(t, 6, sources[domain.ForecastSourceRecurring])
+	// Installments 05/06 and 06/06.
+	assert.Equal(t, 2, sources[domain.ForecastSourceInstallment])
+	// R$2.000 missing, spread over June, July and August.
+	assert.Equal(t, 3, sources[domain.ForecastSourceGoal])
+
+	// The supermarket amount varies too much to be recurring: it is the
+	// baseline, averaged over February to April.
+	assert.Equal(t, []domain.CategoryBaseline{{Category: "Mercado", MonthlyAverage: 700}}, forecast.Baseline)
+
+	june1 := forecast.Days[0]
+	assert.Equal(t, date(6, 1), june1.Date)
+	assert.InDelta(t, 666.67+700.0/30, june1.Expense, 0.01)
+
+	assert.False(t, forecast.GoesNegative)
+	assert.Equal(t, date(6, 4), forecast.LowestBalanceDate)
+}
+
+func TestForecast_MonthsAreBounded(t *testing.T) {
+	service := NewForecastService(&envelopeTransactions{}, &MockGoalRepository{})
+
+	forecast, err := service.Forecast(1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxForecastMonths, forecast.Months)
+}
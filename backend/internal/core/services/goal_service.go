@@ -1,21 +1,62 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var ErrInvalidGoal = domain.Invalid("invalid_goal", "goal is invalid")
+
+var ErrInvalidGoalProgress = domain.Invalid("invalid_goal_progress", "progress amount is invalid")
+
+var ErrInvalidGoalInvestment = domain.Invalid("invalid_goal_investment", "investment needs cdi, selic or ipca and a positive percent")
+
+// Business days and months per year, used to turn an annual spread into a
+// per-period rate the way Brazilian fixed income quotes it.
+const (
+	businessDaysPerYear = 252
+	monthsPerYear       = 12
 )
 
 type GoalService struct {
-	goalRepo ports.GoalRepository
+	goalRepo      ports.GoalRepository
+	indexRepo     ports.IndexRepository
+	audit         ports.AuditService
+	notifications ports.NotificationService
+	now           func() time.Time
 }
 
 func NewGoalService(goalRepo ports.GoalRepository) *GoalService {
-	return &GoalService{goalRepo: goalRepo}
+	return &GoalService{goalRepo: goalRepo, now: time.Now}
+}
+
+// SetIndexRepository enables yield and inflation in Yield; without it goals
+// report their contributions only.
+func (s *GoalService) SetIndexRepository(indexRepo ports.IndexRepository) {
+	s.indexRepo = indexRepo
+}
+
+// SetAuditService records every change made through this service.
+func (s *GoalService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetNotificationService tells users when progress reaches a goal's target.
+func (s *GoalService) SetNotificationService(notifications ports.NotificationService) {
+	s.notifications = notifications
 }
 
-func (s *GoalService) CreateGoal(userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+func (s *GoalService) CreateGoal(ctx context.Context, userID int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
 	goal := domain.Goal{
 		UserID:        userID,
 		Name:          name,
@@ -24,36 +65,287 @@ func (s *GoalService) CreateGoal(userID int, name string, targetAmount float64,
 		Deadline:      deadline,
 		CreatedAt:     time.Now(),
 	}
-
-	id, err := s.goalRepo.Save(goal)
-	if err != nil {
+	if err := s.validateGoal(goal, true); err != nil {
 		return domain.Goal{}, err
 	}
 
-	goal.ID = id
-	return goal, nil
+	events := append([]domain.Event{newEvent(domain.EventGoalCreated, userID, domain.AggregateGoal, 0, goal)},
+		auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, 0, domain.AuditActionCreate, nil, goal)...)
+	return s.goalRepo.Save(goal, events...)
 }
 
-func (s *GoalService) UpdateGoal(userID, id int, name string, targetAmount float64, deadline time.Time) error {
-	goal := domain.Goal{
+func (s *GoalService) GetGoal(userID, id int) (domain.Goal, error) {
+	return s.goalRepo.GetByID(id, userID)
+}
+
+func (s *GoalService) UpdateGoal(ctx context.Context, userID, id, version int, name string, targetAmount float64, deadline time.Time) (domain.Goal, error) {
+	return s.update(ctx, domain.Goal{
 		ID:           id,
 		UserID:       userID,
 		Name:         name,
 		TargetAmount: targetAmount,
 		Deadline:     deadline,
+		Version:      version,
+	})
+}
+
+// update saves the goal's name, target and deadline and returns the goal
+// with its new version.
+func (s *GoalService) update(ctx context.Context, goal domain.Goal) (domain.Goal, error) {
+	if err := s.validateGoal(goal, false); err != nil {
+		return domain.Goal{}, err
+	}
+	before, err := s.goalRepo.GetByID(goal.ID, goal.UserID)
+	if err != nil {
+		return domain.Goal{}, err
+	}
+	changed := before
+	changed.Name = goal.Name
+	changed.TargetAmount = goal.TargetAmount
+	changed.Deadline = goal.Deadline
+	changed.Version = before.Version + 1
+	events := append([]domain.Event{newEvent(domain.EventGoalUpdated, goal.UserID, domain.AggregateGoal, goal.ID, changed)},
+		auditEvents(s.audit, ctx, goal.UserID, domain.AuditEntityGoal, goal.ID, domain.AuditActionUpdate, before, changed)...)
+	if err := s.goalRepo.Update(goal, events...); err != nil {
+		return domain.Goal{}, err
 	}
+	return s.goalRepo.GetByID(goal.ID, goal.UserID)
+}
 
-	return s.goalRepo.Update(goal)
+func (s *GoalService) DeleteGoal(ctx context.Context, userID, id, version int) error {
+	before, err := s.goalRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	events := append([]domain.Event{newEvent(domain.EventGoalDeleted, userID, domain.AggregateGoal, id, before)},
+		auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, id, domain.AuditActionDelete, before, nil)...)
+	return s.goalRepo.Delete(id, userID, version, events...)
 }
 
-func (s *GoalService) DeleteGoal(userID, id int) error {
-	return s.goalRepo.Delete(id, userID)
+// CreateSynced creates a goal made offline under its client id.
+func (s *GoalService) CreateSynced(ctx context.Context, goal domain.Goal) (domain.Goal, error) {
+	if err := s.validateGoal(goal, false); err != nil {
+		return domain.Goal{}, err
+	}
+	goal.CurrentAmount = 0
+	goal.CreatedAt = time.Now()
+	events := append([]domain.Event{newEvent(domain.EventGoalCreated, goal.UserID, domain.AggregateGoal, 0, goal)},
+		auditEvents(s.audit, ctx, goal.UserID, domain.AuditEntityGoal, 0, domain.AuditActionCreate, nil, goal)...)
+	return s.goalRepo.Save(goal, events...)
+}
+
+// UpdateSynced applies the goal's name, target and deadline only over its
+// Version of the row and returns the row with its new version.
+func (s *GoalService) UpdateSynced(ctx context.Context, goal domain.Goal) (domain.Goal, error) {
+	return s.update(ctx, goal)
+}
+
+func (s *GoalService) DeleteSynced(ctx context.Context, userID, id, version int) error {
+	return s.DeleteGoal(ctx, userID, id, version)
 }
 
 func (s *GoalService) ListGoals(userID int) ([]domain.Goal, error) {
 	return s.goalRepo.ListByUserID(userID)
 }
 
-func (s *GoalService) AddProgress(userID, goalID int, amount float64) error {
-	return s.goalRepo.AddProgress(goalID, userID, amount)
+func (s *GoalService) AddProgress(ctx context.Context, userID, goalID int, amount float64) error {
+	err := validation.Validate(ErrInvalidGoalProgress,
+		validation.Field("amount", amount, validation.Positive, validation.Max(maxTransactionAmount)),
+	)
+	if err != nil {
+		return err
+	}
+	events := []domain.Event{newEvent(domain.EventGoalProgressAdded, userID, domain.AggregateGoal, goalID, map[string]any{"goal_id": goalID, "amount": amount})}
+	if s.audit == nil && s.notifications == nil {
+		return s.goalRepo.AddProgress(goalID, userID, amount, events...)
+	}
+
+	before, err := s.goalRepo.GetByID(goalID, userID)
+	if err != nil {
+		return err
+	}
+	after := before
+	after.CurrentAmount += amount
+	events = append(events, auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, goalID, domain.AuditActionUpdate, before, after)...)
+	if err := s.goalRepo.AddProgress(goalID, userID, amount, events...); err != nil {
+		return err
+	}
+	if before.CurrentAmount < before.TargetAmount && after.CurrentAmount >= after.TargetAmount {
+		notify(s.notifications, ctx, domain.Notification{
+			UserID:    userID,
+			EventType: domain.NotificationGoalCompleted,
+			Title:     fmt.Sprintf("Goal reached: %s", after.Name),
+			Body:      fmt.Sprintf("You saved R$ %.2f of R$ %.2f.", after.CurrentAmount, after.TargetAmount),
+			DedupKey:  fmt.Sprintf("goal_completed:%d", goalID),
+		})
+	}
+	return nil
+}
+
+// SetInvestment declares how the goal's money is invested. An empty index
+// clears it, leaving the goal uninvested.
+func (s *GoalService) SetInvestment(ctx context.Context, userID, goalID int, investment domain.GoalInvestment) (domain.GoalInvestment, error) {
+	if _, err := s.goalRepo.GetByID(goalID, userID); err != nil {
+		return domain.GoalInvestment{}, err
+	}
+	investment.GoalID = goalID
+	investment.UserID = userID
+	investment.Index = strings.ToLower(strings.TrimSpace(investment.Index))
+
+	var before *domain.GoalInvestment
+	if existing, err := s.goalRepo.GetInvestment(goalID, userID); err == nil {
+		before = &existing
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return domain.GoalInvestment{}, err
+	}
+
+	if investment.Index == "" {
+		var events []domain.Event
+		if before != nil {
+			events = auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, goalID, domain.AuditActionUpdate, before, nil)
+		}
+		if err := s.goalRepo.DeleteInvestment(goalID, userID, events...); err != nil {
+			return domain.GoalInvestment{}, err
+		}
+		return investment, nil
+	}
+
+	if !isEconomicIndex(investment.Index) || investment.Percent <= 0 {
+		return domain.GoalInvestment{}, ErrInvalidGoalInvestment
+	}
+	events := auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, goalID, domain.AuditActionUpdate, before, investment)
+	if err := s.goalRepo.SaveInvestment(investment, events...); err != nil {
+		return domain.GoalInvestment{}, err
+	}
+	return investment, nil
+}
+
+// Yield values the goal as if every contribution had been invested on the
+// day it was made. Money that predates contribution tracking counts as
+// contributed when the goal was created. Rates accrue from the day after a
+// contribution, so the values only move once new index data is loaded.
+func (s *GoalService) Yield(userID, goalID int) (domain.GoalYield, error) {
+	goal, err := s.goalRepo.GetByID(goalID, userID)
+	if err != nil {
+		return domain.GoalYield{}, err
+	}
+	contributions, err := s.goalRepo.ListContributions(goalID, userID)
+	if err != nil {
+		return domain.GoalYield{}, err
+	}
+
+	tracked := 0.0
+	for _, c := range contributions {
+		tracked += c.Amount
+	}
+	if untracked := goal.CurrentAmount - tracked; math.Abs(untracked) >= 0.005 {
+		contributions = append([]domain.GoalContribution{{GoalID: goalID, UserID: userID, Amount: untracked, CreatedAt: goal.CreatedAt}}, contributions...)
+	}
+
+	now := s.now()
+	result := domain.GoalYield{
+		GoalID:         goalID,
+		Contributed:    roundMoney(goal.CurrentAmount),
+		AdjustedTarget: goal.TargetAmount,
+		AsOf:           now,
+	}
+
+	if s.indexRepo != nil {
+		investment, err := s.goalRepo.GetInvestment(goalID, userID)
+		switch {
+		case err == nil:
+			result.Investment = &investment
+			yield, dataUntil, err := s.accruedYield(investment, contributions, goal.CreatedAt, now)
+			if err != nil {
+				return domain.GoalYield{}, err
+			}
+			result.AccruedYield = roundMoney(yield)
+			result.DataUntil = dataUntil
+		case !errors.Is(err, sql.ErrNoRows):
+			return domain.GoalYield{}, err
+		}
+
+		ipca, err := s.indexRepo.ListRates(domain.IndexIPCA, dayAfter(goal.CreatedAt), now)
+		if err != nil {
+			return domain.GoalYield{}, err
+		}
+		inflation := compound(ipca, 1, 0, monthsPerYear)
+		result.Inflation = roundPercent((inflation - 1) * 100)
+		result.AdjustedTarget = goal.TargetAmount * inflation
+		if result.DataUntil == nil && len(ipca) > 0 {
+			result.DataUntil = &ipca[len(ipca)-1].Date
+		}
+	}
+
+	result.Balance = roundMoney(result.Contributed + result.AccruedYield)
+	result.AdjustedTarget = roundMoney(result.AdjustedTarget)
+	if goal.TargetAmount > 0 {
+		result.NominalProgress = roundPercent(result.Balance / goal.TargetAmount * 100)
+	}
+	if result.AdjustedTarget > 0 {
+		result.RealProgress = roundPercent(result.Balance / result.AdjustedTarget * 100)
+	}
+	return result, nil
+}
+
+// accruedYield compounds each contribution over the index rates published
+// after it was made. Suffix products let every contribution share one pass
+// over the series.
+func (s *GoalService) accruedYield(investment domain.GoalInvestment, contributions []domain.GoalContribution, since, now time.Time) (float64, *time.Time, error) {
+	for _, c := range contributions {
+		if c.CreatedAt.Before(since) {
+			since = c.CreatedAt
+		}
+	}
+	rates, err := s.indexRepo.ListRates(investment.Index, dayAfter(since), now)
+	if err != nil || len(rates) == 0 {
+		return 0, nil, err
+	}
+
+	periodsPerYear := businessDaysPerYear
+	if investment.Index == domain.IndexIPCA {
+		periodsPerYear = monthsPerYear
+	}
+	growth := make([]float64, len(rates)+1)
+	growth[len(rates)] = 1
+	for i := len(rates) - 1; i >= 0; i-- {
+		growth[i] = growth[i+1] * compound(rates[i:i+1], investment.Percent/100, investment.Spread, periodsPerYear)
+	}
+
+	yield := 0.0
+	for _, c := range contributions {
+		start := dayAfter(c.CreatedAt)
+		i := sort.Search(len(rates), func(i int) bool { return !rates[i].Date.Before(start) })
+		yield += c.Amount * (growth[i] - 1)
+	}
+	return yield, &rates[len(rates)-1].Date, nil
+}
+
+// compound returns the growth factor of rates taken at share of their value
+// plus an annual spread, both in percent.
+func compound(rates []domain.IndexRate, share, spread float64, periodsPerYear int) float64 {
+	spreadFactor := math.Pow(1+spread/100, 1/float64(periodsPerYear))
+	factor := 1.0
+	for _, rate := range rates {
+		factor *= (1 + rate.Rate/100*share) * spreadFactor
+	}
+	return factor
+}
+
+// dayAfter is the start of the day following t, in UTC like index dates.
+func dayAfter(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// validateGoal checks a goal's name and target and, for a goal being created
+// now, that its deadline has not passed. Goals past their deadline can still
+// be edited, and ones created offline may reach us after it.
+func (s *GoalService) validateGoal(goal domain.Goal, checkDeadline bool) error {
+	// A day of slack keeps "today" valid for users west of UTC.
+	yesterday := s.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	return validation.Validate(ErrInvalidGoal,
+		validation.Field("name", goal.Name, validation.Required, validation.MaxLength(maxNameLength)),
+		validation.Field("target_amount", goal.TargetAmount, validation.Positive, validation.Max(maxTransactionAmount)),
+		validation.When(checkDeadline, validation.Field("deadline", goal.Deadline, validation.NotBefore(yesterday))),
+	)
 }
@@ -1,41 +1,61 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockGoalRepository struct {
-	goals []domain.Goal
+	goals         []domain.Goal
+	deleted       []domain.Goal
+	contributions []domain.GoalContribution
+	investments   []domain.GoalInvestment
+	events        []domain.Event
 }
 
-func (m *MockGoalRepository) Save(goal domain.Goal) (int, error) {
+func (m *MockGoalRepository) Save(goal domain.Goal, events ...domain.Event) (domain.Goal, error) {
 	goal.ID = len(m.goals) + 1
+	goal.Version = 1
 	m.goals = append(m.goals, goal)
-	return goal.ID, nil
+	m.events = append(m.events, events...)
+	return goal, nil
 }
 
-func (m *MockGoalRepository) Update(goal domain.Goal) error {
+func (m *MockGoalRepository) Update(goal domain.Goal, events ...domain.Event) error {
 	for i, g := range m.goals {
 		if g.ID == goal.ID && g.UserID == goal.UserID {
+			if goal.Version != 0 && goal.Version != g.Version {
+				return ports.ErrVersionConflict
+			}
+			goal.Version = g.Version + 1
 			m.goals[i] = goal
+			m.events = append(m.events, events...)
 			return nil
 		}
 	}
-	return nil
+	return sql.ErrNoRows
 }
 
-func (m *MockGoalRepository) Delete(id, userID int) error {
+func (m *MockGoalRepository) Delete(id, userID, version int, events ...domain.Event) error {
 	for i, g := range m.goals {
 		if g.ID == id && g.UserID == userID {
+			now := time.Now()
+			g.DeletedAt = &now
+			m.deleted = append(m.deleted, g)
 			m.goals = append(m.goals[:i], m.goals[i+1:]...)
+			m.events = append(m.events, events...)
 			return nil
 		}
 	}
-	return nil
+	return sql.ErrNoRows
 }
 
 func (m *MockGoalRepository) ListByUserID(userID int) ([]domain.Goal, error) {
@@ -54,31 +74,128 @@ func (m *MockGoalRepository) GetByID(id, userID int) (domain.Goal, error) {
 			return g, nil
 		}
 	}
-	return domain.Goal{}, nil
+	return domain.Goal{}, sql.ErrNoRows
 }
 
-func (m *MockGoalRepository) AddProgress(id, userID int, amount float64) error {
+func (m *MockGoalRepository) AddProgress(id, userID int, amount float64, events ...domain.Event) error {
 	for i, g := range m.goals {
 		if g.ID == id && g.UserID == userID {
 			m.goals[i].CurrentAmount += amount
+			m.contributions = append(m.contributions, domain.GoalContribution{
+				ID: len(m.contributions) + 1, GoalID: id, UserID: userID, Amount: amount, CreatedAt: time.Now(),
+			})
 			return nil
 		}
 	}
 	return nil
 }
 
+func (m *MockGoalRepository) ListDeleted(userID int) ([]domain.Goal, error) {
+	var result []domain.Goal
+	for _, g := range m.deleted {
+		if g.UserID == userID {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockGoalRepository) Restore(id, userID int, events ...domain.Event) error {
+	for i, g := range m.deleted {
+		if g.ID == id && g.UserID == userID {
+			g.DeletedAt = nil
+			m.goals = append(m.goals, g)
+			m.deleted = append(m.deleted[:i], m.deleted[i+1:]...)
+			m.events = append(m.events, events...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *MockGoalRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	var kept []domain.Goal
+	for _, g := range m.deleted {
+		if !g.DeletedAt.Before(cutoff) {
+			kept = append(kept, g)
+		}
+	}
+	purged := len(m.deleted) - len(kept)
+	m.deleted = kept
+	return purged, nil
+}
+
+func (m *MockGoalRepository) ListContributions(id, userID int) ([]domain.GoalContribution, error) {
+	var result []domain.GoalContribution
+	for _, c := range m.contributions {
+		if c.GoalID == id && c.UserID == userID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockGoalRepository) GetInvestment(id, userID int) (domain.GoalInvestment, error) {
+	for _, i := range m.investments {
+		if i.GoalID == id && i.UserID == userID {
+			return i, nil
+		}
+	}
+	return domain.GoalInvestment{}, sql.ErrNoRows
+}
+
+func (m *MockGoalRepository) SaveInvestment(investment domain.GoalInvestment, events ...domain.Event) error {
+	m.DeleteInvestment(investment.GoalID, investment.UserID)
+	m.investments = append(m.investments, investment)
+	return nil
+}
+
+func (m *MockGoalRepository) DeleteInvestment(id, userID int, events ...domain.Event) error {
+	for i, inv := range m.investments {
+		if inv.GoalID == id && inv.UserID == userID {
+			m.investments = append(m.investments[:i], m.investments[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type memoryIndexRepository struct {
+	rates []domain.IndexRate
+}
+
+func (m *memoryIndexRepository) SaveRates(rates []domain.IndexRate) error {
+	m.rates = append(m.rates, rates...)
+	return nil
+}
+
+func (m *memoryIndexRepository) ListRates(index string, from, to time.Time) ([]domain.IndexRate, error) {
+	var result []domain.IndexRate
+	for _, r := range m.rates {
+		if r.Index == index && !r.Date.Before(from) && !r.Date.After(to) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func utcDate(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
 func TestCreateGoal(t *testing.T) {
 	repo := &MockGoalRepository{}
 	service := NewGoalService(repo)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	goal, err := service.CreateGoal(1, "Viagem", 5000.0, deadline)
+	goal, err := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, deadline)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "Viagem", goal.Name)
 	assert.Equal(t, 5000.0, goal.TargetAmount)
 	assert.Equal(t, 0.0, goal.CurrentAmount)
 	assert.Equal(t, 1, goal.UserID)
+	assert.Equal(t, 1, goal.Version)
 }
 
 func TestListGoals(t *testing.T) {
@@ -86,9 +203,9 @@ func TestListGoals(t *testing.T) {
 	service := NewGoalService(repo)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	service.CreateGoal(1, "Viagem", 5000.0, deadline)
-	service.CreateGoal(1, "Carro", 30000.0, deadline)
-	service.CreateGoal(2, "Casa", 100000.0, deadline)
+	service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, deadline)
+	service.CreateGoal(context.Background(), 1, "Carro", 30000.0, deadline)
+	service.CreateGoal(context.Background(), 2, "Casa", 100000.0, deadline)
 
 	goals, err := service.ListGoals(1)
 
@@ -101,47 +218,226 @@ func TestUpdateGoal(t *testing.T) {
 	service := NewGoalService(repo)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	goal, _ := service.CreateGoal(1, "Viagem", 5000.0, deadline)
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, deadline)
 
 	newDeadline := time.Now().AddDate(1, 0, 0)
-	err := service.UpdateGoal(1, goal.ID, "Viagem Europa", 8000.0, newDeadline)
+	updated, err := service.UpdateGoal(context.Background(), 1, goal.ID, 0, "Viagem Europa", 8000.0, newDeadline)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
 
 	goals, _ := service.ListGoals(1)
 	assert.Equal(t, "Viagem Europa", goals[0].Name)
 	assert.Equal(t, 8000.0, goals[0].TargetAmount)
 }
 
+func TestUpdateGoal_StaleVersion(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, time.Now().AddDate(0, 6, 0))
+	_, err := service.UpdateGoal(context.Background(), 1, goal.ID, 1, "Viagem Europa", 8000.0, goal.Deadline)
+	assert.NoError(t, err)
+
+	_, err = service.UpdateGoal(context.Background(), 1, goal.ID, 1, "Viagem Ásia", 9000.0, goal.Deadline)
+
+	assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	goals, _ := service.ListGoals(1)
+	assert.Equal(t, "Viagem Europa", goals[0].Name)
+}
+
 func TestDeleteGoal(t *testing.T) {
 	repo := &MockGoalRepository{}
 	service := NewGoalService(repo)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	goal, _ := service.CreateGoal(1, "Viagem", 5000.0, deadline)
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, deadline)
 
-	err := service.DeleteGoal(1, goal.ID)
+	err := service.DeleteGoal(context.Background(), 1, goal.ID, 0)
 	assert.NoError(t, err)
 
 	goals, _ := service.ListGoals(1)
 	assert.Len(t, goals, 0)
 }
 
+func TestUpdateAndDeleteGoal_WriteEvents(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+	ctx := context.Background()
+
+	goal, _ := service.CreateGoal(ctx, 1, "Viagem", 5000.0, time.Now().AddDate(0, 6, 0))
+	service.UpdateGoal(ctx, 1, goal.ID, 1, "Viagem Europa", 8000.0, goal.Deadline)
+	service.DeleteGoal(ctx, 1, goal.ID, 2)
+
+	if !assert.Len(t, repo.events, 3) {
+		return
+	}
+	updated, deleted := repo.events[1], repo.events[2]
+	assert.Equal(t, domain.EventGoalUpdated, updated.Type)
+	assert.Equal(t, goal.ID, updated.AggregateID)
+	var payload domain.Goal
+	json.Unmarshal(updated.Payload, &payload)
+	assert.Equal(t, "Viagem Europa", payload.Name)
+	assert.Equal(t, 2, payload.Version)
+	assert.Equal(t, domain.EventGoalDeleted, deleted.Type)
+	assert.Equal(t, domain.AggregateGoal, deleted.AggregateType)
+	assert.Equal(t, 1, deleted.UserID)
+
+	// A failed change raises nothing.
+	_, err := service.UpdateGoal(ctx, 1, goal.ID, 0, "Viagem Ásia", 9000.0, goal.Deadline)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Len(t, repo.events, 3)
+}
+
 func TestAddProgress(t *testing.T) {
 	repo := &MockGoalRepository{}
 	service := NewGoalService(repo)
 
 	deadline := time.Now().AddDate(0, 6, 0)
-	goal, _ := service.CreateGoal(1, "Viagem", 5000.0, deadline)
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, deadline)
 
-	err := service.AddProgress(1, goal.ID, 1000.0)
+	err := service.AddProgress(context.Background(), 1, goal.ID, 1000.0)
 	assert.NoError(t, err)
 
 	goals, _ := service.ListGoals(1)
 	assert.Equal(t, 1000.0, goals[0].CurrentAmount)
 
 	// Add more progress
-	service.AddProgress(1, goal.ID, 500.0)
+	service.AddProgress(context.Background(), 1, goal.ID, 500.0)
 	goals, _ = service.ListGoals(1)
 	assert.Equal(t, 1500.0, goals[0].CurrentAmount)
 }
+
+func TestDeleteGoal_MovesToTrash(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+	trash := NewTrashService(nil, repo, 30*24*time.Hour)
+
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, time.Now().AddDate(0, 6, 0))
+	assert.NoError(t, service.DeleteGoal(context.Background(), 1, goal.ID, 0))
+
+	deleted, _ := repo.ListDeleted(1)
+	assert.Len(t, deleted, 1)
+
+	assert.NoError(t, trash.RestoreGoal(context.Background(), 1, goal.ID))
+	goals, _ := service.ListGoals(1)
+	assert.Len(t, goals, 1)
+	assert.Nil(t, goals[0].DeletedAt)
+}
+
+func TestSetInvestment(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+	goal, _ := service.CreateGoal(context.Background(), 1, "Reserva", 10000.0, time.Now().AddDate(1, 0, 0))
+
+	_, err := service.SetInvestment(context.Background(), 1, goal.ID, domain.GoalInvestment{Index: "poupanca", Percent: 100})
+	assert.ErrorIs(t, err, ErrInvalidGoalInvestment)
+	_, err = service.SetInvestment(context.Background(), 1, goal.ID, domain.GoalInvestment{Index: "cdi"})
+	assert.ErrorIs(t, err, ErrInvalidGoalInvestment)
+
+	investment, err := service.SetInvestment(context.Background(), 1, goal.ID, domain.GoalInvestment{Index: " CDI ", Percent: 110})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IndexCDI, investment.Index)
+	assert.Len(t, repo.investments, 1)
+
+	_, err = service.SetInvestment(context.Background(), 1, goal.ID, domain.GoalInvestment{})
+	assert.NoError(t, err)
+	assert.Empty(t, repo.investments)
+}
+
+func TestYield_CompoundsContributionsFromTheDayAfter(t *testing.T) {
+	repo := &MockGoalRepository{
+		goals: []domain.Goal{{ID: 1, UserID: 1, Name: "Reserva", TargetAmount: 2000, CurrentAmount: 1500, CreatedAt: utcDate(2024, 1, 1)}},
+		contributions: []domain.GoalContribution{
+			{ID: 1, GoalID: 1, UserID: 1, Amount: 1000, CreatedAt: utcDate(2024, 1, 1).Add(10 * time.Hour)},
+			{ID: 2, GoalID: 1, UserID: 1, Amount: 500, CreatedAt: utcDate(2024, 1, 2).Add(10 * time.Hour)},
+		},
+		investments: []domain.GoalInvestment{{GoalID: 1, UserID: 1, Index: domain.IndexCDI, Percent: 200}},
+	}
+	indexes := &memoryIndexRepository{rates: []domain.IndexRate{
+		{Index: domain.IndexCDI, Date: utcDate(2024, 1, 1), Rate: 1},
+		{Index: domain.IndexCDI, Date: utcDate(2024, 1, 2), Rate: 1},
+		{Index: domain.IndexCDI, Date: utcDate(2024, 1, 3), Rate: 1},
+		{Index: domain.IndexIPCA, Date: utcDate(2024, 1, 1), Rate: 10},
+		{Index: domain.IndexIPCA, Date: utcDate(2024, 2, 1), Rate: 10},
+	}}
+	service := NewGoalService(repo)
+	service.SetIndexRepository(indexes)
+	service.now = func() time.Time { return utcDate(2024, 2, 15) }
+
+	yield, err := service.Yield(1, 1)
+
+	assert.NoError(t, err)
+	// 1000 earns 2% on Jan 2 and Jan 3, 500 only on Jan 3.
+	assert.Equal(t, 1500.0, yield.Contributed)
+	assert.Equal(t, 50.4, yield.AccruedYield)
+	assert.Equal(t, 1550.4, yield.Balance)
+	// Only February's IPCA is after the goal was created.
+	assert.Equal(t, 10.0, yield.Inflation)
+	assert.Equal(t, 2200.0, yield.AdjustedTarget)
+	assert.Equal(t, 77.5, yield.NominalProgress)
+	assert.Equal(t, 70.5, yield.RealProgress)
+	assert.Equal(t, utcDate(2024, 1, 3), *yield.DataUntil)
+}
+
+func TestYield_CountsUntrackedProgressFromGoalCreation(t *testing.T) {
+	repo := &MockGoalRepository{
+		goals:       []domain.Goal{{ID: 1, UserID: 1, TargetAmount: 1000, CurrentAmount: 1000, CreatedAt: utcDate(2023, 12, 31)}},
+		investments: []domain.GoalInvestment{{GoalID: 1, UserID: 1, Index: domain.IndexSelic, Percent: 100, Spread: 10}},
+	}
+	indexes := &memoryIndexRepository{rates: []domain.IndexRate{
+		{Index: domain.IndexSelic, Date: utcDate(2024, 1, 2), Rate: 0},
+	}}
+	service := NewGoalService(repo)
+	service.SetIndexRepository(indexes)
+	service.now = func() time.Time { return utcDate(2024, 1, 5) }
+
+	yield, err := service.Yield(1, 1)
+
+	assert.NoError(t, err)
+	// One business day of a 10% a year spread.
+	assert.Equal(t, 0.38, yield.AccruedYield)
+	assert.Equal(t, 1000.0, yield.AdjustedTarget)
+}
+
+func TestYield_WithoutIndexesReportsContributions(t *testing.T) {
+	repo := &MockGoalRepository{
+		goals: []domain.Goal{{ID: 1, UserID: 1, TargetAmount: 4000, CurrentAmount: 1000, CreatedAt: utcDate(2024, 1, 1)}},
+	}
+	service := NewGoalService(repo)
+
+	yield, err := service.Yield(1, 1)
+
+	assert.NoError(t, err)
+	assert.Nil(t, yield.Investment)
+	assert.Equal(t, 1000.0, yield.Balance)
+	assert.Equal(t, 25.0, yield.NominalProgress)
+	assert.Equal(t, 25.0, yield.RealProgress)
+}
+
+func TestCreateGoal_RejectsInvalidGoal(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+
+	_, err := service.CreateGoal(context.Background(), 1, "Viagem", 0, time.Now().AddDate(0, 0, -7))
+
+	assert.ErrorIs(t, err, ErrInvalidGoal)
+	e, _ := domain.AsError(err)
+	assert.Len(t, e.Fields, 2)
+	assert.Equal(t, domain.FieldError{Field: "target_amount", Message: "must be positive"}, e.Fields[0])
+	assert.Equal(t, "deadline", e.Fields[1].Field)
+	assert.Empty(t, repo.goals)
+}
+
+func TestAddProgress_RejectsInvalidAmount(t *testing.T) {
+	repo := &MockGoalRepository{}
+	service := NewGoalService(repo)
+	goal, _ := service.CreateGoal(context.Background(), 1, "Viagem", 5000.0, time.Now().AddDate(0, 6, 0))
+
+	for _, amount := range []float64{0, -50, math.NaN(), 1e12} {
+		err := service.AddProgress(context.Background(), 1, goal.ID, amount)
+		assert.ErrorIs(t, err, ErrInvalidGoalProgress, amount)
+	}
+	assert.Empty(t, repo.contributions)
+	assert.Equal(t, 0.0, repo.goals[0].CurrentAmount)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"log"
+	"time"
+	"unicode"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var (
+	ErrInvalidIdempotencyKey = domain.Invalid("invalid_idempotency_key", "idempotency key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused  = domain.Conflict("idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrIdempotencyInProgress = domain.Conflict("idempotency_in_progress", "a request with this idempotency key is still in progress")
+)
+
+// Responses are replayed for idempotencyTTL. A key whose request has been
+// in progress for idempotencyLockTimeout is assumed abandoned (the instance
+// handling it went down) and can be claimed again.
+const (
+	idempotencyTTL         = 24 * time.Hour
+	idempotencyLockTimeout = 5 * time.Minute
+	maxIdempotencyKey      = 255
+)
+
+type IdempotencyService struct {
+	repo ports.IdempotencyRepository
+	now  func() time.Time
+}
+
+func NewIdempotencyService(repo ports.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{repo: repo, now: time.Now}
+}
+
+func (s *IdempotencyService) Begin(userID int, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	if !validIdempotencyKey(key) {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	now := s.now()
+	record, claimed, err := s.repo.Claim(domain.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      domain.IdempotencyInProgress,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyTTL),
+	}, now.Add(-idempotencyLockTimeout))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case claimed:
+		return nil, nil
+	case record.Fingerprint != fingerprint:
+		return nil, ErrIdempotencyKeyReused
+	case record.Status == domain.IdempotencyInProgress:
+		return nil, ErrIdempotencyInProgress
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyService) Complete(userID int, key string, status int, headers map[string]string, body []byte) error {
+	return s.repo.Complete(domain.IdempotencyRecord{
+		UserID:         userID,
+		Key:            key,
+		ResponseStatus: status,
+		Headers:        headers,
+		ResponseBody:   body,
+	})
+}
+
+func (s *IdempotencyService) Release(userID int, key string) error {
+	return s.repo.Release(userID, key)
+}
+
+// PurgeExpired deletes the records past their TTL.
+func (s *IdempotencyService) PurgeExpired() error {
+	count, err := s.repo.DeleteExpired(s.now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Purged %d expired idempotency keys", count)
+	}
+	return nil
+}
+
+// StartPurgeJob runs PurgeExpired now and then every interval until stop
+// is called.
+func (s *IdempotencyService) StartPurgeJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			if err := s.PurgeExpired(); err != nil {
+				log.Printf("Idempotency key purge failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKey {
+		return false
+	}
+	for _, r := range key {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
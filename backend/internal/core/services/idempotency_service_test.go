@@ -0,0 +1,117 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type memoryIdempotencyRepository struct {
+	records map[string]domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: map[string]domain.IdempotencyRecord{}}
+}
+
+func (m *memoryIdempotencyRepository) Claim(record domain.IdempotencyRecord, staleBefore time.Time) (domain.IdempotencyRecord, bool, error) {
+	existing, ok := m.records[record.Key]
+	if ok && existing.ExpiresAt.After(record.CreatedAt) &&
+		!(existing.Status == domain.IdempotencyInProgress && existing.CreatedAt.Before(staleBefore)) {
+		return existing, false, nil
+	}
+	m.records[record.Key] = record
+	return record, true, nil
+}
+
+func (m *memoryIdempotencyRepository) Complete(record domain.IdempotencyRecord) error {
+	existing, ok := m.records[record.Key]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	existing.Status = domain.IdempotencyCompleted
+	existing.ResponseStatus = record.ResponseStatus
+	existing.Headers = record.Headers
+	existing.ResponseBody = record.ResponseBody
+	m.records[record.Key] = existing
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) Release(userID int, key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) DeleteExpired(now time.Time) (int, error) {
+	count := 0
+	for key, record := range m.records {
+		if !record.ExpiresAt.After(now) {
+			delete(m.records, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestIdempotency_ReplaysCompletedRequest(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	service := NewIdempotencyService(repo)
+
+	record, err := service.Begin(1, "retry-1", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	_, err = service.Begin(1, "retry-1", "fp")
+	assert.ErrorIs(t, err, ErrIdempotencyInProgress)
+
+	assert.NoError(t, service.Complete(1, "retry-1", 201, map[string]string{"Content-Type": "application/json", "ETag": `"1"`}, []byte(`{"id":7}`)))
+
+	record, err = service.Begin(1, "retry-1", "fp")
+	assert.NoError(t, err)
+	assert.Equal(t, 201, record.ResponseStatus)
+	assert.Equal(t, `"1"`, record.Headers["ETag"])
+	assert.Equal(t, `{"id":7}`, string(record.ResponseBody))
+
+	_, err = service.Begin(1, "retry-1", "other-body")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestIdempotency_ExpiredAndAbandonedKeysAreReclaimed(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	service := NewIdempotencyService(repo)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	_, err := service.Begin(1, "abandoned", "fp")
+	assert.NoError(t, err)
+	_, err = service.Begin(1, "done", "fp")
+	assert.NoError(t, err)
+	assert.NoError(t, service.Complete(1, "done", 200, nil, nil))
+
+	now = now.Add(idempotencyLockTimeout + time.Second)
+	record, err := service.Begin(1, "abandoned", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	now = now.Add(idempotencyTTL)
+	record, err = service.Begin(1, "done", "new-fp")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	assert.NoError(t, service.PurgeExpired())
+	assert.Len(t, repo.records, 1)
+}
+
+func TestIdempotency_RejectsInvalidKeys(t *testing.T) {
+	service := NewIdempotencyService(newMemoryIdempotencyRepository())
+
+	for _, key := range []string{strings.Repeat("k", 256), "chave-çã", "line\nbreak"} {
+		_, err := service.Begin(1, key, "fp")
+		assert.ErrorIs(t, err, ErrInvalidIdempotencyKey, key)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var ErrUnknownIndex = domain.Invalid("unknown_index", "index must be cdi, selic or ipca")
+
+var economicIndexes = []string{domain.IndexCDI, domain.IndexSelic, domain.IndexIPCA}
+
+func isEconomicIndex(index string) bool {
+	for _, known := range economicIndexes {
+		if index == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexService loads CDI, Selic and IPCA series from CSV files, so the
+// data can be bundled with a deploy or downloaded once and loaded offline.
+type IndexService struct {
+	repo ports.IndexRepository
+}
+
+func NewIndexService(repo ports.IndexRepository) *IndexService {
+	return &IndexService{repo: repo}
+}
+
+// Import reads a series as exported by the Banco Central SGS ("data";"valor"
+// with DD/MM/YYYY dates and decimal commas) or as plain "date,rate" lines.
+// A header line is skipped and IPCA dates are moved to the first day of
+// their month. Any other bad line aborts the import so a series is never
+// loaded with holes.
+func (s *IndexService) Import(index string, file io.Reader) (int, error) {
+	index = strings.ToLower(strings.TrimSpace(index))
+	if !isEconomicIndex(index) {
+		return 0, ErrUnknownIndex
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	reader := newImportReader(string(content))
+
+	var rates []domain.IndexRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) < 2 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		date, dateErr := parseImportDate(record[0])
+		rate, rateErr := parseDecimal(record[1])
+		if line == 1 && (dateErr != nil || rateErr != nil) {
+			continue // header
+		}
+		if dateErr != nil {
+			return 0, fmt.Errorf("line %d: %w", line, dateErr)
+		}
+		if rateErr != nil {
+			return 0, fmt.Errorf("line %d: invalid rate %q", line, strings.TrimSpace(record[1]))
+		}
+
+		if index == domain.IndexIPCA {
+			date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+		rates = append(rates, domain.IndexRate{Index: index, Date: date, Rate: rate})
+	}
+
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.SaveRates(rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// LoadDir imports cdi.csv, selic.csv and ipca.csv from dir. Missing files
+// are skipped.
+func (s *IndexService) LoadDir(dir string) error {
+	for _, index := range economicIndexes {
+		file, err := os.Open(filepath.Join(dir, index+".csv"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = s.Import(index, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", index, err)
+		}
+	}
+	return nil
+}
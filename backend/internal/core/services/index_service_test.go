@@ -0,0 +1,59 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+func TestIndexImport_ReadsBancoCentralExport(t *testing.T) {
+	repo := &memoryIndexRepository{}
+	service := NewIndexService(repo)
+
+	csv := "\"data\";\"valor\"\n\"02/01/2024\";\"0,043739\"\n\"03/01/2024\";\"0,043739\"\n"
+	count, err := service.Import("CDI", strings.NewReader(csv))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, domain.IndexCDI, repo.rates[0].Index)
+	assert.Equal(t, utcDate(2024, 1, 2), repo.rates[0].Date)
+	assert.Equal(t, 0.043739, repo.rates[0].Rate)
+}
+
+func TestIndexImport_MovesIPCAToFirstOfMonth(t *testing.T) {
+	repo := &memoryIndexRepository{}
+	service := NewIndexService(repo)
+
+	_, err := service.Import(domain.IndexIPCA, strings.NewReader("2024-03-15,0.16\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, utcDate(2024, 3, 1), repo.rates[0].Date)
+}
+
+func TestIndexImport_RejectsBadLinesAndUnknownIndexes(t *testing.T) {
+	repo := &memoryIndexRepository{}
+	service := NewIndexService(repo)
+
+	_, err := service.Import("poupanca", strings.NewReader("2024-01-02,0.1\n"))
+	assert.ErrorIs(t, err, ErrUnknownIndex)
+
+	_, err = service.Import(domain.IndexSelic, strings.NewReader("2024-01-02,0.1\n2024-01-03,abc\n"))
+	assert.ErrorContains(t, err, "line 2")
+	assert.Empty(t, repo.rates)
+}
+
+func TestIndexLoadDir_SkipsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "selic.csv"), []byte("2024-01-02,0.043739\n"), 0o644))
+	repo := &memoryIndexRepository{}
+	service := NewIndexService(repo)
+
+	assert.NoError(t, service.LoadDir(dir))
+	assert.Len(t, repo.rates, 1)
+	assert.Equal(t, domain.IndexSelic, repo.rates[0].Index)
+}
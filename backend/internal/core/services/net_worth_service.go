@@ -0,0 +1,391 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var (
+	ErrInvalidAsset     = domain.Invalid("invalid_asset", "asset needs a name and a kind of asset or liability")
+	ErrInvalidValuation = domain.Invalid("invalid_valuation", "valuation must not be negative")
+	ErrAssetArchived    = domain.Conflict("asset_archived", "asset is archived")
+)
+
+const (
+	netWorthSourceAccount   = "account"
+	netWorthSourceGoal      = "goal"
+	netWorthSourcePortfolio = "portfolio"
+)
+
+// NetWorthService combines account balances derived from transactions, goal
+// balances and the valuations of assets and liabilities.
+type NetWorthService struct {
+	repo            ports.NetWorthRepository
+	transactionRepo ports.TransactionRepository
+	goalRepo        ports.GoalRepository
+	userRepo        ports.UserRepository
+	portfolio       ports.PortfolioService
+	audit           ports.AuditService
+	now             func() time.Time
+}
+
+func NewNetWorthService(repo ports.NetWorthRepository, transactionRepo ports.TransactionRepository, goalRepo ports.GoalRepository, userRepo ports.UserRepository) *NetWorthService {
+	return &NetWorthService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		goalRepo:        goalRepo,
+		userRepo:        userRepo,
+		now:             time.Now,
+	}
+}
+
+// SetPortfolioService counts the market value of the investment portfolio
+// as an asset.
+func (s *NetWorthService) SetPortfolioService(portfolio ports.PortfolioService) {
+	s.portfolio = portfolio
+}
+
+// SetAuditService records every change made through this service.
+func (s *NetWorthService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// CreateAsset saves the asset and, when Value is set, its first valuation
+// dated ValuedAt (today if empty).
+func (s *NetWorthService) CreateAsset(ctx context.Context, userID int, asset domain.Asset) (domain.Asset, error) {
+	asset.UserID = userID
+	if err := normalizeAsset(&asset); err != nil {
+		return domain.Asset{}, err
+	}
+	if asset.Value < 0 {
+		return domain.Asset{}, ErrInvalidValuation
+	}
+
+	valued := asset.Value > 0 || asset.ValuedAt != nil
+	if valued {
+		date := dayOf(s.now())
+		if asset.ValuedAt != nil {
+			date = dayOf(*asset.ValuedAt)
+		}
+		asset.ValuedAt = &date
+	}
+	asset.CreatedAt = s.now()
+
+	id, err := s.repo.SaveAsset(asset, auditEvents(s.audit, ctx, userID, domain.AuditEntityAsset, 0, domain.AuditActionCreate, nil, asset)...)
+	if err != nil {
+		return domain.Asset{}, err
+	}
+	asset.ID = id
+
+	if valued {
+		if _, err := s.repo.SaveValuation(domain.AssetValuation{AssetID: id, UserID: userID, Value: asset.Value, Date: *asset.ValuedAt}); err != nil {
+			return domain.Asset{}, err
+		}
+	}
+	return asset, nil
+}
+
+// UpdateAsset changes the name, kind and category. Values change through
+// AddValuation so history is kept.
+func (s *NetWorthService) UpdateAsset(ctx context.Context, userID, id int, asset domain.Asset) error {
+	before, err := s.repo.GetAsset(id, userID)
+	if err != nil {
+		return err
+	}
+	if before.ArchivedAt != nil {
+		return ErrAssetArchived
+	}
+
+	asset.ID = id
+	asset.UserID = userID
+	if err := normalizeAsset(&asset); err != nil {
+		return err
+	}
+	after := before
+	after.Name, after.Kind, after.Category = asset.Name, asset.Kind, asset.Category
+	return s.repo.UpdateAsset(asset, auditEvents(s.audit, ctx, userID, domain.AuditEntityAsset, id, domain.AuditActionUpdate, before, after)...)
+}
+
+// DeleteAsset archives the asset; months before it keep counting it.
+func (s *NetWorthService) DeleteAsset(ctx context.Context, userID, id int) error {
+	before, err := s.repo.GetAsset(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.ArchiveAsset(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityAsset, id, domain.AuditActionDelete, before, nil)...)
+}
+
+// ListAssets returns the active assets and liabilities.
+func (s *NetWorthService) ListAssets(userID int) ([]domain.Asset, error) {
+	assets, err := s.repo.ListAssets(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := []domain.Asset{}
+	for _, a := range assets {
+		if a.ArchivedAt == nil {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+func (s *NetWorthService) AddValuation(ctx context.Context, userID, assetID int, value float64, date time.Time) (domain.AssetValuation, error) {
+	if value < 0 {
+		return domain.AssetValuation{}, ErrInvalidValuation
+	}
+	asset, err := s.repo.GetAsset(assetID, userID)
+	if err != nil {
+		return domain.AssetValuation{}, err
+	}
+	if asset.ArchivedAt != nil {
+		return domain.AssetValuation{}, ErrAssetArchived
+	}
+	if date.IsZero() {
+		date = s.now()
+	}
+
+	valuation := domain.AssetValuation{AssetID: assetID, UserID: userID, Value: roundMoney(value), Date: dayOf(date)}
+	events := auditEvents(s.audit, ctx, userID, domain.AuditEntityAsset, assetID, domain.AuditActionUpdate,
+		map[string]any{"value": asset.Value}, map[string]any{"value": valuation.Value, "date": valuation.Date})
+	id, err := s.repo.SaveValuation(valuation, events...)
+	if err != nil {
+		return domain.AssetValuation{}, err
+	}
+	valuation.ID = id
+	valuation.CreatedAt = s.now()
+	return valuation, nil
+}
+
+func (s *NetWorthService) ListValuations(userID, assetID int) ([]domain.AssetValuation, error) {
+	if _, err := s.repo.GetAsset(assetID, userID); err != nil {
+		return nil, err
+	}
+	valuations, err := s.repo.ListValuations(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.AssetValuation{}
+	for _, v := range valuations {
+		if v.AssetID == assetID {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// Current computes net worth as of now. Transactions dated in the future
+// are not counted yet.
+func (s *NetWorthService) Current(userID int) (domain.NetWorth, error) {
+	return s.compute(userID, s.now())
+}
+
+// History returns one point per month in [from, to] for which a snapshot
+// exists. The current month is always computed live.
+func (s *NetWorthService) History(userID int, from, to time.Time) ([]domain.NetWorthSnapshot, error) {
+	first, last, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.repo.ListSnapshots(userID, monthStart(first), monthStart(last+1))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	current := monthIndex(int(now.Month()), now.Year())
+	if current < first || current > last {
+		if snapshots == nil {
+			snapshots = []domain.NetWorthSnapshot{}
+		}
+		return snapshots, nil
+	}
+
+	live, err := s.snapshot(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	series := []domain.NetWorthSnapshot{}
+	for _, snapshot := range snapshots {
+		if monthIndex(snapshot.Month, snapshot.Year) != current {
+			series = append(series, snapshot)
+		}
+	}
+	series = append(series, live)
+	sort.Slice(series, func(i, j int) bool {
+		return monthIndex(series[i].Month, series[i].Year) < monthIndex(series[j].Month, series[j].Year)
+	})
+	return series, nil
+}
+
+// SnapshotAll stores the current month's net worth of every user.
+func (s *NetWorthService) SnapshotAll() error {
+	userIDs, err := s.userRepo.ListIDs()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	var failed int
+	for _, userID := range userIDs {
+		snapshot, err := s.snapshot(userID, now)
+		if err == nil {
+			err = s.repo.SaveSnapshot(snapshot)
+		}
+		if err != nil {
+			log.Printf("Net worth snapshot failed for user %d: %v", userID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.New("some net worth snapshots failed")
+	}
+	return nil
+}
+
+// StartSnapshotJob runs SnapshotAll on every tick until stop is called.
+func (s *NetWorthService) StartSnapshotJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			if err := s.SnapshotAll(); err != nil {
+				log.Printf("Net worth snapshot job failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func (s *NetWorthService) snapshot(userID int, at time.Time) (domain.NetWorthSnapshot, error) {
+	worth, err := s.compute(userID, at)
+	if err != nil {
+		return domain.NetWorthSnapshot{}, err
+	}
+	return domain.NetWorthSnapshot{
+		UserID:      userID,
+		Year:        at.Year(),
+		Month:       int(at.Month()),
+		Accounts:    worth.Accounts,
+		Goals:       worth.Goals,
+		Assets:      worth.Assets,
+		Liabilities: worth.Liabilities,
+		NetWorth:    worth.NetWorth,
+		TakenAt:     at,
+	}, nil
+}
+
+func (s *NetWorthService) compute(userID int, at time.Time) (domain.NetWorth, error) {
+	worth := domain.NetWorth{Date: at, Lines: []domain.NetWorthLine{}}
+
+	transactions, err := s.transactionRepo.ListAllByUserID(userID)
+	if err != nil {
+		return domain.NetWorth{}, err
+	}
+	accounts := make(map[string]float64)
+	for _, t := range transactions {
+		if !t.Date.After(at) {
+			accounts[strings.TrimSpace(t.Account)] += signedAmount(t)
+		}
+	}
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		balance := roundMoney(accounts[name])
+		worth.Accounts += balance
+		worth.Lines = append(worth.Lines, domain.NetWorthLine{Source: netWorthSourceAccount, Name: name, Value: balance})
+	}
+
+	goals, err := s.goalRepo.ListByUserID(userID)
+	if err != nil {
+		return domain.NetWorth{}, err
+	}
+	for _, g := range goals {
+		if g.CurrentAmount == 0 {
+			continue
+		}
+		worth.Goals += g.CurrentAmount
+		worth.Lines = append(worth.Lines, domain.NetWorthLine{Source: netWorthSourceGoal, ID: g.ID, Name: g.Name, Value: g.CurrentAmount})
+	}
+
+	assets, err := s.repo.ListAssets(userID)
+	if err != nil {
+		return domain.NetWorth{}, err
+	}
+	valuations, err := s.repo.ListValuations(userID)
+	if err != nil {
+		return domain.NetWorth{}, err
+	}
+	latest := make(map[int]float64)
+	for _, v := range valuations {
+		if !v.Date.After(at) {
+			latest[v.AssetID] = v.Value
+		}
+	}
+	for _, a := range assets {
+		value, ok := latest[a.ID]
+		if !ok || (a.ArchivedAt != nil && !a.ArchivedAt.After(at)) {
+			continue
+		}
+		if a.Kind == domain.AssetKindLiability {
+			worth.Liabilities += value
+		} else {
+			worth.Assets += value
+		}
+		worth.Lines = append(worth.Lines, domain.NetWorthLine{Source: a.Kind, ID: a.ID, Name: a.Name, Value: value})
+	}
+
+	if s.portfolio != nil {
+		portfolio, err := s.portfolio.Portfolio(userID)
+		if err != nil {
+			return domain.NetWorth{}, err
+		}
+		if portfolio.MarketValue != 0 {
+			worth.Assets += portfolio.MarketValue
+			worth.Lines = append(worth.Lines, domain.NetWorthLine{Source: netWorthSourcePortfolio, Name: "Investimentos", Value: portfolio.MarketValue})
+		}
+	}
+
+	worth.Accounts = roundMoney(worth.Accounts)
+	worth.Goals = roundMoney(worth.Goals)
+	worth.Assets = roundMoney(worth.Assets)
+	worth.Liabilities = roundMoney(worth.Liabilities)
+	worth.NetWorth = roundMoney(worth.Accounts + worth.Goals + worth.Assets - worth.Liabilities)
+	return worth, nil
+}
+
+func normalizeAsset(asset *domain.Asset) error {
+	asset.Name = strings.TrimSpace(asset.Name)
+	asset.Category = strings.TrimSpace(asset.Category)
+	asset.Kind = strings.ToLower(strings.TrimSpace(asset.Kind))
+	if asset.Kind == "" {
+		asset.Kind = domain.AssetKindAsset
+	}
+	if asset.Name == "" || (asset.Kind != domain.AssetKindAsset && asset.Kind != domain.AssetKindLiability) {
+		return ErrInvalidAsset
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type memoryNetWorthRepository struct {
+	assets     []domain.Asset
+	valuations []domain.AssetValuation
+	snapshots  []domain.NetWorthSnapshot
+}
+
+func (m *memoryNetWorthRepository) SaveAsset(a domain.Asset, events ...domain.Event) (int, error) {
+	a.ID = len(m.assets) + 1
+	m.assets = append(m.assets, a)
+	return a.ID, nil
+}
+
+func (m *memoryNetWorthRepository) UpdateAsset(a domain.Asset, events ...domain.Event) error {
+	for i := range m.assets {
+		if m.assets[i].ID == a.ID {
+			m.assets[i].Name, m.assets[i].Kind, m.assets[i].Category = a.Name, a.Kind, a.Category
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryNetWorthRepository) ArchiveAsset(id, userID int, events ...domain.Event) error {
+	for i := range m.assets {
+		if m.assets[i].ID == id && m.assets[i].ArchivedAt == nil {
+			now := time.Now()
+			m.assets[i].ArchivedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryNetWorthRepository) GetAsset(id, userID int) (domain.Asset, error) {
+	for _, a := range m.assets {
+		if a.ID == id && a.UserID == userID {
+			return a, nil
+		}
+	}
+	return domain.Asset{}, sql.ErrNoRows
+}
+
+func (m *memoryNetWorthRepository) ListAssets(userID int) ([]domain.Asset, error) {
+	return m.assets, nil
+}
+
+func (m *memoryNetWorthRepository) SaveValuation(v domain.AssetValuation, events ...domain.Event) (int, error) {
+	v.ID = len(m.valuations) + 1
+	m.valuations = append(m.valuations, v)
+	return v.ID, nil
+}
+
+func (m *memoryNetWorthRepository) ListValuations(userID int) ([]domain.AssetValuation, error) {
+	return m.valuations, nil
+}
+
+func (m *memoryNetWorthRepository) SaveSnapshot(s domain.NetWorthSnapshot) error {
+	for i := range m.snapshots {
+		if m.snapshots[i].UserID == s.UserID && m.snapshots[i].Year == s.Year && m.snapshots[i].Month == s.Month {
+			m.snapshots[i] = s
+			return nil
+		}
+	}
+	m.snapshots = append(m.snapshots, s)
+	return nil
+}
+
+func (m *memoryNetWorthRepository) ListSnapshots(userID int, from, to time.Time) ([]domain.NetWorthSnapshot, error) {
+	var result []domain.NetWorthSnapshot
+	for _, s := range m.snapshots {
+		start := time.Date(s.Year, time.Month(s.Month), 1, 0, 0, 0, 0, time.UTC)
+		if s.UserID == userID && !start.Before(from) && start.Before(to) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+type staticUsers struct {
+	ports.UserRepository
+	ids []int
+}
+
+func (u staticUsers) ListIDs() ([]int, error) {
+	return u.ids, nil
+}
+
+func TestNetWorth_CombinesAccountsGoalsAndValuations(t *testing.T) {
+	repo := &memoryNetWorthRepository{}
+	txs := &envelopeTransactions{transactions: []domain.Transaction{
+		{UserID: 1, Type: "income", Amount: 5000, Account: "Nubank", Date: day(1, 5)},
+		{UserID: 1, Type: "expense", Amount: 1200, Account: "Nubank", Date: day(1, 10)},
+		{UserID: 1, Type: "income", Amount: 800, Account: "Itaú", Date: day(2, 5)},
+		{UserID: 1, Type: "expense", Amount: 300, Account: "Nubank", Date: day(4, 1)},
+	}}
+	goals := &MockGoalRepository{}
+	goals.Save(domain.Goal{UserID: 1, Name: "Reserva", CurrentAmount: 2000})
+
+	service := NewNetWorthService(repo, txs, goals, nil)
+	service.now = func() time.Time { return day(2, 20) }
+	ctx := context.Background()
+
+	valuedAt := day(1, 1)
+	car, err := service.CreateAsset(ctx, 1, domain.Asset{Name: "Carro", Category: "vehicle", Value: 40000, ValuedAt: &valuedAt})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AssetKindAsset, car.Kind)
+	_, err = service.CreateAsset(ctx, 1, domain.Asset{Name: "Financiamento", Kind: "Liability", Value: 15000, ValuedAt: &valuedAt})
+	assert.NoError(t, err)
+
+	_, err = service.AddValuation(ctx, 1, car.ID, 38000, day(2, 15))
+	assert.NoError(t, err)
+	_, err = service.AddValuation(ctx, 1, car.ID, 30000, day(6, 1))
+	assert.NoError(t, err)
+
+	worth, err := service.Current(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4600.0, worth.Accounts)
+	assert.Equal(t, 2000.0, worth.Goals)
+	assert.Equal(t, 38000.0, worth.Assets)
+	assert.Equal(t, 15000.0, worth.Liabilities)
+	assert.Equal(t, 29600.0, worth.NetWorth)
+	assert.Len(t, worth.Lines, 5)
+}
+
+func TestNetWorth_SnapshotsAndHistory(t *testing.T) {
+	repo := &memoryNetWorthRepository{}
+	txs := &envelopeTransactions{transactions: []domain.Transaction{
+		{UserID: 1, Type: "income", Amount: 1000, Date: day(1, 5)},
+		{UserID: 1, Type: "income", Amount: 500, Date: day(3, 5)},
+	}}
+	service := NewNetWorthService(repo, txs, &MockGoalRepository{}, staticUsers{ids: []int{1}})
+
+	service.now = func() time.Time { return day(1, 20) }
+	assert.NoError(t, service.SnapshotAll())
+	service.now = func() time.Time { return day(1, 31) }
+	assert.NoError(t, service.SnapshotAll())
+	assert.Len(t, repo.snapshots, 1)
+
+	service.now = func() time.Time { return day(3, 10) }
+	history, err := service.History(1, day(1, 1), day(3, 1))
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, 1, history[0].Month)
+	assert.Equal(t, 1000.0, history[0].NetWorth)
+	assert.Equal(t, 3, history[1].Month)
+	assert.Equal(t, 1500.0, history[1].NetWorth)
+
+	_, err = service.History(1, day(3, 1), day(1, 1))
+	assert.ErrorIs(t, err, ErrInvalidReportRange)
+}
+
+func TestNetWorth_ArchivedAssetRejectsValuations(t *testing.T) {
+	repo := &memoryNetWorthRepository{}
+	service := NewNetWorthService(repo, &envelopeTransactions{}, &MockGoalRepository{}, nil)
+	ctx := context.Background()
+
+	_, err := service.CreateAsset(ctx, 1, domain.Asset{Name: "Casa", Kind: "house"})
+	assert.ErrorIs(t, err, ErrInvalidAsset)
+
+	house, err := service.CreateAsset(ctx, 1, domain.Asset{Name: "Casa", Value: 300000})
+	assert.NoError(t, err)
+	assert.NoError(t, service.DeleteAsset(ctx, 1, house.ID))
+
+	_, err = service.AddValuation(ctx, 1, house.ID, 310000, time.Time{})
+	assert.ErrorIs(t, err, ErrAssetArchived)
+
+	assets, err := service.ListAssets(1)
+	assert.NoError(t, err)
+	assert.Empty(t, assets)
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var (
+	ErrInvalidNotificationSettings = domain.Invalid("invalid_notification_settings", "settings need quiet hours as HH:MM (both or neither), a valid timezone and known event types and channels")
+	ErrInvalidPushSubscription     = domain.Invalid("invalid_push_subscription", "push subscription needs an https endpoint and its p256dh and auth keys")
+)
+
+// defaultNotificationTimezone is used for quiet hours until a user picks one.
+const defaultNotificationTimezone = "America/Sao_Paulo"
+
+// Deliveries are retried with a growing backoff and given up after
+// maxDeliveryAttempts; each dispatch run handles up to deliveryBatchSize.
+const (
+	maxDeliveryAttempts = 5
+	deliveryBackoff     = time.Minute
+	deliveryBatchSize   = 100
+)
+
+type NotificationService struct {
+	repo     ports.NotificationRepository
+	users    ports.UserRepository
+	channels map[string]ports.NotificationChannel
+	audit    ports.AuditService
+	now      func() time.Time
+	wake     chan struct{}
+}
+
+func NewNotificationService(repo ports.NotificationRepository, users ports.UserRepository) *NotificationService {
+	return &NotificationService{
+		repo:     repo,
+		users:    users,
+		channels: map[string]ports.NotificationChannel{},
+		now:      time.Now,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// AddChannel enables an external channel; notifications are queued only for
+// channels that were added.
+func (s *NotificationService) AddChannel(channel ports.NotificationChannel) {
+	s.channels[channel.Name()] = channel
+}
+
+// SetAuditService records every change made through this service.
+func (s *NotificationService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// Notify saves the notification to the inbox and queues it for the user's
+// enabled channels. A notification whose dedup key the user already has is
+// dropped, so callers can raise the same event as often as they see it.
+func (s *NotificationService) Notify(ctx context.Context, notification domain.Notification) error {
+	if notification.DedupKey == "" {
+		return errors.New("notification needs a dedup key")
+	}
+	settings, err := s.repo.GetSettings(notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	notification.CreatedAt = now
+	inApp := settings.Enabled(notification.EventType, domain.ChannelInApp)
+	id, created, err := s.repo.SaveNotification(notification, inApp)
+	if err != nil || !created {
+		return err
+	}
+	notification.ID = id
+
+	sendAfter := quietHoursEnd(settings, now)
+	queued := false
+	for _, name := range domain.NotificationChannels {
+		if _, ok := s.channels[name]; !ok || !settings.Enabled(notification.EventType, name) {
+			continue
+		}
+		delivery := domain.NotificationDelivery{
+			Notification: notification,
+			Channel:      name,
+			SendAfter:    sendAfter,
+			Status:       domain.DeliveryPending,
+		}
+		if err := s.repo.SaveDelivery(delivery); err != nil {
+			return err
+		}
+		queued = true
+	}
+	if queued && !sendAfter.After(now) {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *NotificationService) Inbox(userID int, unreadOnly bool) (domain.NotificationInbox, error) {
+	notifications, err := s.repo.ListNotifications(userID, unreadOnly)
+	if err != nil {
+		return domain.NotificationInbox{}, err
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return domain.NotificationInbox{}, err
+	}
+	return domain.NotificationInbox{Notifications: notifications, Unread: unread}, nil
+}
+
+// MarkRead marks the given notifications read, or every one when ids is
+// empty, and returns how many changed.
+func (s *NotificationService) MarkRead(userID int, ids []int) (int, error) {
+	return s.repo.MarkRead(userID, ids)
+}
+
+func (s *NotificationService) Settings(userID int) (domain.NotificationSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return domain.NotificationSettings{}, err
+	}
+	if settings.Timezone == "" {
+		settings.Timezone = defaultNotificationTimezone
+	}
+	if settings.Preferences == nil {
+		settings.Preferences = []domain.NotificationPreference{}
+	}
+	return settings, nil
+}
+
+// UpdateSettings replaces quiet hours and preferences. Preferences left out
+// fall back to enabled.
+func (s *NotificationService) UpdateSettings(ctx context.Context, userID int, settings domain.NotificationSettings) (domain.NotificationSettings, error) {
+	settings.UserID = userID
+	if err := normalizeNotificationSettings(&settings); err != nil {
+		return domain.NotificationSettings{}, err
+	}
+	before, err := s.Settings(userID)
+	if err != nil {
+		return domain.NotificationSettings{}, err
+	}
+	if err := s.repo.SaveSettings(settings, auditEvents(s.audit, ctx, userID, domain.AuditEntityNotificationSettings, userID, domain.AuditActionUpdate, before, settings)...); err != nil {
+		return domain.NotificationSettings{}, err
+	}
+	return settings, nil
+}
+
+func (s *NotificationService) SubscribePush(ctx context.Context, userID int, subscription domain.PushSubscription) error {
+	subscription.UserID = userID
+	subscription.Endpoint = strings.TrimSpace(subscription.Endpoint)
+	if !strings.HasPrefix(subscription.Endpoint, "https://") || subscription.P256dh == "" || subscription.Auth == "" {
+		return ErrInvalidPushSubscription
+	}
+	return s.repo.SavePushSubscription(subscription)
+}
+
+func (s *NotificationService) UnsubscribePush(ctx context.Context, userID int, endpoint string) error {
+	return s.repo.DeletePushSubscriptions(userID, []string{endpoint})
+}
+
+// Dispatch sends the deliveries that are due. Failures are retried later;
+// push subscriptions the push service rejects as gone are removed.
+func (s *NotificationService) Dispatch(ctx context.Context) error {
+	deliveries, err := s.repo.ListDueDeliveries(s.now(), deliveryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	recipients := map[int]domain.NotificationRecipient{}
+	for _, d := range deliveries {
+		channel, ok := s.channels[d.Channel]
+		if !ok {
+			continue
+		}
+		to, ok := recipients[d.Notification.UserID]
+		if !ok {
+			if to, err = s.recipient(d.Notification.UserID); err != nil {
+				return err
+			}
+			recipients[d.Notification.UserID] = to
+		}
+
+		err := channel.Deliver(ctx, to, d.Notification)
+		var expired *ports.PushExpiredError
+		if errors.As(err, &expired) {
+			if err := s.repo.DeletePushSubscriptions(to.UserID, expired.Endpoints); err != nil {
+				return err
+			}
+			delete(recipients, to.UserID)
+			// Nothing to retry when the only failures were subscriptions now gone.
+			if _, only := err.(*ports.PushExpiredError); only {
+				err = nil
+			}
+		}
+
+		d.Attempts++
+		switch {
+		case err == nil:
+			d.Status = domain.DeliverySent
+			d.LastError = ""
+		case d.Attempts >= maxDeliveryAttempts:
+			d.Status = domain.DeliveryFailed
+			d.LastError = err.Error()
+		default:
+			d.SendAfter = s.now().Add(deliveryBackoff << (d.Attempts - 1))
+			d.LastError = err.Error()
+		}
+		if err != nil {
+			log.Printf("notifications: %s delivery %d attempt %d failed: %v", d.Channel, d.ID, d.Attempts, err)
+		}
+		if err := s.repo.UpdateDelivery(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartDispatchJob runs Dispatch on every tick and right after Notify queues
+// something that is due now, until stop is called.
+func (s *NotificationService) StartDispatchJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			if err := s.Dispatch(context.Background()); err != nil {
+				log.Printf("Notification dispatch job failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func (s *NotificationService) recipient(userID int) (domain.NotificationRecipient, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return domain.NotificationRecipient{}, err
+	}
+	subscriptions, err := s.repo.ListPushSubscriptions(userID)
+	if err != nil {
+		return domain.NotificationRecipient{}, err
+	}
+	return domain.NotificationRecipient{UserID: userID, Email: user.Email, PushSubscriptions: subscriptions}, nil
+}
+
+// notify raises a notification on behalf of another service, which carries
+// on if it cannot.
+func notify(notifications ports.NotificationService, ctx context.Context, notification domain.Notification) {
+	if notifications == nil {
+		return
+	}
+	if err := notifications.Notify(ctx, notification); err != nil {
+		log.Printf("notifications: could not raise %s for user %d: %v", notification.DedupKey, notification.UserID, err)
+	}
+}
+
+// quietHoursEnd returns when external deliveries raised at now may go out:
+// now itself outside quiet hours, or the moment they end.
+func quietHoursEnd(settings domain.NotificationSettings, now time.Time) time.Time {
+	start, okStart := parseClock(settings.QuietHoursStart)
+	end, okEnd := parseClock(settings.QuietHoursEnd)
+	if !okStart || !okEnd || start == end {
+		return now
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if settings.Timezone == "" || err != nil {
+		loc, err = time.LoadLocation(defaultNotificationTimezone)
+		if err != nil {
+			return now
+		}
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	switch {
+	case start < end && minute >= start && minute < end:
+		return midnight.Add(time.Duration(end) * time.Minute)
+	case start > end && minute >= start:
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute)
+	case start > end && minute < end:
+		return midnight.Add(time.Duration(end) * time.Minute)
+	}
+	return now
+}
+
+// parseClock turns "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func normalizeNotificationSettings(settings *domain.NotificationSettings) error {
+	settings.QuietHoursStart = strings.TrimSpace(settings.QuietHoursStart)
+	settings.QuietHoursEnd = strings.TrimSpace(settings.QuietHoursEnd)
+	settings.Timezone = strings.TrimSpace(settings.Timezone)
+	if settings.Timezone == "" {
+		settings.Timezone = defaultNotificationTimezone
+	}
+
+	if (settings.QuietHoursStart == "") != (settings.QuietHoursEnd == "") {
+		return ErrInvalidNotificationSettings
+	}
+	if settings.QuietHoursStart != "" {
+		if _, ok := parseClock(settings.QuietHoursStart); !ok {
+			return ErrInvalidNotificationSettings
+		}
+		if _, ok := parseClock(settings.QuietHoursEnd); !ok {
+			return ErrInvalidNotificationSettings
+		}
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return ErrInvalidNotificationSettings
+	}
+
+	seen := map[[2]string]bool{}
+	preferences := []domain.NotificationPreference{}
+	for _, p := range settings.Preferences {
+		if !slices.Contains(domain.NotificationEvents, p.EventType) || !slices.Contains(domain.NotificationChannels, p.Channel) {
+			return ErrInvalidNotificationSettings
+		}
+		key := [2]string{p.EventType, p.Channel}
+		if seen[key] {
+			return ErrInvalidNotificationSettings
+		}
+		seen[key] = true
+		preferences = append(preferences, p)
+	}
+	settings.Preferences = preferences
+	return nil
+}
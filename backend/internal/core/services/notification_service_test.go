@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+type memoryNotificationRepository struct {
+	notifications []domain.Notification
+	hidden        map[int]bool
+	settings      map[int]domain.NotificationSettings
+	subscriptions []domain.PushSubscription
+	deliveries    []domain.NotificationDelivery
+}
+
+func newMemoryNotificationRepository() *memoryNotificationRepository {
+	return &memoryNotificationRepository{hidden: map[int]bool{}, settings: map[int]domain.NotificationSettings{}}
+}
+
+func (m *memoryNotificationRepository) SaveNotification(n domain.Notification, inApp bool) (int, bool, error) {
+	for _, existing := range m.notifications {
+		if existing.UserID == n.UserID && existing.DedupKey == n.DedupKey {
+			return 0, false, nil
+		}
+	}
+	n.ID = len(m.notifications) + 1
+	m.notifications = append(m.notifications, n)
+	m.hidden[n.ID] = !inApp
+	return n.ID, true, nil
+}
+
+func (m *memoryNotificationRepository) ListNotifications(userID int, unreadOnly bool) ([]domain.Notification, error) {
+	var result []domain.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID && !m.hidden[n.ID] && (!unreadOnly || n.ReadAt == nil) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryNotificationRepository) CountUnread(userID int) (int, error) {
+	unread, _ := m.ListNotifications(userID, true)
+	return len(unread), nil
+}
+
+func (m *memoryNotificationRepository) MarkRead(userID int, ids []int) (int, error) {
+	now := time.Now()
+	count := 0
+	for i, n := range m.notifications {
+		if n.UserID != userID || n.ReadAt != nil || (len(ids) > 0 && !containsInt(ids, n.ID)) {
+			continue
+		}
+		m.notifications[i].ReadAt = &now
+		count++
+	}
+	return count, nil
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryNotificationRepository) GetSettings(userID int) (domain.NotificationSettings, error) {
+	if s, ok := m.settings[userID]; ok {
+		return s, nil
+	}
+	return domain.NotificationSettings{UserID: userID}, nil
+}
+
+func (m *memoryNotificationRepository) SaveSettings(s domain.NotificationSettings, events ...domain.Event) error {
+	m.settings[s.UserID] = s
+	return nil
+}
+
+func (m *memoryNotificationRepository) SavePushSubscription(p domain.PushSubscription) error {
+	m.subscriptions = append(m.subscriptions, p)
+	return nil
+}
+
+func (m *memoryNotificationRepository) DeletePushSubscriptions(userID int, endpoints []string) error {
+	kept := m.subscriptions[:0]
+	for _, p := range m.subscriptions {
+		gone := false
+		for _, e := range endpoints {
+			gone = gone || (p.UserID == userID && p.Endpoint == e)
+		}
+		if !gone {
+			kept = append(kept, p)
+		}
+	}
+	m.subscriptions = kept
+	return nil
+}
+
+func (m *memoryNotificationRepository) ListPushSubscriptions(userID int) ([]domain.PushSubscription, error) {
+	var result []domain.PushSubscription
+	for _, p := range m.subscriptions {
+		if p.UserID == userID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryNotificationRepository) SaveDelivery(d domain.NotificationDelivery) error {
+	d.ID = len(m.deliveries) + 1
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memoryNotificationRepository) ListDueDeliveries(now time.Time, limit int) ([]domain.NotificationDelivery, error) {
+	var result []domain.NotificationDelivery
+	for _, d := range m.deliveries {
+		if d.Status == domain.DeliveryPending && !d.SendAfter.After(now) && len(result) < limit {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryNotificationRepository) UpdateDelivery(d domain.NotificationDelivery) error {
+	m.deliveries[d.ID-1] = d
+	return nil
+}
+
+type emailUsers struct {
+	ports.UserRepository
+}
+
+func (emailUsers) GetByID(id int) (domain.User, error) {
+	return domain.User{ID: id, Email: "ana@example.com"}, nil
+}
+
+// recordingChannel remembers what it delivered and fails with err.
+type recordingChannel struct {
+	name      string
+	err       error
+	delivered []domain.Notification
+	to        []domain.NotificationRecipient
+}
+
+func (c *recordingChannel) Name() string {
+	return c.name
+}
+
+func (c *recordingChannel) Deliver(ctx context.Context, to domain.NotificationRecipient, n domain.Notification) error {
+	c.to = append(c.to, to)
+	if c.err != nil {
+		return c.err
+	}
+	c.delivered = append(c.delivered, n)
+	return nil
+}
+
+func newTestNotificationService(now time.Time) (*NotificationService, *memoryNotificationRepository, *recordingChannel, *recordingChannel) {
+	repo := newMemoryNotificationRepository()
+	service := NewNotificationService(repo, emailUsers{})
+	service.now = func() time.Time { return now }
+	email := &recordingChannel{name: domain.ChannelEmail}
+	push := &recordingChannel{name: domain.ChannelPush}
+	service.AddChannel(email)
+	service.AddChannel(push)
+	return service, repo, email, push
+}
+
+func TestNotify_DeduplicatesAndQueuesEveryChannel(t *testing.T) {
+	service, repo, email, push := newTestNotificationService(time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC))
+	n := domain.Notification{UserID: 1, EventType: domain.NotificationBillOverdue, Title: "Luz is overdue", DedupKey: "bill_overdue:4"}
+
+	assert.NoError(t, service.Notify(context.Background(), n))
+	assert.NoError(t, service.Notify(context.Background(), n))
+
+	inbox, err := service.Inbox(1, false)
+	assert.NoError(t, err)
+	assert.Len(t, inbox.Notifications, 1)
+	assert.Equal(t, 1, inbox.Unread)
+	assert.Len(t, repo.deliveries, 2)
+
+	assert.NoError(t, service.Dispatch(context.Background()))
+	assert.Len(t, email.delivered, 1)
+	assert.Len(t, push.delivered, 1)
+	assert.Equal(t, "ana@example.com", email.to[0].Email)
+
+	read, err := service.MarkRead(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, read)
+	inbox, _ = service.Inbox(1, true)
+	assert.Empty(t, inbox.Notifications)
+}
+
+func TestNotify_HonoursPreferences(t *testing.T) {
+	service, repo, _, _ := newTestNotificationService(time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC))
+	_, err := service.UpdateSettings(context.Background(), 1, domain.NotificationSettings{
+		Preferences: []domain.NotificationPreference{
+			{EventType: domain.NotificationBudgetAlert, Channel: domain.ChannelInApp, Enabled: false},
+			{EventType: domain.NotificationBudgetAlert, Channel: domain.ChannelEmail, Enabled: false},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.Notify(context.Background(), domain.Notification{UserID: 1, EventType: domain.NotificationBudgetAlert, Title: "Mercado: 80%", DedupKey: "budget_alert:mercado:2026-03:80"}))
+
+	inbox, _ := service.Inbox(1, false)
+	assert.Empty(t, inbox.Notifications)
+	if assert.Len(t, repo.deliveries, 1) {
+		assert.Equal(t, domain.ChannelPush, repo.deliveries[0].Channel)
+	}
+}
+
+func TestNotify_HoldsDeliveriesDuringQuietHours(t *testing.T) {
+	// 22:30 in São Paulo (UTC-3), inside quiet hours that end at 07:00.
+	now := time.Date(2026, 3, 11, 1, 30, 0, 0, time.UTC)
+	service, repo, email, _ := newTestNotificationService(now)
+	_, err := service.UpdateSettings(context.Background(), 1, domain.NotificationSettings{QuietHoursStart: "22:00", QuietHoursEnd: "07:00"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.Notify(context.Background(), domain.Notification{UserID: 1, EventType: domain.NotificationGoalCompleted, Title: "Viagem", DedupKey: "goal_completed:2"}))
+	inbox, _ := service.Inbox(1, false)
+	assert.Len(t, inbox.Notifications, 1)
+	assert.True(t, repo.deliveries[0].SendAfter.Equal(time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)))
+
+	assert.NoError(t, service.Dispatch(context.Background()))
+	assert.Empty(t, email.delivered)
+
+	service.now = func() time.Time { return time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC) }
+	assert.NoError(t, service.Dispatch(context.Background()))
+	assert.Len(t, email.delivered, 1)
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	settings := domain.NotificationSettings{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "UTC"}
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 10, h, m, 0, 0, time.UTC) }
+
+	assert.Equal(t, at(12, 0), quietHoursEnd(settings, at(12, 0)))
+	assert.Equal(t, at(7, 0).AddDate(0, 0, 1), quietHoursEnd(settings, at(23, 15)))
+	assert.Equal(t, at(7, 0), quietHoursEnd(settings, at(6, 59)))
+	assert.Equal(t, at(7, 0), quietHoursEnd(settings, at(7, 0)))
+
+	settings.QuietHoursStart, settings.QuietHoursEnd = "12:00", "14:00"
+	assert.Equal(t, at(14, 0), quietHoursEnd(settings, at(13, 0)))
+	assert.Equal(t, at(11, 0), quietHoursEnd(settings, at(11, 0)))
+}
+
+func TestDispatch_RetriesAndDropsExpiredSubscriptions(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	service, repo, email, push := newTestNotificationService(now)
+	repo.subscriptions = []domain.PushSubscription{{UserID: 1, Endpoint: "https://push.example/gone"}}
+	push.err = &ports.PushExpiredError{Endpoints: []string{"https://push.example/gone"}}
+	email.err = errors.New("connection refused")
+
+	assert.NoError(t, service.Notify(context.Background(), domain.Notification{UserID: 1, EventType: domain.NotificationBillDue, Title: "Luz", DedupKey: "bill_due:4"}))
+	assert.NoError(t, service.Dispatch(context.Background()))
+
+	assert.Empty(t, repo.subscriptions)
+	assert.Equal(t, domain.DeliveryPending, repo.deliveries[0].Status)
+	assert.Equal(t, 1, repo.deliveries[0].Attempts)
+	assert.True(t, repo.deliveries[0].SendAfter.Equal(now.Add(time.Minute)))
+	assert.Equal(t, domain.DeliverySent, repo.deliveries[1].Status)
+
+	for i := 1; i < maxDeliveryAttempts; i++ {
+		later := repo.deliveries[0].SendAfter
+		service.now = func() time.Time { return later }
+		assert.NoError(t, service.Dispatch(context.Background()))
+	}
+	assert.Equal(t, domain.DeliveryFailed, repo.deliveries[0].Status)
+	assert.Equal(t, maxDeliveryAttempts, repo.deliveries[0].Attempts)
+	assert.Equal(t, "connection refused", repo.deliveries[0].LastError)
+}
+
+func TestUpdateNotificationSettings_Validates(t *testing.T) {
+	service, _, _, _ := newTestNotificationService(time.Now())
+	invalid := []domain.NotificationSettings{
+		{QuietHoursStart: "22:00"},
+		{QuietHoursStart: "25:00", QuietHoursEnd: "07:00"},
+		{Timezone: "Mars/Olympus"},
+		{Preferences: []domain.NotificationPreference{{EventType: "login", Channel: domain.ChannelEmail}}},
+		{Preferences: []domain.NotificationPreference{{EventType: domain.NotificationBillDue, Channel: "sms"}}},
+	}
+	for _, settings := range invalid {
+		_, err := service.UpdateSettings(context.Background(), 1, settings)
+		assert.ErrorIs(t, err, ErrInvalidNotificationSettings)
+	}
+
+	settings, err := service.Settings(1)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultNotificationTimezone, settings.Timezone)
+}
+
+func TestRefreshStatuses_NotifiesDueAndOverdueBills(t *testing.T) {
+	repo := &memoryBillRepository{}
+	bills := NewBillService(repo, nil)
+	today := utcDate(2026, 3, 10) // Tuesday
+	bills.now = func() time.Time { return today }
+	notifications, notificationRepo, _, _ := newTestNotificationService(today)
+	bills.SetNotificationService(notifications)
+
+	repo.Save(domain.Bill{UserID: 1, Payee: "Luz", Amount: 180, DueDate: utcDate(2026, 3, 6), Status: domain.BillStatusOpen})
+	repo.Save(domain.Bill{UserID: 1, Payee: "Internet", Amount: 120, DueDate: utcDate(2026, 3, 12), Status: domain.BillStatusOpen})
+	repo.Save(domain.Bill{UserID: 1, Payee: "Aluguel", Amount: 2500, DueDate: utcDate(2026, 3, 20), Status: domain.BillStatusOpen})
+
+	assert.NoError(t, bills.RefreshStatuses())
+	assert.NoError(t, bills.RefreshStatuses())
+
+	var keys []string
+	for _, n := range notificationRepo.notifications {
+		keys = append(keys, n.DedupKey)
+	}
+	assert.Equal(t, []string{"bill_overdue:1", "bill_due:2"}, keys)
+	assert.Equal(t, domain.BillStatusOverdue, repo.bills[0].Status)
+}
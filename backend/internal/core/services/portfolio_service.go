@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidHolding       = domain.Invalid("invalid_holding", "holding needs a ticker and a known asset class")
+	ErrInvalidOperation     = domain.Invalid("invalid_portfolio_operation", "operation needs a holding, a type of buy, sell or dividend and positive values")
+	ErrInsufficientPosition = domain.Conflict("insufficient_position", "cannot sell more than the position held at that date")
+	ErrHoldingNotEmpty      = domain.Conflict("holding_not_empty", "sell the whole position before deleting the holding")
+	ErrInvalidPrice         = domain.Invalid("invalid_price", "price must not be negative")
+)
+
+var assetClasses = []string{
+	domain.AssetClassTesouroDireto,
+	domain.AssetClassCDB,
+	domain.AssetClassStock,
+	domain.AssetClassFII,
+	domain.AssetClassOther,
+}
+
+// quantityEpsilon absorbs float noise when a position is sold in parts.
+const quantityEpsilon = 1e-9
+
+type PortfolioService struct {
+	repo  ports.PortfolioRepository
+	audit ports.AuditService
+	now   func() time.Time
+}
+
+func NewPortfolioService(repo ports.PortfolioRepository) *PortfolioService {
+	return &PortfolioService{repo: repo, now: time.Now}
+}
+
+// SetAuditService records every change made through this service.
+func (s *PortfolioService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+func (s *PortfolioService) CreateHolding(ctx context.Context, userID int, holding domain.Holding) (domain.Holding, error) {
+	holding.UserID = userID
+	holding.Ticker = strings.ToUpper(strings.TrimSpace(holding.Ticker))
+	holding.Name = strings.TrimSpace(holding.Name)
+	holding.AssetClass = strings.ToLower(strings.TrimSpace(holding.AssetClass))
+	err := validation.Validate(ErrInvalidHolding,
+		validation.Field("ticker", holding.Ticker, validation.Required),
+		validation.Field("asset_class", holding.AssetClass, validation.OneOf(assetClasses...)),
+	)
+	if err != nil {
+		return domain.Holding{}, err
+	}
+
+	holding.CreatedAt = s.now()
+	id, err := s.repo.SaveHolding(holding, auditEvents(s.audit, ctx, userID, domain.AuditEntityHolding, 0, domain.AuditActionCreate, nil, holding)...)
+	if err != nil {
+		return domain.Holding{}, err
+	}
+	holding.ID = id
+	return holding, nil
+}
+
+func (s *PortfolioService) ListHoldings(userID int) ([]domain.Holding, error) {
+	holdings, err := s.repo.ListHoldings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if holdings == nil {
+		holdings = []domain.Holding{}
+	}
+	return holdings, nil
+}
+
+// DeleteHolding removes a holding with no open position, together with its
+// operations and prices.
+func (s *PortfolioService) DeleteHolding(ctx context.Context, userID, id int) error {
+	holding, err := s.repo.GetHolding(id, userID)
+	if err != nil {
+		return err
+	}
+	operations, err := s.repo.ListOperations(userID)
+	if err != nil {
+		return err
+	}
+	position, err := replayPosition(holding, operations)
+	if err != nil {
+		return err
+	}
+	if position.Quantity > quantityEpsilon {
+		return ErrHoldingNotEmpty
+	}
+
+	return s.repo.DeleteHolding(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityHolding, id, domain.AuditActionDelete, holding, nil)...)
+}
+
+// RecordOperation saves a buy, sell or dividend. Operations may be entered
+// out of order, so the holding's history is replayed to make sure no sell
+// exceeds the quantity held at its date.
+func (s *PortfolioService) RecordOperation(ctx context.Context, userID int, operation domain.InvestmentOperation) (domain.InvestmentOperation, error) {
+	operation.UserID = userID
+	if err := normalizeOperation(&operation); err != nil {
+		return domain.InvestmentOperation{}, err
+	}
+	if operation.Date.IsZero() {
+		operation.Date = dayOf(s.now())
+	}
+
+	holding, err := s.repo.GetHolding(operation.HoldingID, userID)
+	if err != nil {
+		return domain.InvestmentOperation{}, err
+	}
+	operations, err := s.repo.ListOperations(userID)
+	if err != nil {
+		return domain.InvestmentOperation{}, err
+	}
+	if _, err := replayPosition(holding, append(operations, operation)); err != nil {
+		return domain.InvestmentOperation{}, err
+	}
+
+	operation.CreatedAt = s.now()
+	id, err := s.repo.SaveOperation(operation, auditEvents(s.audit, ctx, userID, domain.AuditEntityHolding, holding.ID, domain.AuditActionCreate, nil, operation)...)
+	if err != nil {
+		return domain.InvestmentOperation{}, err
+	}
+	operation.ID = id
+	return operation, nil
+}
+
+// DeleteOperation refuses to remove a buy that a later sell depends on.
+func (s *PortfolioService) DeleteOperation(ctx context.Context, userID, id int) error {
+	operations, err := s.repo.ListOperations(userID)
+	if err != nil {
+		return err
+	}
+
+	var deleted *domain.InvestmentOperation
+	remaining := make([]domain.InvestmentOperation, 0, len(operations))
+	for i := range operations {
+		if operations[i].ID == id {
+			deleted = &operations[i]
+			continue
+		}
+		remaining = append(remaining, operations[i])
+	}
+	if deleted == nil {
+		return sql.ErrNoRows
+	}
+
+	holding, err := s.repo.GetHolding(deleted.HoldingID, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := replayPosition(holding, remaining); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteOperation(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityHolding, holding.ID, domain.AuditActionDelete, *deleted, nil)...)
+}
+
+// ListOperations returns the operations of one holding, or of all holdings
+// when holdingID is 0.
+func (s *PortfolioService) ListOperations(userID, holdingID int) ([]domain.InvestmentOperation, error) {
+	operations, err := s.repo.ListOperations(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.InvestmentOperation{}
+	for _, o := range operations {
+		if holdingID == 0 || o.HoldingID == holdingID {
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+func (s *PortfolioService) SetPrice(ctx context.Context, userID, holdingID int, price float64, date time.Time) (domain.PriceSnapshot, error) {
+	if err := validation.Validate(ErrInvalidPrice, validation.Field("price", price, validation.Min(0.0))); err != nil {
+		return domain.PriceSnapshot{}, err
+	}
+	if _, err := s.repo.GetHolding(holdingID, userID); err != nil {
+		return domain.PriceSnapshot{}, err
+	}
+	if date.IsZero() {
+		date = s.now()
+	}
+
+	snapshot := domain.PriceSnapshot{HoldingID: holdingID, UserID: userID, Price: price, Date: dayOf(date), Source: domain.PriceSourceManual}
+	if err := s.repo.SavePrices([]domain.PriceSnapshot{snapshot}); err != nil {
+		return domain.PriceSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// ImportPrices reads "ticker,date,price" lines. The separator may be a
+// comma or a semicolon, dates YYYY-MM-DD or DD/MM/YYYY and prices may use a
+// decimal comma, so broker and B3 exports load as they are. A header line
+// is skipped. Lines with unknown tickers or bad values are reported and
+// skipped; the rest is saved in one go.
+func (s *PortfolioService) ImportPrices(ctx context.Context, userID int, file io.Reader) (domain.PriceImport, error) {
+	holdings, err := s.repo.ListHoldings(userID)
+	if err != nil {
+		return domain.PriceImport{}, err
+	}
+	byTicker := make(map[string]int, len(holdings))
+	for _, h := range holdings {
+		byTicker[h.Ticker] = h.ID
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return domain.PriceImport{}, err
+	}
+	reader := newImportReader(string(content))
+
+	result := domain.PriceImport{Errors: []domain.PriceImportError{}}
+	var prices []domain.PriceSnapshot
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, domain.PriceImportError{Line: line, Message: err.Error()})
+			continue
+		}
+		if len(record) < 3 {
+			result.Errors = append(result.Errors, domain.PriceImportError{Line: line, Message: "expected ticker, date and price"})
+			continue
+		}
+
+		ticker := strings.ToUpper(strings.TrimSpace(record[0]))
+		date, dateErr := parseImportDate(record[1])
+		price, priceErr := parseImportPrice(record[2])
+		if line == 1 && (dateErr != nil || priceErr != nil) {
+			continue // header
+		}
+
+		holdingID, ok := byTicker[ticker]
+		switch {
+		case !ok:
+			result.Errors = append(result.Errors, domain.PriceImportError{Line: line, Message: fmt.Sprintf("unknown ticker %q", ticker)})
+		case dateErr != nil:
+			result.Errors = append(result.Errors, domain.PriceImportError{Line: line, Message: dateErr.Error()})
+		case priceErr != nil:
+			result.Errors = append(result.Errors, domain.PriceImportError{Line: line, Message: priceErr.Error()})
+		default:
+			prices = append(prices, domain.PriceSnapshot{HoldingID: holdingID, UserID: userID, Price: price, Date: date, Source: domain.PriceSourceCSV})
+		}
+	}
+
+	if len(prices) > 0 {
+		if err := s.repo.SavePrices(prices); err != nil {
+			return domain.PriceImport{}, err
+		}
+	}
+	result.Imported = len(prices)
+	return result, nil
+}
+
+// Portfolio values every holding and breaks the market value down by asset
+// class.
+func (s *PortfolioService) Portfolio(userID int) (domain.Portfolio, error) {
+	holdings, err := s.repo.ListHoldings(userID)
+	if err != nil {
+		return domain.Portfolio{}, err
+	}
+	operations, err := s.repo.ListOperations(userID)
+	if err != nil {
+		return domain.Portfolio{}, err
+	}
+	prices, err := s.repo.LatestPrices(userID)
+	if err != nil {
+		return domain.Portfolio{}, err
+	}
+	latest := make(map[int]domain.PriceSnapshot, len(prices))
+	for _, p := range prices {
+		latest[p.HoldingID] = p
+	}
+
+	portfolio := domain.Portfolio{Positions: []domain.Position{}, Allocation: []domain.AllocationSlice{}}
+	byClass := make(map[string]float64)
+	for _, h := range holdings {
+		position, err := replayPosition(h, operations)
+		if err != nil {
+			return domain.Portfolio{}, err
+		}
+		if p, ok := latest[h.ID]; ok {
+			position.Price = p.Price
+			date := p.Date
+			position.PricedAt = &date
+		}
+
+		position.MarketValue = roundMoney(position.Quantity * position.Price)
+		position.CostBasis = roundMoney(position.CostBasis)
+		position.UnrealizedGain = roundMoney(position.MarketValue - position.CostBasis)
+		position.RealizedGain = roundMoney(position.RealizedGain)
+		position.Dividends = roundMoney(position.Dividends)
+		position.Fees = roundMoney(position.Fees)
+
+		portfolio.Positions = append(portfolio.Positions, position)
+		portfolio.CostBasis += position.CostBasis
+		portfolio.MarketValue += position.MarketValue
+		portfolio.RealizedGain += position.RealizedGain
+		portfolio.Dividends += position.Dividends
+		byClass[h.AssetClass] += position.MarketValue
+	}
+
+	portfolio.CostBasis = roundMoney(portfolio.CostBasis)
+	portfolio.MarketValue = roundMoney(portfolio.MarketValue)
+	portfolio.UnrealizedGain = roundMoney(portfolio.MarketValue - portfolio.CostBasis)
+	portfolio.RealizedGain = roundMoney(portfolio.RealizedGain)
+	portfolio.Dividends = roundMoney(portfolio.Dividends)
+
+	for class, value := range byClass {
+		if value <= 0 {
+			continue
+		}
+		portfolio.Allocation = append(portfolio.Allocation, domain.AllocationSlice{
+			AssetClass:  class,
+			MarketValue: roundMoney(value),
+			Percent:     roundPercent(value / portfolio.MarketValue * 100),
+		})
+	}
+	sort.Slice(portfolio.Allocation, func(i, j int) bool {
+		return portfolio.Allocation[i].MarketValue > portfolio.Allocation[j].MarketValue
+	})
+	return portfolio, nil
+}
+
+// replayPosition applies the holding's operations in date order at average
+// cost. Price falls back to the last trade price.
+func replayPosition(holding domain.Holding, operations []domain.InvestmentOperation) (domain.Position, error) {
+	var own []domain.InvestmentOperation
+	for _, o := range operations {
+		if o.HoldingID == holding.ID {
+			own = append(own, o)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool {
+		return own[i].Date.Before(own[j].Date)
+	})
+
+	position := domain.Position{Holding: holding}
+	for _, o := range own {
+		position.Fees += o.Fees
+		switch o.Type {
+		case domain.OperationBuy:
+			position.Quantity += o.Quantity
+			position.CostBasis += o.Amount + o.Fees
+			position.Price = o.Price
+		case domain.OperationSell:
+			if o.Quantity > position.Quantity+quantityEpsilon {
+				return domain.Position{}, ErrInsufficientPosition
+			}
+			costOut := position.CostBasis * o.Quantity / position.Quantity
+			position.RealizedGain += o.Amount - o.Fees - costOut
+			position.CostBasis -= costOut
+			position.Quantity -= o.Quantity
+			position.Price = o.Price
+			if position.Quantity < quantityEpsilon {
+				position.Quantity, position.CostBasis = 0, 0
+			}
+		case domain.OperationDividend:
+			position.Dividends += o.Amount - o.Fees
+		}
+	}
+	if position.Quantity > 0 {
+		position.AverageCost = math.Round(position.CostBasis/position.Quantity*1e6) / 1e6
+	}
+	return position, nil
+}
+
+func normalizeOperation(o *domain.InvestmentOperation) error {
+	o.Type = strings.ToLower(strings.TrimSpace(o.Type))
+	trade := o.Type == domain.OperationBuy || o.Type == domain.OperationSell
+	err := validation.Validate(ErrInvalidOperation,
+		validation.Field("holding_id", o.HoldingID, validation.Positive),
+		validation.Field("type", o.Type, validation.OneOf(domain.OperationBuy, domain.OperationSell, domain.OperationDividend)),
+		validation.Field("fees", o.Fees, validation.Min(0.0)),
+		validation.When(trade, validation.Field("quantity", o.Quantity, validation.Positive)),
+		validation.When(trade, validation.Field("price", o.Price, validation.Min(0.0))),
+		validation.When(o.Type == domain.OperationDividend, validation.Field("amount", o.Amount, validation.Positive)),
+	)
+	if err != nil {
+		return err
+	}
+	if trade {
+		o.Amount = roundMoney(o.Quantity * o.Price)
+	} else {
+		o.Quantity, o.Price = 0, 0
+	}
+	if !o.Date.IsZero() {
+		o.Date = dayOf(o.Date)
+	}
+	return nil
+}
+
+func parseImportPrice(value string) (float64, error) {
+	price, err := parseDecimal(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	if err != nil || price < 0 {
+		return 0, fmt.Errorf("invalid price %q", strings.TrimSpace(value))
+	}
+	return price, nil
+}
@@ -0,0 +1,198 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type memoryPortfolioRepository struct {
+	holdings   []domain.Holding
+	operations []domain.InvestmentOperation
+	prices     []domain.PriceSnapshot
+}
+
+func (m *memoryPortfolioRepository) SaveHolding(h domain.Holding, events ...domain.Event) (int, error) {
+	for _, existing := range m.holdings {
+		if existing.UserID == h.UserID && existing.Ticker == h.Ticker {
+			return 0, ports.ErrHoldingExists
+		}
+	}
+	h.ID = len(m.holdings) + 1
+	m.holdings = append(m.holdings, h)
+	return h.ID, nil
+}
+
+func (m *memoryPortfolioRepository) GetHolding(id, userID int) (domain.Holding, error) {
+	for _, h := range m.holdings {
+		if h.ID == id && h.UserID == userID {
+			return h, nil
+		}
+	}
+	return domain.Holding{}, sql.ErrNoRows
+}
+
+func (m *memoryPortfolioRepository) ListHoldings(userID int) ([]domain.Holding, error) {
+	return m.holdings, nil
+}
+
+func (m *memoryPortfolioRepository) DeleteHolding(id, userID int, events ...domain.Event) error {
+	for i, h := range m.holdings {
+		if h.ID == id {
+			m.holdings = append(m.holdings[:i], m.holdings[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryPortfolioRepository) SaveOperation(o domain.InvestmentOperation, events ...domain.Event) (int, error) {
+	o.ID = len(m.operations) + 1
+	m.operations = append(m.operations, o)
+	return o.ID, nil
+}
+
+func (m *memoryPortfolioRepository) DeleteOperation(id, userID int, events ...domain.Event) error {
+	for i, o := range m.operations {
+		if o.ID == id {
+			m.operations = append(m.operations[:i], m.operations[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryPortfolioRepository) ListOperations(userID int) ([]domain.InvestmentOperation, error) {
+	return m.operations, nil
+}
+
+func (m *memoryPortfolioRepository) SavePrices(prices []domain.PriceSnapshot) error {
+	m.prices = append(m.prices, prices...)
+	return nil
+}
+
+func (m *memoryPortfolioRepository) LatestPrices(userID int) ([]domain.PriceSnapshot, error) {
+	latest := map[int]domain.PriceSnapshot{}
+	for _, p := range m.prices {
+		if current, ok := latest[p.HoldingID]; !ok || !p.Date.Before(current.Date) {
+			latest[p.HoldingID] = p
+		}
+	}
+	var result []domain.PriceSnapshot
+	for _, p := range latest {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func tradeDay(month, d int) time.Time {
+	return time.Date(2025, time.Month(month), d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPortfolio_AverageCostAndGains(t *testing.T) {
+	repo := &memoryPortfolioRepository{}
+	service := services.NewPortfolioService(repo)
+	ctx := context.Background()
+
+	petr, err := service.CreateHolding(ctx, 1, domain.Holding{Ticker: " petr4 ", AssetClass: "acoes"})
+	assert.NoError(t, err)
+	assert.Equal(t, "PETR4", petr.Ticker)
+	selic, err := service.CreateHolding(ctx, 1, domain.Holding{Ticker: "Tesouro Selic 2029", AssetClass: "tesouro_direto"})
+	assert.NoError(t, err)
+
+	record := func(o domain.InvestmentOperation) {
+		_, err := service.RecordOperation(ctx, 1, o)
+		assert.NoError(t, err)
+	}
+	record(domain.InvestmentOperation{HoldingID: petr.ID, Type: "buy", Quantity: 100, Price: 30, Fees: 10, Date: tradeDay(1, 10)})
+	record(domain.InvestmentOperation{HoldingID: petr.ID, Type: "buy", Quantity: 100, Price: 40, Fees: 10, Date: tradeDay(2, 10)})
+	record(domain.InvestmentOperation{HoldingID: petr.ID, Type: "sell", Quantity: 50, Price: 45, Fees: 5, Date: tradeDay(3, 10)})
+	record(domain.InvestmentOperation{HoldingID: petr.ID, Type: "dividend", Amount: 120, Date: tradeDay(3, 20)})
+	record(domain.InvestmentOperation{HoldingID: selic.ID, Type: "buy", Quantity: 1, Price: 15000, Date: tradeDay(1, 5)})
+
+	_, err = service.SetPrice(ctx, 1, petr.ID, 42, tradeDay(4, 1))
+	assert.NoError(t, err)
+
+	portfolio, err := service.Portfolio(1)
+	assert.NoError(t, err)
+	assert.Len(t, portfolio.Positions, 2)
+
+	position := portfolio.Positions[0]
+	assert.Equal(t, 150.0, position.Quantity)
+	assert.Equal(t, 35.1, position.AverageCost)
+	assert.Equal(t, 5265.0, position.CostBasis)
+	assert.Equal(t, 6300.0, position.MarketValue)
+	assert.Equal(t, 1035.0, position.UnrealizedGain)
+	assert.Equal(t, 490.0, position.RealizedGain)
+	assert.Equal(t, 120.0, position.Dividends)
+
+	// Without a price snapshot the last trade price values the position.
+	assert.Equal(t, 15000.0, portfolio.Positions[1].MarketValue)
+
+	assert.Equal(t, 21300.0, portfolio.MarketValue)
+	assert.Len(t, portfolio.Allocation, 2)
+	assert.Equal(t, domain.AssetClassTesouroDireto, portfolio.Allocation[0].AssetClass)
+	assert.Equal(t, 70.4, portfolio.Allocation[0].Percent)
+	assert.Equal(t, 29.6, portfolio.Allocation[1].Percent)
+}
+
+func TestPortfolio_RejectsSellBeyondPosition(t *testing.T) {
+	repo := &memoryPortfolioRepository{}
+	service := services.NewPortfolioService(repo)
+	ctx := context.Background()
+
+	fii, _ := service.CreateHolding(ctx, 1, domain.Holding{Ticker: "HGLG11", AssetClass: "fii"})
+	buy, err := service.RecordOperation(ctx, 1, domain.InvestmentOperation{HoldingID: fii.ID, Type: "buy", Quantity: 10, Price: 160, Date: tradeDay(2, 1)})
+	assert.NoError(t, err)
+
+	// A sell dated before the buy is entered late and must not go through.
+	_, err = service.RecordOperation(ctx, 1, domain.InvestmentOperation{HoldingID: fii.ID, Type: "sell", Quantity: 5, Price: 170, Date: tradeDay(1, 15)})
+	assert.ErrorIs(t, err, services.ErrInsufficientPosition)
+
+	_, err = service.RecordOperation(ctx, 1, domain.InvestmentOperation{HoldingID: fii.ID, Type: "sell", Quantity: 5, Price: 170, Date: tradeDay(3, 1)})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.DeleteOperation(ctx, 1, buy.ID), services.ErrInsufficientPosition)
+	assert.ErrorIs(t, service.DeleteHolding(ctx, 1, fii.ID), services.ErrHoldingNotEmpty)
+
+	_, err = service.RecordOperation(ctx, 1, domain.InvestmentOperation{HoldingID: fii.ID, Type: "split"})
+	assert.ErrorIs(t, err, services.ErrInvalidOperation)
+	_, err = service.RecordOperation(ctx, 1, domain.InvestmentOperation{HoldingID: fii.ID, Type: "buy", Price: 170, Fees: -1})
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "fees", Message: "must be at least 0"},
+		{Field: "quantity", Message: "must be positive"},
+	}, e.Fields)
+}
+
+func TestPortfolio_ImportPricesCSV(t *testing.T) {
+	repo := &memoryPortfolioRepository{}
+	service := services.NewPortfolioService(repo)
+	ctx := context.Background()
+	service.CreateHolding(ctx, 1, domain.Holding{Ticker: "PETR4", AssetClass: "acoes"})
+	service.CreateHolding(ctx, 1, domain.Holding{Ticker: "HGLG11", AssetClass: "fii"})
+
+	csv := "ticker;data;preco\n" +
+		"PETR4;02/01/2025;38,50\n" +
+		"hglg11;2025-01-02;R$ 1.605,10\n" +
+		"VALE3;02/01/2025;60,00\n" +
+		"PETR4;31/02/2025;39,00\n"
+
+	result, err := service.ImportPrices(ctx, 1, strings.NewReader(csv))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Len(t, result.Errors, 2)
+	assert.Equal(t, 4, result.Errors[0].Line)
+	assert.Equal(t, 5, result.Errors[1].Line)
+
+	assert.Equal(t, 38.5, repo.prices[0].Price)
+	assert.Equal(t, 1605.1, repo.prices[1].Price)
+	assert.Equal(t, domain.PriceSourceCSV, repo.prices[1].Source)
+}
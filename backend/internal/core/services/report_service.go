@@ -0,0 +1,172 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// MaxReportMonths bounds the range of a single report.
+const MaxReportMonths = 120
+
+var ErrInvalidReportRange = domain.Invalid("invalid_report_range", "report range must start before it ends and span at most 120 months")
+
+// ReportService shapes the per-month aggregates computed by the database.
+// from and to are inclusive and only their year and month matter.
+type ReportService struct {
+	repo ports.ReportRepository
+}
+
+func NewReportService(repo ports.ReportRepository) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+func (s *ReportService) Monthly(userID int, from, to time.Time) ([]domain.MonthlyReport, error) {
+	first, last, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.MonthlyTotals(userID, monthStart(first), monthStart(last+1))
+	if err != nil {
+		return nil, err
+	}
+	byMonth := make(map[int]domain.MonthlyTotal, len(totals))
+	for _, t := range totals {
+		byMonth[monthIndex(t.Month, t.Year)] = t
+	}
+
+	reports := make([]domain.MonthlyReport, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		t := byMonth[index]
+		report := domain.MonthlyReport{
+			Year:    index / 12,
+			Month:   index%12 + 1,
+			Income:  roundMoney(t.Income),
+			Expense: roundMoney(t.Expense),
+			Net:     roundMoney(t.Income - t.Expense),
+		}
+		if t.Income > 0 {
+			report.SavingsRate = roundPercent((t.Income - t.Expense) / t.Income * 100)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Categories returns each category's monthly expenses with month-over-month
+// deltas. The month before from is loaded so the first delta is real.
+func (s *ReportService) Categories(userID int, from, to time.Time) ([]domain.CategoryReport, error) {
+	first, last, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.CategoryTotals(userID, monthStart(first-1), monthStart(last+1))
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]map[int]float64)
+	for _, t := range totals {
+		if byCategory[t.Category] == nil {
+			byCategory[t.Category] = make(map[int]float64)
+		}
+		byCategory[t.Category][monthIndex(t.Month, t.Year)] += t.Total
+	}
+
+	reports := []domain.CategoryReport{}
+	for category, months := range byCategory {
+		report := domain.CategoryReport{Category: category, Months: make([]domain.CategoryMonth, 0, last-first+1)}
+		for index := first; index <= last; index++ {
+			total, previous := months[index], months[index-1]
+			report.Months = append(report.Months, domain.CategoryMonth{
+				Year:         index / 12,
+				Month:        index%12 + 1,
+				Total:        roundMoney(total),
+				Delta:        roundMoney(total - previous),
+				DeltaPercent: percentChange(previous, total),
+			})
+			report.Total += total
+		}
+		report.Total = roundMoney(report.Total)
+		if report.Total == 0 {
+			// Only spent in the month before the range.
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Total == reports[j].Total {
+			return reports[i].Category < reports[j].Category
+		}
+		return reports[i].Total > reports[j].Total
+	})
+	return reports, nil
+}
+
+// YearOverYear compares every month of year with the same month of the
+// previous year.
+func (s *ReportService) YearOverYear(userID, year int) (domain.YearOverYear, error) {
+	totals, err := s.repo.MonthlyTotals(userID, monthStart(monthIndex(1, year-1)), monthStart(monthIndex(1, year+1)))
+	if err != nil {
+		return domain.YearOverYear{}, err
+	}
+	byMonth := make(map[int]domain.MonthlyTotal, len(totals))
+	for _, t := range totals {
+		byMonth[monthIndex(t.Month, t.Year)] = t
+	}
+
+	result := domain.YearOverYear{Year: year, Months: make([]domain.YearOverYearMonth, 0, 12)}
+	for month := 1; month <= 12; month++ {
+		current, previous := byMonth[monthIndex(month, year)], byMonth[monthIndex(month, year-1)]
+		result.Months = append(result.Months, domain.YearOverYearMonth{
+			Month:           month,
+			Income:          roundMoney(current.Income),
+			Expense:         roundMoney(current.Expense),
+			PreviousIncome:  roundMoney(previous.Income),
+			PreviousExpense: roundMoney(previous.Expense),
+			IncomeChange:    percentChange(previous.Income, current.Income),
+			ExpenseChange:   percentChange(previous.Expense, current.Expense),
+		})
+		result.Income += current.Income
+		result.Expense += current.Expense
+		result.PreviousIncome += previous.Income
+		result.PreviousExpense += previous.Expense
+	}
+	result.IncomeChange = percentChange(result.PreviousIncome, result.Income)
+	result.ExpenseChange = percentChange(result.PreviousExpense, result.Expense)
+	result.Income = roundMoney(result.Income)
+	result.Expense = roundMoney(result.Expense)
+	result.PreviousIncome = roundMoney(result.PreviousIncome)
+	result.PreviousExpense = roundMoney(result.PreviousExpense)
+	return result, nil
+}
+
+func reportRange(from, to time.Time) (first, last int, err error) {
+	first = monthIndex(int(from.Month()), from.Year())
+	last = monthIndex(int(to.Month()), to.Year())
+	if first > last || last-first+1 > MaxReportMonths {
+		return 0, 0, ErrInvalidReportRange
+	}
+	return first, last, nil
+}
+
+func monthStart(index int) time.Time {
+	return time.Date(index/12, time.Month(index%12+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func percentChange(previous, current float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := roundPercent((current - previous) / previous * 100)
+	return &change
+}
+
+func roundPercent(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type stubReportRepository struct {
+	monthly    []domain.MonthlyTotal
+	categories []domain.CategoryTotal
+	from, to   time.Time
+}
+
+func (s *stubReportRepository) MonthlyTotals(userID int, from, to time.Time) ([]domain.MonthlyTotal, error) {
+	s.from, s.to = from, to
+	return s.monthly, nil
+}
+
+func (s *stubReportRepository) CategoryTotals(userID int, from, to time.Time) ([]domain.CategoryTotal, error) {
+	s.from, s.to = from, to
+	return s.categories, nil
+}
+
+func (s *stubReportRepository) CategorySpent(userID int, category string, from, to time.Time) (float64, error) {
+	return 0, nil
+}
+
+func month(year, m int) time.Time {
+	return time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestReportMonthly_FillsGapsAndSavingsRate(t *testing.T) {
+	repo := &stubReportRepository{monthly: []domain.MonthlyTotal{
+		{Year: 2024, Month: 11, Income: 5000, Expense: 4000},
+		{Year: 2025, Month: 1, Income: 0, Expense: 300},
+	}}
+	service := services.NewReportService(repo)
+
+	reports, err := service.Monthly(1, month(2024, 11), month(2025, 1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, month(2024, 11), repo.from)
+	assert.Equal(t, month(2025, 2), repo.to)
+	assert.Len(t, reports, 3)
+	assert.Equal(t, 20.0, reports[0].SavingsRate)
+	assert.Equal(t, domain.MonthlyReport{Year: 2024, Month: 12}, reports[1])
+	assert.Equal(t, -300.0, reports[2].Net)
+	assert.Equal(t, 0.0, reports[2].SavingsRate)
+}
+
+func TestReportCategories_MonthOverMonth(t *testing.T) {
+	repo := &stubReportRepository{categories: []domain.CategoryTotal{
+		{Year: 2025, Month: 1, Category: "Mercado", Total: 800},
+		{Year: 2025, Month: 2, Category: "Mercado", Total: 1000},
+		{Year: 2025, Month: 3, Category: "Mercado", Total: 900},
+		{Year: 2025, Month: 3, Category: "Lazer", Total: 200},
+		{Year: 2025, Month: 1, Category: "Farmácia", Total: 50},
+	}}
+	service := services.NewReportService(repo)
+
+	reports, err := service.Categories(1, month(2025, 2), month(2025, 3))
+
+	assert.NoError(t, err)
+	assert.Equal(t, month(2025, 1), repo.from)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "Mercado", reports[0].Category)
+	assert.Equal(t, 1900.0, reports[0].Total)
+	assert.Equal(t, 200.0, reports[0].Months[0].Delta)
+	assert.Equal(t, 25.0, *reports[0].Months[0].DeltaPercent)
+	assert.Equal(t, -100.0, reports[0].Months[1].Delta)
+	assert.Nil(t, reports[1].Months[1].DeltaPercent)
+}
+
+func TestReportYearOverYear(t *testing.T) {
+	repo := &stubReportRepository{monthly: []domain.MonthlyTotal{
+		{Year: 2024, Month: 3, Income: 4000, Expense: 3000},
+		{Year: 2025, Month: 3, Income: 5000, Expense: 2700},
+	}}
+	service := services.NewReportService(repo)
+
+	report, err := service.YearOverYear(1, 2025)
+
+	assert.NoError(t, err)
+	assert.Equal(t, month(2024, 1), repo.from)
+	assert.Equal(t, month(2026, 1), repo.to)
+	assert.Len(t, report.Months, 12)
+	assert.Equal(t, 25.0, *report.Months[2].IncomeChange)
+	assert.Equal(t, -10.0, *report.Months[2].ExpenseChange)
+	assert.Nil(t, report.Months[0].IncomeChange)
+	assert.Equal(t, 25.0, *report.IncomeChange)
+}
+
+func TestReport_InvalidRange(t *testing.T) {
+	service := services.NewReportService(&stubReportRepository{})
+
+	_, err := service.Monthly(1, month(2025, 5), month(2025, 1))
+	assert.ErrorIs(t, err, services.ErrInvalidReportRange)
+}
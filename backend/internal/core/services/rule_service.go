@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrRuleWithoutCondition = domain.Invalid("rule_without_condition", "rule must have at least one condition")
+	ErrRuleWithoutAction    = domain.Invalid("rule_without_action", "rule must set a category, tags or bucket")
+	ErrInvalidRule          = domain.Invalid("invalid_rule", "rule has invalid conditions")
+)
+
+type RuleService struct {
+	ruleRepo        ports.RuleRepository
+	transactionRepo ports.TransactionRepository
+	transactions    ports.TransactionRecategorizer
+	audit           ports.AuditService
+}
+
+func NewRuleService(ruleRepo ports.RuleRepository, transactionRepo ports.TransactionRepository) *RuleService {
+	return &RuleService{
+		ruleRepo:        ruleRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// SetAuditService records every change made through this service.
+func (s *RuleService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetTransactionService is what ApplyRuleRetroactively writes through; it
+// is required for retroactive runs.
+func (s *RuleService) SetTransactionService(transactions ports.TransactionRecategorizer) {
+	s.transactions = transactions
+}
+
+func (s *RuleService) CreateRule(ctx context.Context, userID int, rule domain.CategoryRule) (domain.CategoryRule, error) {
+	rule.UserID = userID
+	rule.SetTags = normalizeTags(rule.SetTags)
+	if err := validateRule(rule); err != nil {
+		return domain.CategoryRule{}, err
+	}
+
+	rule.CreatedAt = time.Now()
+	id, err := s.ruleRepo.Save(rule, auditEvents(s.audit, ctx, userID, domain.AuditEntityRule, 0, domain.AuditActionCreate, nil, rule)...)
+	if err != nil {
+		return domain.CategoryRule{}, err
+	}
+
+	rule.ID = id
+	return rule, nil
+}
+
+func (s *RuleService) UpdateRule(ctx context.Context, userID, id int, rule domain.CategoryRule) error {
+	rule.ID = id
+	rule.UserID = userID
+	rule.SetTags = normalizeTags(rule.SetTags)
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
+	before, err := s.ruleRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	rule.CreatedAt = before.CreatedAt
+	return s.ruleRepo.Update(rule, auditEvents(s.audit, ctx, userID, domain.AuditEntityRule, id, domain.AuditActionUpdate, before, rule)...)
+}
+
+func (s *RuleService) DeleteRule(ctx context.Context, userID, id int) error {
+	before, err := s.ruleRepo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityRule, id, domain.AuditActionDelete, before, nil)...)
+}
+
+func (s *RuleService) ListRules(userID int) ([]domain.CategoryRule, error) {
+	return s.ruleRepo.ListByUserID(userID)
+}
+
+// ApplyRules runs the user's enabled rules in priority order. The first
+// matching rule that sets a category or bucket wins; tags accumulate. A
+// category the user entered is kept: rules only fill in an empty one.
+func (s *RuleService) ApplyRules(t domain.Transaction) (domain.Transaction, error) {
+	rules, err := s.ruleRepo.ListByUserID(t.UserID)
+	if err != nil {
+		return t, err
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		c, err := compileRule(rule)
+		if err != nil {
+			// A rule saved before validation existed should not block writes.
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].rule.Priority < compiled[j].rule.Priority
+	})
+
+	result := applyCompiledRules(t, compiled)
+	if t.Category != "" {
+		result.Category = t.Category
+	}
+	return result, nil
+}
+
+func (s *RuleService) PreviewRule(userID int, rule domain.CategoryRule) ([]domain.RuleMatch, error) {
+	rule.UserID = userID
+	rule.Enabled = true
+	c, err := compileRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.ListAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []domain.RuleMatch{}
+	for _, t := range transactions {
+		updated := applyCompiledRules(t, []compiledRule{c})
+		if transactionChanged(t, updated) {
+			matches = append(matches, domain.RuleMatch{
+				Transaction: t,
+				Category:    updated.Category,
+				Tags:        updated.Tags,
+				Bucket:      updated.Bucket,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// ApplyRuleRetroactively applies the rule to every transaction it matches,
+// all together or, when one fails, none at all. It returns how many were
+// changed.
+func (s *RuleService) ApplyRuleRetroactively(ctx context.Context, userID, id int) (int, error) {
+	if s.transactions == nil {
+		return 0, errors.New("rules: no transaction service to apply rules through")
+	}
+	rule, err := s.ruleRepo.GetByID(id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	matches, err := s.PreviewRule(userID, rule)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.transactions.Recategorize(ctx, userID, matches); err != nil {
+		return 0, err
+	}
+	return len(matches), nil
+}
+
+type compiledRule struct {
+	rule  domain.CategoryRule
+	regex *regexp.Regexp
+}
+
+func compileRule(rule domain.CategoryRule) (compiledRule, error) {
+	c := compiledRule{rule: rule}
+
+	hasCondition := rule.DescriptionContains != "" || rule.DescriptionRegex != "" ||
+		rule.MinAmount != nil || rule.MaxAmount != nil || rule.Account != "" ||
+		rule.TransactionType != "" || rule.DayOfMonthFrom != 0 || rule.DayOfMonthTo != 0
+	if !hasCondition {
+		return c, ErrRuleWithoutCondition
+	}
+	if rule.SetCategory == "" && rule.SetBucket == "" && len(rule.SetTags) == 0 {
+		return c, ErrRuleWithoutAction
+	}
+
+	var regexErr error
+	if rule.DescriptionRegex != "" {
+		c.regex, regexErr = regexp.Compile(rule.DescriptionRegex)
+	}
+	err := validation.Validate(ErrInvalidRule,
+		validation.When(rule.TransactionType != "", validation.Field("transaction_type", rule.TransactionType, validation.OneOf("income", "expense"))),
+		validation.Field("day_of_month_from", rule.DayOfMonthFrom, validation.Min(0), validation.Max(31)),
+		validation.Field("day_of_month_to", rule.DayOfMonthTo, validation.Min(0), validation.Max(31)),
+		validation.When(regexErr != nil, validation.Field("description_regex", rule.DescriptionRegex, func(string) string {
+			return "invalid regular expression: " + regexErr.Error()
+		})),
+	)
+	return c, err
+}
+
+// validateRule checks a rule about to be stored, which also needs a name.
+func validateRule(rule domain.CategoryRule) error {
+	if err := validation.Validate(ErrInvalidRule, validation.Field("name", rule.Name, validation.Required, validation.MaxLength(maxNameLength))); err != nil {
+		return err
+	}
+	_, err := compileRule(rule)
+	return err
+}
+
+func (c compiledRule) matches(t domain.Transaction) bool {
+	r := c.rule
+	if r.DescriptionContains != "" && !strings.Contains(strings.ToLower(t.Description), strings.ToLower(r.DescriptionContains)) {
+		return false
+	}
+	if c.regex != nil && !c.regex.MatchString(t.Description) {
+		return false
+	}
+	if r.MinAmount != nil && t.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && t.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.Account != "" && !strings.EqualFold(r.Account, t.Account) {
+		return false
+	}
+	if r.TransactionType != "" && r.TransactionType != t.Type {
+		return false
+	}
+	if r.DayOfMonthFrom != 0 || r.DayOfMonthTo != 0 {
+		from, to := r.DayOfMonthFrom, r.DayOfMonthTo
+		if from == 0 {
+			from = 1
+		}
+		if to == 0 {
+			to = 31
+		}
+		day := t.Date.Day()
+		// A range such as 25..5 wraps around the end of the month.
+		if from <= to && (day < from || day > to) {
+			return false
+		}
+		if from > to && day < from && day > to {
+			return false
+		}
+	}
+	return true
+}
+
+func applyCompiledRules(t domain.Transaction, rules []compiledRule) domain.Transaction {
+	categorySet, bucketSet := false, false
+	tags := slices.Clone(t.Tags)
+
+	for _, c := range rules {
+		if !c.matches(t) {
+			continue
+		}
+		if c.rule.SetCategory != "" && !categorySet {
+			t.Category = c.rule.SetCategory
+			categorySet = true
+		}
+		if c.rule.SetBucket != "" && !bucketSet {
+			t.Bucket = c.rule.SetBucket
+			bucketSet = true
+		}
+		tags = append(tags, c.rule.SetTags...)
+	}
+
+	t.Tags = normalizeTags(tags)
+	return t
+}
+
+func transactionChanged(before, after domain.Transaction) bool {
+	return before.Category != after.Category ||
+		before.Bucket != after.Bucket ||
+		!slices.Equal(normalizeTags(before.Tags), after.Tags)
+}
+
+func normalizeTags(tags []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+type memoryRuleRepository struct {
+	rules []domain.CategoryRule
+}
+
+func (m *memoryRuleRepository) Save(rule domain.CategoryRule, events ...domain.Event) (int, error) {
+	rule.ID = len(m.rules) + 1
+	m.rules = append(m.rules, rule)
+	return rule.ID, nil
+}
+
+func (m *memoryRuleRepository) Update(rule domain.CategoryRule, events ...domain.Event) error {
+	for i, r := range m.rules {
+		if r.ID == rule.ID && r.UserID == rule.UserID {
+			m.rules[i] = rule
+		}
+	}
+	return nil
+}
+
+func (m *memoryRuleRepository) Delete(id, userID int, events ...domain.Event) error { return nil }
+
+func (m *memoryRuleRepository) GetByID(id, userID int) (domain.CategoryRule, error) {
+	for _, r := range m.rules {
+		if r.ID == id && r.UserID == userID {
+			return r, nil
+		}
+	}
+	return domain.CategoryRule{}, nil
+}
+
+func (m *memoryRuleRepository) ListByUserID(userID int) ([]domain.CategoryRule, error) {
+	var result []domain.CategoryRule
+	for _, r := range m.rules {
+		if r.UserID == userID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func amount(v float64) *float64 { return &v }
+
+func TestApplyRules_PriorityOrder(t *testing.T) {
+	repo := &memoryRuleRepository{}
+	service := services.NewRuleService(repo, new(MockTransactionRepository))
+
+	_, err := service.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Delivery", Priority: 20, Enabled: true,
+		DescriptionContains: "ifood", SetCategory: "Desejos", SetTags: []string{"delivery"},
+	})
+	assert.NoError(t, err)
+	_, err = service.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Big delivery", Priority: 10, Enabled: true,
+		DescriptionRegex: `(?i)^ifood`, MinAmount: amount(150), SetCategory: "Essenciais", SetBucket: "Essenciais",
+	})
+	assert.NoError(t, err)
+
+	result, err := service.ApplyRules(domain.Transaction{UserID: 1, Description: "IFOOD *Restaurante", Amount: 200})
+	assert.NoError(t, err)
+	assert.Equal(t, "Essenciais", result.Category)
+	assert.Equal(t, "Essenciais", result.Bucket)
+	assert.Equal(t, []string{"delivery"}, result.Tags)
+
+	result, err = service.ApplyRules(domain.Transaction{UserID: 1, Description: "iFood pedido", Amount: 40})
+	assert.NoError(t, err)
+	assert.Equal(t, "Desejos", result.Category)
+}
+
+func TestApplyRules_DayOfMonthAndAccount(t *testing.T) {
+	repo := &memoryRuleRepository{}
+	service := services.NewRuleService(repo, new(MockTransactionRepository))
+
+	_, err := service.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Aluguel", Enabled: true, Account: "Nubank",
+		DayOfMonthFrom: 28, DayOfMonthTo: 5, SetCategory: "Essenciais",
+	})
+	assert.NoError(t, err)
+
+	matched, _ := service.ApplyRules(domain.Transaction{UserID: 1, Account: "nubank", Date: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)})
+	assert.Equal(t, "Essenciais", matched.Category)
+
+	missed, _ := service.ApplyRules(domain.Transaction{UserID: 1, Account: "nubank", Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)})
+	assert.Equal(t, "", missed.Category)
+}
+
+func TestCreateRule_Validation(t *testing.T) {
+	service := services.NewRuleService(&memoryRuleRepository{}, new(MockTransactionRepository))
+
+	_, err := service.CreateRule(context.Background(), 1, domain.CategoryRule{Name: "Empty", SetCategory: "Desejos"})
+	assert.ErrorIs(t, err, services.ErrRuleWithoutCondition)
+
+	_, err = service.CreateRule(context.Background(), 1, domain.CategoryRule{Name: "No action", DescriptionContains: "uber"})
+	assert.ErrorIs(t, err, services.ErrRuleWithoutAction)
+
+	_, err = service.CreateRule(context.Background(), 1, domain.CategoryRule{Name: "Bad regex", DescriptionRegex: "(", SetCategory: "Desejos"})
+	assert.ErrorIs(t, err, services.ErrInvalidRule)
+
+	_, err = service.CreateRule(context.Background(), 1, domain.CategoryRule{Name: " ", DescriptionContains: "uber", SetCategory: "Transporte"})
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{{Field: "name", Message: "is required"}}, e.Fields)
+
+	_, err = service.CreateRule(context.Background(), 1, domain.CategoryRule{Name: "Transfer", TransactionType: "transfer", DayOfMonthTo: 32, SetCategory: "Outros"})
+	e, _ = domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "transaction_type", Message: "must be one of income, expense"},
+		{Field: "day_of_month_to", Message: "must be at most 31"},
+	}, e.Fields)
+}
+
+func TestApplyRuleRetroactively(t *testing.T) {
+	repo := &memoryRuleRepository{}
+	txRepo := new(MockTransactionRepository)
+	service := services.NewRuleService(repo, txRepo)
+	service.SetTransactionService(services.NewTransactionService(txRepo))
+
+	rule, _ := service.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Uber", Enabled: true, DescriptionContains: "uber", SetCategory: "Transporte",
+	})
+
+	txRepo.On("ListAllByUserID", 1).Return([]domain.Transaction{
+		{ID: 1, UserID: 1, Description: "Uber trip", Category: "Desejos", Version: 3},
+		{ID: 2, UserID: 1, Description: "Uber trip", Category: "Transporte", Version: 1},
+		{ID: 4, UserID: 1, Description: "uber eats", Category: "Lazer", Version: 2},
+		{ID: 3, UserID: 1, Description: "Padaria", Category: "Essenciais", Version: 1},
+	}, nil)
+	txRepo.On("ApplyBatch", mock.MatchedBy(func(writes []ports.TransactionWrite) bool {
+		return len(writes) == 2 &&
+			writes[0].Transaction.ID == 1 && writes[0].Transaction.Category == "Transporte" && writes[0].Transaction.Version == 3 &&
+			writes[1].Transaction.ID == 4 && writes[1].Transaction.Category == "Transporte" && writes[1].Transaction.Version == 2 &&
+			writes[0].Events[0].Type == domain.EventTransactionUpdated
+	})).Return([]domain.Transaction{{ID: 1, Version: 4}, {ID: 4, Version: 3}}, nil).Once()
+
+	preview, err := service.PreviewRule(1, rule)
+	assert.NoError(t, err)
+	assert.Len(t, preview, 2)
+
+	updated, err := service.ApplyRuleRetroactively(context.Background(), 1, rule.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	txRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	// A transaction edited since the match fails the run as a whole.
+	txRepo.On("ApplyBatch", mock.Anything).Return(nil, &ports.BatchError{Index: 1, Err: ports.ErrVersionConflict})
+	updated, err = service.ApplyRuleRetroactively(context.Background(), 1, rule.ID)
+	assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	assert.Equal(t, 0, updated)
+}
+
+func TestCreateExpense_AppliesRules(t *testing.T) {
+	ruleRepo := &memoryRuleRepository{}
+	txRepo := new(MockTransactionRepository)
+	rules := services.NewRuleService(ruleRepo, txRepo)
+	rules.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Mercado", Enabled: true, DescriptionContains: "carrefour", SetCategory: "Essenciais",
+	})
+
+	service := services.NewTransactionService(txRepo)
+	service.SetRuleService(rules)
+
+	txRepo.On("Save", mock.MatchedBy(func(tr domain.Transaction) bool {
+		return tr.Category == "Essenciais"
+	})).Return(7, nil)
+
+	result, err := service.CreateExpense(context.Background(), 1, 320, "", "CARREFOUR 123", "", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 7, result.ID)
+	assert.Equal(t, "Essenciais", result.Category)
+}
+
+func TestCreateExpense_KeepsExplicitCategory(t *testing.T) {
+	ruleRepo := &memoryRuleRepository{}
+	txRepo := new(MockTransactionRepository)
+	rules := services.NewRuleService(ruleRepo, txRepo)
+	rules.CreateRule(context.Background(), 1, domain.CategoryRule{
+		Name: "Mercado", Enabled: true, DescriptionContains: "carrefour", SetCategory: "Essenciais", SetTags: []string{"mercado"},
+	})
+
+	service := services.NewTransactionService(txRepo)
+	service.SetRuleService(rules)
+
+	txRepo.On("Save", mock.MatchedBy(func(tr domain.Transaction) bool {
+		return tr.Category == "Desejos"
+	})).Return(8, nil)
+
+	result, err := service.CreateExpense(context.Background(), 1, 80, "Desejos", "CARREFOUR vinhos", "", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "Desejos", result.Category)
+	assert.Equal(t, []string{"mercado"}, result.Tags)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// The hub keeps the last streamReplaySize events for clients resuming with
+// Last-Event-ID. A client more than streamClientBuffer events behind is
+// dropped and resumes from the buffer when it reconnects.
+const (
+	streamReplaySize   = 512
+	streamClientBuffer = 32
+)
+
+type streamClient struct {
+	userID int
+	events chan domain.Event
+}
+
+// StreamHub fans transaction and goal events out to the open dashboards of
+// their user on this API instance. Events reach every instance through the
+// publisher; without one the hub only sees the events its own bus handles.
+type StreamHub struct {
+	publisher ports.EventPublisher
+	mu        sync.Mutex
+	recent    []domain.Event
+	// floor is the lowest Last-Event-ID the buffer can still resume from.
+	floor   int64
+	latest  int64
+	clients map[*streamClient]struct{}
+}
+
+// NewStreamHub takes the latest event id written before the hub started:
+// events up to it may never have passed through this hub, so clients that
+// last saw one of them are asked to reload.
+func NewStreamHub(latestEventID int64) *StreamHub {
+	return &StreamHub{
+		floor:   latestEventID + 1,
+		latest:  latestEventID,
+		clients: map[*streamClient]struct{}{},
+	}
+}
+
+// SetPublisher sends every event through the publisher so all instances
+// broadcast it; the publisher must feed them back to Broadcast.
+func (h *StreamHub) SetPublisher(publisher ports.EventPublisher) {
+	h.publisher = publisher
+}
+
+// HandleEvent is subscribed to the event bus.
+func (h *StreamHub) HandleEvent(ctx context.Context, event domain.Event) error {
+	if h.publisher != nil {
+		return h.publisher.Publish(ctx, event)
+	}
+	h.Broadcast(event)
+	return nil
+}
+
+// Broadcast buffers the event and sends it to its user's clients. An event
+// already in the buffer is ignored, as the bus may hand it over twice.
+func (h *StreamHub) Broadcast(event domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if slices.ContainsFunc(h.recent, func(e domain.Event) bool { return e.ID == event.ID }) {
+		return
+	}
+	h.recent = append(h.recent, event)
+	if len(h.recent) > streamReplaySize {
+		h.floor = max(h.floor, h.recent[0].ID)
+		h.recent = slices.Delete(h.recent, 0, 1)
+	}
+	h.latest = max(h.latest, event.ID)
+
+	for client := range h.clients {
+		if client.userID != event.UserID {
+			continue
+		}
+		select {
+		case client.events <- event:
+		default:
+			h.drop(client)
+		}
+	}
+}
+
+// Reset forgets the buffer and drops every client, for when events may have
+// been missed (the publisher lost its connection). Clients reconnect and
+// are told to reload.
+func (h *StreamHub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.floor = h.latest + 1
+	h.recent = nil
+	for client := range h.clients {
+		h.drop(client)
+	}
+}
+
+func (h *StreamHub) Subscribe(userID int, lastEventID int64) *ports.StreamSubscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &ports.StreamSubscription{}
+	if lastEventID > 0 {
+		if lastEventID < h.floor {
+			sub.Reset = true
+		} else {
+			for _, e := range h.recent {
+				if e.UserID == userID && e.ID > lastEventID {
+					sub.Replay = append(sub.Replay, e)
+				}
+			}
+		}
+	}
+
+	client := &streamClient{userID: userID, events: make(chan domain.Event, streamClientBuffer)}
+	h.clients[client] = struct{}{}
+	sub.Events = client.events
+	sub.Close = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.drop(client)
+	}
+	return sub
+}
+
+// drop must be called with h.mu held.
+func (h *StreamHub) drop(client *streamClient) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.events)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+func streamEvent(id int64, userID int) domain.Event {
+	return domain.Event{ID: id, Type: domain.EventTransactionCreated, UserID: userID}
+}
+
+func TestStreamHub_BroadcastsToTheUsersClients(t *testing.T) {
+	hub := NewStreamHub(0)
+	mine := hub.Subscribe(1, 0)
+	other := hub.Subscribe(2, 0)
+	defer mine.Close()
+	defer other.Close()
+
+	hub.HandleEvent(context.Background(), streamEvent(1, 1))
+	// The bus may hand the same event over again.
+	hub.HandleEvent(context.Background(), streamEvent(1, 1))
+
+	assert.Equal(t, int64(1), (<-mine.Events).ID)
+	assert.Empty(t, mine.Events)
+	assert.Empty(t, other.Events)
+}
+
+func TestStreamHub_ResumesFromTheBuffer(t *testing.T) {
+	hub := NewStreamHub(10)
+	for id := int64(11); id <= 14; id++ {
+		hub.Broadcast(streamEvent(id, 1))
+	}
+	hub.Broadcast(streamEvent(15, 2))
+
+	sub := hub.Subscribe(1, 12)
+	assert.False(t, sub.Reset)
+	assert.Equal(t, []domain.Event{streamEvent(13, 1), streamEvent(14, 1)}, sub.Replay)
+	sub.Close()
+
+	// Events from before the hub started were never buffered.
+	sub = hub.Subscribe(1, 9)
+	assert.True(t, sub.Reset)
+	assert.Empty(t, sub.Replay)
+	sub.Close()
+
+	for id := int64(100); id < 100+streamReplaySize; id++ {
+		hub.Broadcast(streamEvent(id, 1))
+	}
+	assert.True(t, hub.Subscribe(1, 14).Reset)
+	assert.False(t, hub.Subscribe(1, 99).Reset)
+}
+
+func TestStreamHub_DropsSlowClientsAndResets(t *testing.T) {
+	hub := NewStreamHub(0)
+	slow := hub.Subscribe(1, 0)
+	for id := int64(1); id <= streamClientBuffer+1; id++ {
+		hub.Broadcast(streamEvent(id, 1))
+	}
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	assert.Equal(t, streamClientBuffer, received)
+	slow.Close()
+
+	// The dropped client reconnects and catches up from the buffer.
+	resumed := hub.Subscribe(1, streamClientBuffer)
+	assert.Len(t, resumed.Replay, 1)
+
+	hub.Reset()
+	_, open := <-resumed.Events
+	assert.False(t, open)
+	assert.True(t, hub.Subscribe(1, streamClientBuffer+1).Reset)
+}
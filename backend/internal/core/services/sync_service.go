@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidSyncToken    = domain.Invalid("invalid_sync_token", "invalid sync token")
+	ErrInvalidSyncMutation = domain.Invalid("invalid_sync_mutation", "invalid sync mutation")
+)
+
+// A push carries at most maxSyncMutations mutations. A mutation whose row
+// changes between reading and writing it is merged again, up to
+// syncWriteAttempts times.
+const (
+	maxSyncMutations  = 500
+	syncWriteAttempts = 3
+)
+
+var clientIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// SyncService lets offline clients pull what changed since their token and
+// push what they changed meanwhile.
+//
+// Pushed upserts are merged field by field against the row as the server
+// has it: when the row is still at the mutation's base_version every change
+// applies; otherwise a change applies only when the server value of that
+// field still equals its base value, so edits to different fields on
+// different devices both survive. A field both sides changed to different
+// values keeps the server value and is reported as a conflict, along with
+// the current row. A delete of a row changed since base_version, or an
+// upsert of a deleted row, is a conflict as well: the server's side wins.
+type SyncService struct {
+	repo         ports.SyncRepository
+	transactions ports.TransactionSyncer
+	goals        ports.GoalSyncer
+}
+
+func NewSyncService(repo ports.SyncRepository, transactions ports.TransactionSyncer, goals ports.GoalSyncer) *SyncService {
+	return &SyncService{repo: repo, transactions: transactions, goals: goals}
+}
+
+// Pull returns the changes since token; an empty token returns every row.
+func (s *SyncService) Pull(userID int, token string) (domain.SyncChanges, error) {
+	since, err := parseSyncToken(token)
+	if err != nil {
+		return domain.SyncChanges{}, err
+	}
+	changes, horizon, err := s.repo.Changes(userID, since)
+	if err != nil {
+		return domain.SyncChanges{}, err
+	}
+	return domain.SyncChanges{Token: strconv.FormatInt(horizon, 10), Changes: changes}, nil
+}
+
+// Push applies the mutations in order and then pulls from token, so the
+// response also holds the rows as the mutations left them.
+func (s *SyncService) Push(ctx context.Context, userID int, token string, mutations []domain.SyncMutation) (domain.SyncResponse, error) {
+	if _, err := parseSyncToken(token); err != nil {
+		return domain.SyncResponse{}, err
+	}
+	if err := validation.Validate(ErrInvalidSyncMutation, validation.Field("mutations", len(mutations), validation.Max(maxSyncMutations))); err != nil {
+		return domain.SyncResponse{}, err
+	}
+
+	results := make([]domain.SyncResult, len(mutations))
+	for i, m := range mutations {
+		result, err := s.apply(ctx, userID, m)
+		if err != nil {
+			return domain.SyncResponse{}, err
+		}
+		result.Index = i
+		results[i] = result
+	}
+
+	changes, err := s.Pull(userID, token)
+	if err != nil {
+		return domain.SyncResponse{}, err
+	}
+	return domain.SyncResponse{Results: results, SyncChanges: changes}, nil
+}
+
+func (s *SyncService) apply(ctx context.Context, userID int, m domain.SyncMutation) (domain.SyncResult, error) {
+	m.ClientID = strings.ToLower(m.ClientID)
+	if err := validateSyncMutation(m); err != nil {
+		return rejectedSync(m, err), nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		var result domain.SyncResult
+		var err error
+		if m.Entity == domain.SyncEntityTransaction {
+			result, err = s.applyTransaction(ctx, userID, m)
+		} else {
+			result, err = s.applyGoal(ctx, userID, m)
+		}
+		switch {
+		case errors.Is(err, ports.ErrVersionConflict) && attempt < syncWriteAttempts:
+			continue
+		case errors.Is(err, ports.ErrVersionConflict):
+			return domain.SyncResult{Status: domain.SyncConflict, ID: m.ID, ClientID: m.ClientID, Error: err.Error()}, nil
+		case isValidationError(err):
+			return rejectedSync(m, err), nil
+		}
+		return result, err
+	}
+}
+
+func validateSyncMutation(m domain.SyncMutation) error {
+	return validation.Validate(ErrInvalidSyncMutation,
+		validation.Field("entity", m.Entity, validation.OneOf(domain.SyncEntityTransaction, domain.SyncEntityGoal)),
+		validation.Field("op", m.Op, validation.OneOf(domain.SyncOpUpsert, domain.SyncOpDelete)),
+		validation.When(m.ClientID == "", validation.Field("id", m.ID, func(id int) string {
+			if id == 0 {
+				return "id or client_id is required"
+			}
+			return ""
+		})),
+		validation.When(m.ClientID != "", validation.Field("client_id", m.ClientID, func(clientID string) string {
+			if !clientIDPattern.MatchString(clientID) {
+				return "must be a UUID"
+			}
+			return ""
+		})),
+	)
+}
+
+func rejectedSync(m domain.SyncMutation, err error) domain.SyncResult {
+	result := domain.SyncResult{Status: domain.SyncRejected, ID: m.ID, ClientID: m.ClientID, Error: err.Error()}
+	if e, ok := domain.AsError(err); ok {
+		result.Errors = e.Fields
+	}
+	return result
+}
+
+// isValidationError tells a mutation the services refuse, such as one with
+// an invalid field, from a failure to apply it.
+func isValidationError(err error) bool {
+	e, ok := domain.AsError(err)
+	return ok && e.Kind == domain.KindValidation
+}
+
+// syncOutcome reports a mutation on the row now at current, a conflict when
+// some of its changes were not applied.
+func syncOutcome(current domain.SyncChange, conflicts []string) domain.SyncResult {
+	result := domain.SyncResult{Status: domain.SyncApplied, ID: current.ID, ClientID: current.ClientID, Version: current.Version}
+	if len(conflicts) > 0 {
+		result.Status = domain.SyncConflict
+		result.Conflicts = conflicts
+		result.Current = &current
+	}
+	return result
+}
+
+func syncDeleteConflict(current domain.SyncChange, reason string) domain.SyncResult {
+	return domain.SyncResult{
+		Status:   domain.SyncConflict,
+		ID:       current.ID,
+		ClientID: current.ClientID,
+		Version:  current.Version,
+		Error:    reason,
+		Current:  &current,
+	}
+}
+
+// transactionFields are the transaction fields a client can change.
+type transactionFields struct {
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	Account     string    `json:"account"`
+	Date        time.Time `json:"date"`
+	Tags        []string  `json:"tags"`
+	Bucket      string    `json:"bucket"`
+}
+
+func newTransactionFields(t domain.Transaction) transactionFields {
+	return transactionFields{
+		Type:        t.Type,
+		Amount:      t.Amount,
+		Category:    t.Category,
+		Description: t.Description,
+		Account:     t.Account,
+		Date:        t.Date,
+		Tags:        t.Tags,
+		Bucket:      t.Bucket,
+	}
+}
+
+func normalizeTransactionFields(f *transactionFields) {
+	f.Date = f.Date.UTC()
+	if len(f.Tags) == 0 {
+		f.Tags = nil
+	}
+}
+
+func (f transactionFields) applyTo(t domain.Transaction) (domain.Transaction, error) {
+	if err := validation.Validate(ErrInvalidSyncMutation, validation.Field("type", f.Type, validation.OneOf("income", "expense"))); err != nil {
+		return t, err
+	}
+	t.Type = f.Type
+	t.Amount = f.Amount
+	t.Category = f.Category
+	t.Description = f.Description
+	t.Account = f.Account
+	t.Date = f.Date
+	t.Tags = f.Tags
+	t.Bucket = f.Bucket
+	return t, nil
+}
+
+func (s *SyncService) applyTransaction(ctx context.Context, userID int, m domain.SyncMutation) (domain.SyncResult, error) {
+	current, err := s.repo.FindTransaction(userID, m.ID, m.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		switch {
+		case m.Op == domain.SyncOpDelete:
+			return domain.SyncResult{Status: domain.SyncApplied, ID: m.ID, ClientID: m.ClientID}, nil
+		case m.ID != 0:
+			return domain.SyncResult{}, ErrInvalidSyncMutation.WithField("id", "transaction not found")
+		}
+		fields, _, err := mergeSyncFields(transactionFields{}, m, -1, normalizeTransactionFields)
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		t, err := fields.applyTo(domain.Transaction{UserID: userID, ClientID: m.ClientID})
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		created, err := s.transactions.CreateSynced(ctx, t)
+		if errors.Is(err, ports.ErrClientIDExists) {
+			// Created by a concurrent push: merge into it instead.
+			return domain.SyncResult{}, ports.ErrVersionConflict
+		}
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		return syncOutcome(domain.TransactionChange(created), nil), nil
+	}
+	if err != nil {
+		return domain.SyncResult{}, err
+	}
+
+	if m.Op == domain.SyncOpDelete {
+		if current.DeletedAt != nil {
+			return syncOutcome(domain.TransactionChange(current), nil), nil
+		}
+		if m.BaseVersion != 0 && m.BaseVersion != current.Version {
+			return syncDeleteConflict(domain.TransactionChange(current), "changed on the server since base_version"), nil
+		}
+		if err := s.transactions.DeleteSynced(ctx, userID, current.ID, current.Version); err != nil {
+			return domain.SyncResult{}, err
+		}
+		return domain.SyncResult{Status: domain.SyncApplied, ID: current.ID, ClientID: current.ClientID}, nil
+	}
+
+	if current.DeletedAt != nil {
+		return syncDeleteConflict(domain.TransactionChange(current), "deleted on the server"), nil
+	}
+	existing := newTransactionFields(current)
+	normalizeTransactionFields(&existing)
+	merged, conflicts, err := mergeSyncFields(existing, m, current.Version, normalizeTransactionFields)
+	if err != nil {
+		return domain.SyncResult{}, err
+	}
+	if !reflect.DeepEqual(merged, existing) {
+		t, err := merged.applyTo(current)
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		if current, err = s.transactions.UpdateSynced(ctx, t); err != nil {
+			return domain.SyncResult{}, err
+		}
+	}
+	return syncOutcome(domain.TransactionChange(current), conflicts), nil
+}
+
+// goalFields are the goal fields a client can change; progress is added
+// online only.
+type goalFields struct {
+	Name         string    `json:"name"`
+	TargetAmount float64   `json:"target_amount"`
+	Deadline     time.Time `json:"deadline"`
+}
+
+func normalizeGoalFields(f *goalFields) {
+	f.Deadline = f.Deadline.UTC()
+}
+
+func (f goalFields) applyTo(g domain.Goal) (domain.Goal, error) {
+	if err := validation.Validate(ErrInvalidSyncMutation, validation.Field("name", f.Name, validation.Required)); err != nil {
+		return g, err
+	}
+	g.Name = f.Name
+	g.TargetAmount = f.TargetAmount
+	g.Deadline = f.Deadline
+	return g, nil
+}
+
+func (s *SyncService) applyGoal(ctx context.Context, userID int, m domain.SyncMutation) (domain.SyncResult, error) {
+	current, err := s.repo.FindGoal(userID, m.ID, m.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		switch {
+		case m.Op == domain.SyncOpDelete:
+			return domain.SyncResult{Status: domain.SyncApplied, ID: m.ID, ClientID: m.ClientID}, nil
+		case m.ID != 0:
+			return domain.SyncResult{}, ErrInvalidSyncMutation.WithField("id", "goal not found")
+		}
+		fields, _, err := mergeSyncFields(goalFields{}, m, -1, normalizeGoalFields)
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		g, err := fields.applyTo(domain.Goal{UserID: userID, ClientID: m.ClientID})
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		created, err := s.goals.CreateSynced(ctx, g)
+		if errors.Is(err, ports.ErrClientIDExists) {
+			return domain.SyncResult{}, ports.ErrVersionConflict
+		}
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		return syncOutcome(domain.GoalChange(created), nil), nil
+	}
+	if err != nil {
+		return domain.SyncResult{}, err
+	}
+
+	if m.Op == domain.SyncOpDelete {
+		if current.DeletedAt != nil {
+			return syncOutcome(domain.GoalChange(current), nil), nil
+		}
+		if m.BaseVersion != 0 && m.BaseVersion != current.Version {
+			return syncDeleteConflict(domain.GoalChange(current), "changed on the server since base_version"), nil
+		}
+		if err := s.goals.DeleteSynced(ctx, userID, current.ID, current.Version); err != nil {
+			return domain.SyncResult{}, err
+		}
+		return domain.SyncResult{Status: domain.SyncApplied, ID: current.ID, ClientID: current.ClientID}, nil
+	}
+
+	if current.DeletedAt != nil {
+		return syncDeleteConflict(domain.GoalChange(current), "deleted on the server"), nil
+	}
+	existing := goalFields{Name: current.Name, TargetAmount: current.TargetAmount, Deadline: current.Deadline}
+	normalizeGoalFields(&existing)
+	merged, conflicts, err := mergeSyncFields(existing, m, current.Version, normalizeGoalFields)
+	if err != nil {
+		return domain.SyncResult{}, err
+	}
+	if !reflect.DeepEqual(merged, existing) {
+		g, err := merged.applyTo(current)
+		if err != nil {
+			return domain.SyncResult{}, err
+		}
+		if current, err = s.goals.UpdateSynced(ctx, g); err != nil {
+			return domain.SyncResult{}, err
+		}
+	}
+	return syncOutcome(domain.GoalChange(current), conflicts), nil
+}
+
+// mergeSyncFields applies the mutation's changes to current, the row's
+// fields at version, and returns the fields whose change was not applied.
+// Pass version -1 for a new row, which takes every change.
+func mergeSyncFields[F any](current F, m domain.SyncMutation, version int, normalize func(*F)) (F, []string, error) {
+	var zero F
+	fields, err := syncFieldMap(current)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	names := make([]string, 0, len(m.Changes))
+	for name := range m.Changes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var conflicts []string
+	for _, name := range names {
+		value := m.Changes[name]
+		if _, ok := fields[name]; !ok {
+			return zero, nil, ErrInvalidSyncMutation.WithField(name, "is not a field of this entity")
+		}
+		same, err := sameSyncField(current, name, value, normalize)
+		if err != nil {
+			return zero, nil, err
+		}
+		switch {
+		case same:
+			continue
+		case version == -1 || m.BaseVersion == version:
+		default:
+			unchanged := false
+			if base, ok := m.Base[name]; ok {
+				if unchanged, err = sameSyncField(current, name, base, normalize); err != nil {
+					return zero, nil, err
+				}
+			}
+			if !unchanged {
+				conflicts = append(conflicts, name)
+				continue
+			}
+		}
+		fields[name] = value
+	}
+
+	merged, err := fromSyncFieldMap[F](fields)
+	if err != nil {
+		return zero, nil, err
+	}
+	normalize(&merged)
+	return merged, conflicts, nil
+}
+
+// sameSyncField tells whether setting the field to value leaves current as
+// it is.
+func sameSyncField[F any](current F, name string, value json.RawMessage, normalize func(*F)) (bool, error) {
+	fields, err := syncFieldMap(current)
+	if err != nil {
+		return false, err
+	}
+	fields[name] = value
+	updated, err := fromSyncFieldMap[F](fields)
+	if err != nil {
+		return false, err
+	}
+	normalize(&updated)
+	normalize(&current)
+	return reflect.DeepEqual(updated, current), nil
+}
+
+func syncFieldMap[F any](f F) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// fromSyncFieldMap reads the fields back into F, naming a field of the wrong
+// type rather than passing on the decoder's message.
+func fromSyncFieldMap[F any](fields map[string]json.RawMessage) (F, error) {
+	var f F
+	data, err := json.Marshal(fields)
+	if err == nil {
+		err = json.Unmarshal(data, &f)
+	}
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return f, ErrInvalidSyncMutation.WithField(typeErr.Field, "has the wrong type")
+	case err != nil:
+		return f, ErrInvalidSyncMutation.WithField("fields", "are not valid JSON")
+	}
+	return f, nil
+}
+
+func parseSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	since, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || since <= 0 {
+		return 0, ErrInvalidSyncToken
+	}
+	return since, nil
+}
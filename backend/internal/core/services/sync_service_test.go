@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+// memorySyncStore is the sync repository and both syncers over the same
+// rows, bumping versions the way the database triggers do.
+type memorySyncStore struct {
+	transactions []domain.Transaction
+	goals        []domain.Goal
+}
+
+func (m *memorySyncStore) Changes(userID int, since int64) ([]domain.SyncChange, int64, error) {
+	changes := []domain.SyncChange{}
+	for _, t := range m.transactions {
+		if t.UserID == userID && (since > 0 || t.DeletedAt == nil) {
+			changes = append(changes, domain.TransactionChange(t))
+		}
+	}
+	for _, g := range m.goals {
+		if g.UserID == userID && (since > 0 || g.DeletedAt == nil) {
+			changes = append(changes, domain.GoalChange(g))
+		}
+	}
+	return changes, 42, nil
+}
+
+func (m *memorySyncStore) FindTransaction(userID, id int, clientID string) (domain.Transaction, error) {
+	for _, t := range m.transactions {
+		if t.UserID == userID && (t.ID == id || (id == 0 && t.ClientID == clientID)) {
+			return t, nil
+		}
+	}
+	return domain.Transaction{}, sql.ErrNoRows
+}
+
+func (m *memorySyncStore) FindGoal(userID, id int, clientID string) (domain.Goal, error) {
+	for _, g := range m.goals {
+		if g.UserID == userID && (g.ID == id || (id == 0 && g.ClientID == clientID)) {
+			return g, nil
+		}
+	}
+	return domain.Goal{}, sql.ErrNoRows
+}
+
+func (m *memorySyncStore) CreateSynced(ctx context.Context, t domain.Transaction) (domain.Transaction, error) {
+	t.ID = len(m.transactions) + 1
+	t.Version = 1
+	m.transactions = append(m.transactions, t)
+	return t, nil
+}
+
+func (m *memorySyncStore) UpdateSynced(ctx context.Context, t domain.Transaction) (domain.Transaction, error) {
+	current := &m.transactions[t.ID-1]
+	if t.Version != current.Version {
+		return domain.Transaction{}, ports.ErrVersionConflict
+	}
+	t.Version++
+	*current = t
+	return t, nil
+}
+
+func (m *memorySyncStore) DeleteSynced(ctx context.Context, userID, id, version int) error {
+	current := &m.transactions[id-1]
+	if version != current.Version {
+		return ports.ErrVersionConflict
+	}
+	now := time.Now()
+	current.DeletedAt = &now
+	current.Version++
+	return nil
+}
+
+type memoryGoalSyncer struct{ store *memorySyncStore }
+
+func (m memoryGoalSyncer) CreateSynced(ctx context.Context, g domain.Goal) (domain.Goal, error) {
+	g.ID = len(m.store.goals) + 1
+	g.Version = 1
+	m.store.goals = append(m.store.goals, g)
+	return g, nil
+}
+
+func (m memoryGoalSyncer) UpdateSynced(ctx context.Context, g domain.Goal) (domain.Goal, error) {
+	g.Version++
+	m.store.goals[g.ID-1] = g
+	return g, nil
+}
+
+func (m memoryGoalSyncer) DeleteSynced(ctx context.Context, userID, id, version int) error {
+	now := time.Now()
+	m.store.goals[id-1].DeletedAt = &now
+	return nil
+}
+
+func newTestSyncService() (*SyncService, *memorySyncStore) {
+	store := &memorySyncStore{}
+	return NewSyncService(store, store, memoryGoalSyncer{store: store}), store
+}
+
+func syncFields(fields map[string]any) map[string]json.RawMessage {
+	raw := map[string]json.RawMessage{}
+	for name, value := range fields {
+		data, _ := json.Marshal(value)
+		raw[name] = data
+	}
+	return raw
+}
+
+const testClientID = "6f1c2a0e-8a4b-4c4e-9f7d-2b1e5a3c9d10"
+
+func TestSync_PullTokens(t *testing.T) {
+	service, store := newTestSyncService()
+	store.transactions = []domain.Transaction{{ID: 1, UserID: 1, Type: "expense", Amount: 10, Version: 1}}
+
+	changes, err := service.Pull(1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", changes.Token)
+	assert.Len(t, changes.Changes, 1)
+
+	_, err = service.Pull(1, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidSyncToken)
+	_, err = service.Push(context.Background(), 1, "-1", nil)
+	assert.ErrorIs(t, err, ErrInvalidSyncToken)
+}
+
+func TestSync_CreateIsIdempotentByClientID(t *testing.T) {
+	service, store := newTestSyncService()
+	create := domain.SyncMutation{
+		Entity:   domain.SyncEntityTransaction,
+		Op:       domain.SyncOpUpsert,
+		ClientID: testClientID,
+		Changes:  syncFields(map[string]any{"type": "expense", "amount": 35.5, "category": "Mercado", "date": "2025-03-10T00:00:00Z"}),
+	}
+
+	first, err := service.Push(context.Background(), 1, "", []domain.SyncMutation{create})
+	assert.NoError(t, err)
+	retry, err := service.Push(context.Background(), 1, "", []domain.SyncMutation{create})
+	assert.NoError(t, err)
+
+	assert.Len(t, store.transactions, 1)
+	assert.Equal(t, domain.SyncApplied, first.Results[0].Status)
+	assert.Equal(t, domain.SyncApplied, retry.Results[0].Status)
+	assert.Equal(t, first.Results[0].ID, retry.Results[0].ID)
+	assert.Equal(t, 1, retry.Results[0].Version)
+	assert.Equal(t, 35.5, store.transactions[0].Amount)
+	assert.Equal(t, "42", retry.Token)
+}
+
+func TestSync_MergesFieldsAndReportsConflicts(t *testing.T) {
+	service, store := newTestSyncService()
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	// Synced at version 1 with amount 100 and category Mercado; another
+	// device has since changed the category.
+	store.transactions = []domain.Transaction{{ID: 1, UserID: 1, Type: "expense", Amount: 100, Category: "Feira", Date: date, Version: 2}}
+
+	response, err := service.Push(context.Background(), 1, "7", []domain.SyncMutation{
+		{
+			Entity:      domain.SyncEntityTransaction,
+			Op:          domain.SyncOpUpsert,
+			ID:          1,
+			BaseVersion: 1,
+			Base:        syncFields(map[string]any{"amount": 100, "category": "Mercado"}),
+			Changes:     syncFields(map[string]any{"amount": 120, "category": "Restaurante"}),
+		},
+	})
+
+	assert.NoError(t, err)
+	result := response.Results[0]
+	assert.Equal(t, domain.SyncConflict, result.Status)
+	assert.Equal(t, []string{"category"}, result.Conflicts)
+	assert.Equal(t, 3, result.Version)
+	assert.Equal(t, "Feira", result.Current.Transaction.Category)
+	assert.Equal(t, 120.0, store.transactions[0].Amount)
+	assert.Equal(t, "Feira", store.transactions[0].Category)
+}
+
+func TestSync_UnchangedRowTakesEveryChange(t *testing.T) {
+	service, store := newTestSyncService()
+	store.goals = []domain.Goal{{ID: 1, UserID: 1, Name: "Viagem", TargetAmount: 5000, Version: 4}}
+
+	response, err := service.Push(context.Background(), 1, "7", []domain.SyncMutation{
+		{
+			Entity:      domain.SyncEntityGoal,
+			Op:          domain.SyncOpUpsert,
+			ID:          1,
+			BaseVersion: 4,
+			Changes:     syncFields(map[string]any{"name": "Viagem ao Chile", "target_amount": 8000}),
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SyncApplied, response.Results[0].Status)
+	assert.Equal(t, 5, response.Results[0].Version)
+	assert.Equal(t, "Viagem ao Chile", store.goals[0].Name)
+	assert.Equal(t, 8000.0, store.goals[0].TargetAmount)
+}
+
+func TestSync_Deletes(t *testing.T) {
+	service, store := newTestSyncService()
+	store.transactions = []domain.Transaction{
+		{ID: 1, UserID: 1, Type: "expense", Amount: 10, Version: 3},
+		{ID: 2, UserID: 1, Type: "expense", Amount: 20, Version: 1},
+	}
+
+	response, err := service.Push(context.Background(), 1, "7", []domain.SyncMutation{
+		{Entity: domain.SyncEntityTransaction, Op: domain.SyncOpDelete, ID: 1, BaseVersion: 2},
+		{Entity: domain.SyncEntityTransaction, Op: domain.SyncOpDelete, ID: 2, BaseVersion: 1},
+		{Entity: domain.SyncEntityTransaction, Op: domain.SyncOpDelete, ID: 99},
+		{Entity: domain.SyncEntityTransaction, Op: domain.SyncOpUpsert, ID: 2, Changes: syncFields(map[string]any{"amount": 25})},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SyncConflict, response.Results[0].Status)
+	assert.Nil(t, store.transactions[0].DeletedAt)
+	assert.Equal(t, domain.SyncApplied, response.Results[1].Status)
+	assert.NotNil(t, store.transactions[1].DeletedAt)
+	assert.Equal(t, domain.SyncApplied, response.Results[2].Status)
+	assert.Equal(t, domain.SyncConflict, response.Results[3].Status)
+	assert.True(t, response.Results[3].Current.Deleted)
+	assert.Equal(t, 3, response.Results[3].Index)
+}
+
+func TestSync_RejectsInvalidMutations(t *testing.T) {
+	service, store := newTestSyncService()
+	store.goals = []domain.Goal{{ID: 1, UserID: 1, Name: "Viagem", Version: 1}}
+
+	response, err := service.Push(context.Background(), 1, "", []domain.SyncMutation{
+		{Entity: "budget", Op: domain.SyncOpUpsert, ID: 1},
+		{Entity: domain.SyncEntityGoal, Op: domain.SyncOpUpsert, ClientID: "not-a-uuid"},
+		{Entity: domain.SyncEntityGoal, Op: domain.SyncOpUpsert, ID: 1, BaseVersion: 1, Changes: syncFields(map[string]any{"current_amount": 500})},
+		{Entity: domain.SyncEntityGoal, Op: domain.SyncOpUpsert, ID: 1, BaseVersion: 1, Changes: syncFields(map[string]any{"name": ""})},
+		{Entity: domain.SyncEntityTransaction, Op: domain.SyncOpUpsert, ClientID: testClientID, Changes: syncFields(map[string]any{"type": "transfer"})},
+		{Entity: domain.SyncEntityGoal, Op: domain.SyncOpUpsert, ID: 1, BaseVersion: 1, Changes: syncFields(map[string]any{"target_amount": "muito"})},
+	})
+
+	assert.NoError(t, err)
+	for _, result := range response.Results {
+		assert.Equal(t, domain.SyncRejected, result.Status, result.Error)
+	}
+	assert.Equal(t, []domain.FieldError{{Field: "entity", Message: "must be one of transaction, goal"}}, response.Results[0].Errors)
+	assert.Equal(t, []domain.FieldError{{Field: "client_id", Message: "must be a UUID"}}, response.Results[1].Errors)
+	assert.NotContains(t, response.Results[5].Error, "json")
+	assert.Equal(t, []domain.FieldError{{Field: "target_amount", Message: "has the wrong type"}}, response.Results[5].Errors)
+	assert.Equal(t, "Viagem", store.goals[0].Name)
+	assert.Empty(t, store.transactions)
+}
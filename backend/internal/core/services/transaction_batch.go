@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var (
+	ErrInvalidBatch          = domain.Invalid("invalid_batch", "invalid batch")
+	ErrInvalidBatchOperation = domain.Invalid("invalid_batch_operation", "batch operation is invalid")
+)
+
+// A batch carries at most maxBatchOperations operations.
+const maxBatchOperations = 500
+
+// ApplyBatch validates every operation and then applies them: in atomic
+// mode, the default, all in one database transaction or none at all, and
+// in partial mode one by one, each succeeding or failing on its own. The
+// error is for the batch as a whole; a failed operation is reported in its
+// result.
+func (s *TransactionService) ApplyBatch(ctx context.Context, userID int, mode string, ops []domain.BatchOperation) ([]domain.BatchResult, error) {
+	if mode == "" {
+		mode = domain.BatchModeAtomic
+	}
+	err := validation.Validate(ErrInvalidBatch,
+		validation.Field("mode", mode, validation.OneOf(domain.BatchModeAtomic, domain.BatchModePartial)),
+		validation.Field("operations", len(ops), validation.Min(1), validation.Max(maxBatchOperations)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.BatchResult, len(ops))
+	prepared := make([]domain.Transaction, len(ops))
+	invalid := false
+	seen := make(map[int]bool)
+	for i, op := range ops {
+		results[i] = domain.BatchResult{Index: i, Op: op.Op, ID: op.ID}
+		t, err := s.prepareBatchOperation(userID, op, seen)
+		if err != nil {
+			if _, ok := domain.AsError(err); !ok {
+				return nil, err
+			}
+			failBatchResult(&results[i], err)
+			invalid = true
+			continue
+		}
+		prepared[i] = t
+	}
+
+	if mode == domain.BatchModePartial {
+		for i, op := range ops {
+			if results[i].Status == domain.BatchFailed {
+				continue
+			}
+			results[i] = s.applyBatchOperation(ctx, i, op, prepared[i])
+		}
+		return results, nil
+	}
+
+	if invalid {
+		return skipRest(results, -1), nil
+	}
+	return s.applyAtomicBatch(ctx, userID, ops, prepared, results)
+}
+
+// prepareBatchOperation checks op and returns the transaction it writes,
+// categorized by the user's rules for a create.
+func (s *TransactionService) prepareBatchOperation(userID int, op domain.BatchOperation, seen map[int]bool) (domain.Transaction, error) {
+	// Each write would need the version left by the one before it; such
+	// edits belong in a single operation.
+	duplicate := false
+	if op.Op != domain.BatchOpCreate {
+		duplicate = seen[op.ID]
+		seen[op.ID] = true
+	}
+
+	err := validation.Validate(ErrInvalidBatchOperation,
+		validation.Field("op", op.Op, validation.OneOf(domain.BatchOpCreate, domain.BatchOpUpdate, domain.BatchOpDelete)),
+		validation.When(op.Op == domain.BatchOpCreate, validation.Field("id", op.ID, func(id int) string {
+			if id != 0 {
+				return "must be empty for a create"
+			}
+			return ""
+		})),
+		validation.When(op.Op != domain.BatchOpCreate, validation.Field("id", op.ID, validation.Positive, func(int) string {
+			if duplicate {
+				return "appears more than once in the batch"
+			}
+			return ""
+		})),
+		validation.Field("version", op.Version, validation.Min(0)),
+	)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if op.Op == domain.BatchOpDelete {
+		return domain.Transaction{ID: op.ID, UserID: userID, Version: op.Version}, nil
+	}
+
+	t := newBatchTransaction(userID, op)
+	if op.Op == domain.BatchOpCreate {
+		if t, err = s.categorize(t); err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+	return t, validateTransaction(t)
+}
+
+// applyBatchOperation applies one operation of a partial batch through the
+// same paths as the single-item endpoints.
+func (s *TransactionService) applyBatchOperation(ctx context.Context, index int, op domain.BatchOperation, t domain.Transaction) domain.BatchResult {
+	result := domain.BatchResult{Index: index, Op: op.Op, ID: op.ID, Status: domain.BatchApplied}
+	var err error
+	switch op.Op {
+	case domain.BatchOpCreate:
+		var created domain.Transaction
+		created, err = s.save(ctx, t)
+		result.ID, result.Version = created.ID, created.Version
+	case domain.BatchOpUpdate:
+		var updated domain.Transaction
+		updated, err = s.UpdateTransaction(ctx, t.UserID, op.ID, op.Version, t.Amount, t.Category, t.Description, t.Account, t.Date, t.Type)
+		result.Version = updated.Version
+	case domain.BatchOpDelete:
+		err = s.DeleteTransaction(ctx, t.UserID, op.ID, op.Version)
+	}
+	if err != nil {
+		result.Version = 0
+		if op.Op == domain.BatchOpCreate {
+			result.ID = 0
+		}
+		failBatchResult(&result, err)
+	}
+	return result
+}
+
+// applyAtomicBatch reads the rows the batch changes, so the writes apply
+// only over the versions read, and writes everything in one transaction.
+func (s *TransactionService) applyAtomicBatch(ctx context.Context, userID int, ops []domain.BatchOperation, prepared []domain.Transaction, results []domain.BatchResult) ([]domain.BatchResult, error) {
+	writes := make([]ports.TransactionWrite, len(ops))
+	existing := make([]domain.Transaction, len(ops))
+	for i, op := range ops {
+		t := prepared[i]
+		if op.Op == domain.BatchOpCreate {
+			writes[i] = ports.TransactionWrite{
+				Transaction: t,
+				Events: append([]domain.Event{newEvent(domain.EventTransactionCreated, userID, domain.AggregateTransaction, 0, t)},
+					auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, 0, domain.AuditActionCreate, nil, t)...),
+			}
+			continue
+		}
+
+		current, err := s.repo.GetByID(op.ID, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return failAt(results, i, err), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if op.Version != 0 && op.Version != current.Version {
+			return failAt(results, i, ports.ErrVersionConflict), nil
+		}
+		existing[i] = current
+		t.Version = current.Version
+
+		if op.Op == domain.BatchOpDelete {
+			writes[i] = ports.TransactionWrite{
+				Transaction: t,
+				Delete:      true,
+				Events: append([]domain.Event{newEvent(domain.EventTransactionDeleted, userID, domain.AggregateTransaction, op.ID, current)},
+					auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, op.ID, domain.AuditActionDelete, current, nil)...),
+			}
+			continue
+		}
+		t.ID = op.ID
+		t.Tags = current.Tags
+		t.Bucket = current.Bucket
+		t.CreatedAt = current.CreatedAt
+		writes[i] = ports.TransactionWrite{
+			Transaction: t,
+			Events: append([]domain.Event{newEvent(domain.EventTransactionUpdated, userID, domain.AggregateTransaction, op.ID, t)},
+				auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, op.ID, domain.AuditActionUpdate, current, t)...),
+		}
+	}
+
+	written, err := s.repo.ApplyBatch(writes)
+	var batchErr *ports.BatchError
+	if errors.As(err, &batchErr) && isBatchOperationError(batchErr.Err) {
+		return failAt(results, batchErr.Index, batchErr.Err), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		t := written[i]
+		results[i].Status = domain.BatchApplied
+		results[i].ID = t.ID
+		switch op.Op {
+		case domain.BatchOpCreate:
+			results[i].Version = t.Version
+			s.afterCreate(t)
+		case domain.BatchOpUpdate:
+			results[i].Version = t.Version
+			if s.suggestions != nil {
+				s.suggestions.Forget(existing[i])
+				s.suggestions.Learn(t)
+			}
+		case domain.BatchOpDelete:
+			if s.suggestions != nil {
+				s.suggestions.Forget(existing[i])
+			}
+		}
+	}
+	return results, nil
+}
+
+func newBatchTransaction(userID int, op domain.BatchOperation) domain.Transaction {
+	date := op.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	return domain.Transaction{
+		UserID:      userID,
+		Type:        op.Type,
+		Amount:      op.Amount,
+		Category:    op.Category,
+		Description: op.Description,
+		Account:     op.Account,
+		Date:        date,
+	}
+}
+
+func failBatchResult(result *domain.BatchResult, err error) {
+	result.Status = domain.BatchFailed
+	result.Error = batchErrorMessage(err)
+	if e, ok := domain.AsError(err); ok {
+		result.Errors = e.Fields
+	}
+}
+
+// failAt marks the operation at index as failed and, the batch being
+// atomic, every other one as skipped.
+func failAt(results []domain.BatchResult, index int, err error) []domain.BatchResult {
+	failBatchResult(&results[index], err)
+	return skipRest(results, index)
+}
+
+func skipRest(results []domain.BatchResult, failed int) []domain.BatchResult {
+	for i := range results {
+		if i == failed || results[i].Status == domain.BatchFailed {
+			continue
+		}
+		results[i].Status = domain.BatchSkipped
+		results[i].Version = 0
+		if results[i].Op == domain.BatchOpCreate {
+			results[i].ID = 0
+		}
+	}
+	return results
+}
+
+// isBatchOperationError tells the errors caused by an operation itself from
+// those of the database, which fail the whole request.
+func isBatchOperationError(err error) bool {
+	_, ok := domain.AsError(err)
+	return ok || errors.Is(err, sql.ErrNoRows)
+}
+
+func batchErrorMessage(err error) string {
+	if errors.Is(err, sql.ErrNoRows) {
+		return "transaction not found"
+	}
+	if _, ok := domain.AsError(err); ok {
+		return err.Error()
+	}
+	log.Printf("batch: operation failed: %v", err)
+	return "operation could not be applied"
+}
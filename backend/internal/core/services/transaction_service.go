@@ -1,14 +1,31 @@
 package services
 
 import (
+	"context"
+	"log"
 	"time"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/validation"
+)
+
+var ErrInvalidTransaction = domain.Invalid("invalid_transaction", "transaction is invalid")
+
+// maxTransactionAmount is the largest amount the DECIMAL(10, 2) column
+// holds. Names fit the VARCHAR(255) columns, and free text is capped too.
+const (
+	maxTransactionAmount = 99999999.99
+	maxNameLength        = 255
+	maxDescriptionLength = 1000
 )
 
 type TransactionService struct {
-	repo ports.TransactionRepository
+	repo        ports.TransactionRepository
+	rules       ports.RuleService
+	suggestions ports.CategorySuggestionService
+	audit       ports.AuditService
+	budgets     ports.BudgetService
 }
 
 func NewTransactionService(repo ports.TransactionRepository) *TransactionService {
@@ -17,7 +34,28 @@ func NewTransactionService(repo ports.TransactionRepository) *TransactionService
 	}
 }
 
-func (s *TransactionService) CreateIncome(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error) {
+// SetRuleService enables automatic categorization of new transactions.
+func (s *TransactionService) SetRuleService(rules ports.RuleService) {
+	s.rules = rules
+}
+
+// SetCategorySuggestionService keeps the learned category model in sync with
+// every change made through this service.
+func (s *TransactionService) SetCategorySuggestionService(suggestions ports.CategorySuggestionService) {
+	s.suggestions = suggestions
+}
+
+// SetAuditService records every change made through this service.
+func (s *TransactionService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// SetBudgetService enables budget threshold alerts for new expenses.
+func (s *TransactionService) SetBudgetService(budgets ports.BudgetService) {
+	s.budgets = budgets
+}
+
+func (s *TransactionService) CreateIncome(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error) {
 	if date.IsZero() {
 		date = time.Now()
 	}
@@ -27,19 +65,14 @@ func (s *TransactionService) CreateIncome(userID int, amount float64, category,
 		Amount:      amount,
 		Category:    category,
 		Description: description,
+		Account:     account,
 		Date:        date,
 	}
 
-	id, err := s.repo.Save(transaction)
-	if err != nil {
-		return domain.Transaction{}, err
-	}
-
-	transaction.ID = id
-	return transaction, nil
+	return s.create(ctx, transaction)
 }
 
-func (s *TransactionService) CreateExpense(userID int, amount float64, category, description string, date time.Time) (domain.Transaction, error) {
+func (s *TransactionService) CreateExpense(ctx context.Context, userID int, amount float64, category, description, account string, date time.Time) (domain.Transaction, error) {
 	if date.IsZero() {
 		date = time.Now()
 	}
@@ -49,42 +82,225 @@ func (s *TransactionService) CreateExpense(userID int, amount float64, category,
 		Amount:      amount,
 		Category:    category,
 		Description: description,
+		Account:     account,
 		Date:        date,
 	}
 
-	id, err := s.repo.Save(transaction)
+	return s.create(ctx, transaction)
+}
+
+// create applies categorization rules and persists a new transaction. Every
+// entry point that adds transactions (manual entry, imports) goes through it.
+func (s *TransactionService) create(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error) {
+	transaction, err := s.categorize(transaction)
 	if err != nil {
 		return domain.Transaction{}, err
 	}
+	return s.save(ctx, transaction)
+}
+
+func (s *TransactionService) categorize(transaction domain.Transaction) (domain.Transaction, error) {
+	if s.rules == nil {
+		return transaction, nil
+	}
+	return s.rules.ApplyRules(transaction)
+}
 
-	transaction.ID = id
+// save validates and persists a categorized transaction.
+func (s *TransactionService) save(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error) {
+	if err := validateTransaction(transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+	events := append([]domain.Event{newEvent(domain.EventTransactionCreated, transaction.UserID, domain.AggregateTransaction, 0, transaction)},
+		auditEvents(s.audit, ctx, transaction.UserID, domain.AuditEntityTransaction, 0, domain.AuditActionCreate, nil, transaction)...)
+	transaction, err := s.repo.Save(transaction, events...)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	s.afterCreate(transaction)
 	return transaction, nil
 }
 
-func (s *TransactionService) UpdateTransaction(userID, id int, amount float64, category, description string, date time.Time, typeStr string) error {
+// afterCreate learns from and checks budgets for a saved transaction.
+func (s *TransactionService) afterCreate(transaction domain.Transaction) {
+	if s.suggestions != nil {
+		s.suggestions.Learn(transaction)
+	}
+	if s.budgets != nil && transaction.Type == "expense" {
+		if _, err := s.budgets.CheckExpense(transaction); err != nil {
+			log.Printf("budget: could not check alerts for transaction %d: %v", transaction.ID, err)
+		}
+	}
+}
+
+func (s *TransactionService) GetTransaction(userID, id int) (domain.Transaction, error) {
+	return s.repo.GetByID(id, userID)
+}
+
+func (s *TransactionService) UpdateTransaction(ctx context.Context, userID, id, version int, amount float64, category, description, account string, date time.Time, typeStr string) (domain.Transaction, error) {
 	if date.IsZero() {
 		date = time.Now()
 	}
+	// Checked before the read, so a bad request is told so even for a
+	// transaction that is gone.
+	if err := validateTransaction(domain.Transaction{Type: typeStr, Amount: amount, Category: category, Description: description, Account: account}); err != nil {
+		return domain.Transaction{}, err
+	}
+	existing, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
 	t := domain.Transaction{
 		ID:          id,
 		UserID:      userID,
 		Amount:      amount,
 		Category:    category,
 		Description: description,
+		Account:     account,
+		Tags:        existing.Tags,
+		Bucket:      existing.Bucket,
 		Date:        date,
 		Type:        typeStr,
+		Version:     version,
+	}
+	if err := s.update(ctx, existing, t); err != nil {
+		return domain.Transaction{}, err
 	}
-	return s.repo.Update(t)
+	return s.repo.GetByID(id, userID)
 }
 
-func (s *TransactionService) DeleteTransaction(userID, id int) error {
-	return s.repo.Delete(id, userID)
+// update writes t over existing, the row as it was read before the change.
+func (s *TransactionService) update(ctx context.Context, existing, t domain.Transaction) error {
+	if err := validateTransaction(t); err != nil {
+		return err
+	}
+	t.CreatedAt = existing.CreatedAt
+	events := append([]domain.Event{newEvent(domain.EventTransactionUpdated, t.UserID, domain.AggregateTransaction, t.ID, t)},
+		auditEvents(s.audit, ctx, t.UserID, domain.AuditEntityTransaction, t.ID, domain.AuditActionUpdate, existing, t)...)
+	if err := s.repo.Update(t, events...); err != nil {
+		return err
+	}
+
+	if s.suggestions != nil {
+		s.suggestions.Forget(existing)
+		s.suggestions.Learn(t)
+	}
+	if s.budgets != nil && t.Type == "expense" {
+		if _, err := s.budgets.CheckExpenseChange(existing, t); err != nil {
+			log.Printf("budget: could not check alerts for transaction %d: %v", t.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *TransactionService) DeleteTransaction(ctx context.Context, userID, id, version int) error {
+	existing, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+
+	events := append([]domain.Event{newEvent(domain.EventTransactionDeleted, userID, domain.AggregateTransaction, id, existing)},
+		auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, id, domain.AuditActionDelete, existing, nil)...)
+	if err := s.repo.Delete(id, userID, version, events...); err != nil {
+		return err
+	}
+
+	if s.suggestions != nil {
+		s.suggestions.Forget(existing)
+	}
+	return nil
+}
+
+// Recategorize sets each matched transaction's category, tags and bucket in
+// one database transaction, only over the version the match was made from:
+// a transaction edited since fails the whole run with ErrVersionConflict
+// and nothing is applied.
+func (s *TransactionService) Recategorize(ctx context.Context, userID int, matches []domain.RuleMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	writes := make([]ports.TransactionWrite, len(matches))
+	for i, m := range matches {
+		t := m.Transaction
+		t.UserID = userID
+		t.Category = m.Category
+		t.Tags = m.Tags
+		t.Bucket = m.Bucket
+		writes[i] = ports.TransactionWrite{
+			Transaction: t,
+			Events: append([]domain.Event{newEvent(domain.EventTransactionUpdated, userID, domain.AggregateTransaction, t.ID, t)},
+				auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, t.ID, domain.AuditActionUpdate, m.Transaction, t)...),
+		}
+	}
+
+	written, err := s.repo.ApplyBatch(writes)
+	if err != nil {
+		return err
+	}
+	if s.suggestions != nil {
+		for i, m := range matches {
+			s.suggestions.Forget(m.Transaction)
+			s.suggestions.Learn(written[i])
+		}
+	}
+	return nil
+}
+
+// CreateSynced creates a transaction made offline under its client id.
+func (s *TransactionService) CreateSynced(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error) {
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+	return s.create(ctx, transaction)
+}
+
+// UpdateSynced applies transaction only over its Version of the row and
+// returns the row with its new version.
+func (s *TransactionService) UpdateSynced(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error) {
+	existing, err := s.repo.GetByID(transaction.ID, transaction.UserID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if err := s.update(ctx, existing, transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+	return s.repo.GetByID(transaction.ID, transaction.UserID)
+}
+
+func (s *TransactionService) DeleteSynced(ctx context.Context, userID, id, version int) error {
+	return s.DeleteTransaction(ctx, userID, id, version)
 }
 
 func (s *TransactionService) ListTransactions(userID, month, year int) ([]domain.Transaction, error) {
 	return s.repo.ListByUserID(userID, month, year)
 }
 
-func (s *TransactionService) ResetData(userID int) error {
-	return s.repo.DeleteAllByUserID(userID)
+// ResetData moves every transaction to the trash under a single batch, so
+// the reset can be undone until the retention window expires.
+func (s *TransactionService) ResetData(ctx context.Context, userID int) error {
+	batch, err := randomKey()
+	if err != nil {
+		return err
+	}
+	details := map[string]any{"batch": "reset-" + batch}
+	events := append([]domain.Event{newEvent(domain.EventDataReset, userID, domain.AggregateUser, userID, details)},
+		auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, 0, domain.AuditActionReset, nil, details)...)
+	if _, err := s.repo.SoftDeleteAllByUserID(userID, "reset-"+batch, events...); err != nil {
+		return err
+	}
+
+	if s.suggestions != nil {
+		s.suggestions.Reset(userID)
+	}
+	return nil
+}
+
+func validateTransaction(t domain.Transaction) error {
+	return validation.Validate(ErrInvalidTransaction,
+		validation.Field("type", t.Type, validation.OneOf("income", "expense")),
+		validation.Field("amount", t.Amount, validation.Positive, validation.Max(maxTransactionAmount)),
+		validation.Field("category", t.Category, validation.Required, validation.MaxLength(maxNameLength)),
+		validation.Field("description", t.Description, validation.MaxLength(maxDescriptionLength)),
+		validation.Field("account", t.Account, validation.MaxLength(maxNameLength)),
+	)
 }
@@ -1,7 +1,10 @@
 package services_test
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,16 +12,24 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
 	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
 )
 
 type MockTransactionRepository struct {
 	mock.Mock
+	events []domain.Event
 }
 
-func (m *MockTransactionRepository) Save(transaction domain.Transaction) (int, error) {
+func (m *MockTransactionRepository) Save(transaction domain.Transaction, events ...domain.Event) (domain.Transaction, error) {
+	m.events = append(m.events, events...)
 	args := m.Called(transaction)
-	return args.Int(0), args.Error(1)
+	if err := args.Error(1); err != nil {
+		return domain.Transaction{}, err
+	}
+	transaction.ID = args.Int(0)
+	transaction.Version = 1
+	return transaction, nil
 }
 
 func (m *MockTransactionRepository) ListByUserID(userID, month, year int) ([]domain.Transaction, error) {
@@ -26,21 +37,79 @@ func (m *MockTransactionRepository) ListByUserID(userID, month, year int) ([]dom
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) ListAllByUserID(userID int) ([]domain.Transaction, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) DeleteAllByUserID(userID int) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) Update(transaction domain.Transaction) error {
+func (m *MockTransactionRepository) Update(transaction domain.Transaction, events ...domain.Event) error {
+	m.events = append(m.events, events...)
 	args := m.Called(transaction)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) Delete(id, userID int) error {
+func (m *MockTransactionRepository) Delete(id, userID, version int, events ...domain.Event) error {
+	m.events = append(m.events, events...)
 	args := m.Called(id, userID)
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) ApplyBatch(writes []ports.TransactionWrite) ([]domain.Transaction, error) {
+	args := m.Called(writes)
+	if written, ok := args.Get(0).([]domain.Transaction); ok {
+		return written, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetByID(id, userID int) (domain.Transaction, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SoftDeleteAllByUserID(userID int, batch string, events ...domain.Event) (int, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(userID, batch)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) ListDeleted(userID int) ([]domain.Transaction, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) Restore(id, userID int, events ...domain.Event) (domain.Transaction, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(id, userID)
+	return args.Get(0).(domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) LatestDeleteBatch(userID int) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) RestoreBatch(userID int, batch string, events ...domain.Event) ([]domain.Transaction, error) {
+	m.events = append(m.events, events...)
+	args := m.Called(userID, batch)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) ListDeletedBefore(cutoff time.Time) ([]domain.Transaction, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	args := m.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
 func TestCreateIncome_Success(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
 	service := services.NewTransactionService(mockRepo)
@@ -55,16 +124,26 @@ func TestCreateIncome_Success(t *testing.T) {
 
 	mockRepo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(expectedID, nil)
 
-	result, err := service.CreateIncome(userID, amount, category, description, date)
+	result, err := service.CreateIncome(context.Background(), userID, amount, category, description, "", date)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedID, result.ID)
+	assert.Equal(t, 1, result.Version)
 	assert.Equal(t, "income", result.Type)
 	assert.Equal(t, amount, result.Amount)
 
 	mockRepo.AssertCalled(t, "Save", mock.MatchedBy(func(tr domain.Transaction) bool {
 		return tr.UserID == userID && tr.Type == "income" && tr.Amount == amount
 	}))
+
+	// The created event goes to the outbox with the insert; the repository
+	// fills in the new id.
+	if assert.Len(t, mockRepo.events, 1) {
+		assert.Equal(t, domain.EventTransactionCreated, mockRepo.events[0].Type)
+		assert.Equal(t, userID, mockRepo.events[0].UserID)
+		assert.Equal(t, 0, mockRepo.events[0].AggregateID)
+		assert.Contains(t, string(mockRepo.events[0].Payload), `"category":"Salário"`)
+	}
 }
 
 func TestCreateExpense_Success(t *testing.T) {
@@ -81,7 +160,7 @@ func TestCreateExpense_Success(t *testing.T) {
 
 	mockRepo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(expectedID, nil)
 
-	result, err := service.CreateExpense(userID, amount, category, description, date)
+	result, err := service.CreateExpense(context.Background(), userID, amount, category, description, "", date)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedID, result.ID)
@@ -127,3 +206,154 @@ func TestListTransactions_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.EqualError(t, err, "db error")
 }
+
+func TestResetData_IsSoftDelete(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	mockRepo.On("SoftDeleteAllByUserID", 1, mock.MatchedBy(func(batch string) bool {
+		return strings.HasPrefix(batch, "reset-")
+	})).Return(12, nil)
+
+	err := service.ResetData(context.Background(), 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "DeleteAllByUserID", 1)
+	if assert.Len(t, mockRepo.events, 1) {
+		assert.Equal(t, domain.EventDataReset, mockRepo.events[0].Type)
+		assert.Equal(t, domain.AggregateUser, mockRepo.events[0].AggregateType)
+		assert.Equal(t, 1, mockRepo.events[0].AggregateID)
+	}
+}
+
+func TestApplyBatch_AtomicWritesInOneCall(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetByID", 5, 1).Return(domain.Transaction{ID: 5, UserID: 1, Type: "expense", Amount: 80, Tags: []string{"casa"}, Version: 2}, nil)
+	mockRepo.On("GetByID", 6, 1).Return(domain.Transaction{ID: 6, UserID: 1, Type: "income", Amount: 10, Version: 4}, nil)
+	mockRepo.On("ApplyBatch", mock.MatchedBy(func(writes []ports.TransactionWrite) bool {
+		// Updates and deletes apply only over the versions read.
+		return len(writes) == 3 &&
+			writes[0].Transaction.ID == 0 &&
+			writes[1].Transaction.Version == 2 && writes[1].Transaction.Tags[0] == "casa" &&
+			writes[2].Delete && writes[2].Transaction.Version == 4
+	})).Return([]domain.Transaction{{ID: 11, Version: 1}, {ID: 5, Version: 3}, {ID: 6, Version: 4}}, nil)
+
+	results, err := service.ApplyBatch(context.Background(), 1, "", []domain.BatchOperation{
+		{Op: "create", Type: "expense", Amount: 50, Category: "Mercado", Date: date},
+		{Op: "update", ID: 5, Type: "expense", Amount: 90, Category: "Casa", Date: date},
+		{Op: "delete", ID: 6},
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, domain.BatchResult{Index: 0, Op: "create", Status: "applied", ID: 11, Version: 1}, results[0])
+		assert.Equal(t, domain.BatchResult{Index: 1, Op: "update", Status: "applied", ID: 5, Version: 3}, results[1])
+		assert.Equal(t, domain.BatchResult{Index: 2, Op: "delete", Status: "applied", ID: 6}, results[2])
+	}
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestApplyBatch_AtomicInvalidAppliesNothing(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	results, err := service.ApplyBatch(context.Background(), 1, "atomic", []domain.BatchOperation{
+		{Op: "create", Type: "expense", Amount: 50, Category: "Mercado"},
+		{Op: "create", Type: "transfer", Amount: -5, Category: "Mercado"},
+		{Op: "delete", ID: 6},
+		{Op: "update", ID: 6, Type: "income", Amount: 1, Category: "Salário"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "skipped", results[0].Status)
+	assert.Equal(t, "failed", results[1].Status)
+	assert.Equal(t, "transaction is invalid", results[1].Error)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "type", Message: "must be one of income, expense"},
+		{Field: "amount", Message: "must be positive"},
+	}, results[1].Errors)
+	assert.Equal(t, "skipped", results[2].Status)
+	assert.Equal(t, "failed", results[3].Status)
+	assert.Equal(t, []domain.FieldError{{Field: "id", Message: "appears more than once in the batch"}}, results[3].Errors)
+	mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+}
+
+func TestApplyBatch_AtomicConflictSkipsTheRest(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	mockRepo.On("GetByID", 5, 1).Return(domain.Transaction{ID: 5, UserID: 1, Version: 2}, nil)
+	mockRepo.On("ApplyBatch", mock.Anything).Return(nil, &ports.BatchError{Index: 1, Err: ports.ErrVersionConflict})
+
+	results, err := service.ApplyBatch(context.Background(), 1, "atomic", []domain.BatchOperation{
+		{Op: "create", Type: "expense", Amount: 50, Category: "Mercado"},
+		{Op: "delete", ID: 5},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BatchResult{Index: 0, Op: "create", Status: "skipped"}, results[0])
+	assert.Equal(t, "failed", results[1].Status)
+	assert.Equal(t, ports.ErrVersionConflict.Error(), results[1].Error)
+}
+
+func TestApplyBatch_PartialAppliesEachOnItsOwn(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	mockRepo.On("Save", mock.AnythingOfType("domain.Transaction")).Return(11, nil)
+	mockRepo.On("GetByID", 5, 1).Return(domain.Transaction{}, sql.ErrNoRows)
+
+	results, err := service.ApplyBatch(context.Background(), 1, "partial", []domain.BatchOperation{
+		{Op: "create", Type: "income", Amount: 50, Category: "Salário"},
+		{Op: "delete", ID: 5},
+		{Op: "move", ID: 6},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BatchResult{Index: 0, Op: "create", Status: "applied", ID: 11, Version: 1}, results[0])
+	assert.Equal(t, domain.BatchResult{Index: 1, Op: "delete", Status: "failed", ID: 5, Error: "transaction not found"}, results[1])
+	assert.Equal(t, []domain.FieldError{{Field: "op", Message: "must be one of create, update, delete"}}, results[2].Errors)
+	mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+}
+
+func TestApplyBatch_RejectsUnknownMode(t *testing.T) {
+	service := services.NewTransactionService(new(MockTransactionRepository))
+
+	_, err := service.ApplyBatch(context.Background(), 1, "eventual", []domain.BatchOperation{{Op: "delete", ID: 1}})
+	assert.ErrorIs(t, err, services.ErrInvalidBatch)
+	e, _ := domain.AsError(err)
+	assert.Equal(t, []domain.FieldError{{Field: "mode", Message: "must be one of atomic, partial"}}, e.Fields)
+
+	_, err = service.ApplyBatch(context.Background(), 1, "atomic", nil)
+	assert.ErrorIs(t, err, services.ErrInvalidBatch)
+}
+
+func TestCreateExpense_RejectsInvalidFields(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	_, err := service.CreateExpense(context.Background(), 1, -10, "", "Conta de Luz", "", time.Now())
+
+	assert.ErrorIs(t, err, services.ErrInvalidTransaction)
+	e, ok := domain.AsError(err)
+	assert.True(t, ok)
+	var fields []string
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.Equal(t, []string{"amount", "category"}, fields)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestUpdateTransaction_RejectsUnknownType(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo)
+
+	_, err := service.UpdateTransaction(context.Background(), 1, 7, 1, 50, "Lazer", "", "", time.Now(), "transfer")
+
+	assert.ErrorIs(t, err, services.ErrInvalidTransaction)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
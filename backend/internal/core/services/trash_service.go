@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var ErrNothingToUndo = domain.NotFound("nothing_to_undo", "no reset to undo")
+
+type TrashService struct {
+	transactionRepo ports.TransactionRepository
+	goalRepo        ports.GoalRepository
+	attachments     ports.AttachmentService
+	suggestions     ports.CategorySuggestionService
+	audit           ports.AuditService
+	retention       time.Duration
+	now             func() time.Time
+}
+
+func NewTrashService(transactionRepo ports.TransactionRepository, goalRepo ports.GoalRepository, retention time.Duration) *TrashService {
+	return &TrashService{
+		transactionRepo: transactionRepo,
+		goalRepo:        goalRepo,
+		retention:       retention,
+		now:             time.Now,
+	}
+}
+
+// SetAttachmentService enables removal of stored receipts when their
+// transaction is purged.
+func (s *TrashService) SetAttachmentService(attachments ports.AttachmentService) {
+	s.attachments = attachments
+}
+
+// SetCategorySuggestionService lets restored transactions count again for
+// category suggestions.
+func (s *TrashService) SetCategorySuggestionService(suggestions ports.CategorySuggestionService) {
+	s.suggestions = suggestions
+}
+
+// SetAuditService records restores.
+func (s *TrashService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+func (s *TrashService) ListTrash(userID int) (domain.Trash, error) {
+	transactions, err := s.transactionRepo.ListDeleted(userID)
+	if err != nil {
+		return domain.Trash{}, err
+	}
+	goals, err := s.goalRepo.ListDeleted(userID)
+	if err != nil {
+		return domain.Trash{}, err
+	}
+
+	if transactions == nil {
+		transactions = []domain.Transaction{}
+	}
+	if goals == nil {
+		goals = []domain.Goal{}
+	}
+	return domain.Trash{
+		Transactions:  transactions,
+		Goals:         goals,
+		RetentionDays: int(s.retention.Hours() / 24),
+	}, nil
+}
+
+func (s *TrashService) RestoreTransaction(ctx context.Context, userID, id int) error {
+	events := []domain.Event{newEvent(domain.EventTransactionRestored, userID, domain.AggregateTransaction, id, map[string]any{"id": id})}
+	if s.audit != nil {
+		trashed, err := s.trashedTransaction(userID, id)
+		if err != nil {
+			return err
+		}
+		events = append(events, auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, id, domain.AuditActionRestore, nil, trashed)...)
+	}
+	t, err := s.transactionRepo.Restore(id, userID, events...)
+	if err != nil {
+		return err
+	}
+
+	if s.suggestions != nil {
+		s.suggestions.Learn(t)
+	}
+	return nil
+}
+
+// trashedTransaction reads a transaction from the trash as restoring it will
+// bring it back; rows in the trash cannot change.
+func (s *TrashService) trashedTransaction(userID, id int) (domain.Transaction, error) {
+	deleted, err := s.transactionRepo.ListDeleted(userID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	for _, t := range deleted {
+		if t.ID == id {
+			t.DeletedAt = nil
+			return t, nil
+		}
+	}
+	return domain.Transaction{}, sql.ErrNoRows
+}
+
+func (s *TrashService) RestoreGoal(ctx context.Context, userID, id int) error {
+	events := []domain.Event{newEvent(domain.EventGoalRestored, userID, domain.AggregateGoal, id, map[string]any{"id": id})}
+	if s.audit != nil {
+		trashed, err := s.trashedGoal(userID, id)
+		if err != nil {
+			return err
+		}
+		events = append(events, auditEvents(s.audit, ctx, userID, domain.AuditEntityGoal, id, domain.AuditActionRestore, nil, trashed)...)
+	}
+	return s.goalRepo.Restore(id, userID, events...)
+}
+
+// trashedGoal is trashedTransaction for goals.
+func (s *TrashService) trashedGoal(userID, id int) (domain.Goal, error) {
+	deleted, err := s.goalRepo.ListDeleted(userID)
+	if err != nil {
+		return domain.Goal{}, err
+	}
+	for _, g := range deleted {
+		if g.ID == id {
+			g.DeletedAt = nil
+			return g, nil
+		}
+	}
+	return domain.Goal{}, sql.ErrNoRows
+}
+
+// UndoReset restores every transaction trashed by the user's latest reset.
+// Like the reset, it is audited as one entry for the whole batch.
+func (s *TrashService) UndoReset(ctx context.Context, userID int) (int, error) {
+	batch, err := s.transactionRepo.LatestDeleteBatch(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNothingToUndo
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	details := map[string]any{"batch": batch}
+	events := append([]domain.Event{newEvent(domain.EventDataResetUndone, userID, domain.AggregateUser, userID, details)},
+		auditEvents(s.audit, ctx, userID, domain.AuditEntityTransaction, 0, domain.AuditActionRestore, nil, details)...)
+	restored, err := s.transactionRepo.RestoreBatch(userID, batch, events...)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.suggestions != nil {
+		s.suggestions.Reset(userID)
+	}
+	return len(restored), nil
+}
+
+// PurgeExpired permanently removes rows that stayed in the trash for longer
+// than the retention window.
+func (s *TrashService) PurgeExpired() error {
+	cutoff := s.now().Add(-s.retention)
+
+	if s.attachments != nil {
+		expired, err := s.transactionRepo.ListDeletedBefore(cutoff)
+		if err != nil {
+			return err
+		}
+		for _, t := range expired {
+			if err := s.attachments.DeleteByTransaction(t.UserID, t.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	transactions, err := s.transactionRepo.PurgeDeletedBefore(cutoff)
+	if err != nil {
+		return err
+	}
+	goals, err := s.goalRepo.PurgeDeletedBefore(cutoff)
+	if err != nil {
+		return err
+	}
+
+	if transactions > 0 || goals > 0 {
+		log.Printf("Trash purge removed %d transactions and %d goals", transactions, goals)
+	}
+	return nil
+}
+
+// StartPurgeJob runs PurgeExpired on every tick until stop is called.
+func (s *TrashService) StartPurgeJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			if err := s.PurgeExpired(); err != nil {
+				log.Printf("Trash purge failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/services"
+)
+
+// trashGoalRepository only implements what the trash service touches; the
+// embedded interface panics if anything else is called.
+type trashGoalRepository struct {
+	mock.Mock
+	ports.GoalRepository
+}
+
+func (m *trashGoalRepository) ListDeleted(userID int) ([]domain.Goal, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domain.Goal), args.Error(1)
+}
+
+func (m *trashGoalRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	args := m.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+func TestListTrash(t *testing.T) {
+	txRepo := new(MockTransactionRepository)
+	goalRepo := new(trashGoalRepository)
+	service := services.NewTrashService(txRepo, goalRepo, 30*24*time.Hour)
+
+	deletedAt := time.Now()
+	txRepo.On("ListDeleted", 1).Return([]domain.Transaction{{ID: 3, DeletedAt: &deletedAt}}, nil)
+	goalRepo.On("ListDeleted", 1).Return([]domain.Goal(nil), nil)
+
+	trash, err := service.ListTrash(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, trash.Transactions, 1)
+	assert.NotNil(t, trash.Goals)
+	assert.Equal(t, 30, trash.RetentionDays)
+}
+
+func TestUndoReset(t *testing.T) {
+	txRepo := new(MockTransactionRepository)
+	service := services.NewTrashService(txRepo, new(trashGoalRepository), 30*24*time.Hour)
+
+	txRepo.On("LatestDeleteBatch", 1).Return("reset-abc", nil)
+	txRepo.On("RestoreBatch", 1, "reset-abc").Return([]domain.Transaction{{ID: 1}, {ID: 2}}, nil)
+
+	restored, err := service.UndoReset(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, restored)
+	if assert.Len(t, txRepo.events, 1) {
+		assert.Equal(t, domain.EventDataResetUndone, txRepo.events[0].Type)
+		assert.JSONEq(t, `{"batch":"reset-abc"}`, string(txRepo.events[0].Payload))
+	}
+}
+
+func TestRestoreTransaction_WritesEvent(t *testing.T) {
+	txRepo := new(MockTransactionRepository)
+	service := services.NewTrashService(txRepo, new(trashGoalRepository), 30*24*time.Hour)
+
+	txRepo.On("Restore", 4, 1).Return(domain.Transaction{ID: 4, UserID: 1}, nil)
+
+	assert.NoError(t, service.RestoreTransaction(context.Background(), 1, 4))
+	if assert.Len(t, txRepo.events, 1) {
+		assert.Equal(t, domain.EventTransactionRestored, txRepo.events[0].Type)
+		assert.Equal(t, 4, txRepo.events[0].AggregateID)
+	}
+}
+
+func TestUndoReset_NothingToUndo(t *testing.T) {
+	txRepo := new(MockTransactionRepository)
+	service := services.NewTrashService(txRepo, new(trashGoalRepository), 30*24*time.Hour)
+
+	txRepo.On("LatestDeleteBatch", 1).Return("", sql.ErrNoRows)
+
+	_, err := service.UndoReset(context.Background(), 1)
+
+	assert.ErrorIs(t, err, services.ErrNothingToUndo)
+}
+
+func TestPurgeExpired_RemovesAttachments(t *testing.T) {
+	attachments, repo, store, txRepo := newAttachmentService(1 << 20)
+	txRepo.On("GetByID", 10, 1).Return(domain.Transaction{ID: 10, UserID: 1}, nil)
+	_, err := attachments.Upload(context.Background(), 1, 10, "nota.pdf", bytes.NewReader([]byte("%PDF-1.4\n")))
+	assert.NoError(t, err)
+
+	goalRepo := new(trashGoalRepository)
+	service := services.NewTrashService(txRepo, goalRepo, 30*24*time.Hour)
+	service.SetAttachmentService(attachments)
+
+	txRepo.On("ListDeletedBefore", mock.AnythingOfType("time.Time")).Return([]domain.Transaction{{ID: 10, UserID: 1}}, nil)
+	txRepo.On("PurgeDeletedBefore", mock.AnythingOfType("time.Time")).Return(1, nil)
+	goalRepo.On("PurgeDeletedBefore", mock.AnythingOfType("time.Time")).Return(0, nil)
+
+	assert.NoError(t, service.PurgeExpired())
+	assert.Empty(t, repo.attachments)
+	assert.Empty(t, store.objects)
+}
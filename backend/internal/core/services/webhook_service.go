@@ -0,0 +1,480 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+	"github.com/larissasthefanny/plena-app/backend/internal/core/ports"
+)
+
+var ErrInvalidWebhook = domain.Invalid("invalid_webhook", "webhook needs an absolute http(s) URL and known event types")
+
+// errWebhookAddress is returned when dialing a webhook whose host resolves
+// to an address it may not reach.
+var errWebhookAddress = errors.New("webhook address is not public")
+
+// Failed deliveries are retried with a growing backoff capped at
+// maxWebhookBackoff and given up after maxWebhookAttempts. A webhook whose
+// last webhookDisableAfter attempts all failed is deactivated. A claimed
+// delivery is left to its instance for webhookClaimLease, long enough for a
+// whole batch to time out.
+const (
+	maxWebhookAttempts   = 8
+	webhookBackoff       = 30 * time.Second
+	maxWebhookBackoff    = 6 * time.Hour
+	webhookDisableAfter  = 10
+	webhookBatchSize     = 50
+	webhookTimeout       = 10 * time.Second
+	webhookResponseLimit = 1024
+	webhookClaimLease    = 15 * time.Minute
+)
+
+// WebhookService posts the users' domain events to their webhooks. Each
+// request is signed with the webhook's secret in the X-Plena-Signature
+// header: t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">.
+//
+// Webhooks may only reach public addresses. The host is checked when a
+// webhook is saved and every connection is checked again when it is
+// dialed, so a name re-pointed at an internal address later is refused;
+// redirects are not followed.
+type WebhookService struct {
+	repo      ports.WebhookRepository
+	client    *http.Client
+	audit     ports.AuditService
+	now       func() time.Time
+	wake      chan struct{}
+	lookupIP  func(ctx context.Context, host string) ([]netip.Addr, error)
+	allowAddr func(netip.Addr) bool
+}
+
+func NewWebhookService(repo ports.WebhookRepository) *WebhookService {
+	s := &WebhookService{
+		repo: repo,
+		now:  time.Now,
+		wake: make(chan struct{}, 1),
+		lookupIP: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+		allowAddr: publicAddr,
+	}
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: s.controlDial}
+	s.client = &http.Client{
+		Timeout: webhookTimeout,
+		// No proxy: the dialer must see the webhook's own address.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// SetAuditService records every change made through this service.
+func (s *WebhookService) SetAuditService(audit ports.AuditService) {
+	s.audit = audit
+}
+
+// CreateWebhook returns the webhook with its secret; it is not shown again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID int, webhook domain.Webhook) (domain.Webhook, error) {
+	webhook.UserID = userID
+	if err := s.validateWebhook(ctx, &webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	secret, err := randomKey()
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.Secret = "whsec_" + secret
+	webhook.Active = true
+
+	webhook.CreatedAt = s.now()
+	id, err := s.repo.Save(webhook, auditEvents(s.audit, ctx, userID, domain.AuditEntityWebhook, 0, domain.AuditActionCreate, nil, redactWebhook(webhook))...)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.ID = id
+	return webhook, nil
+}
+
+// UpdateWebhook replaces the URL, event types and active flag. Turning a
+// disabled webhook back on clears its failures; deliveries it still had
+// pending resume.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, userID, id int, webhook domain.Webhook) (domain.Webhook, error) {
+	if err := s.validateWebhook(ctx, &webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	before, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	after := before
+	after.URL = webhook.URL
+	after.EventTypes = webhook.EventTypes
+	after.Active = webhook.Active
+	if after.Active && !before.Active {
+		after.ConsecutiveFailures = 0
+		after.DisabledAt = nil
+	}
+	if err := s.repo.Update(after, auditEvents(s.audit, ctx, userID, domain.AuditEntityWebhook, id, domain.AuditActionUpdate, redactWebhook(before), redactWebhook(after))...); err != nil {
+		return domain.Webhook{}, err
+	}
+	if after.Active && !before.Active {
+		s.signal()
+	}
+	return redactWebhook(after), nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, id int) error {
+	before, err := s.repo.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(id, userID, auditEvents(s.audit, ctx, userID, domain.AuditEntityWebhook, id, domain.AuditActionDelete, redactWebhook(before), nil)...)
+}
+
+func (s *WebhookService) ListWebhooks(userID int) ([]domain.Webhook, error) {
+	webhooks, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i] = redactWebhook(webhooks[i])
+	}
+	return webhooks, nil
+}
+
+// ListDeliveries returns the webhook's most recent deliveries, newest first.
+func (s *WebhookService) ListDeliveries(userID, webhookID int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(webhookID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(webhookID, userID)
+}
+
+// Redeliver sends a past delivery's payload again as a new delivery and
+// attempts it straight away, also on a disabled webhook, so users can check
+// their endpoint after fixing it. A failed redelivery is retried like any
+// other while the webhook is active.
+func (s *WebhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int) (domain.WebhookDelivery, error) {
+	webhook, err := s.repo.GetByID(webhookID, userID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	original, err := s.repo.GetDelivery(deliveryID, webhookID, userID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	now := s.now()
+	delivery := domain.WebhookDelivery{
+		WebhookID: webhook.ID,
+		UserID:    userID,
+		EventID:   original.EventID,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+		Status:    domain.WebhookDeliveryPending,
+		// Saved already claimed, since it is attempted right here.
+		NextAttemptAt: now.Add(webhookClaimLease),
+		RedeliveryOf:  &original.ID,
+		CreatedAt:     now,
+	}
+	id, _, err := s.repo.SaveDelivery(delivery)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	delivery.ID = id
+	if _, err := s.attempt(ctx, webhook, &delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// HandleEvent queues the event for each of its user's active webhooks that
+// wants it. It is subscribed to the event bus; an event seen again is not
+// queued twice.
+func (s *WebhookService) HandleEvent(ctx context.Context, event domain.Event) error {
+	if event.UserID == 0 {
+		return nil
+	}
+	webhooks, err := s.repo.ListByUserID(event.UserID)
+	if err != nil {
+		return err
+	}
+
+	var payload json.RawMessage
+	queued := false
+	for _, webhook := range webhooks {
+		if !webhook.Active || (len(webhook.EventTypes) > 0 && !slices.Contains(webhook.EventTypes, event.Type)) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = webhookPayload(event); err != nil {
+				return err
+			}
+		}
+		now := s.now()
+		_, created, err := s.repo.SaveDelivery(domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        event.UserID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+		queued = queued || created
+	}
+	if queued {
+		s.signal()
+	}
+	return nil
+}
+
+// Deliver claims the deliveries that are due, sends them and returns how
+// many it attempted.
+func (s *WebhookService) Deliver(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(s.now(), webhookClaimLease, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := map[int]domain.Webhook{}
+	for _, d := range deliveries {
+		webhook, ok := webhooks[d.WebhookID]
+		if !ok {
+			webhook, err = s.repo.GetByID(d.WebhookID, d.UserID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return 0, err
+			}
+			webhooks[d.WebhookID] = webhook
+		}
+		// The webhook may have been deleted since, or disabled earlier in
+		// this batch.
+		if !webhook.Active {
+			continue
+		}
+		if webhook.Active, err = s.attempt(ctx, webhook, &d); err != nil {
+			return 0, err
+		}
+		webhooks[d.WebhookID] = webhook
+	}
+	return len(deliveries), nil
+}
+
+// StartDeliveryJob runs Deliver on every tick and right after new
+// deliveries are queued, straight away again while a full batch was
+// attempted, until stop is called.
+func (s *WebhookService) StartDeliveryJob(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			attempted, err := s.Deliver(context.Background())
+			if err != nil {
+				log.Printf("Webhook delivery job failed: %v", err)
+			}
+			if attempted == webhookBatchSize {
+				continue
+			}
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// attempt posts the delivery once, saves the outcome and counts it towards
+// the webhook's consecutive failures. It reports whether the webhook is
+// still active.
+func (s *WebhookService) attempt(ctx context.Context, webhook domain.Webhook, d *domain.WebhookDelivery) (bool, error) {
+	now := s.now()
+	code, body, err := s.post(ctx, webhook, *d, now)
+
+	d.Attempts++
+	d.ResponseCode = code
+	d.ResponseBody = body
+	d.Error = ""
+	switch {
+	case err == nil:
+		d.Status = domain.WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= maxWebhookAttempts:
+		d.Status = domain.WebhookDeliveryFailed
+		d.Error = err.Error()
+	default:
+		d.NextAttemptAt = now.Add(webhookRetryBackoff(d.Attempts))
+		d.Error = err.Error()
+	}
+	if err != nil {
+		log.Printf("webhooks: delivery %d to webhook %d attempt %d failed: %v", d.ID, webhook.ID, d.Attempts, err)
+	}
+	if err := s.repo.UpdateDelivery(*d); err != nil {
+		return false, err
+	}
+
+	active, err := s.repo.RecordResult(webhook.ID, d.Status == domain.WebhookDeliverySucceeded, webhookDisableAfter, now)
+	if err != nil {
+		return false, err
+	}
+	if webhook.Active && !active {
+		log.Printf("webhooks: disabled webhook %d after %d consecutive failures", webhook.ID, webhookDisableAfter)
+	}
+	return active, nil
+}
+
+// post sends the payload and returns the response status and the start of
+// its body. Any status outside 2xx is an error.
+func (s *WebhookService) post(ctx context.Context, webhook domain.Webhook, d domain.WebhookDelivery, now time.Time) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plena-Webhooks/1.0")
+	req.Header.Set("X-Plena-Event", d.EventType)
+	req.Header.Set("X-Plena-Delivery", strconv.Itoa(d.ID))
+	req.Header.Set("X-Plena-Signature", signWebhook(webhook.Secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(body), fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+func (s *WebhookService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// signWebhook builds the X-Plena-Signature header. Receivers recompute the
+// HMAC over "<t>.<body>" and should reject old timestamps to stop replays.
+func signWebhook(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookPayload(event domain.Event) (json.RawMessage, error) {
+	return json.Marshal(struct {
+		ID         int64           `json:"id"`
+		Type       string          `json:"type"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Data       json.RawMessage `json:"data"`
+	}{event.ID, event.Type, event.OccurredAt, event.Payload})
+}
+
+func webhookRetryBackoff(attempts int) time.Duration {
+	wait := webhookBackoff << (attempts - 1)
+	if wait <= 0 || wait > maxWebhookBackoff {
+		return maxWebhookBackoff
+	}
+	return wait
+}
+
+func (s *WebhookService) validateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return ErrInvalidWebhook
+	}
+	if err := s.checkHost(ctx, u.Hostname()); err != nil {
+		return err
+	}
+	for _, eventType := range webhook.EventTypes {
+		if !slices.Contains(domain.EventTypes, eventType) {
+			return ErrInvalidWebhook
+		}
+	}
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+	return nil
+}
+
+// checkHost rejects a host that does not resolve, or that resolves to any
+// address the webhooks may not reach.
+func (s *WebhookService) checkHost(ctx context.Context, host string) error {
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else if addrs, err = s.lookupIP(ctx, host); err != nil || len(addrs) == 0 {
+		return ErrInvalidWebhook.WithField("url", "host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !s.allowAddr(addr.Unmap()) {
+			return ErrInvalidWebhook.WithField("url", "must not point to a private or local address")
+		}
+	}
+	return nil
+}
+
+// controlDial refuses connections to addresses the webhooks may not reach.
+// It runs after name resolution, for every address tried.
+func (s *WebhookService) controlDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !s.allowAddr(addrPort.Addr().Unmap()) {
+		return fmt.Errorf("%w: %s", errWebhookAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// publicAddr reports whether addr is a public unicast address, not a
+// loopback, link-local, private, multicast or unspecified one.
+func publicAddr(addr netip.Addr) bool {
+	return addr.IsValid() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() && !addr.IsPrivate() && !addr.IsUnspecified()
+}
+
+// redactWebhook drops the secret from a webhook leaving the service.
+func redactWebhook(webhook domain.Webhook) domain.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
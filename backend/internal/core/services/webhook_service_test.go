@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+type memoryWebhookRepository struct {
+	webhooks   []domain.Webhook
+	deliveries []domain.WebhookDelivery
+}
+
+func (m *memoryWebhookRepository) Save(w domain.Webhook, events ...domain.Event) (int, error) {
+	w.ID = len(m.webhooks) + 1
+	m.webhooks = append(m.webhooks, w)
+	return w.ID, nil
+}
+
+func (m *memoryWebhookRepository) Update(w domain.Webhook, events ...domain.Event) error {
+	m.webhooks[w.ID-1] = w
+	return nil
+}
+
+func (m *memoryWebhookRepository) Delete(id, userID int, events ...domain.Event) error {
+	return nil
+}
+
+func (m *memoryWebhookRepository) GetByID(id, userID int) (domain.Webhook, error) {
+	if id < 1 || id > len(m.webhooks) || m.webhooks[id-1].UserID != userID {
+		return domain.Webhook{}, sql.ErrNoRows
+	}
+	return m.webhooks[id-1], nil
+}
+
+func (m *memoryWebhookRepository) ListByUserID(userID int) ([]domain.Webhook, error) {
+	var result []domain.Webhook
+	for _, w := range m.webhooks {
+		if w.UserID == userID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryWebhookRepository) SaveDelivery(d domain.WebhookDelivery) (int, bool, error) {
+	for _, existing := range m.deliveries {
+		if d.RedeliveryOf == nil && existing.RedeliveryOf == nil && existing.WebhookID == d.WebhookID && existing.EventID == d.EventID {
+			return 0, false, nil
+		}
+	}
+	d.ID = len(m.deliveries) + 1
+	m.deliveries = append(m.deliveries, d)
+	return d.ID, true, nil
+}
+
+func (m *memoryWebhookRepository) GetDelivery(id, webhookID, userID int) (domain.WebhookDelivery, error) {
+	if id < 1 || id > len(m.deliveries) || m.deliveries[id-1].WebhookID != webhookID {
+		return domain.WebhookDelivery{}, sql.ErrNoRows
+	}
+	return m.deliveries[id-1], nil
+}
+
+func (m *memoryWebhookRepository) ListDeliveries(webhookID, userID int) ([]domain.WebhookDelivery, error) {
+	var result []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.WebhookID == webhookID {
+			result = append([]domain.WebhookDelivery{d}, result...)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var result []domain.WebhookDelivery
+	for i, d := range m.deliveries {
+		if d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && m.webhooks[d.WebhookID-1].Active && len(result) < limit {
+			d.NextAttemptAt = now.Add(lease)
+			m.deliveries[i] = d
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryWebhookRepository) UpdateDelivery(d domain.WebhookDelivery) error {
+	m.deliveries[d.ID-1] = d
+	return nil
+}
+
+func (m *memoryWebhookRepository) RecordResult(webhookID int, succeeded bool, disableAfter int, now time.Time) (bool, error) {
+	w := &m.webhooks[webhookID-1]
+	if succeeded {
+		w.ConsecutiveFailures = 0
+		return w.Active, nil
+	}
+	w.ConsecutiveFailures++
+	if w.Active && w.ConsecutiveFailures >= disableAfter {
+		w.Active = false
+		w.DisabledAt = &now
+	}
+	return w.Active, nil
+}
+
+// webhookReceiver answers with status and keeps the requests it got.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rec *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, body)
+	w.WriteHeader(rec.status)
+	w.Write([]byte("ack"))
+}
+
+func newTestWebhookService(t *testing.T, status int) (*WebhookService, *memoryWebhookRepository, *webhookReceiver, *httptest.Server, *time.Time) {
+	receiver := &webhookReceiver{status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	repo := &memoryWebhookRepository{}
+	svc := NewWebhookService(repo)
+	svc.lookupIP = fakeLookupIP
+	// The test server listens on loopback.
+	svc.allowAddr = func(addr netip.Addr) bool { return addr.IsLoopback() || publicAddr(addr) }
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, receiver, server, &now
+}
+
+func fakeLookupIP(_ context.Context, host string) ([]netip.Addr, error) {
+	hosts := map[string][]string{
+		"example.com":    {"93.184.216.34"},
+		"localhost":      {"127.0.0.1", "::1"},
+		"mixed.example":  {"93.184.216.34", "10.1.2.3"},
+		"rebind.example": {"169.254.169.254"},
+	}
+	var addrs []netip.Addr
+	for _, a := range hosts[host] {
+		addrs = append(addrs, netip.MustParseAddr(a))
+	}
+	if addrs == nil {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestWebhooks_DeliversSignedPayload(t *testing.T) {
+	svc, repo, receiver, server, now := newTestWebhookService(t, http.StatusOK)
+	ctx := context.Background()
+
+	created, err := svc.CreateWebhook(ctx, 1, domain.Webhook{URL: server.URL + "/hook", EventTypes: []string{domain.EventTransactionCreated}})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	svc.CreateWebhook(ctx, 2, domain.Webhook{URL: server.URL + "/other"})
+
+	event := domain.Event{ID: 42, Type: domain.EventTransactionCreated, UserID: 1, Payload: json.RawMessage(`{"id":7}`), OccurredAt: *now}
+	assert.NoError(t, svc.HandleEvent(ctx, event))
+	// At-least-once delivery from the bus must not queue the event twice.
+	assert.NoError(t, svc.HandleEvent(ctx, event))
+	assert.NoError(t, svc.HandleEvent(ctx, domain.Event{ID: 43, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now}))
+	assert.Len(t, repo.deliveries, 1)
+
+	attempted, err := svc.Deliver(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	if !assert.Len(t, receiver.requests, 1) {
+		return
+	}
+
+	req, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, "/hook", req.URL.Path)
+	assert.Equal(t, domain.EventTransactionCreated, req.Header.Get("X-Plena-Event"))
+	assert.Equal(t, "1", req.Header.Get("X-Plena-Delivery"))
+	assert.JSONEq(t, `{"id":42,"type":"transaction.created","occurred_at":"2026-05-04T09:00:00Z","data":{"id":7}}`, string(body))
+
+	timestamp := "1777885200"
+	mac := hmac.New(sha256.New, []byte(created.Secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	assert.Equal(t, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Plena-Signature"))
+
+	d := repo.deliveries[0]
+	assert.Equal(t, domain.WebhookDeliverySucceeded, d.Status)
+	assert.Equal(t, http.StatusOK, d.ResponseCode)
+	assert.Equal(t, "ack", d.ResponseBody)
+	assert.Equal(t, *now, *d.DeliveredAt)
+
+	webhooks, _ := svc.ListWebhooks(1)
+	assert.Empty(t, webhooks[0].Secret)
+}
+
+func TestWebhooks_RetriesWithBackoffAndDisables(t *testing.T) {
+	svc, repo, receiver, server, now := newTestWebhookService(t, http.StatusInternalServerError)
+	ctx := context.Background()
+	svc.CreateWebhook(ctx, 1, domain.Webhook{URL: server.URL})
+
+	svc.HandleEvent(ctx, domain.Event{ID: 1, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now})
+	svc.Deliver(ctx)
+	d := repo.deliveries[0]
+	assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+	assert.Equal(t, http.StatusInternalServerError, d.ResponseCode)
+	assert.Equal(t, "webhook responded 500 Internal Server Error", d.Error)
+	assert.Equal(t, now.Add(webhookBackoff), d.NextAttemptAt)
+
+	// Not due yet.
+	svc.Deliver(ctx)
+	assert.Len(t, receiver.requests, 1)
+
+	for repo.deliveries[0].Status == domain.WebhookDeliveryPending {
+		*now = repo.deliveries[0].NextAttemptAt
+		svc.Deliver(ctx)
+	}
+	assert.Equal(t, domain.WebhookDeliveryFailed, repo.deliveries[0].Status)
+	assert.Equal(t, maxWebhookAttempts, repo.deliveries[0].Attempts)
+	assert.Equal(t, maxWebhookAttempts, repo.webhooks[0].ConsecutiveFailures)
+	assert.True(t, repo.webhooks[0].Active)
+
+	svc.HandleEvent(ctx, domain.Event{ID: 2, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now})
+	for i := maxWebhookAttempts; i < webhookDisableAfter; i++ {
+		*now = repo.deliveries[1].NextAttemptAt
+		svc.Deliver(ctx)
+	}
+	assert.False(t, repo.webhooks[0].Active)
+	assert.Equal(t, *now, *repo.webhooks[0].DisabledAt)
+
+	// A disabled webhook gets neither retries nor new events.
+	*now = now.Add(maxWebhookBackoff)
+	attempted, _ := svc.Deliver(ctx)
+	assert.Equal(t, 0, attempted)
+	svc.HandleEvent(ctx, domain.Event{ID: 3, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now})
+	assert.Len(t, repo.deliveries, 2)
+	assert.Len(t, receiver.requests, webhookDisableAfter)
+}
+
+func TestWebhooks_RedeliverAndReenable(t *testing.T) {
+	svc, repo, receiver, server, now := newTestWebhookService(t, http.StatusBadGateway)
+	ctx := context.Background()
+	svc.CreateWebhook(ctx, 1, domain.Webhook{URL: server.URL})
+	svc.HandleEvent(ctx, domain.Event{ID: 9, Type: domain.EventUserRegistered, UserID: 1, OccurredAt: *now})
+	svc.Deliver(ctx)
+	repo.webhooks[0].Active = false
+
+	receiver.status = http.StatusNoContent
+	redelivery, err := svc.Redeliver(ctx, 1, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, redelivery.ID)
+	assert.Equal(t, 1, *redelivery.RedeliveryOf)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, redelivery.Status)
+	assert.Equal(t, http.StatusNoContent, redelivery.ResponseCode)
+	assert.Equal(t, "2", receiver.requests[1].Header.Get("X-Plena-Delivery"))
+	assert.Equal(t, receiver.bodies[0], receiver.bodies[1])
+	assert.Equal(t, 0, repo.webhooks[0].ConsecutiveFailures)
+	// Redelivering does not turn the webhook back on by itself.
+	assert.False(t, repo.webhooks[0].Active)
+
+	_, err = svc.Redeliver(ctx, 2, 1, 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	updated, err := svc.UpdateWebhook(ctx, 1, 1, domain.Webhook{URL: server.URL, Active: true})
+	assert.NoError(t, err)
+	assert.True(t, updated.Active)
+	assert.Nil(t, updated.DisabledAt)
+
+	// The original delivery was still pending and resumes.
+	*now = repo.deliveries[0].NextAttemptAt
+	svc.Deliver(ctx)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, repo.deliveries[0].Status)
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	svc, _, _, _, _ := newTestWebhookService(t, http.StatusOK)
+	ctx := context.Background()
+
+	_, err := svc.CreateWebhook(ctx, 1, domain.Webhook{URL: "ftp://example.com/hook"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = svc.CreateWebhook(ctx, 1, domain.Webhook{URL: "/relative"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = svc.CreateWebhook(ctx, 1, domain.Webhook{URL: "https://example.com/hook", EventTypes: []string{"transaction.exploded"}})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	created, err := svc.CreateWebhook(ctx, 1, domain.Webhook{URL: "https://example.com/hook"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, created.EventTypes)
+	assert.True(t, created.Active)
+}
+
+func TestWebhooks_RejectsNonPublicAddresses(t *testing.T) {
+	svc := NewWebhookService(&memoryWebhookRepository{})
+	svc.lookupIP = fakeLookupIP
+	ctx := context.Background()
+
+	blocked := []string{
+		"http://localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://127.8.9.10:8080/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hook",
+		"http://10.0.0.1/hook",
+		"http://172.16.5.4/hook",
+		"http://192.168.1.1/hook",
+		"http://[fd00::1]/hook",
+		"http://0.0.0.0/hook",
+		"http://[::]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://224.0.0.1/hook",
+		"https://mixed.example/hook",
+		"https://rebind.example/hook",
+	}
+	for _, u := range blocked {
+		_, err := svc.CreateWebhook(ctx, 1, domain.Webhook{URL: u})
+		assert.ErrorIs(t, err, ErrInvalidWebhook, u)
+		e, _ := domain.AsError(err)
+		assert.Equal(t, []domain.FieldError{{Field: "url", Message: "must not point to a private or local address"}}, e.Fields, u)
+	}
+
+	_, err := svc.CreateWebhook(ctx, 1, domain.Webhook{URL: "https://unknown.example/hook"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = svc.UpdateWebhook(ctx, 1, 1, domain.Webhook{URL: "http://10.0.0.1/hook"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+}
+
+func TestWebhooks_RefusesNonPublicAddressAtDialTime(t *testing.T) {
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	// A webhook saved while its host was public, now resolving to loopback.
+	repo := &memoryWebhookRepository{}
+	repo.Save(domain.Webhook{UserID: 1, URL: server.URL, Active: true})
+	svc := NewWebhookService(repo)
+	ctx := context.Background()
+
+	svc.HandleEvent(ctx, domain.Event{ID: 1, Type: domain.EventGoalCreated, UserID: 1})
+	svc.Deliver(ctx)
+
+	assert.Empty(t, receiver.requests)
+	assert.Contains(t, repo.deliveries[0].Error, "webhook address is not public")
+}
+
+func TestWebhooks_DoesNotFollowRedirects(t *testing.T) {
+	svc, repo, receiver, server, now := newTestWebhookService(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL+"/internal", http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+	ctx := context.Background()
+
+	svc.CreateWebhook(ctx, 1, domain.Webhook{URL: redirect.URL})
+	svc.HandleEvent(ctx, domain.Event{ID: 1, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now})
+	svc.Deliver(ctx)
+
+	assert.Empty(t, receiver.requests)
+	assert.Equal(t, http.StatusTemporaryRedirect, repo.deliveries[0].ResponseCode)
+	assert.Equal(t, domain.WebhookDeliveryPending, repo.deliveries[0].Status)
+}
+
+func TestWebhooks_SkipsDeliveriesClaimedElsewhere(t *testing.T) {
+	svc, repo, receiver, server, now := newTestWebhookService(t, http.StatusOK)
+	ctx := context.Background()
+	svc.CreateWebhook(ctx, 1, domain.Webhook{URL: server.URL})
+	svc.HandleEvent(ctx, domain.Event{ID: 1, Type: domain.EventGoalCreated, UserID: 1, OccurredAt: *now})
+
+	// Another instance claimed the delivery and has not finished with it.
+	repo.ClaimDueDeliveries(*now, webhookClaimLease, webhookBatchSize)
+	attempted, err := svc.Deliver(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+	assert.Empty(t, receiver.requests)
+
+	// Once its lease runs out the delivery is taken over.
+	*now = now.Add(webhookClaimLease)
+	attempted, _ = svc.Deliver(ctx)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, repo.deliveries[0].Status)
+}
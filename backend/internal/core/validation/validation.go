@@ -0,0 +1,182 @@
+// Package validation checks service inputs against declarative rules and
+// reports every field at fault at once, as a domain validation error.
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+// Rule checks a value and returns what is wrong with it, or "" when it is
+// valid.
+type Rule[T any] func(T) string
+
+// Check is the outcome of validating one field.
+type Check func() (domain.FieldError, bool)
+
+// Field validates value against rules in order and reports the first one it
+// breaks under name.
+func Field[T any](name string, value T, rules ...Rule[T]) Check {
+	return func() (domain.FieldError, bool) {
+		for _, rule := range rules {
+			if message := rule(value); message != "" {
+				return domain.FieldError{Field: name, Message: message}, false
+			}
+		}
+		return domain.FieldError{}, true
+	}
+}
+
+// When runs check only if cond holds.
+func When(cond bool, check Check) Check {
+	if !cond {
+		return func() (domain.FieldError, bool) { return domain.FieldError{}, true }
+	}
+	return check
+}
+
+// Validate runs every check and returns nil when all pass, or base carrying
+// the field errors of those that failed.
+func Validate(base *domain.Error, checks ...Check) error {
+	var fields []domain.FieldError
+	for _, check := range checks {
+		if field, ok := check(); !ok {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	err := *base
+	err.Fields = append(append([]domain.FieldError(nil), base.Fields...), fields...)
+	return &err
+}
+
+// Required rejects empty and blank strings.
+func Required(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// Present rejects the zero value, for fields such as dates that have no
+// blank form.
+func Present[T comparable](value T) string {
+	var zero T
+	if value == zero {
+		return "is required"
+	}
+	return ""
+}
+
+// MaxLength limits a string to n characters.
+func MaxLength(n int) Rule[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+func OneOf(allowed ...string) Rule[string] {
+	return func(value string) string {
+		if !slices.Contains(allowed, value) {
+			return "must be one of " + strings.Join(allowed, ", ")
+		}
+		return ""
+	}
+}
+
+// Email accepts a bare address, without a display name.
+func Email(value string) string {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || !strings.Contains(address.Address[strings.LastIndex(address.Address, "@"):], ".") {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// Positive rejects zero and negative values. Written as a negated
+// comparison, it and the bounds below also reject NaN.
+func Positive[T cmp.Ordered](value T) string {
+	var zero T
+	if !(value > zero) {
+		return "must be positive"
+	}
+	return ""
+}
+
+// Min rejects values below limit.
+func Min[T cmp.Ordered](limit T) Rule[T] {
+	return func(value T) string {
+		if !(value >= limit) {
+			return fmt.Sprintf("must be at least %v", limit)
+		}
+		return ""
+	}
+}
+
+// Max rejects values above limit.
+func Max[T cmp.Ordered](limit T) Rule[T] {
+	return func(value T) string {
+		if !(value <= limit) {
+			if f, ok := any(limit).(float64); ok {
+				return "must be at most " + strconv.FormatFloat(f, 'f', -1, 64)
+			}
+			return fmt.Sprintf("must be at most %v", limit)
+		}
+		return ""
+	}
+}
+
+// NotBefore rejects dates before day; the zero time, for no date, passes.
+func NotBefore(day time.Time) Rule[time.Time] {
+	return func(value time.Time) string {
+		if !value.IsZero() && value.Before(day) {
+			return "must not be before " + day.Format("2006-01-02")
+		}
+		return ""
+	}
+}
+
+// PasswordPolicy is what a new password must have. MaxBytes should stay
+// at most 72, the most bcrypt hashes.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxBytes      int
+	RequireLetter bool
+	RequireDigit  bool
+}
+
+// DefaultPasswordPolicy asks for 8 characters with a letter and a digit.
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, MaxBytes: 72, RequireLetter: true, RequireDigit: true}
+
+// Password checks a new password against policy.
+func Password(policy PasswordPolicy) Rule[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) < policy.MinLength {
+			return fmt.Sprintf("must be at least %d characters", policy.MinLength)
+		}
+		if policy.MaxBytes > 0 && len(value) > policy.MaxBytes {
+			return fmt.Sprintf("must be at most %d bytes", policy.MaxBytes)
+		}
+		if policy.RequireLetter && !strings.ContainsFunc(value, unicode.IsLetter) {
+			return "must contain a letter"
+		}
+		if policy.RequireDigit && !strings.ContainsFunc(value, unicode.IsDigit) {
+			return "must contain a digit"
+		}
+		return ""
+	}
+}
@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/larissasthefanny/plena-app/backend/internal/core/domain"
+)
+
+var errInvalidThing = domain.Invalid("invalid_thing", "thing is invalid")
+
+func TestValidate_AggregatesFieldErrors(t *testing.T) {
+	err := Validate(errInvalidThing,
+		Field("name", "  ", Required, MaxLength(3)),
+		Field("type", "transfer", OneOf("income", "expense")),
+		Field("amount", 10.0, Positive),
+		Field("id", 0, Positive),
+		Field("amount_cap", 1e9, Positive, Max(99999999.99)),
+	)
+
+	var e *domain.Error
+	if assert.True(t, errors.As(err, &e)) {
+		assert.Equal(t, []domain.FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "type", Message: "must be one of income, expense"},
+			{Field: "id", Message: "must be positive"},
+			{Field: "amount_cap", Message: "must be at most 99999999.99"},
+		}, e.Fields)
+	}
+	assert.ErrorIs(t, err, errInvalidThing)
+	assert.Empty(t, errInvalidThing.Fields)
+}
+
+func TestValidate_PassesValidInput(t *testing.T) {
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	err := Validate(errInvalidThing,
+		Field("email", "ana@example.com", Required, Email),
+		Field("deadline", today, NotBefore(today)),
+		Field("no_deadline", time.Time{}, NotBefore(today)),
+		When(false, Field("skipped", "", Required)),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestBounds_RejectNaN(t *testing.T) {
+	nan := math.NaN()
+
+	assert.Equal(t, "must be positive", Positive(nan))
+	assert.NotEmpty(t, Min(0.0)(nan))
+	assert.NotEmpty(t, Max(10.0)(nan))
+	assert.NotEmpty(t, Max(10.0)(math.Inf(1)))
+}
+
+func TestPresent(t *testing.T) {
+	assert.Equal(t, "is required", Present(time.Time{}))
+	assert.Empty(t, Present(time.Now()))
+}
+
+func TestEmail(t *testing.T) {
+	assert.Empty(t, Email("ana@example.com"))
+	assert.NotEmpty(t, Email("ana"))
+	assert.NotEmpty(t, Email("ana@localhost"))
+	assert.NotEmpty(t, Email("Ana <ana@example.com>"))
+}
+
+func TestPassword(t *testing.T) {
+	rule := Password(DefaultPasswordPolicy)
+
+	assert.Equal(t, "must be at least 8 characters", rule("a1"))
+	assert.Equal(t, "must contain a digit", rule("abcdefgh"))
+	assert.Equal(t, "must contain a letter", rule("12345678"))
+	assert.Equal(t, "must be at most 72 bytes", rule("a1"+string(make([]byte, 80))))
+	assert.Empty(t, rule("plena2025"))
+	assert.Empty(t, Password(PasswordPolicy{MinLength: 4})("abcd"))
+}
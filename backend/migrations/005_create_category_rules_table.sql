@@ -0,0 +1,23 @@
+-- Create category rules table
+CREATE TABLE IF NOT EXISTS category_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 100,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description_contains TEXT,
+    description_regex TEXT,
+    min_amount DECIMAL(10, 2),
+    max_amount DECIMAL(10, 2),
+    account TEXT,
+    transaction_type TEXT,
+    day_of_month_from INTEGER,
+    day_of_month_to INTEGER,
+    set_category TEXT,
+    set_tags TEXT[] DEFAULT '{}',
+    set_bucket TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for faster queries
+CREATE INDEX IF NOT EXISTS idx_category_rules_user_id ON category_rules(user_id, priority);
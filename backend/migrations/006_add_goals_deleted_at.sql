@@ -0,0 +1,5 @@
+-- Soft delete support for the trash
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Create index for trash listing and purging
+CREATE INDEX IF NOT EXISTS idx_goals_deleted_at ON goals(deleted_at) WHERE deleted_at IS NOT NULL;
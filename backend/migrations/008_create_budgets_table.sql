@@ -0,0 +1,29 @@
+-- Create category budgets table
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    month INTEGER NOT NULL CHECK (month BETWEEN 1 AND 12),
+    year INTEGER NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, category, year, month)
+);
+
+-- Create index for faster queries
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets(user_id);
+
+-- Threshold alerts already sent, so each one fires only once per month
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(255) NOT NULL,
+    month INTEGER NOT NULL,
+    year INTEGER NOT NULL,
+    threshold INTEGER NOT NULL,
+    spent DECIMAL(10, 2) NOT NULL,
+    available DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, category, year, month, threshold)
+);
@@ -0,0 +1,33 @@
+-- Zero-based budgeting opt-in per user
+CREATE TABLE IF NOT EXISTS envelope_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    start_month INTEGER NOT NULL,
+    start_year INTEGER NOT NULL
+);
+
+-- Create envelopes table
+CREATE TABLE IF NOT EXISTS envelopes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(255),
+    goal_id INTEGER REFERENCES goals(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_envelopes_user_id ON envelopes(user_id);
+
+-- Assignments and moves between envelopes; NULL is the "to be assigned" pool
+CREATE TABLE IF NOT EXISTS envelope_transfers (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_envelope_id INTEGER REFERENCES envelopes(id),
+    to_envelope_id INTEGER REFERENCES envelopes(id),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_envelope_transfers_user_id ON envelope_transfers(user_id, created_at);
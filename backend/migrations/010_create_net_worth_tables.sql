@@ -0,0 +1,38 @@
+-- Assets and liabilities tracked for net worth
+CREATE TABLE IF NOT EXISTS assets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('asset', 'liability')),
+    category VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_assets_user_id ON assets(user_id);
+
+-- Dated valuations; the latest one on or before a day is the asset's value
+CREATE TABLE IF NOT EXISTS asset_valuations (
+    id SERIAL PRIMARY KEY,
+    asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value DECIMAL(14, 2) NOT NULL CHECK (value >= 0),
+    date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_valuations_asset_id ON asset_valuations(asset_id, date);
+
+-- Monthly net worth written by the snapshot job
+CREATE TABLE IF NOT EXISTS net_worth_snapshots (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL,
+    month INTEGER NOT NULL CHECK (month BETWEEN 1 AND 12),
+    accounts DECIMAL(14, 2) NOT NULL,
+    goals DECIMAL(14, 2) NOT NULL,
+    assets DECIMAL(14, 2) NOT NULL,
+    liabilities DECIMAL(14, 2) NOT NULL,
+    net_worth DECIMAL(14, 2) NOT NULL,
+    taken_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, year, month)
+);
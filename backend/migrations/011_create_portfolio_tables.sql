@@ -0,0 +1,37 @@
+-- Investment holdings, one per ticker
+CREATE TABLE IF NOT EXISTS holdings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ticker VARCHAR(64) NOT NULL,
+    name VARCHAR(255),
+    asset_class VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, ticker)
+);
+
+-- Buys, sells and dividends
+CREATE TABLE IF NOT EXISTS investment_operations (
+    id SERIAL PRIMARY KEY,
+    holding_id INTEGER NOT NULL REFERENCES holdings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('buy', 'sell', 'dividend')),
+    quantity DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    price DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    amount DECIMAL(14, 2) NOT NULL,
+    fees DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (fees >= 0),
+    date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_investment_operations_user_id ON investment_operations(user_id, date);
+
+-- Unit prices entered manually or imported from CSV
+CREATE TABLE IF NOT EXISTS price_snapshots (
+    id SERIAL PRIMARY KEY,
+    holding_id INTEGER NOT NULL REFERENCES holdings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    price DECIMAL(18, 8) NOT NULL CHECK (price >= 0),
+    date DATE NOT NULL,
+    source VARCHAR(16) NOT NULL DEFAULT 'manual',
+    UNIQUE (holding_id, date)
+);
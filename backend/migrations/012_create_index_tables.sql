@@ -0,0 +1,27 @@
+-- CDI, Selic (daily) and IPCA (monthly) rates in percent per period
+CREATE TABLE IF NOT EXISTS index_rates (
+    index VARCHAR(16) NOT NULL,
+    date DATE NOT NULL,
+    rate DECIMAL(12, 8) NOT NULL,
+    PRIMARY KEY (index, date)
+);
+
+-- How each goal's money is invested, e.g. 110% of CDI
+CREATE TABLE IF NOT EXISTS goal_investments (
+    goal_id INTEGER PRIMARY KEY REFERENCES goals(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    index VARCHAR(16) NOT NULL,
+    percent DECIMAL(8, 2) NOT NULL CHECK (percent > 0),
+    spread DECIMAL(8, 4) NOT NULL DEFAULT 0
+);
+
+-- Dated contributions so yield accrues from when money went in
+CREATE TABLE IF NOT EXISTS goal_contributions (
+    id SERIAL PRIMARY KEY,
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_contributions_goal_id ON goal_contributions(goal_id);
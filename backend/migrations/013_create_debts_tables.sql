@@ -0,0 +1,26 @@
+-- Loans and financing repaid in monthly installments (SAC or Price)
+CREATE TABLE IF NOT EXISTS debts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    principal DECIMAL(14, 2) NOT NULL CHECK (principal > 0),
+    interest_rate DECIMAL(8, 4) NOT NULL CHECK (interest_rate >= 0),
+    amortization_system VARCHAR(8) NOT NULL CHECK (amortization_system IN ('sac', 'price')),
+    installments INTEGER NOT NULL CHECK (installments > 0),
+    first_due_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_debts_user_id ON debts(user_id);
+
+-- Expense transactions that paid an installment
+CREATE TABLE IF NOT EXISTS debt_payments (
+    id SERIAL PRIMARY KEY,
+    debt_id INTEGER NOT NULL REFERENCES debts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    installment INTEGER NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (debt_id, installment),
+    UNIQUE (transaction_id)
+);
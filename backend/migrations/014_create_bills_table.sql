@@ -0,0 +1,21 @@
+-- Boletos and utility bills, one row per occurrence
+CREATE TABLE IF NOT EXISTS bills (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payee VARCHAR(255) NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount >= 0),
+    estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    category VARCHAR(255),
+    account VARCHAR(255),
+    due_date DATE NOT NULL,
+    due_day INTEGER NOT NULL CHECK (due_day BETWEEN 1 AND 31),
+    recurrence VARCHAR(16) NOT NULL DEFAULT 'none' CHECK (recurrence IN ('none', 'weekly', 'monthly', 'yearly')),
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'overdue')),
+    paid_amount DECIMAL(14, 2),
+    paid_at TIMESTAMP,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bills_user_id ON bills(user_id, due_date);
+CREATE INDEX IF NOT EXISTS idx_bills_open ON bills(due_date) WHERE status <> 'paid';
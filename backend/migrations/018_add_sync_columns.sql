@@ -0,0 +1,64 @@
+-- Offline sync: client-generated ids, row versions and change tracking for
+-- transactions and goals. change_txid is the database transaction that last
+-- wrote the row; sync tokens are transaction-id horizons, so a change is
+-- only handed out once every transaction before it has finished.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS client_id UUID;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_txid BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS client_id UUID;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS change_txid BIGINT NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_client_id ON transactions(user_id, client_id) WHERE client_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_goals_client_id ON goals(user_id, client_id) WHERE client_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_change_txid ON transactions(user_id, change_txid);
+CREATE INDEX IF NOT EXISTS idx_goals_change_txid ON goals(user_id, change_txid);
+
+-- Rows removed for good (trash purge) leave a tombstone for clients to sync.
+-- No foreign key on user_id: deleting a user cascades into its rows, whose
+-- tombstones are written while the user row is going away.
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    client_id UUID,
+    change_txid BIGINT NOT NULL,
+    deleted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_tombstones_user ON sync_tombstones(user_id, change_txid);
+
+CREATE OR REPLACE FUNCTION sync_touch() RETURNS trigger AS $$
+BEGIN
+    NEW.change_txid := txid_current();
+    IF TG_OP = 'UPDATE' THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sync_tombstone() RETURNS trigger AS $$
+BEGIN
+    IF OLD.user_id IS NOT NULL THEN
+        INSERT INTO sync_tombstones (entity_type, entity_id, user_id, client_id, change_txid)
+        VALUES (TG_ARGV[0], OLD.id, OLD.user_id, OLD.client_id, txid_current());
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transactions_sync_touch ON transactions;
+CREATE TRIGGER transactions_sync_touch BEFORE INSERT OR UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION sync_touch();
+DROP TRIGGER IF EXISTS transactions_sync_tombstone ON transactions;
+CREATE TRIGGER transactions_sync_tombstone AFTER DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION sync_tombstone('transaction');
+
+DROP TRIGGER IF EXISTS goals_sync_touch ON goals;
+CREATE TRIGGER goals_sync_touch BEFORE INSERT OR UPDATE ON goals
+    FOR EACH ROW EXECUTE FUNCTION sync_touch();
+DROP TRIGGER IF EXISTS goals_sync_tombstone ON goals;
+CREATE TRIGGER goals_sync_tombstone AFTER DELETE ON goals
+    FOR EACH ROW EXECUTE FUNCTION sync_tombstone('goal');
@@ -0,0 +1,4 @@
+-- Account, tags and bucket set on transactions by categorization rules
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags TEXT[] DEFAULT '{}';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS bucket TEXT;
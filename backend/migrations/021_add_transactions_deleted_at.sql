@@ -0,0 +1,7 @@
+-- Soft delete support for the trash; delete_batch groups the transactions
+-- removed together by a data reset, so the reset can be undone
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS delete_batch TEXT;
+
+-- Create index for trash listing and purging
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Per user, month, type and category sum and count of active (not trashed)
+-- transactions; the transaction repository keeps it current on every write
+CREATE TABLE IF NOT EXISTS monthly_category_totals (
+    user_id INTEGER NOT NULL,
+    year INTEGER NOT NULL,
+    month INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    total DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, year, month, type, category)
+);
+
+-- Backfill from the existing transactions when the table is still empty
+INSERT INTO monthly_category_totals (user_id, year, month, type, category, total, count)
+SELECT user_id, EXTRACT(YEAR FROM date)::int, EXTRACT(MONTH FROM date)::int, type, COALESCE(category, ''), SUM(amount), COUNT(*)
+FROM transactions
+WHERE deleted_at IS NULL AND user_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM monthly_category_totals)
+GROUP BY 1, 2, 3, 4, 5;